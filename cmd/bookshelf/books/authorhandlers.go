@@ -35,8 +35,17 @@ func (m *Module) PostAuthorHandler(w http.ResponseWriter, r *http.Request) {
 	}
 	logger.Info("author created", "id", authorID)
 
+	createdAuthor, err := types.ReadAuthor(ctx, &m.models, *authorID)
+	if err != nil {
+		logger.Error("unable to read created author", "id", authorID, "error", err)
+		rest.ServerErrorResponse(w, r, err)
+		return
+	}
+
 	logger.Info("writing response")
-	rest.Respond(w, r, http.StatusCreated, nil, nil)
+	headers := rest.ETagHeader(createdAuthor.UpdatedAt)
+	headers.Set("Location", "/api/v1/books/authors/"+authorID.String())
+	rest.Respond(w, r, http.StatusCreated, createdAuthor, headers)
 }
 
 func (m *Module) GetAuthorHandler(w http.ResponseWriter, r *http.Request) {
@@ -66,8 +75,14 @@ func (m *Module) GetAuthorHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	tag := rest.ETag(author.UpdatedAt)
+	if rest.NotModified(r, tag) {
+		rest.NotModifiedResponse(w, r, tag)
+		return
+	}
+
 	logger.Info("writing response")
-	rest.Respond(w, r, http.StatusOK, author, nil)
+	rest.Respond(w, r, http.StatusOK, author, rest.ETagHeader(author.UpdatedAt))
 }
 
 func (m *Module) ListAuthorHandler(w http.ResponseWriter, r *http.Request) {
@@ -137,22 +152,39 @@ func (m *Module) PatchAuthorHandler(w http.ResponseWriter, r *http.Request) {
 	}
 	logger.Info("ID parsed", slog.String("id", id.String()))
 
+	logger.Info("reading version")
+	version, err := rest.ReadIfMatch(r)
+	if err != nil {
+		logger.Info("unable to read version", "error", err)
+		rest.PreconditionErrorResponse(w, r, err)
+		return
+	}
+
 	logger.Info("parsing request body")
-	var updateData types.Author
-	err = rest.ReadJSON(r, &updateData)
+	var patch data.AuthorPatch
+	err = rest.ReadJSON(r, &patch)
 	if err != nil {
 		logger.Info("unable to read request body", "error", err)
 		rest.BadRequestResponse(w, r, fmt.Sprintf("unable to read request body: %s\n", err))
 		return
 	}
-	updateData.ID = *id
 
-	updatedAuthor, err := types.UpdateAuthor(ctx, &m.models, updateData)
+	v := validator.New()
+	if data.ValidateAuthorPatch(v, patch); !v.Valid() {
+		logger.Info("patch validation failed", "validationErrors", v.Errors)
+		rest.FailedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	updatedAuthor, err := types.UpdateAuthor(ctx, &m.models, *id, patch, version)
 	if err != nil {
 		switch {
 		case errors.Is(err, data.ErrRecordNotFound):
 			logger.Info("author not found", "id", id)
 			rest.NotFoundResponse(w, r)
+		case errors.Is(err, data.ErrEditConflict):
+			logger.Info("author changed since version", "id", id, "version", version)
+			rest.PreconditionFailedResponse(w, r)
 		default:
 			logger.Error("unable to get author", "id", id, "error", err)
 			rest.ServerErrorResponse(w, r, err)
@@ -161,7 +193,7 @@ func (m *Module) PatchAuthorHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	logger.Info("writing response")
-	rest.Respond(w, r, http.StatusOK, updatedAuthor, nil)
+	rest.Respond(w, r, http.StatusOK, updatedAuthor, rest.ETagHeader(updatedAuthor.UpdatedAt))
 }
 
 func (m *Module) DeleteAuthorHandler(w http.ResponseWriter, r *http.Request) {
@@ -177,12 +209,23 @@ func (m *Module) DeleteAuthorHandler(w http.ResponseWriter, r *http.Request) {
 	}
 	logger.Info("ID parsed", slog.String("id", id.String()))
 
+	logger.Info("reading version")
+	version, err := rest.ReadIfMatch(r)
+	if err != nil {
+		logger.Info("unable to read version", "error", err)
+		rest.PreconditionErrorResponse(w, r, err)
+		return
+	}
+
 	logger.Info("deleting author", "id", id)
-	if err := types.DeleteAuthor(ctx, &m.models, *id); err != nil {
+	if err := types.DeleteAuthor(ctx, &m.models, *id, version); err != nil {
 		switch {
 		case errors.Is(err, data.ErrRecordNotFound):
 			logger.Info("author not found", "id", id)
 			rest.NotFoundResponse(w, r)
+		case errors.Is(err, data.ErrEditConflict):
+			logger.Info("author changed since version", "id", id, "version", version)
+			rest.PreconditionFailedResponse(w, r)
 		default:
 			logger.Error("unable to delete author", "id", id, "error", err)
 			rest.ServerErrorResponse(w, r, err)
@@ -60,6 +60,9 @@ func TestAuthorHandlers(t *testing.T) {
 		}
 	})
 
+	// etag is the ETag of the latest version of the author read or written by the handlers
+	var etag string
+
 	t.Run("TestGetAuthorHandler", func(t *testing.T) {
 		getReq := httptest.NewRequest(
 			http.MethodGet,
@@ -82,6 +85,27 @@ func TestAuthorHandlers(t *testing.T) {
 			)
 			return
 		}
+		etag = rr.Header().Get("ETag")
+	})
+
+	t.Run("TestGetAuthorHandlerNotModified", func(t *testing.T) {
+		getReq := httptest.NewRequest(http.MethodGet, "/api/v1/bookshelf/books/authors", nil)
+		getReq.SetPathValue("id", id.String())
+		getReq.Header.Set("If-None-Match", etag)
+
+		rr := httptest.NewRecorder()
+
+		handler := http.HandlerFunc(mod.GetAuthorHandler)
+		handler.ServeHTTP(rr, getReq)
+
+		if status := rr.Code; status != http.StatusNotModified {
+			t.Errorf(
+				"handler returned the wrong error code: got %d, expected %d\n",
+				status,
+				http.StatusNotModified,
+			)
+			return
+		}
 	})
 
 	t.Run("TestListAuthorHandler", func(t *testing.T) {
@@ -122,7 +146,7 @@ func TestAuthorHandlers(t *testing.T) {
 		updateData := types.Author{
 			Description: &newDescription,
 		}
-		reqBody, err := json.Marshal(updateData)
+		reqBody, err := json.Marshal(updateData.Patch())
 		if err != nil {
 			t.Errorf("unable to marshal data: %v\n", updateData)
 			return
@@ -135,6 +159,7 @@ func TestAuthorHandlers(t *testing.T) {
 		)
 		patchReq.Header.Set("Content-Type", "application/json")
 		patchReq.SetPathValue("id", id.String())
+		patchReq.Header.Set("If-Match", etag)
 
 		rr := httptest.NewRecorder()
 
@@ -149,6 +174,96 @@ func TestAuthorHandlers(t *testing.T) {
 			)
 			return
 		}
+		etag = rr.Header().Get("ETag")
+	})
+
+	t.Run("TestPatchAuthorHandlerMergePatch", func(t *testing.T) {
+		for _, tc := range []struct {
+			body     string
+			expected int
+		}{
+			{`{"description": null}`, http.StatusOK},
+			{`{"name": null}`, http.StatusUnprocessableEntity},
+			{`{"id": "` + id.String() + `"}`, http.StatusBadRequest},
+		} {
+			patchReq := httptest.NewRequest(
+				http.MethodPatch,
+				"/api/v1/bookshelf/books/authors",
+				strings.NewReader(tc.body),
+			)
+			patchReq.Header.Set("Content-Type", "application/json")
+			patchReq.SetPathValue("id", id.String())
+			patchReq.Header.Set("If-Match", "*")
+
+			rr := httptest.NewRecorder()
+
+			handler := http.HandlerFunc(mod.PatchAuthorHandler)
+			handler.ServeHTTP(rr, patchReq)
+
+			if status := rr.Code; status != tc.expected {
+				t.Errorf(
+					"handler returned wrong error code for %s: got %d, expected %d",
+					tc.body,
+					status,
+					tc.expected,
+				)
+				return
+			}
+			if tc.expected != http.StatusOK {
+				continue
+			}
+
+			var updated types.Author
+			if err := json.Unmarshal(rr.Body.Bytes(), &updated); err != nil {
+				t.Errorf("unable to unmarshal response: %v\n", err)
+				return
+			}
+			if updated.Description != nil {
+				t.Errorf("expected the description to be cleared, got %s", *updated.Description)
+				return
+			}
+			if updated.Name == nil {
+				t.Error("expected the name to be unchanged, got none")
+				return
+			}
+			etag = rr.Header().Get("ETag")
+		}
+	})
+
+	t.Run("TestPatchAuthorHandlerPreconditions", func(t *testing.T) {
+		for _, tc := range []struct {
+			ifMatch  string
+			expected int
+		}{
+			{"", http.StatusPreconditionRequired},
+			{`"0"`, http.StatusPreconditionFailed},
+		} {
+			patchReq := httptest.NewRequest(
+				http.MethodPatch,
+				"/api/v1/bookshelf/books/authors",
+				strings.NewReader(`{"description": "A lost update"}`),
+			)
+			patchReq.Header.Set("Content-Type", "application/json")
+			patchReq.SetPathValue("id", id.String())
+			if tc.ifMatch != "" {
+				patchReq.Header.Set("If-Match", tc.ifMatch)
+			}
+
+			rr := httptest.NewRecorder()
+
+			handler := http.HandlerFunc(mod.PatchAuthorHandler)
+			handler.ServeHTTP(rr, patchReq)
+
+			if status := rr.Code; status != tc.expected {
+				t.Errorf(
+					"handler returned wrong error code for If-Match %q: got %d, expected %d",
+					tc.ifMatch,
+					status,
+					tc.expected,
+				)
+				return
+			}
+		}
 	})
 
 	t.Run("TestDeleteAuthorHandler", func(t *testing.T) {
@@ -159,6 +274,7 @@ func TestAuthorHandlers(t *testing.T) {
 		)
 		deleteReq.Header.Set("Content-Type", "application/json")
 		deleteReq.SetPathValue("id", id.String())
+		deleteReq.Header.Set("If-Match", etag)
 
 		rr := httptest.NewRecorder()
 
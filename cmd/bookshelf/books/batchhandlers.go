@@ -0,0 +1,500 @@
+package books
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/r3d5un/Bookshelf/internal/books/data"
+	"github.com/r3d5un/Bookshelf/internal/books/types"
+	"github.com/r3d5un/Bookshelf/internal/logging"
+	"github.com/r3d5un/Bookshelf/internal/rest"
+	"github.com/r3d5un/Bookshelf/internal/validator"
+)
+
+// maxBatchOperations is the largest number of operations accepted in a single batch.
+const maxBatchOperations = 1_000
+
+// batchResources are the resources a batch operation may change.
+var batchResources = []string{"books", "authors", "series", "genres"}
+
+// batchOperation is a single change to the catalog made by a batch. IDs of records created earlier
+// in the same batch are referred to by the ref of their create operation prefixed with $, e.g.
+// "$fantasy".
+type batchOperation struct {
+	// Op is one of create, update, delete or link
+	Op string `json:"op"`
+	// Resource is one of books, authors, series or genres
+	Resource string `json:"resource"`
+	// Ref names the record created by a create operation
+	Ref string `json:"ref,omitempty"`
+	// ID is the record to update, delete, or link the book to
+	ID string `json:"id,omitempty"`
+	// Book is the book to link to the record
+	Book string `json:"book,omitempty"`
+	// Order is the position of the book in the series it is linked to
+	Order float32 `json:"order,omitempty"`
+	// IfMatch is the entity tag of the version an update or delete is based on, as in If-Match
+	IfMatch string `json:"ifMatch,omitempty"`
+	// Data is the new record of a create, and the JSON Merge Patch of an update
+	Data json.RawMessage `json:"data,omitempty"`
+}
+
+// batchResult is the outcome of a single operation of a batch, with the status code and body the
+// operation would have been answered with on its own.
+type batchResult struct {
+	Index    int        `json:"index"`
+	Op       string     `json:"op"`
+	Resource string     `json:"resource"`
+	Ref      string     `json:"ref,omitempty"`
+	ID       *uuid.UUID `json:"id,omitempty"`
+	Status   int        `json:"status"`
+	ETag     string     `json:"etag,omitempty"`
+	Data     any        `json:"data,omitempty"`
+	Message  any        `json:"message,omitempty"`
+}
+
+// BatchHandler applies a list of create, update, delete and link operations to the catalog, in
+// order. Each operation is applied on its own, so that a failing operation leaves the ones before
+// and after it in place, and the outcome of every operation is reported in the response.
+// Operations referring to a record whose create operation failed fail with 424 Failed
+// Dependency.
+func (m *Module) BatchHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := logging.LoggerFromContext(ctx)
+
+	logger.Info("parsing request body")
+	var input struct {
+		Operations []batchOperation `json:"operations"`
+	}
+	err := rest.ReadJSON(r, &input)
+	if err != nil {
+		logger.Info("unable to read request body", "error", err)
+		rest.BadRequestResponse(w, r, fmt.Sprintf("unable to read request body: %s\n", err))
+		return
+	}
+
+	v := validator.New()
+	if validateBatch(v, input.Operations); !v.Valid() {
+		logger.Info("batch validation failed", "validationErrors", v.Errors)
+		rest.FailedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	logger.Info("applying batch", "operations", len(input.Operations))
+	refs := make(map[string]*uuid.UUID)
+	results := make([]batchResult, len(input.Operations))
+	for i, op := range input.Operations {
+		results[i] = m.applyBatchOperation(ctx, op, refs)
+		results[i].Index = i
+		if op.Op == "create" && op.Ref != "" {
+			refs[op.Ref] = results[i].ID
+		}
+	}
+	logger.Info("batch applied")
+
+	logger.Info("writing response")
+	rest.Respond(w, r, http.StatusOK, map[string][]batchResult{"results": results}, nil)
+}
+
+// validateBatch checks the shape of the operations before any of them is applied, including that
+// every ref is defined once, before it is used.
+func validateBatch(v *validator.Validator, ops []batchOperation) {
+	v.Check(len(ops) > 0, "operations", "must not be empty")
+	v.Check(
+		len(ops) <= maxBatchOperations,
+		"operations",
+		fmt.Sprintf("must not hold more than %d operations", maxBatchOperations),
+	)
+
+	refs := make(map[string]bool)
+	checkID := func(key string, id string) {
+		if id == "" {
+			v.AddError(key, "must be provided")
+			return
+		}
+		if ref, ok := strings.CutPrefix(id, "$"); ok {
+			v.Check(refs[ref], key, "must refer to a record created earlier in the batch")
+			return
+		}
+		_, err := uuid.Parse(id)
+		v.Check(err == nil, key, "must be a UUID, or a ref prefixed with $")
+	}
+
+	for i, op := range ops {
+		key := fmt.Sprintf("operations[%d]", i)
+		v.Check(
+			slices.Contains(batchResources, op.Resource),
+			key+".resource",
+			"must be one of books, authors, series or genres",
+		)
+
+		switch op.Op {
+		case "create":
+			v.Check(len(op.Data) > 0, key+".data", "must be provided")
+			if op.Ref != "" {
+				v.Check(!refs[op.Ref], key+".ref", "must be unique within the batch")
+				refs[op.Ref] = true
+			}
+		case "update":
+			checkID(key+".id", op.ID)
+			v.Check(len(op.Data) > 0, key+".data", "must be provided")
+		case "delete":
+			checkID(key+".id", op.ID)
+		case "link":
+			v.Check(op.Resource != "books", key+".resource", "must be authors, series or genres")
+			checkID(key+".id", op.ID)
+			checkID(key+".book", op.Book)
+		default:
+			v.AddError(key+".op", "must be one of create, update, delete or link")
+		}
+	}
+}
+
+// applyBatchOperation applies a single operation of a batch, resolving refs to the IDs of the
+// records created earlier in the batch.
+func (m *Module) applyBatchOperation(
+	ctx context.Context,
+	op batchOperation,
+	refs map[string]*uuid.UUID,
+) batchResult {
+	logger := logging.LoggerFromContext(ctx).With("op", op.Op, "resource", op.Resource)
+	ctx = context.WithValue(ctx, logging.LoggerKey, logger)
+
+	result := batchResult{Op: op.Op, Resource: op.Resource, Ref: op.Ref}
+	fail := func(status int, message any) batchResult {
+		result.Status = status
+		result.Message = message
+		return result
+	}
+
+	var id, bookID uuid.UUID
+	if op.ID != "" {
+		resolved, ok := resolveBatchID(op.ID, refs)
+		if !ok {
+			return fail(http.StatusFailedDependency, "the referenced record was not created")
+		}
+		id = resolved
+		result.ID = &id
+	}
+	if op.Book != "" {
+		resolved, ok := resolveBatchID(op.Book, refs)
+		if !ok {
+			return fail(http.StatusFailedDependency, "the referenced book was not created")
+		}
+		bookID = resolved
+	}
+
+	var version *time.Time
+	if op.Op == "update" || op.Op == "delete" {
+		var err error
+		version, err = rest.ParseIfMatch(op.IfMatch)
+		if err != nil {
+			if errors.Is(err, rest.ErrNoPrecondition) {
+				return fail(http.StatusPreconditionRequired, "ifMatch must hold the ETag of the record")
+			}
+			return fail(http.StatusPreconditionFailed, err.Error())
+		}
+	}
+
+	var (
+		record    any
+		updatedAt *time.Time
+		status    int
+		err       error
+	)
+	switch op.Op {
+	case "create":
+		status = http.StatusCreated
+		var createdID *uuid.UUID
+		createdID, record, updatedAt, err = m.batchCreate(ctx, op.Resource, op.Data)
+		result.ID = createdID
+	case "update":
+		status = http.StatusOK
+		record, updatedAt, err = m.batchUpdate(ctx, op.Resource, id, op.Data, version)
+	case "delete":
+		status = http.StatusNoContent
+		err = m.batchDelete(ctx, op.Resource, id, version)
+	case "link":
+		status = http.StatusCreated
+		err = m.batchLink(ctx, op.Resource, bookID, id, op.Order)
+	}
+
+	var validationErr batchValidationError
+	switch {
+	case err == nil:
+	case errors.As(err, &validationErr):
+		logger.Info("operation validation failed", "validationErrors", validationErr.errors)
+		return fail(http.StatusUnprocessableEntity, validationErr.errors)
+	case errors.Is(err, errBatchData):
+		logger.Info("unable to read operation data", "error", err)
+		return fail(http.StatusBadRequest, err.Error())
+	case errors.Is(err, data.ErrRecordNotFound):
+		logger.Info("record not found", "id", id, "book", bookID)
+		return fail(http.StatusNotFound, "the requested resource could not be found")
+	case errors.Is(err, data.ErrEditConflict):
+		logger.Info("record changed since version", "id", id, "version", version)
+		return fail(
+			http.StatusPreconditionFailed,
+			"the resource has been changed since it was read; read it again and retry",
+		)
+	case errors.Is(err, data.ErrDuplicateRecord):
+		logger.Info("duplicate record", "id", id, "book", bookID)
+		return fail(http.StatusConflict, "the record already exists")
+	default:
+		logger.Error("unable to apply operation", "error", err)
+		return fail(
+			http.StatusInternalServerError,
+			fmt.Sprintf("the server encountered a problem and could not process the operation: %s", err),
+		)
+	}
+
+	result.Status = status
+	result.Data = record
+	result.ETag = rest.ETag(updatedAt)
+	return result
+}
+
+// resolveBatchID returns the ID, or the ID of the record created by the ref prefixed with $. The
+// ID is not resolved if the create operation of the ref failed.
+func resolveBatchID(id string, refs map[string]*uuid.UUID) (uuid.UUID, bool) {
+	if ref, ok := strings.CutPrefix(id, "$"); ok {
+		resolved := refs[ref]
+		if resolved == nil {
+			return uuid.UUID{}, false
+		}
+		return *resolved, true
+	}
+	// The ID has been validated by validateBatch
+	return uuid.MustParse(id), true
+}
+
+// errBatchData is returned when the data of an operation cannot be read.
+var errBatchData = errors.New("unable to read operation data")
+
+// batchValidationError is returned when the data of an operation fails validation.
+type batchValidationError struct {
+	errors map[string]string
+}
+
+func (e batchValidationError) Error() string {
+	return fmt.Sprintf("validation failed: %v", e.errors)
+}
+
+// decodeBatchData reads the data of an operation as ReadJSON reads a request body.
+func decodeBatchData(raw json.RawMessage, dst any) error {
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(dst); err != nil {
+		return fmt.Errorf("%w: %s", errBatchData, err)
+	}
+	return nil
+}
+
+func (m *Module) batchCreate(
+	ctx context.Context,
+	resource string,
+	raw json.RawMessage,
+) (*uuid.UUID, any, *time.Time, error) {
+	v := validator.New()
+
+	switch resource {
+	case "books":
+		var newBook types.Book
+		if err := decodeBatchData(raw, &newBook); err != nil {
+			return nil, nil, nil, err
+		}
+		v.Check(newBook.Title != nil && *newBook.Title != "", "title", "must be provided")
+		if !v.Valid() {
+			return nil, nil, nil, batchValidationError{v.Errors}
+		}
+		id, err := types.CreateBook(ctx, &m.models, m.events, newBook)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		book, err := types.ReadBook(ctx, &m.models, *id)
+		if err != nil {
+			return id, nil, nil, err
+		}
+		return id, book, book.UpdatedAt, nil
+	case "authors":
+		var newAuthor types.NewAuthorData
+		if err := decodeBatchData(raw, &newAuthor); err != nil {
+			return nil, nil, nil, err
+		}
+		v.Check(newAuthor.Name != "", "name", "must be provided")
+		if !v.Valid() {
+			return nil, nil, nil, batchValidationError{v.Errors}
+		}
+		id, err := types.CreateAuthor(ctx, &m.models, newAuthor)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		author, err := types.ReadAuthor(ctx, &m.models, *id)
+		if err != nil {
+			return id, nil, nil, err
+		}
+		return id, author, author.UpdatedAt, nil
+	case "series":
+		var newSeries types.NewSeriesData
+		if err := decodeBatchData(raw, &newSeries); err != nil {
+			return nil, nil, nil, err
+		}
+		v.Check(newSeries.Name != "", "name", "must be provided")
+		if !v.Valid() {
+			return nil, nil, nil, batchValidationError{v.Errors}
+		}
+		id, err := types.CreateSeries(ctx, &m.models, newSeries)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		series, err := types.ReadSeries(ctx, &m.models, *id)
+		if err != nil {
+			return id, nil, nil, err
+		}
+		return id, series, series.UpdatedAt, nil
+	default:
+		var newGenre types.NewGenreData
+		if err := decodeBatchData(raw, &newGenre); err != nil {
+			return nil, nil, nil, err
+		}
+		v.Check(newGenre.Name != "", "name", "must be provided")
+		if !v.Valid() {
+			return nil, nil, nil, batchValidationError{v.Errors}
+		}
+		id, err := types.CreateGenre(ctx, &m.models, newGenre)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		genre, err := types.ReadGenre(ctx, &m.models, *id)
+		if err != nil {
+			return id, nil, nil, err
+		}
+		return id, genre, genre.UpdatedAt, nil
+	}
+}
+
+func (m *Module) batchUpdate(
+	ctx context.Context,
+	resource string,
+	id uuid.UUID,
+	raw json.RawMessage,
+	version *time.Time,
+) (any, *time.Time, error) {
+	v := validator.New()
+
+	switch resource {
+	case "books":
+		var patch data.BookPatch
+		if err := decodeBatchData(raw, &patch); err != nil {
+			return nil, nil, err
+		}
+		if data.ValidateBookPatch(v, patch); !v.Valid() {
+			return nil, nil, batchValidationError{v.Errors}
+		}
+		book, err := types.UpdateBook(ctx, &m.models, m.events, id, patch, version)
+		if err != nil {
+			return nil, nil, err
+		}
+		return book, book.UpdatedAt, nil
+	case "authors":
+		var patch data.AuthorPatch
+		if err := decodeBatchData(raw, &patch); err != nil {
+			return nil, nil, err
+		}
+		if data.ValidateAuthorPatch(v, patch); !v.Valid() {
+			return nil, nil, batchValidationError{v.Errors}
+		}
+		author, err := types.UpdateAuthor(ctx, &m.models, id, patch, version)
+		if err != nil {
+			return nil, nil, err
+		}
+		return author, author.UpdatedAt, nil
+	case "series":
+		var patch data.SeriesPatch
+		if err := decodeBatchData(raw, &patch); err != nil {
+			return nil, nil, err
+		}
+		if data.ValidateSeriesPatch(v, patch); !v.Valid() {
+			return nil, nil, batchValidationError{v.Errors}
+		}
+		series, err := types.UpdateSeries(ctx, &m.models, id, patch, version)
+		if err != nil {
+			return nil, nil, err
+		}
+		return series, series.UpdatedAt, nil
+	default:
+		var patch data.GenrePatch
+		if err := decodeBatchData(raw, &patch); err != nil {
+			return nil, nil, err
+		}
+		if data.ValidateGenrePatch(v, patch); !v.Valid() {
+			return nil, nil, batchValidationError{v.Errors}
+		}
+		genre, err := types.UpdateGenre(ctx, &m.models, id, patch, version)
+		if err != nil {
+			return nil, nil, err
+		}
+		return genre, genre.UpdatedAt, nil
+	}
+}
+
+func (m *Module) batchDelete(
+	ctx context.Context,
+	resource string,
+	id uuid.UUID,
+	version *time.Time,
+) error {
+	switch resource {
+	case "books":
+		return types.DeleteBook(ctx, &m.models, m.events, id, version)
+	case "authors":
+		return types.DeleteAuthor(ctx, &m.models, id, version)
+	case "series":
+		return types.DeleteSeries(ctx, &m.models, id, version)
+	default:
+		return types.DeleteGenre(ctx, &m.models, id, version)
+	}
+}
+
+// batchLink links the book to the author, series or genre, after checking that both exist, so
+// that a missing record is reported as such.
+func (m *Module) batchLink(
+	ctx context.Context,
+	resource string,
+	bookID uuid.UUID,
+	id uuid.UUID,
+	order float32,
+) error {
+	if _, err := m.models.Books.Get(ctx, bookID); err != nil {
+		return err
+	}
+
+	switch resource {
+	case "authors":
+		if _, err := m.models.Authors.Get(ctx, id); err != nil {
+			return err
+		}
+		_, err := m.models.BookAuthors.Insert(ctx, bookID, id)
+		return err
+	case "series":
+		if _, err := m.models.Series.Get(ctx, id); err != nil {
+			return err
+		}
+		_, err := m.models.BookSeries.Insert(ctx, bookID, id, order)
+		return err
+	default:
+		if _, err := m.models.Genres.Get(ctx, id); err != nil {
+			return err
+		}
+		_, err := m.models.BookGenres.Insert(ctx, bookID, id)
+		return err
+	}
+}
@@ -0,0 +1,128 @@
+package books_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestBatchHandler(t *testing.T) {
+	type result struct {
+		Index  int        `json:"index"`
+		ID     *uuid.UUID `json:"id"`
+		Status int        `json:"status"`
+		ETag   string     `json:"etag"`
+	}
+
+	batch := func(t *testing.T, body string) (int, []result) {
+		req := httptest.NewRequest(
+			http.MethodPost,
+			"/api/v1/books/batch",
+			strings.NewReader(body),
+		)
+		req.Header.Set("Content-Type", "application/json")
+
+		rr := httptest.NewRecorder()
+
+		handler := http.HandlerFunc(mod.BatchHandler)
+		handler.ServeHTTP(rr, req)
+
+		var output struct {
+			Results []result `json:"results"`
+		}
+		if rr.Code == http.StatusOK {
+			if err := json.Unmarshal(rr.Body.Bytes(), &output); err != nil {
+				t.Errorf("unable to unmarshal response: %v\n", err)
+			}
+		}
+
+		return rr.Code, output.Results
+	}
+
+	t.Run("TestBatchHandlerRefs", func(t *testing.T) {
+		status, results := batch(t, `{"operations": [
+			{"op": "create", "resource": "genres", "ref": "grimdark", "data": {"name": "Grimdark"}},
+			{"op": "create", "resource": "books", "ref": "book", "data": {"title": "The Blade Itself"}},
+			{"op": "link", "resource": "genres", "id": "$grimdark", "book": "$book"},
+			{"op": "link", "resource": "genres", "id": "$grimdark", "book": "$book"},
+			{"op": "update", "resource": "books", "id": "$book", "ifMatch": "*",
+			 "data": {"description": "The first book of the First Law"}},
+			{"op": "delete", "resource": "books", "id": "$book"},
+			{"op": "delete", "resource": "authors", "id": "`+uuid.NewString()+`", "ifMatch": "*"}
+		]}`)
+		if status != http.StatusOK {
+			t.Errorf("handler returned wrong error code: got %d, expected %d", status, http.StatusOK)
+			return
+		}
+
+		expected := []int{
+			http.StatusCreated,
+			http.StatusCreated,
+			http.StatusCreated,
+			http.StatusConflict,
+			http.StatusOK,
+			http.StatusPreconditionRequired,
+			http.StatusNotFound,
+		}
+		if len(results) != len(expected) {
+			t.Errorf("expected %d results, got %d", len(expected), len(results))
+			return
+		}
+		for i, res := range results {
+			if res.Status != expected[i] {
+				t.Errorf("operation %d returned %d, expected %d", i, res.Status, expected[i])
+			}
+		}
+		if results[1].ID == nil || results[4].ID == nil || *results[1].ID != *results[4].ID {
+			t.Errorf("expected the update to resolve the ref of the created book")
+		}
+		if results[4].ETag == "" {
+			t.Errorf("expected the updated book to have an entity tag")
+		}
+	})
+
+	t.Run("TestBatchHandlerFailedDependency", func(t *testing.T) {
+		status, results := batch(t, `{"operations": [
+			{"op": "create", "resource": "authors", "ref": "nameless", "data": {"name": ""}},
+			{"op": "update", "resource": "authors", "id": "$nameless", "ifMatch": "*",
+			 "data": {"website": null}}
+		]}`)
+		if status != http.StatusOK {
+			t.Errorf("handler returned wrong error code: got %d, expected %d", status, http.StatusOK)
+			return
+		}
+		if len(results) != 2 {
+			t.Errorf("expected 2 results, got %d", len(results))
+			return
+		}
+		if results[0].Status != http.StatusUnprocessableEntity {
+			t.Errorf("expected %d, got %d", http.StatusUnprocessableEntity, results[0].Status)
+		}
+		if results[1].Status != http.StatusFailedDependency {
+			t.Errorf("expected %d, got %d", http.StatusFailedDependency, results[1].Status)
+		}
+	})
+
+	t.Run("TestBatchHandlerValidation", func(t *testing.T) {
+		for _, body := range []string{
+			`{"operations": []}`,
+			`{"operations": [{"op": "create", "resource": "publishers", "data": {}}]}`,
+			`{"operations": [{"op": "delete", "resource": "books", "id": "$unknown"}]}`,
+			`{"operations": [{"op": "link", "resource": "books", "id": "` + uuid.NewString() +
+				`", "book": "` + uuid.NewString() + `"}]}`,
+		} {
+			if status, _ := batch(t, body); status != http.StatusUnprocessableEntity {
+				t.Errorf(
+					"handler returned wrong error code for %s: got %d, expected %d",
+					body,
+					status,
+					http.StatusUnprocessableEntity,
+				)
+			}
+		}
+	})
+}
@@ -40,8 +40,14 @@ func (m *Module) GetBookHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	tag := rest.ETag(book.UpdatedAt)
+	if rest.NotModified(r, tag) {
+		rest.NotModifiedResponse(w, r, tag)
+		return
+	}
+
 	logger.Info("writing response")
-	rest.Respond(w, r, http.StatusOK, book, nil)
+	rest.Respond(w, r, http.StatusOK, book, rest.ETagHeader(book.UpdatedAt))
 }
 
 func (m *Module) ListBookHandler(w http.ResponseWriter, r *http.Request) {
@@ -115,15 +121,25 @@ func (m *Module) PostBookHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	_, err = types.CreateBook(ctx, &m.models, newBook)
+	bookID, err := types.CreateBook(ctx, &m.models, m.events, newBook)
 	if err != nil {
 		logger.Error("unable to create new book records", "error", err)
 		rest.ServerErrorResponse(w, r, err)
 		return
 	}
+	logger.Info("book created", "id", bookID)
+
+	createdBook, err := types.ReadBook(ctx, &m.models, *bookID)
+	if err != nil {
+		logger.Error("unable to read created book", "id", bookID, "error", err)
+		rest.ServerErrorResponse(w, r, err)
+		return
+	}
 
 	logger.Info("writing response")
-	rest.Respond(w, r, http.StatusCreated, nil, nil)
+	headers := rest.ETagHeader(createdBook.UpdatedAt)
+	headers.Set("Location", "/api/v1/books/books/"+bookID.String())
+	rest.Respond(w, r, http.StatusCreated, createdBook, headers)
 }
 
 func (m *Module) PatchBookHandler(w http.ResponseWriter, r *http.Request) {
@@ -139,23 +155,39 @@ func (m *Module) PatchBookHandler(w http.ResponseWriter, r *http.Request) {
 	}
 	logger.Info("ID parsed", slog.String("id", id.String()))
 
-	logger.Info("parsing request body")
-	updateData := types.Book{
-		ID: id,
+	logger.Info("reading version")
+	version, err := rest.ReadIfMatch(r)
+	if err != nil {
+		logger.Info("unable to read version", "error", err)
+		rest.PreconditionErrorResponse(w, r, err)
+		return
 	}
-	err = rest.ReadJSON(r, &updateData)
+
+	logger.Info("parsing request body")
+	var patch data.BookPatch
+	err = rest.ReadJSON(r, &patch)
 	if err != nil {
 		logger.Info("unable to read request body", "error", err)
 		rest.BadRequestResponse(w, r, fmt.Sprintf("unable to read request body: %s\n", err))
 		return
 	}
 
-	updatedBook, err := types.UpdateBook(ctx, &m.models, updateData)
+	v := validator.New()
+	if data.ValidateBookPatch(v, patch); !v.Valid() {
+		logger.Info("patch validation failed", "validationErrors", v.Errors)
+		rest.FailedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	updatedBook, err := types.UpdateBook(ctx, &m.models, m.events, *id, patch, version)
 	if err != nil {
 		switch {
 		case errors.Is(err, data.ErrRecordNotFound):
 			logger.Info("book not found", "id", id)
 			rest.NotFoundResponse(w, r)
+		case errors.Is(err, data.ErrEditConflict):
+			logger.Info("book changed since version", "id", id, "version", version)
+			rest.PreconditionFailedResponse(w, r)
 		default:
 			logger.Error("unable to get book", "id", id, "error", err)
 			rest.ServerErrorResponse(w, r, err)
@@ -164,7 +196,7 @@ func (m *Module) PatchBookHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	logger.Info("writing response")
-	rest.Respond(w, r, http.StatusOK, updatedBook, nil)
+	rest.Respond(w, r, http.StatusOK, updatedBook, rest.ETagHeader(updatedBook.UpdatedAt))
 }
 
 func (m *Module) DeleteBookHandler(w http.ResponseWriter, r *http.Request) {
@@ -180,12 +212,23 @@ func (m *Module) DeleteBookHandler(w http.ResponseWriter, r *http.Request) {
 	}
 	logger.Info("ID parsed", slog.String("id", id.String()))
 
+	logger.Info("reading version")
+	version, err := rest.ReadIfMatch(r)
+	if err != nil {
+		logger.Info("unable to read version", "error", err)
+		rest.PreconditionErrorResponse(w, r, err)
+		return
+	}
+
 	logger.Info("deleting book", "id", id)
-	if err := types.DeleteBook(ctx, &m.models, *id); err != nil {
+	if err := types.DeleteBook(ctx, &m.models, m.events, *id, version); err != nil {
 		switch {
 		case errors.Is(err, data.ErrRecordNotFound):
 			logger.Info("book not found", "id", id)
 			rest.NotFoundResponse(w, r)
+		case errors.Is(err, data.ErrEditConflict):
+			logger.Info("book changed since version", "id", id, "version", version)
+			rest.PreconditionFailedResponse(w, r)
 		default:
 			logger.Error("unable to delete book", "id", id, "error", err)
 			rest.ServerErrorResponse(w, r, err)
@@ -90,7 +90,7 @@ func TestBookHandlers(t *testing.T) {
 		},
 	}
 
-	id, err := types.CreateBook(context.Background(), models, book)
+	id, err := types.CreateBook(context.Background(), models, nil, book)
 	if err != nil {
 		t.Errorf("error occurred when registering new book: %s\n", err)
 		return
@@ -125,6 +125,9 @@ func TestBookHandlers(t *testing.T) {
 		}
 	})
 
+	// etag is the ETag of the latest version of the book read or written by the handlers
+	var etag string
+
 	t.Run("TestGetBook", func(t *testing.T) {
 		getReq := httptest.NewRequest(
 			http.MethodGet,
@@ -147,6 +150,7 @@ func TestBookHandlers(t *testing.T) {
 			)
 			return
 		}
+		etag = rr.Header().Get("ETag")
 	})
 
 	t.Run("TestListBook", func(t *testing.T) {
@@ -187,7 +191,7 @@ func TestBookHandlers(t *testing.T) {
 		updateData := types.Book{
 			Title: &newTitle,
 		}
-		reqBody, err := json.Marshal(updateData)
+		reqBody, err := json.Marshal(updateData.Patch())
 		if err != nil {
 			t.Errorf("unable to marshal data: %v\n", updateData)
 			return
@@ -200,6 +204,7 @@ func TestBookHandlers(t *testing.T) {
 		)
 		patchReq.Header.Set("Content-Type", "application/json")
 		patchReq.SetPathValue("id", id.String())
+		patchReq.Header.Set("If-Match", etag)
 
 		rr := httptest.NewRecorder()
 
@@ -214,6 +219,7 @@ func TestBookHandlers(t *testing.T) {
 			)
 			return
 		}
+		etag = rr.Header().Get("ETag")
 	})
 
 	t.Run("TestDeleteBookHandler", func(t *testing.T) {
@@ -224,6 +230,7 @@ func TestBookHandlers(t *testing.T) {
 		)
 		deleteReq.Header.Set("Content-Type", "application/json")
 		deleteReq.SetPathValue("id", id.String())
+		deleteReq.Header.Set("If-Match", etag)
 
 		rr := httptest.NewRecorder()
 
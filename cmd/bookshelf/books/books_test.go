@@ -10,11 +10,13 @@ import (
 	"testing"
 	"time"
 
+	"github.com/google/uuid"
 	_ "github.com/jackc/pgx/v5/stdlib"
 	"github.com/r3d5un/Bookshelf/cmd/bookshelf/books"
 	"github.com/r3d5un/Bookshelf/internal/books/data"
 	"github.com/r3d5un/Bookshelf/internal/config"
 	"github.com/r3d5un/Bookshelf/internal/database"
+	"github.com/r3d5un/Bookshelf/internal/events"
 	"github.com/r3d5un/Bookshelf/internal/system"
 	tt "github.com/r3d5un/Bookshelf/internal/testing"
 	"github.com/testcontainers/testcontainers-go"
@@ -35,25 +37,12 @@ func TestMain(m *testing.M) {
 	dbUser := "postgres"
 	dbPassword := "postgres"
 
-	projectRoot, err := tt.FindProjectRoot()
-	if err != nil {
-		slog.Error("unable to get project root")
-		os.Exit(1)
-	}
-
-	migrations, err := tt.ListUpMigrationScrips(fmt.Sprintf("%s/migrations", projectRoot))
-	if err != nil {
-		slog.Error("unable to list up migrations", "error", err)
-		os.Exit(1)
-	}
-
 	postgresContainer, err := postgres.Run(
 		context.Background(),
 		"docker.io/postgres:16-alpine",
 		postgres.WithDatabase(dbName),
 		postgres.WithUsername(dbUser),
 		postgres.WithPassword(dbPassword),
-		postgres.WithInitScripts(migrations...),
 		testcontainers.WithWaitStrategy(
 			wait.ForLog("database system is ready to accept connections").
 				WithOccurrence(2).
@@ -88,6 +77,12 @@ func TestMain(m *testing.M) {
 	)
 	slog.Info("DSN", "connString", connString)
 
+	err = tt.MigrateDatabase(context.Background(), connString)
+	if err != nil {
+		slog.Error("unable to apply migrations", "error", err)
+		os.Exit(1)
+	}
+
 	duration := time.Second * 5
 	db, err = database.OpenPool(connString, 15, 15, "15m", duration)
 	if err != nil {
@@ -114,6 +109,7 @@ func TestMain(m *testing.M) {
 		&system.Modules{},
 		db,
 		&cfg,
+		events.NewBus(uuid.New()),
 	)
 
 	mod.Startup(context.Background(), &app)
@@ -0,0 +1,222 @@
+package books
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/r3d5un/Bookshelf/internal/books/data"
+	"github.com/r3d5un/Bookshelf/internal/books/types"
+	"github.com/r3d5un/Bookshelf/internal/logging"
+	"github.com/r3d5un/Bookshelf/internal/rest"
+	"github.com/r3d5un/Bookshelf/internal/validator"
+)
+
+// PostBookFileHandler attaches the request body as a file to the book. The file is named by the
+// name query parameter, or the filename of the Content-Disposition header.
+func (m *Module) PostBookFileHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := logging.LoggerFromContext(ctx)
+
+	logger.Info("parsing ID")
+	id, err := rest.ReadUUIDParam("id", r)
+	if err != nil {
+		logger.Info("unable to read id", "id", id, "error", err)
+		rest.NotFoundResponse(w, r)
+		return
+	}
+	logger.Info("ID parsed", slog.String("id", id.String()))
+
+	name := r.URL.Query().Get("name")
+	if name == "" {
+		if _, params, err := mime.ParseMediaType(r.Header.Get("Content-Disposition")); err == nil {
+			name = params["filename"]
+		}
+	}
+
+	v := validator.New()
+	v.Check(name != "", "name", "must be provided")
+	v.Check(len(name) <= 512, "name", "must not be more than 512 bytes long")
+	if !v.Valid() {
+		logger.Info("file validation failed", "validationErrors", v.Errors)
+		rest.FailedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	logger.Info("reading file content")
+	content, err := io.ReadAll(http.MaxBytesReader(w, r.Body, types.MaxFileSize))
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			logger.Info("file too large", "limit", maxBytesErr.Limit)
+			rest.ErrorResponse(
+				w,
+				r,
+				http.StatusRequestEntityTooLarge,
+				fmt.Sprintf("files must not be larger than %d bytes", maxBytesErr.Limit),
+			)
+			return
+		}
+		logger.Info("unable to read request body", "error", err)
+		rest.BadRequestResponse(w, r, fmt.Sprintf("unable to read request body: %s\n", err))
+		return
+	}
+
+	logger.Info("attaching file", "bookId", id, "name", name, "size", len(content))
+	file, err := types.AttachBookFile(ctx, &m.models, *id, name, r.Header.Get("Content-Type"), content)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			logger.Info("book not found", "id", id)
+			rest.NotFoundResponse(w, r)
+		default:
+			logger.Error("unable to attach file", "id", id, "error", err)
+			rest.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+	logger.Info("file attached", "fileId", file.ID)
+
+	logger.Info("writing response")
+	headers := http.Header{}
+	headers.Set("Location", "/api/v1/books/files/"+file.ID.String())
+	rest.Respond(w, r, http.StatusCreated, file, headers)
+}
+
+func (m *Module) ListBookFilesHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := logging.LoggerFromContext(ctx)
+
+	logger.Info("parsing ID")
+	id, err := rest.ReadUUIDParam("id", r)
+	if err != nil {
+		logger.Info("unable to read id", "id", id, "error", err)
+		rest.NotFoundResponse(w, r)
+		return
+	}
+	logger.Info("ID parsed", slog.String("id", id.String()))
+
+	logger.Info("getting book files", "bookId", id)
+	files, err := types.ReadBookFiles(ctx, &m.models, *id)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			logger.Info("book not found", "id", id)
+			rest.NotFoundResponse(w, r)
+		default:
+			logger.Error("unable to get book files", "id", id, "error", err)
+			rest.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	logger.Info("writing response")
+	rest.Respond(w, r, http.StatusOK, files, nil)
+}
+
+func (m *Module) GetBookFileHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := logging.LoggerFromContext(ctx)
+
+	logger.Info("parsing ID")
+	id, err := rest.ReadUUIDParam("id", r)
+	if err != nil {
+		logger.Info("unable to read id", "id", id, "error", err)
+		rest.NotFoundResponse(w, r)
+		return
+	}
+	logger.Info("ID parsed", slog.String("id", id.String()))
+
+	logger.Info("getting book file", "id", id)
+	file, err := types.ReadBookFile(ctx, &m.models, *id)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			logger.Info("book file not found", "id", id)
+			rest.NotFoundResponse(w, r)
+		default:
+			logger.Error("unable to get book file", "id", id, "error", err)
+			rest.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	logger.Info("writing response")
+	rest.Respond(w, r, http.StatusOK, file, nil)
+}
+
+// DownloadBookFileHandler responds with the content of the file, to be saved under its name.
+func (m *Module) DownloadBookFileHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := logging.LoggerFromContext(ctx)
+
+	logger.Info("parsing ID")
+	id, err := rest.ReadUUIDParam("id", r)
+	if err != nil {
+		logger.Info("unable to read id", "id", id, "error", err)
+		rest.NotFoundResponse(w, r)
+		return
+	}
+	logger.Info("ID parsed", slog.String("id", id.String()))
+
+	logger.Info("getting book file", "id", id)
+	file, content, err := types.ReadBookFileContent(ctx, &m.models, *id)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			logger.Info("book file not found", "id", id)
+			rest.NotFoundResponse(w, r)
+		default:
+			logger.Error("unable to get book file", "id", id, "error", err)
+			rest.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	logger.Info("writing response", "size", len(content))
+	w.Header().Set("Content-Type", file.ContentType)
+	w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+	w.Header().Set(
+		"Content-Disposition",
+		mime.FormatMediaType("attachment", map[string]string{"filename": file.Name}),
+	)
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(content); err != nil {
+		logger.Error("unable to write response", "error", err)
+	}
+}
+
+func (m *Module) DeleteBookFileHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := logging.LoggerFromContext(ctx)
+
+	logger.Info("parsing ID")
+	id, err := rest.ReadUUIDParam("id", r)
+	if err != nil {
+		logger.Info("unable to read id", "id", id, "error", err)
+		rest.NotFoundResponse(w, r)
+		return
+	}
+	logger.Info("ID parsed", slog.String("id", id.String()))
+
+	logger.Info("deleting book file", "id", id)
+	if err := types.DeleteBookFile(ctx, &m.models, *id); err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			logger.Info("book file not found", "id", id)
+			rest.NotFoundResponse(w, r)
+		default:
+			logger.Error("unable to delete book file", "id", id, "error", err)
+			rest.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+	logger.Info("book file deleted")
+
+	logger.Info("writing response")
+	rest.Respond(w, r, http.StatusNoContent, nil, nil)
+}
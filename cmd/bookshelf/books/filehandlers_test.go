@@ -0,0 +1,146 @@
+package books_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/r3d5un/Bookshelf/internal/books/data"
+)
+
+func TestBookFileHandlers(t *testing.T) {
+	timestamp := time.Now()
+	book, err := models.Books.Insert(context.Background(), data.Book{
+		ID:        uuid.New(),
+		Title:     "TestBookFileTitle",
+		CreatedAt: &timestamp,
+		UpdatedAt: &timestamp,
+	})
+	if err != nil {
+		t.Errorf("unable to insert book: %s\n", err)
+		return
+	}
+
+	content := []byte("Call me Ishmael.")
+	var file data.BookFile
+
+	t.Run("TestPostBookFileHandler", func(t *testing.T) {
+		postReq := httptest.NewRequest(
+			http.MethodPost,
+			"/api/v1/books/books/files?name=moby-dick.txt",
+			bytes.NewReader(content),
+		)
+		postReq.SetPathValue("id", book.ID.String())
+
+		rr := httptest.NewRecorder()
+
+		handler := http.HandlerFunc(mod.PostBookFileHandler)
+		handler.ServeHTTP(rr, postReq)
+
+		if status := rr.Code; status != http.StatusCreated {
+			t.Errorf(
+				"handler returned wrong error code: got %d, expected %d",
+				status,
+				http.StatusCreated,
+			)
+			return
+		}
+		if err := json.Unmarshal(rr.Body.Bytes(), &file); err != nil {
+			t.Errorf("unable to unmarshal response: %v\n", err)
+			return
+		}
+		if file.Size != int64(len(content)) || file.ContentType != "text/plain; charset=utf-8" {
+			t.Errorf("unexpected file: %+v\n", file)
+			return
+		}
+	})
+
+	t.Run("TestPostBookFileHandlerMissingName", func(t *testing.T) {
+		postReq := httptest.NewRequest(
+			http.MethodPost,
+			"/api/v1/books/books/files",
+			bytes.NewReader(content),
+		)
+		postReq.SetPathValue("id", book.ID.String())
+
+		rr := httptest.NewRecorder()
+
+		handler := http.HandlerFunc(mod.PostBookFileHandler)
+		handler.ServeHTTP(rr, postReq)
+
+		if status := rr.Code; status != http.StatusUnprocessableEntity {
+			t.Errorf(
+				"handler returned wrong error code: got %d, expected %d",
+				status,
+				http.StatusUnprocessableEntity,
+			)
+			return
+		}
+	})
+
+	t.Run("TestListBookFilesHandler", func(t *testing.T) {
+		listReq := httptest.NewRequest(http.MethodGet, "/api/v1/books/books/files", nil)
+		listReq.SetPathValue("id", book.ID.String())
+
+		rr := httptest.NewRecorder()
+
+		handler := http.HandlerFunc(mod.ListBookFilesHandler)
+		handler.ServeHTTP(rr, listReq)
+
+		if status := rr.Code; status != http.StatusOK {
+			t.Errorf(
+				"handler returned wrong error code: got %d, expected %d",
+				status,
+				http.StatusOK,
+			)
+			return
+		}
+	})
+
+	t.Run("TestDownloadBookFileHandler", func(t *testing.T) {
+		getReq := httptest.NewRequest(http.MethodGet, "/api/v1/books/files/content", nil)
+		getReq.SetPathValue("id", file.ID.String())
+
+		rr := httptest.NewRecorder()
+
+		handler := http.HandlerFunc(mod.DownloadBookFileHandler)
+		handler.ServeHTTP(rr, getReq)
+
+		if status := rr.Code; status != http.StatusOK {
+			t.Errorf(
+				"handler returned wrong error code: got %d, expected %d",
+				status,
+				http.StatusOK,
+			)
+			return
+		}
+		if !bytes.Equal(rr.Body.Bytes(), content) {
+			t.Errorf("unexpected file content: %q\n", rr.Body.Bytes())
+			return
+		}
+	})
+
+	t.Run("TestDeleteBookFileHandler", func(t *testing.T) {
+		deleteReq := httptest.NewRequest(http.MethodDelete, "/api/v1/books/files", nil)
+		deleteReq.SetPathValue("id", file.ID.String())
+
+		rr := httptest.NewRecorder()
+
+		handler := http.HandlerFunc(mod.DeleteBookFileHandler)
+		handler.ServeHTTP(rr, deleteReq)
+
+		if status := rr.Code; status != http.StatusNoContent {
+			t.Errorf(
+				"handler returned wrong error code: got %d, expected %d",
+				status,
+				http.StatusNoContent,
+			)
+			return
+		}
+	})
+}
@@ -35,8 +35,17 @@ func (m *Module) PostGenreHandler(w http.ResponseWriter, r *http.Request) {
 	}
 	logger.Info("genre created", "id", genreID)
 
+	createdGenre, err := types.ReadGenre(ctx, &m.models, *genreID)
+	if err != nil {
+		logger.Error("unable to read created genre", "id", genreID, "error", err)
+		rest.ServerErrorResponse(w, r, err)
+		return
+	}
+
 	logger.Info("writing response")
-	rest.Respond(w, r, http.StatusCreated, nil, nil)
+	headers := rest.ETagHeader(createdGenre.UpdatedAt)
+	headers.Set("Location", "/api/v1/books/genre/"+genreID.String())
+	rest.Respond(w, r, http.StatusCreated, createdGenre, headers)
 }
 
 func (m *Module) GetGenreHandler(w http.ResponseWriter, r *http.Request) {
@@ -66,8 +75,14 @@ func (m *Module) GetGenreHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	tag := rest.ETag(genre.UpdatedAt)
+	if rest.NotModified(r, tag) {
+		rest.NotModifiedResponse(w, r, tag)
+		return
+	}
+
 	logger.Info("writing response")
-	rest.Respond(w, r, http.StatusOK, genre, nil)
+	rest.Respond(w, r, http.StatusOK, genre, rest.ETagHeader(genre.UpdatedAt))
 }
 
 func (m *Module) ListGenreHandler(w http.ResponseWriter, r *http.Request) {
@@ -137,22 +152,42 @@ func (m *Module) PatchGenreHandler(w http.ResponseWriter, r *http.Request) {
 	}
 	logger.Info("ID parsed", slog.String("id", id.String()))
 
+	logger.Info("reading version")
+	version, err := rest.ReadIfMatch(r)
+	if err != nil {
+		logger.Info("unable to read version", "error", err)
+		rest.PreconditionErrorResponse(w, r, err)
+		return
+	}
+
 	logger.Info("parsing request body")
-	var updateData types.Genre
-	err = rest.ReadJSON(r, &updateData)
+	var patch data.GenrePatch
+	err = rest.ReadJSON(r, &patch)
 	if err != nil {
 		logger.Info("unable to read request body", "error", err)
 		rest.BadRequestResponse(w, r, fmt.Sprintf("unable to read request body: %s\n", err))
 		return
 	}
-	updateData.ID = *id
 
-	updatedGenre, err := types.UpdateGenre(ctx, &m.models, updateData)
+	v := validator.New()
+	if data.ValidateGenrePatch(v, patch); !v.Valid() {
+		logger.Info("patch validation failed", "validationErrors", v.Errors)
+		rest.FailedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	updatedGenre, err := types.UpdateGenre(ctx, &m.models, *id, patch, version)
 	if err != nil {
 		switch {
 		case errors.Is(err, data.ErrRecordNotFound):
 			logger.Info("genre not found", "id", id)
 			rest.NotFoundResponse(w, r)
+		case errors.Is(err, data.ErrEditConflict):
+			logger.Info("genre changed since version", "id", id, "version", version)
+			rest.PreconditionFailedResponse(w, r)
+		case errors.Is(err, data.ErrDuplicateRecord):
+			logger.Info("genre name already in use", "id", id)
+			rest.ConflictResponse(w, r, "a genre with the name already exists")
 		default:
 			logger.Error("unable to get genre", "id", id, "error", err)
 			rest.ServerErrorResponse(w, r, err)
@@ -161,7 +196,7 @@ func (m *Module) PatchGenreHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	logger.Info("writing response")
-	rest.Respond(w, r, http.StatusOK, updatedGenre, nil)
+	rest.Respond(w, r, http.StatusOK, updatedGenre, rest.ETagHeader(updatedGenre.UpdatedAt))
 }
 
 func (m *Module) DeleteGenreHandler(w http.ResponseWriter, r *http.Request) {
@@ -177,12 +212,23 @@ func (m *Module) DeleteGenreHandler(w http.ResponseWriter, r *http.Request) {
 	}
 	logger.Info("ID parsed", slog.String("id", id.String()))
 
+	logger.Info("reading version")
+	version, err := rest.ReadIfMatch(r)
+	if err != nil {
+		logger.Info("unable to read version", "error", err)
+		rest.PreconditionErrorResponse(w, r, err)
+		return
+	}
+
 	logger.Info("deleting genre", "id", id)
-	if err := types.DeleteGenre(ctx, &m.models, *id); err != nil {
+	if err := types.DeleteGenre(ctx, &m.models, *id, version); err != nil {
 		switch {
 		case errors.Is(err, data.ErrRecordNotFound):
 			logger.Info("genre not found", "id", id)
 			rest.NotFoundResponse(w, r)
+		case errors.Is(err, data.ErrEditConflict):
+			logger.Info("genre changed since version", "id", id, "version", version)
+			rest.PreconditionFailedResponse(w, r)
 		default:
 			logger.Error("unable to delete genre", "id", id, "error", err)
 			rest.ServerErrorResponse(w, r, err)
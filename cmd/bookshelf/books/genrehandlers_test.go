@@ -56,8 +56,21 @@ func TestGenreHandlers(t *testing.T) {
 			)
 			return
 		}
+
+		var created types.Genre
+		if err := json.Unmarshal(rr.Body.Bytes(), &created); err != nil {
+			t.Errorf("unable to unmarshal response: %v\n", err)
+			return
+		}
+		if location := rr.Header().Get("Location"); location != "/api/v1/books/genre/"+created.ID.String() {
+			t.Errorf("unexpected location of created genre: %s", location)
+			return
+		}
 	})
 
+	// etag is the ETag of the latest version of the genre read or written by the handlers
+	var etag string
+
 	t.Run("TestGetGenreHandler", func(t *testing.T) {
 		getReq := httptest.NewRequest(
 			http.MethodGet,
@@ -80,6 +93,7 @@ func TestGenreHandlers(t *testing.T) {
 			)
 			return
 		}
+		etag = rr.Header().Get("ETag")
 	})
 
 	t.Run("TestListGenreHandler", func(t *testing.T) {
@@ -120,7 +134,7 @@ func TestGenreHandlers(t *testing.T) {
 		updateData := types.Genre{
 			Description: &newDescription,
 		}
-		reqBody, err := json.Marshal(updateData)
+		reqBody, err := json.Marshal(updateData.Patch())
 		if err != nil {
 			t.Errorf("unable to marshal data: %v\n", updateData)
 			return
@@ -133,6 +147,7 @@ func TestGenreHandlers(t *testing.T) {
 		)
 		patchReq.Header.Set("Content-Type", "application/json")
 		patchReq.SetPathValue("id", id.String())
+		patchReq.Header.Set("If-Match", etag)
 
 		rr := httptest.NewRecorder()
 
@@ -147,6 +162,7 @@ func TestGenreHandlers(t *testing.T) {
 			)
 			return
 		}
+		etag = rr.Header().Get("ETag")
 	})
 
 	t.Run("TestDeleteGenreHandler", func(t *testing.T) {
@@ -157,6 +173,7 @@ func TestGenreHandlers(t *testing.T) {
 		)
 		deleteReq.Header.Set("Content-Type", "application/json")
 		deleteReq.SetPathValue("id", id.String())
+		deleteReq.Header.Set("If-Match", etag)
 
 		rr := httptest.NewRecorder()
 
@@ -0,0 +1,59 @@
+package books
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/r3d5un/Bookshelf/internal/books/data"
+	"github.com/r3d5un/Bookshelf/internal/books/types"
+	"github.com/r3d5un/Bookshelf/internal/logging"
+	"github.com/r3d5un/Bookshelf/internal/rest"
+	"github.com/r3d5un/Bookshelf/internal/validator"
+)
+
+// ListHistoryHandler lists the recorded changes to a book, author, series or genre, newest
+// first. The history of deleted records is kept, so unknown IDs return an empty list.
+func (m *Module) ListHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := logging.LoggerFromContext(ctx)
+
+	logger.Info("parsing ID")
+	id, err := rest.ReadUUIDParam("id", r)
+	if err != nil {
+		logger.Info("unable to read id", "id", id, "error", err)
+		rest.NotFoundResponse(w, r)
+		return
+	}
+	logger.Info("ID parsed", slog.String("id", id.String()))
+
+	var input struct {
+		data.Filters
+	}
+	v := validator.New()
+
+	qs := r.URL.Query()
+
+	input.Filters.CreatedAtFrom = rest.ReadQueryDate(qs, "createdAtFrom", v)
+	input.Filters.CreatedAtTo = rest.ReadQueryDate(qs, "createdAtTo", v)
+
+	input.Filters.Page = rest.ReadQueryInt(qs, "page", 1, v)
+	input.Filters.PageSize = rest.ReadQueryInt(qs, "page_size", 100, v)
+	logger.InfoContext(ctx, "filters set", "filters", input)
+
+	if data.ValidateFilters(v, input.Filters); !v.Valid() {
+		logger.Info("filter validation failed", "validationErrors", v.Errors)
+		rest.FailedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	logger.Info("getting history", "id", id, "filters", input.Filters)
+	entries, err := types.ReadHistory(ctx, &m.models, *id, input.Filters)
+	if err != nil {
+		logger.Error("unable to get history", "id", id, "error", err)
+		rest.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	logger.Info("writing response")
+	rest.Respond(w, r, http.StatusOK, entries, nil)
+}
@@ -2,6 +2,7 @@ package books
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/r3d5un/Bookshelf/internal/books/data"
@@ -39,16 +40,16 @@ func (m *Module) ReadAllAuthors(
 }
 
 func (m *Module) UpdateAuthor(ctx context.Context, data types.Author) (*types.Author, error) {
-	a, err := types.UpdateAuthor(ctx, &m.models, data)
+	a, err := types.UpdateAuthor(ctx, &m.models, data.ID, data.Patch(), data.UpdatedAt)
 	if err != nil {
-		return a, nil
+		return nil, err
 	}
 
 	return a, nil
 }
 
 func (m *Module) DeleteAuthor(ctx context.Context, id uuid.UUID) error {
-	err := types.DeleteAuthor(ctx, &m.models, id)
+	err := types.DeleteAuthor(ctx, &m.models, id, nil)
 	if err != nil {
 		return err
 	}
@@ -56,6 +57,28 @@ func (m *Module) DeleteAuthor(ctx context.Context, id uuid.UUID) error {
 	return nil
 }
 
+func (m *Module) RestoreAuthor(ctx context.Context, id uuid.UUID) (*types.Author, error) {
+	a, err := types.RestoreAuthor(ctx, &m.models, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return a, nil
+}
+
+func (m *Module) ReadAuthorHistory(
+	ctx context.Context,
+	id uuid.UUID,
+	filters data.Filters,
+) ([]*data.AuditEntry, error) {
+	entries, err := types.ReadHistory(ctx, &m.models, id, filters)
+	if err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
 func (m *Module) CreateSeries(ctx context.Context, data types.NewSeriesData) (*uuid.UUID, error) {
 	id, err := types.CreateSeries(ctx, &m.models, data)
 	if err != nil {
@@ -87,16 +110,16 @@ func (m *Module) ReadAllSeries(
 }
 
 func (m *Module) UpdateSeries(ctx context.Context, data types.Series) (*types.Series, error) {
-	a, err := types.UpdateSeries(ctx, &m.models, data)
+	a, err := types.UpdateSeries(ctx, &m.models, data.ID, data.Patch(), data.UpdatedAt)
 	if err != nil {
-		return a, nil
+		return nil, err
 	}
 
 	return a, nil
 }
 
 func (m *Module) DeleteSeries(ctx context.Context, id uuid.UUID) error {
-	err := types.DeleteSeries(ctx, &m.models, id)
+	err := types.DeleteSeries(ctx, &m.models, id, nil)
 	if err != nil {
 		return err
 	}
@@ -104,6 +127,28 @@ func (m *Module) DeleteSeries(ctx context.Context, id uuid.UUID) error {
 	return nil
 }
 
+func (m *Module) RestoreSeries(ctx context.Context, id uuid.UUID) (*types.Series, error) {
+	s, err := types.RestoreSeries(ctx, &m.models, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+func (m *Module) ReadSeriesHistory(
+	ctx context.Context,
+	id uuid.UUID,
+	filters data.Filters,
+) ([]*data.AuditEntry, error) {
+	entries, err := types.ReadHistory(ctx, &m.models, id, filters)
+	if err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
 func (m *Module) CreateGenre(ctx context.Context, data types.NewGenreData) (*uuid.UUID, error) {
 	id, err := types.CreateGenre(ctx, &m.models, data)
 	if err != nil {
@@ -135,16 +180,16 @@ func (m *Module) ReadAllGenre(
 }
 
 func (m *Module) UpdateGenre(ctx context.Context, data types.Genre) (*types.Genre, error) {
-	a, err := types.UpdateGenre(ctx, &m.models, data)
+	a, err := types.UpdateGenre(ctx, &m.models, data.ID, data.Patch(), data.UpdatedAt)
 	if err != nil {
-		return a, nil
+		return nil, err
 	}
 
 	return a, nil
 }
 
 func (m *Module) DeleteGenre(ctx context.Context, id uuid.UUID) error {
-	err := types.DeleteGenre(ctx, &m.models, id)
+	err := types.DeleteGenre(ctx, &m.models, id, nil)
 	if err != nil {
 		return err
 	}
@@ -152,8 +197,30 @@ func (m *Module) DeleteGenre(ctx context.Context, id uuid.UUID) error {
 	return nil
 }
 
+func (m *Module) RestoreGenre(ctx context.Context, id uuid.UUID) (*types.Genre, error) {
+	g, err := types.RestoreGenre(ctx, &m.models, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return g, nil
+}
+
+func (m *Module) ReadGenreHistory(
+	ctx context.Context,
+	id uuid.UUID,
+	filters data.Filters,
+) ([]*data.AuditEntry, error) {
+	entries, err := types.ReadHistory(ctx, &m.models, id, filters)
+	if err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
 func (m *Module) CreateBook(ctx context.Context, data types.Book) (*uuid.UUID, error) {
-	id, err := types.CreateBook(ctx, &m.models, data)
+	id, err := types.CreateBook(ctx, &m.models, m.events, data)
 	if err != nil {
 		return nil, err
 	}
@@ -192,19 +259,104 @@ func (m *Module) ReadBooksBySeries(ctx context.Context, seriesID uuid.UUID) ([]*
 }
 
 func (m *Module) UpdateBook(ctx context.Context, data types.Book) (*types.Book, error) {
-	a, err := types.UpdateBook(ctx, &m.models, data)
+	a, err := types.UpdateBook(ctx, &m.models, m.events, *data.ID, data.Patch(), data.UpdatedAt)
 	if err != nil {
-		return a, nil
+		return nil, err
 	}
 
 	return a, nil
 }
 
 func (m *Module) DeleteBook(ctx context.Context, id uuid.UUID) error {
-	err := types.DeleteBook(ctx, &m.models, id)
+	err := types.DeleteBook(ctx, &m.models, m.events, id, nil)
 	if err != nil {
 		return err
 	}
 
 	return nil
 }
+
+func (m *Module) RestoreBook(ctx context.Context, id uuid.UUID) (*types.Book, error) {
+	b, err := types.RestoreBook(ctx, &m.models, m.events, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return b, nil
+}
+
+func (m *Module) ReadBookHistory(
+	ctx context.Context,
+	id uuid.UUID,
+	filters data.Filters,
+) ([]*data.AuditEntry, error) {
+	entries, err := types.ReadHistory(ctx, &m.models, id, filters)
+	if err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+func (m *Module) AttachBookFile(
+	ctx context.Context,
+	bookID uuid.UUID,
+	name string,
+	contentType string,
+	content []byte,
+) (*data.BookFile, error) {
+	f, err := types.AttachBookFile(ctx, &m.models, bookID, name, contentType, content)
+	if err != nil {
+		return nil, err
+	}
+
+	return f, nil
+}
+
+func (m *Module) ReadBookFiles(ctx context.Context, bookID uuid.UUID) ([]*data.BookFile, error) {
+	files, err := types.ReadBookFiles(ctx, &m.models, bookID)
+	if err != nil {
+		return nil, err
+	}
+
+	return files, nil
+}
+
+func (m *Module) ReadBookFileContent(
+	ctx context.Context,
+	id uuid.UUID,
+) (*data.BookFile, []byte, error) {
+	f, content, err := types.ReadBookFileContent(ctx, &m.models, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return f, content, nil
+}
+
+func (m *Module) DeleteBookFile(ctx context.Context, id uuid.UUID) error {
+	err := types.DeleteBookFile(ctx, &m.models, id)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (m *Module) ReadTrash(ctx context.Context, filters data.Filters) (*types.Trash, error) {
+	trash, err := types.ReadTrash(ctx, &m.models, filters)
+	if err != nil {
+		return nil, err
+	}
+
+	return trash, nil
+}
+
+func (m *Module) PurgeTrash(ctx context.Context, before time.Time) (*types.PurgedTrash, error) {
+	purged, err := types.PurgeTrash(ctx, &m.models, before)
+	if err != nil {
+		return nil, err
+	}
+
+	return purged, nil
+}
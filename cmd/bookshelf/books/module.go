@@ -9,6 +9,7 @@ import (
 
 	"github.com/r3d5un/Bookshelf/internal/books/data"
 	"github.com/r3d5un/Bookshelf/internal/config"
+	"github.com/r3d5un/Bookshelf/internal/events"
 	"github.com/r3d5un/Bookshelf/internal/system"
 )
 
@@ -20,6 +21,7 @@ type Module struct {
 	db     *sql.DB
 	models data.Models
 	cfg    *config.Config
+	events events.Publisher
 }
 
 func (m *Module) Startup(ctx context.Context, mono system.Monolith) (err error) {
@@ -32,6 +34,9 @@ func (m *Module) Startup(ctx context.Context, mono system.Monolith) (err error)
 	m.logger.Info("injecting mux")
 	m.mux = mono.Mux()
 
+	m.logger.Info("injecting event bus")
+	m.events = mono.Events()
+
 	m.logger.Info("injecting database connection")
 	m.db = mono.DB()
 
@@ -69,24 +74,43 @@ func (m *Module) registerEndpoints(mux *http.ServeMux) {
 		{"POST /api/v1/books/books", m.PostBookHandler},
 		{"PATCH /api/v1/books/books/{id}", m.PatchBookHandler},
 		{"DELETE /api/v1/books/books/{id}", m.DeleteBookHandler},
+		{"GET /api/v1/books/books/{id}/history", m.ListHistoryHandler},
+		{"POST /api/v1/books/books/{id}/restore", m.RestoreBookHandler},
+		// Book files
+		{"GET /api/v1/books/books/{id}/files", m.ListBookFilesHandler},
+		{"POST /api/v1/books/books/{id}/files", m.PostBookFileHandler},
+		{"GET /api/v1/books/files/{id}", m.GetBookFileHandler},
+		{"GET /api/v1/books/files/{id}/content", m.DownloadBookFileHandler},
+		{"DELETE /api/v1/books/files/{id}", m.DeleteBookFileHandler},
 		// Authors
 		{"GET /api/v1/books/authors", m.ListAuthorHandler},
 		{"GET /api/v1/books/authors/{id}", m.GetAuthorHandler},
 		{"POST /api/v1/books/authors", m.PostAuthorHandler},
 		{"PATCH /api/v1/books/authors/{id}", m.PatchAuthorHandler},
 		{"DELETE /api/v1/books/authors/{id}", m.DeleteAuthorHandler},
+		{"GET /api/v1/books/authors/{id}/history", m.ListHistoryHandler},
+		{"POST /api/v1/books/authors/{id}/restore", m.RestoreAuthorHandler},
 		// Series
 		{"GET /api/v1/books/series", m.ListSeriesHandler},
 		{"GET /api/v1/books/series/{id}", m.GetSeriesHandler},
 		{"POST /api/v1/books/series", m.PostSeriesHandler},
 		{"PATCH /api/v1/books/series/{id}", m.PatchSeriesHandler},
 		{"DELETE /api/v1/books/series/{id}", m.DeleteSeriesHandler},
+		{"GET /api/v1/books/series/{id}/history", m.ListHistoryHandler},
+		{"POST /api/v1/books/series/{id}/restore", m.RestoreSeriesHandler},
 		// Genre
 		{"GET /api/v1/books/genre", m.ListGenreHandler},
 		{"GET /api/v1/books/genre/{id}", m.GetGenreHandler},
 		{"POST /api/v1/books/genre", m.PostGenreHandler},
 		{"PATCH /api/v1/books/genre/{id}", m.PatchGenreHandler},
 		{"DELETE /api/v1/books/genre/{id}", m.DeleteGenreHandler},
+		{"GET /api/v1/books/genre/{id}/history", m.ListHistoryHandler},
+		{"POST /api/v1/books/genre/{id}/restore", m.RestoreGenreHandler},
+		// Trash
+		{"GET /api/v1/books/trash", m.ListTrashHandler},
+		{"DELETE /api/v1/books/trash", m.PurgeTrashHandler},
+		// Batch
+		{"POST /api/v1/books/batch", m.BatchHandler},
 	}
 
 	m.logger.Info("adding protected endpoints")
@@ -35,8 +35,17 @@ func (m *Module) PostSeriesHandler(w http.ResponseWriter, r *http.Request) {
 	}
 	logger.Info("series created", "id", seriesID)
 
+	createdSeries, err := types.ReadSeries(ctx, &m.models, *seriesID)
+	if err != nil {
+		logger.Error("unable to read created series", "id", seriesID, "error", err)
+		rest.ServerErrorResponse(w, r, err)
+		return
+	}
+
 	logger.Info("writing response")
-	rest.Respond(w, r, http.StatusCreated, nil, nil)
+	headers := rest.ETagHeader(createdSeries.UpdatedAt)
+	headers.Set("Location", "/api/v1/books/series/"+seriesID.String())
+	rest.Respond(w, r, http.StatusCreated, createdSeries, headers)
 }
 
 func (m *Module) GetSeriesHandler(w http.ResponseWriter, r *http.Request) {
@@ -66,8 +75,14 @@ func (m *Module) GetSeriesHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	tag := rest.ETag(series.UpdatedAt)
+	if rest.NotModified(r, tag) {
+		rest.NotModifiedResponse(w, r, tag)
+		return
+	}
+
 	logger.Info("writing response")
-	rest.Respond(w, r, http.StatusOK, series, nil)
+	rest.Respond(w, r, http.StatusOK, series, rest.ETagHeader(series.UpdatedAt))
 }
 
 func (m *Module) ListSeriesHandler(w http.ResponseWriter, r *http.Request) {
@@ -137,22 +152,39 @@ func (m *Module) PatchSeriesHandler(w http.ResponseWriter, r *http.Request) {
 	}
 	logger.Info("ID parsed", slog.String("id", id.String()))
 
+	logger.Info("reading version")
+	version, err := rest.ReadIfMatch(r)
+	if err != nil {
+		logger.Info("unable to read version", "error", err)
+		rest.PreconditionErrorResponse(w, r, err)
+		return
+	}
+
 	logger.Info("parsing request body")
-	var updateData types.Series
-	err = rest.ReadJSON(r, &updateData)
+	var patch data.SeriesPatch
+	err = rest.ReadJSON(r, &patch)
 	if err != nil {
 		logger.Info("unable to read request body", "error", err)
 		rest.BadRequestResponse(w, r, fmt.Sprintf("unable to read request body: %s\n", err))
 		return
 	}
-	updateData.ID = *id
 
-	updatedSeries, err := types.UpdateSeries(ctx, &m.models, updateData)
+	v := validator.New()
+	if data.ValidateSeriesPatch(v, patch); !v.Valid() {
+		logger.Info("patch validation failed", "validationErrors", v.Errors)
+		rest.FailedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	updatedSeries, err := types.UpdateSeries(ctx, &m.models, *id, patch, version)
 	if err != nil {
 		switch {
 		case errors.Is(err, data.ErrRecordNotFound):
 			logger.Info("series not found", "id", id)
 			rest.NotFoundResponse(w, r)
+		case errors.Is(err, data.ErrEditConflict):
+			logger.Info("series changed since version", "id", id, "version", version)
+			rest.PreconditionFailedResponse(w, r)
 		default:
 			logger.Error("unable to get series", "id", id, "error", err)
 			rest.ServerErrorResponse(w, r, err)
@@ -161,7 +193,7 @@ func (m *Module) PatchSeriesHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	logger.Info("writing response")
-	rest.Respond(w, r, http.StatusOK, updatedSeries, nil)
+	rest.Respond(w, r, http.StatusOK, updatedSeries, rest.ETagHeader(updatedSeries.UpdatedAt))
 }
 
 func (m *Module) DeleteSeriesHandler(w http.ResponseWriter, r *http.Request) {
@@ -177,12 +209,23 @@ func (m *Module) DeleteSeriesHandler(w http.ResponseWriter, r *http.Request) {
 	}
 	logger.Info("ID parsed", slog.String("id", id.String()))
 
+	logger.Info("reading version")
+	version, err := rest.ReadIfMatch(r)
+	if err != nil {
+		logger.Info("unable to read version", "error", err)
+		rest.PreconditionErrorResponse(w, r, err)
+		return
+	}
+
 	logger.Info("deleting series", "id", id)
-	if err := types.DeleteSeries(ctx, &m.models, *id); err != nil {
+	if err := types.DeleteSeries(ctx, &m.models, *id, version); err != nil {
 		switch {
 		case errors.Is(err, data.ErrRecordNotFound):
 			logger.Info("series not found", "id", id)
 			rest.NotFoundResponse(w, r)
+		case errors.Is(err, data.ErrEditConflict):
+			logger.Info("series changed since version", "id", id, "version", version)
+			rest.PreconditionFailedResponse(w, r)
 		default:
 			logger.Error("unable to delete series", "id", id, "error", err)
 			rest.ServerErrorResponse(w, r, err)
@@ -58,6 +58,9 @@ func TestSeriesHandlers(t *testing.T) {
 		}
 	})
 
+	// etag is the ETag of the latest version of the series read or written by the handlers
+	var etag string
+
 	t.Run("TestGetSeriesHandler", func(t *testing.T) {
 		getReq := httptest.NewRequest(
 			http.MethodGet,
@@ -80,6 +83,7 @@ func TestSeriesHandlers(t *testing.T) {
 			)
 			return
 		}
+		etag = rr.Header().Get("ETag")
 	})
 
 	t.Run("TestListSeriesHandler", func(t *testing.T) {
@@ -120,7 +124,7 @@ func TestSeriesHandlers(t *testing.T) {
 		updateData := types.Series{
 			Description: &newDescription,
 		}
-		reqBody, err := json.Marshal(updateData)
+		reqBody, err := json.Marshal(updateData.Patch())
 		if err != nil {
 			t.Errorf("unable to marshal data: %v\n", updateData)
 			return
@@ -133,6 +137,7 @@ func TestSeriesHandlers(t *testing.T) {
 		)
 		patchReq.Header.Set("Content-Type", "application/json")
 		patchReq.SetPathValue("id", id.String())
+		patchReq.Header.Set("If-Match", etag)
 
 		rr := httptest.NewRecorder()
 
@@ -147,6 +152,7 @@ func TestSeriesHandlers(t *testing.T) {
 			)
 			return
 		}
+		etag = rr.Header().Get("ETag")
 	})
 
 	t.Run("TestDeleteSeriesHandler", func(t *testing.T) {
@@ -157,6 +163,7 @@ func TestSeriesHandlers(t *testing.T) {
 		)
 		deleteReq.Header.Set("Content-Type", "application/json")
 		deleteReq.SetPathValue("id", id.String())
+		deleteReq.Header.Set("If-Match", etag)
 
 		rr := httptest.NewRecorder()
 
@@ -0,0 +1,211 @@
+package books
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/r3d5un/Bookshelf/internal/books/data"
+	"github.com/r3d5un/Bookshelf/internal/books/types"
+	"github.com/r3d5un/Bookshelf/internal/logging"
+	"github.com/r3d5un/Bookshelf/internal/rest"
+	"github.com/r3d5un/Bookshelf/internal/validator"
+)
+
+// ListTrashHandler lists the deleted books, authors, series and genres that have not yet been
+// purged, most recently deleted first.
+func (m *Module) ListTrashHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := logging.LoggerFromContext(ctx)
+
+	var input struct {
+		data.Filters
+	}
+	v := validator.New()
+
+	qs := r.URL.Query()
+
+	input.Filters.Page = rest.ReadQueryInt(qs, "page", 1, v)
+	input.Filters.PageSize = rest.ReadQueryInt(qs, "page_size", 100, v)
+	logger.InfoContext(ctx, "filters set", "filters", input)
+
+	if data.ValidateFilters(v, input.Filters); !v.Valid() {
+		logger.Info("filter validation failed", "validationErrors", v.Errors)
+		rest.FailedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	logger.Info("getting trash", "filters", input.Filters)
+	trash, err := types.ReadTrash(ctx, &m.models, input.Filters)
+	if err != nil {
+		logger.Error("unable to get trash", "error", err)
+		rest.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	logger.Info("writing response")
+	rest.Respond(w, r, http.StatusOK, trash, nil)
+}
+
+// PurgeTrashHandler permanently deletes the records moved to the trash before the time given by
+// the before query parameter, or every record in the trash if it is left out.
+func (m *Module) PurgeTrashHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := logging.LoggerFromContext(ctx)
+
+	v := validator.New()
+	before := rest.ReadQueryDate(r.URL.Query(), "before", v)
+	if !v.Valid() {
+		logger.Info("query validation failed", "validationErrors", v.Errors)
+		rest.FailedValidationResponse(w, r, v.Errors)
+		return
+	}
+	if before == nil {
+		now := time.Now()
+		before = &now
+	}
+
+	logger.Info("purging trash", "before", before)
+	purged, err := types.PurgeTrash(ctx, &m.models, *before)
+	if err != nil {
+		logger.Error("unable to purge trash", "error", err)
+		rest.ServerErrorResponse(w, r, err)
+		return
+	}
+	logger.Info("trash purged", "purged", purged)
+
+	logger.Info("writing response")
+	rest.Respond(w, r, http.StatusOK, purged, nil)
+}
+
+func (m *Module) RestoreBookHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := logging.LoggerFromContext(ctx)
+
+	logger.Info("parsing ID")
+	id, err := rest.ReadUUIDParam("id", r)
+	if err != nil {
+		logger.Info("unable to read id", "id", id, "error", err)
+		rest.NotFoundResponse(w, r)
+		return
+	}
+	logger.Info("ID parsed", slog.String("id", id.String()))
+
+	logger.Info("restoring book", "id", id)
+	book, err := types.RestoreBook(ctx, &m.models, m.events, *id)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			logger.Info("book not in trash", "id", id)
+			rest.NotFoundResponse(w, r)
+		default:
+			logger.Error("unable to restore book", "id", id, "error", err)
+			rest.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+	logger.Info("book restored")
+
+	logger.Info("writing response")
+	rest.Respond(w, r, http.StatusOK, book, rest.ETagHeader(book.UpdatedAt))
+}
+
+func (m *Module) RestoreAuthorHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := logging.LoggerFromContext(ctx)
+
+	logger.Info("parsing ID")
+	id, err := rest.ReadUUIDParam("id", r)
+	if err != nil {
+		logger.Info("unable to read id", "id", id, "error", err)
+		rest.NotFoundResponse(w, r)
+		return
+	}
+	logger.Info("ID parsed", slog.String("id", id.String()))
+
+	logger.Info("restoring author", "id", id)
+	author, err := types.RestoreAuthor(ctx, &m.models, *id)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			logger.Info("author not in trash", "id", id)
+			rest.NotFoundResponse(w, r)
+		default:
+			logger.Error("unable to restore author", "id", id, "error", err)
+			rest.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+	logger.Info("author restored")
+
+	logger.Info("writing response")
+	rest.Respond(w, r, http.StatusOK, author, rest.ETagHeader(author.UpdatedAt))
+}
+
+func (m *Module) RestoreSeriesHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := logging.LoggerFromContext(ctx)
+
+	logger.Info("parsing ID")
+	id, err := rest.ReadUUIDParam("id", r)
+	if err != nil {
+		logger.Info("unable to read id", "id", id, "error", err)
+		rest.NotFoundResponse(w, r)
+		return
+	}
+	logger.Info("ID parsed", slog.String("id", id.String()))
+
+	logger.Info("restoring series", "id", id)
+	series, err := types.RestoreSeries(ctx, &m.models, *id)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			logger.Info("series not in trash", "id", id)
+			rest.NotFoundResponse(w, r)
+		default:
+			logger.Error("unable to restore series", "id", id, "error", err)
+			rest.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+	logger.Info("series restored")
+
+	logger.Info("writing response")
+	rest.Respond(w, r, http.StatusOK, series, rest.ETagHeader(series.UpdatedAt))
+}
+
+func (m *Module) RestoreGenreHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := logging.LoggerFromContext(ctx)
+
+	logger.Info("parsing ID")
+	id, err := rest.ReadUUIDParam("id", r)
+	if err != nil {
+		logger.Info("unable to read id", "id", id, "error", err)
+		rest.NotFoundResponse(w, r)
+		return
+	}
+	logger.Info("ID parsed", slog.String("id", id.String()))
+
+	logger.Info("restoring genre", "id", id)
+	genre, err := types.RestoreGenre(ctx, &m.models, *id)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			logger.Info("genre not in trash", "id", id)
+			rest.NotFoundResponse(w, r)
+		case errors.Is(err, data.ErrDuplicateRecord):
+			logger.Info("genre name already in use", "id", id)
+			rest.ConflictResponse(w, r, "a genre with the name already exists")
+		default:
+			logger.Error("unable to restore genre", "id", id, "error", err)
+			rest.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+	logger.Info("genre restored")
+
+	logger.Info("writing response")
+	rest.Respond(w, r, http.StatusOK, genre, rest.ETagHeader(genre.UpdatedAt))
+}
@@ -0,0 +1,170 @@
+package cli
+
+import (
+	"context"
+	"errors"
+
+	"github.com/r3d5un/Bookshelf/internal/books/types"
+)
+
+func listAuthors(ctx context.Context, args []string) error {
+	cmd := newCommand("authors list")
+	name := cmd.flags.String("name", "", "filter by name")
+	lf := cmd.listFlags("name")
+	if err := cmd.parse(args); err != nil {
+		return err
+	}
+
+	filters, err := lf.filters(nameOrderBySafeList)
+	if err != nil {
+		return err
+	}
+	filters.Name = *name
+
+	catalog, closeCatalog, err := cmd.catalog(ctx)
+	if err != nil {
+		return err
+	}
+	defer closeCatalog()
+
+	authors, err := catalog.ReadAllAuthors(ctx, filters)
+	if err != nil {
+		return err
+	}
+
+	t := table{header: []string{"ID", "NAME", "WEBSITE", "BOOKS"}}
+	for _, author := range authors {
+		t.rows = append(t.rows, []string{
+			author.ID.String(),
+			str(author.Name),
+			str(author.Website),
+			bookCount(author.Books),
+		})
+	}
+
+	return cmd.print(authors, t)
+}
+
+func showAuthor(ctx context.Context, args []string) error {
+	cmd := newCommand("authors show")
+	if err := cmd.parse(args); err != nil {
+		return err
+	}
+	id, err := cmd.id()
+	if err != nil {
+		return err
+	}
+
+	catalog, closeCatalog, err := cmd.catalog(ctx)
+	if err != nil {
+		return err
+	}
+	defer closeCatalog()
+
+	author, err := catalog.ReadAuthor(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return cmd.print(author, details(
+		[2]string{"ID", author.ID.String()},
+		[2]string{"Name", str(author.Name)},
+		[2]string{"Description", str(author.Description)},
+		[2]string{"Website", str(author.Website)},
+		[2]string{"Books", bookTitles(author.Books)},
+		[2]string{"Created", timestamp(author.CreatedAt)},
+		[2]string{"Updated", timestamp(author.UpdatedAt)},
+	))
+}
+
+func addAuthor(ctx context.Context, args []string) error {
+	cmd := newCommand("authors add")
+	name := cmd.flags.String("name", "", "name of the author (required)")
+	description := cmd.flags.String("description", "", "description of the author")
+	website := cmd.flags.String("website", "", "website of the author")
+	if err := cmd.parse(args); err != nil {
+		return err
+	}
+	if *name == "" {
+		return errors.New("name is required")
+	}
+
+	catalog, closeCatalog, err := cmd.catalog(ctx)
+	if err != nil {
+		return err
+	}
+	defer closeCatalog()
+
+	id, err := catalog.CreateAuthor(ctx, types.NewAuthorData{
+		Name:        *name,
+		Description: cmd.optionalString("description", *description),
+		Website:     cmd.optionalString("website", *website),
+	})
+	if err != nil {
+		return err
+	}
+
+	return cmd.printCreated("author", id)
+}
+
+func editAuthor(ctx context.Context, args []string) error {
+	cmd := newCommand("authors edit")
+	name := cmd.flags.String("name", "", "new name of the author")
+	description := cmd.flags.String("description", "", "new description of the author")
+	website := cmd.flags.String("website", "", "new website of the author")
+	if err := cmd.parse(args); err != nil {
+		return err
+	}
+	id, err := cmd.id()
+	if err != nil {
+		return err
+	}
+
+	catalog, closeCatalog, err := cmd.catalog(ctx)
+	if err != nil {
+		return err
+	}
+	defer closeCatalog()
+
+	author, err := catalog.UpdateAuthor(ctx, types.Author{
+		ID:          id,
+		Name:        cmd.optionalString("name", *name),
+		Description: cmd.optionalString("description", *description),
+		Website:     cmd.optionalString("website", *website),
+	})
+	if err != nil {
+		return err
+	}
+
+	return cmd.print(author, details(
+		[2]string{"ID", author.ID.String()},
+		[2]string{"Name", str(author.Name)},
+		[2]string{"Description", str(author.Description)},
+		[2]string{"Website", str(author.Website)},
+		[2]string{"Updated", timestamp(author.UpdatedAt)},
+	))
+}
+
+func deleteAuthor(ctx context.Context, args []string) error {
+	cmd := newCommand("authors delete")
+	if err := cmd.parse(args); err != nil {
+		return err
+	}
+	id, err := cmd.id()
+	if err != nil {
+		return err
+	}
+
+	catalog, closeCatalog, err := cmd.catalog(ctx)
+	if err != nil {
+		return err
+	}
+	defer closeCatalog()
+
+	if err := catalog.DeleteAuthor(ctx, id); err != nil {
+		return err
+	}
+
+	cmd.printMessage("deleted author %s", id)
+	return nil
+}
@@ -0,0 +1,280 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/r3d5un/Bookshelf/internal/books/data"
+	"github.com/r3d5un/Bookshelf/internal/books/types"
+)
+
+var bookOrderBySafeList = []string{
+	"id",
+	"updated_at",
+	"created_at",
+	"published",
+	"title",
+	"-id",
+	"-updated_at",
+	"-created_at",
+	"-published",
+	"-title",
+}
+
+// seriesEntryList is a repeatable flag collecting series memberships in the form ID[:ORDER].
+type seriesEntryList []*data.BookSeries
+
+func (l *seriesEntryList) String() string {
+	entries := make([]string, len(*l))
+	for i, entry := range *l {
+		entries[i] = fmt.Sprintf("%s:%g", entry.SeriesID, entry.SeriesOrder)
+	}
+	return strings.Join(entries, ",")
+}
+
+func (l *seriesEntryList) Set(value string) error {
+	idValue, orderValue, hasOrder := strings.Cut(value, ":")
+
+	id, err := uuid.Parse(idValue)
+	if err != nil {
+		return err
+	}
+
+	var order float64
+	if hasOrder {
+		order, err = strconv.ParseFloat(orderValue, 32)
+		if err != nil {
+			return fmt.Errorf("invalid series order %q: %w", orderValue, err)
+		}
+	}
+
+	*l = append(*l, &data.BookSeries{SeriesID: id, SeriesOrder: float32(order)})
+	return nil
+}
+
+func listBooks(ctx context.Context, args []string) error {
+	cmd := newCommand("books list")
+	title := cmd.flags.String("title", "", "filter by title")
+	var publishedFrom, publishedTo dateFlag
+	cmd.flags.Var(&publishedFrom, "published-from", "only books published on or after `DATE`")
+	cmd.flags.Var(&publishedTo, "published-to", "only books published on or before `DATE`")
+	lf := cmd.listFlags("published")
+	if err := cmd.parse(args); err != nil {
+		return err
+	}
+
+	filters, err := lf.filters(bookOrderBySafeList)
+	if err != nil {
+		return err
+	}
+	filters.Title = *title
+	filters.PublishedFrom = publishedFrom.date
+	filters.PublishedTo = publishedTo.date
+
+	catalog, closeCatalog, err := cmd.catalog(ctx)
+	if err != nil {
+		return err
+	}
+	defer closeCatalog()
+
+	books, err := catalog.ReadAllBook(ctx, filters)
+	if err != nil {
+		return err
+	}
+
+	t := table{header: []string{"ID", "TITLE", "AUTHORS", "PUBLISHED"}}
+	for _, book := range books {
+		t.rows = append(t.rows, []string{
+			idString(book.ID),
+			str(book.Title),
+			authorNames(book.Authors),
+			date(book.Published),
+		})
+	}
+
+	return cmd.print(books, t)
+}
+
+func showBook(ctx context.Context, args []string) error {
+	cmd := newCommand("books show")
+	if err := cmd.parse(args); err != nil {
+		return err
+	}
+	id, err := cmd.id()
+	if err != nil {
+		return err
+	}
+
+	catalog, closeCatalog, err := cmd.catalog(ctx)
+	if err != nil {
+		return err
+	}
+	defer closeCatalog()
+
+	book, err := catalog.ReadBook(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return cmd.print(book, bookDetails(book))
+}
+
+func addBook(ctx context.Context, args []string) error {
+	cmd := newCommand("books add")
+	title := cmd.flags.String("title", "", "title of the book (required)")
+	description := cmd.flags.String("description", "", "description of the book")
+	var published dateFlag
+	cmd.flags.Var(&published, "published", "publication `DATE` of the book")
+	var authors, genres uuidList
+	cmd.flags.Var(&authors, "author", "`ID` of an author of the book, may be repeated")
+	cmd.flags.Var(&genres, "genre", "`ID` of a genre of the book, may be repeated")
+	var series seriesEntryList
+	cmd.flags.Var(
+		&series,
+		"series",
+		"series the book is part of as `ID[:ORDER]`, may be repeated",
+	)
+	if err := cmd.parse(args); err != nil {
+		return err
+	}
+	if *title == "" {
+		return errors.New("title is required")
+	}
+
+	newBook := types.Book{
+		Title:       title,
+		Description: cmd.optionalString("description", *description),
+		Published:   published.date,
+		BookSeries:  series,
+	}
+	for _, id := range authors {
+		newBook.Authors = append(newBook.Authors, &data.Author{ID: id})
+	}
+	for _, id := range genres {
+		newBook.Genres = append(newBook.Genres, &data.Genre{ID: id})
+	}
+
+	catalog, closeCatalog, err := cmd.catalog(ctx)
+	if err != nil {
+		return err
+	}
+	defer closeCatalog()
+
+	id, err := catalog.CreateBook(ctx, newBook)
+	if err != nil {
+		return err
+	}
+
+	return cmd.printCreated("book", id)
+}
+
+func editBook(ctx context.Context, args []string) error {
+	cmd := newCommand("books edit")
+	title := cmd.flags.String("title", "", "new title of the book")
+	description := cmd.flags.String("description", "", "new description of the book")
+	var published dateFlag
+	cmd.flags.Var(&published, "published", "new publication `DATE` of the book")
+	if err := cmd.parse(args); err != nil {
+		return err
+	}
+	id, err := cmd.id()
+	if err != nil {
+		return err
+	}
+
+	catalog, closeCatalog, err := cmd.catalog(ctx)
+	if err != nil {
+		return err
+	}
+	defer closeCatalog()
+
+	book, err := catalog.UpdateBook(ctx, types.Book{
+		ID:          &id,
+		Title:       cmd.optionalString("title", *title),
+		Description: cmd.optionalString("description", *description),
+		Published:   published.date,
+	})
+	if err != nil {
+		return err
+	}
+
+	return cmd.print(book, bookDetails(book))
+}
+
+func deleteBook(ctx context.Context, args []string) error {
+	cmd := newCommand("books delete")
+	if err := cmd.parse(args); err != nil {
+		return err
+	}
+	id, err := cmd.id()
+	if err != nil {
+		return err
+	}
+
+	catalog, closeCatalog, err := cmd.catalog(ctx)
+	if err != nil {
+		return err
+	}
+	defer closeCatalog()
+
+	if err := catalog.DeleteBook(ctx, id); err != nil {
+		return err
+	}
+
+	cmd.printMessage("deleted book %s", id)
+	return nil
+}
+
+func bookDetails(book *types.Book) table {
+	seriesNames := make([]string, 0, len(book.Series))
+	for _, series := range book.Series {
+		seriesNames = append(seriesNames, str(series.Name))
+	}
+	genreNames := make([]string, 0, len(book.Genres))
+	for _, genre := range book.Genres {
+		genreNames = append(genreNames, str(genre.Name))
+	}
+
+	return details(
+		[2]string{"ID", idString(book.ID)},
+		[2]string{"Title", str(book.Title)},
+		[2]string{"Description", str(book.Description)},
+		[2]string{"Published", date(book.Published)},
+		[2]string{"Authors", authorNames(book.Authors)},
+		[2]string{"Series", strings.Join(seriesNames, ", ")},
+		[2]string{"Genres", strings.Join(genreNames, ", ")},
+		[2]string{"Created", timestamp(book.CreatedAt)},
+		[2]string{"Updated", timestamp(book.UpdatedAt)},
+	)
+}
+
+func authorNames(authors []*data.Author) string {
+	names := make([]string, 0, len(authors))
+	for _, author := range authors {
+		names = append(names, str(author.Name))
+	}
+	return strings.Join(names, ", ")
+}
+
+func bookTitles(books []*types.Book) string {
+	titles := make([]string, 0, len(books))
+	for _, book := range books {
+		titles = append(titles, str(book.Title))
+	}
+	return strings.Join(titles, ", ")
+}
+
+func bookCount(books []*types.Book) string {
+	return strconv.Itoa(len(books))
+}
+
+func idString(id *uuid.UUID) string {
+	if id == nil {
+		return ""
+	}
+	return id.String()
+}
@@ -0,0 +1,367 @@
+// Package cli implements the catalog management commands of the bookshelf binary. Commands
+// work against the books module directly through the database, or against a running server
+// through the REST API.
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/r3d5un/Bookshelf/cmd/bookshelf/books"
+	"github.com/r3d5un/Bookshelf/internal/audit"
+	"github.com/r3d5un/Bookshelf/internal/books/data"
+	"github.com/r3d5un/Bookshelf/internal/config"
+	"github.com/r3d5un/Bookshelf/internal/database"
+	"github.com/r3d5un/Bookshelf/internal/events"
+	"github.com/r3d5un/Bookshelf/internal/system"
+	"github.com/r3d5un/Bookshelf/internal/validator"
+)
+
+// RemoteEnv is the environment variable holding the default server URL. When set, commands
+// use the REST API instead of connecting to the database.
+const RemoteEnv string = "BOOKSHELF_URL"
+
+const usage string = `usage: bookshelf <resource> <action> [flags] [id]
+
+resources:
+  books, authors, series, genres, trash
+
+actions:
+  list    list records matching the given filters
+  show    show a single record by ID
+  add     create a new record
+  edit    update the given fields of a record by ID
+  delete  move a record to the trash by ID
+  restore take a record out of the trash by ID
+  history list the recorded changes to a record by ID, newest first
+
+book file actions:
+  attach   attach a file, such as the ebook itself, to a book by ID
+  files    list the files attached to a book by ID
+  download write the content of an attached file by file ID
+  detach   remove an attached file by file ID
+
+trash actions:
+  list    list the deleted records, most recently deleted first
+  purge   permanently delete the records in the trash
+
+common flags:
+  -remote URL     use the REST API of the server at URL instead of the database
+                  (defaults to $BOOKSHELF_URL)
+  -output FORMAT  output format, table or json (default table)
+
+Run 'bookshelf <resource> <action> -h' for the flags of an action.`
+
+type action func(ctx context.Context, args []string) error
+
+var resources = map[string]map[string]action{
+	"books": {
+		"list":     listBooks,
+		"show":     showBook,
+		"add":      addBook,
+		"edit":     editBook,
+		"delete":   deleteBook,
+		"history":  showHistory("books", system.Books.ReadBookHistory),
+		"restore":  restoreRecord("books", "book", system.Books.RestoreBook),
+		"attach":   attachBookFile,
+		"files":    listBookFiles,
+		"download": downloadBookFile,
+		"detach":   detachBookFile,
+	},
+	"authors": {
+		"list":    listAuthors,
+		"show":    showAuthor,
+		"add":     addAuthor,
+		"edit":    editAuthor,
+		"delete":  deleteAuthor,
+		"history": showHistory("authors", system.Books.ReadAuthorHistory),
+		"restore": restoreRecord("authors", "author", system.Books.RestoreAuthor),
+	},
+	"series": {
+		"list":    listSeries,
+		"show":    showSeries,
+		"add":     addSeries,
+		"edit":    editSeries,
+		"delete":  deleteSeries,
+		"history": showHistory("series", system.Books.ReadSeriesHistory),
+		"restore": restoreRecord("series", "series", system.Books.RestoreSeries),
+	},
+	"genres": {
+		"list":    listGenres,
+		"show":    showGenre,
+		"add":     addGenre,
+		"edit":    editGenre,
+		"delete":  deleteGenre,
+		"history": showHistory("genres", system.Books.ReadGenreHistory),
+		"restore": restoreRecord("genres", "genre", system.Books.RestoreGenre),
+	},
+	"trash": {
+		"list":  listTrash,
+		"purge": purgeTrash,
+	},
+}
+
+// IsCommand reports whether the given argument names a catalog resource handled by Run.
+func IsCommand(name string) bool {
+	_, ok := resources[name]
+	return ok
+}
+
+// Run runs the catalog command described by the given arguments, e.g. books list -title Dune.
+func Run(ctx context.Context, args []string) error {
+	if len(args) < 2 {
+		fmt.Fprintln(os.Stderr, usage)
+		return errors.New("missing resource or action")
+	}
+
+	actions, ok := resources[args[0]]
+	if !ok {
+		fmt.Fprintln(os.Stderr, usage)
+		return fmt.Errorf("unknown resource %q", args[0])
+	}
+
+	run, ok := actions[args[1]]
+	if !ok {
+		fmt.Fprintln(os.Stderr, usage)
+		return fmt.Errorf("unknown %s action %q", args[0], args[1])
+	}
+
+	// Changes made by the command are recorded in the audit log as made by the user running it,
+	// as part of a single request
+	ctx = audit.WithRequestID(audit.WithActor(ctx, actor()), uuid.New())
+
+	return run(ctx, args[2:])
+}
+
+// command holds the flags shared by every action.
+type command struct {
+	flags  *flag.FlagSet
+	remote string
+	output string
+	stdout io.Writer
+}
+
+func newCommand(name string) *command {
+	cmd := &command{
+		flags:  flag.NewFlagSet(name, flag.ContinueOnError),
+		stdout: os.Stdout,
+	}
+	cmd.flags.StringVar(
+		&cmd.remote,
+		"remote",
+		os.Getenv(RemoteEnv),
+		"URL of a Bookshelf server to use instead of the database",
+	)
+	cmd.flags.StringVar(&cmd.output, "output", "table", "output format, table or json")
+
+	return cmd
+}
+
+func (c *command) parse(args []string) error {
+	if err := c.flags.Parse(args); err != nil {
+		return err
+	}
+
+	if c.output != "table" && c.output != "json" {
+		return fmt.Errorf("unknown output format %q", c.output)
+	}
+
+	return nil
+}
+
+// isSet reports whether the flag with the given name was passed on the command line.
+func (c *command) isSet(name string) bool {
+	set := false
+	c.flags.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
+}
+
+// id parses the single positional argument as a record ID.
+func (c *command) id() (uuid.UUID, error) {
+	if c.flags.NArg() != 1 {
+		return uuid.Nil, errors.New("expected a single ID argument")
+	}
+
+	id, err := uuid.Parse(c.flags.Arg(0))
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("invalid ID %q: %w", c.flags.Arg(0), err)
+	}
+
+	return id, nil
+}
+
+// catalog returns the books module to run the command against, and a function releasing
+// any resources held by it.
+func (c *command) catalog(ctx context.Context) (system.Books, func(), error) {
+	if c.remote != "" {
+		return NewClient(c.remote), func() {}, nil
+	}
+
+	// Module logs are only of interest when something goes wrong, and must not be mixed
+	// with the command output.
+	logger := slog.New(
+		slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelWarn}),
+	)
+	slog.SetDefault(logger)
+
+	cfg, err := config.New()
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to load configuration: %w", err)
+	}
+
+	db, err := database.OpenPool(
+		cfg.DB.DSN,
+		cfg.DB.MaxOpenConns,
+		cfg.DB.MaxIdleConns,
+		cfg.DB.MaxIdleTime,
+		time.Duration(cfg.DB.Timeout)*time.Second,
+	)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to open database connection pool: %w", err)
+	}
+
+	// Changes made by commands reach the subscribers of running instances, and are sent to
+	// webhooks, through the outbox. Without it, no running instance learns of them.
+	bus := events.NewBus(system.InstanceFromContext(ctx))
+	if cfg.Events != nil && cfg.Events.Outbox {
+		timeout := time.Duration(cfg.DB.Timeout) * time.Second
+		if err := bus.UseOutbox(ctx, events.NewOutbox(db, &timeout)); err != nil {
+			db.Close()
+			return nil, nil, fmt.Errorf("unable to use event outbox: %w", err)
+		}
+	}
+
+	module := &books.Module{}
+	mono := system.NewMonolith(
+		ctx,
+		logger,
+		http.NewServeMux(),
+		&system.Modules{Books: module},
+		db,
+		cfg,
+		bus,
+	)
+	if err := module.Startup(ctx, &mono); err != nil {
+		db.Close()
+		return nil, nil, err
+	}
+
+	return module, func() {
+		module.Shutdown()
+		db.Close()
+	}, nil
+}
+
+// uuidList is a repeatable flag collecting record IDs.
+type uuidList []uuid.UUID
+
+func (l *uuidList) String() string {
+	ids := make([]string, len(*l))
+	for i, id := range *l {
+		ids[i] = id.String()
+	}
+	return strings.Join(ids, ",")
+}
+
+func (l *uuidList) Set(value string) error {
+	id, err := uuid.Parse(value)
+	if err != nil {
+		return err
+	}
+	*l = append(*l, id)
+	return nil
+}
+
+// dateFlag is a flag holding an optional date in the format accepted by the REST API.
+type dateFlag struct {
+	date *time.Time
+}
+
+func (d *dateFlag) String() string {
+	if d.date == nil {
+		return ""
+	}
+	return d.date.Format(time.DateOnly)
+}
+
+func (d *dateFlag) Set(value string) error {
+	date, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		return err
+	}
+	d.date = &date
+	return nil
+}
+
+// listFlags registers the paging and filtering flags shared by the list actions.
+type listFlags struct {
+	description string
+	page        int
+	pageSize    int
+	orderBy     string
+}
+
+func (c *command) listFlags(defaultOrderBy string) *listFlags {
+	lf := listFlags{}
+	c.flags.StringVar(&lf.description, "description", "", "filter by description")
+	c.flags.IntVar(&lf.page, "page", 1, "page number")
+	c.flags.IntVar(&lf.pageSize, "page-size", 50, "number of records per page")
+	c.flags.StringVar(&lf.orderBy, "order-by", defaultOrderBy, "comma separated sort columns")
+	return &lf
+}
+
+// optionalString returns a pointer to the value of the given flag if it was set.
+func (c *command) optionalString(name string, value string) *string {
+	if !c.isSet(name) {
+		return nil
+	}
+	return &value
+}
+
+// filters builds and validates the list filters, using the same sort columns as the REST API.
+func (lf *listFlags) filters(orderBySafeList []string) (data.Filters, error) {
+	filters := data.Filters{
+		Description:     lf.description,
+		Page:            lf.page,
+		PageSize:        lf.pageSize,
+		OrderBy:         strings.Split(lf.orderBy, ","),
+		OrderBySafeList: orderBySafeList,
+	}
+
+	v := validator.New()
+	if data.ValidateFilters(v, filters); !v.Valid() {
+		msgs := make([]string, 0, len(v.Errors))
+		for key, msg := range v.Errors {
+			msgs = append(msgs, fmt.Sprintf("%s: %s", key, msg))
+		}
+		slices.Sort(msgs)
+		return filters, fmt.Errorf("invalid filters: %s", strings.Join(msgs, "; "))
+	}
+
+	return filters, nil
+}
+
+var nameOrderBySafeList = []string{
+	"id",
+	"updated_at",
+	"created_at",
+	"name",
+	"-id",
+	"-updated_at",
+	"-created_at",
+	"-name",
+}
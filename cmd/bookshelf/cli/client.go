@@ -0,0 +1,622 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/r3d5un/Bookshelf/internal/audit"
+	"github.com/r3d5un/Bookshelf/internal/books/data"
+	"github.com/r3d5un/Bookshelf/internal/books/types"
+	"github.com/r3d5un/Bookshelf/internal/idempotency"
+	"github.com/r3d5un/Bookshelf/internal/rest"
+)
+
+// Client implements the system.Books interface over the books module REST API.
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewClient creates a REST client for the Bookshelf server at the given base URL,
+// e.g. http://localhost:4000.
+func NewClient(baseURL string) *Client {
+	return &Client{
+		baseURL:    strings.TrimSuffix(baseURL, "/") + "/api/v1/books",
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// APIError is returned when the server responds with an unexpected status code.
+type APIError struct {
+	StatusCode int
+	Message    any
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("server responded with %d: %v", e.StatusCode, e.Message)
+}
+
+func (c *Client) do(
+	ctx context.Context,
+	method string,
+	path string,
+	query url.Values,
+	body any,
+	out any,
+) error {
+	_, err := c.send(ctx, method, path, query, nil, body, out)
+	return err
+}
+
+// send performs a request with the given headers, decodes the response body into out, and
+// returns the response headers.
+func (c *Client) send(
+	ctx context.Context,
+	method string,
+	path string,
+	query url.Values,
+	header http.Header,
+	body any,
+	out any,
+) (http.Header, error) {
+	endpoint := c.baseURL + path
+	if len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+
+	var reqBody io.Reader
+	contentType := "application/json"
+	switch b := body.(type) {
+	case nil:
+	case fileBody:
+		reqBody = bytes.NewReader(b.content)
+		contentType = b.contentType
+	default:
+		js, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reqBody = bytes.NewReader(js)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, reqBody)
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range header {
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil && contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if b, ok := body.(fileBody); ok {
+		req.Header.Set(
+			"Content-Disposition",
+			mime.FormatMediaType("attachment", map[string]string{"filename": b.name}),
+		)
+	}
+	if actor := audit.ActorFromContext(ctx); actor != "" {
+		req.Header.Set(audit.ActorHeader, actor)
+	}
+
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusNotFound {
+		return nil, data.ErrRecordNotFound
+	}
+	if res.StatusCode == http.StatusPreconditionFailed {
+		return nil, data.ErrEditConflict
+	}
+	if res.StatusCode >= http.StatusBadRequest {
+		var errMsg rest.ErrorMessage
+		if err := json.NewDecoder(res.Body).Decode(&errMsg); err != nil {
+			errMsg.Message = http.StatusText(res.StatusCode)
+		}
+		return nil, &APIError{StatusCode: res.StatusCode, Message: errMsg.Message}
+	}
+
+	if out == nil || res.StatusCode == http.StatusNoContent {
+		return res.Header, nil
+	}
+
+	if content, ok := out.(*[]byte); ok {
+		*content, err = io.ReadAll(res.Body)
+		return res.Header, err
+	}
+
+	err = json.NewDecoder(res.Body).Decode(out)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+
+	return res.Header, nil
+}
+
+// fileBody is a request body sent as is, rather than encoded as JSON, carrying the name of the
+// file in the Content-Disposition header. The server detects the content type if it is empty.
+type fileBody struct {
+	name        string
+	contentType string
+	content     []byte
+}
+
+func filterQuery(filters data.Filters) url.Values {
+	qs := url.Values{}
+
+	if filters.ID != nil {
+		qs.Set("id", filters.ID.String())
+	}
+	if filters.Title != "" {
+		qs.Set("title", filters.Title)
+	}
+	if filters.Name != "" {
+		qs.Set("name", filters.Name)
+	}
+	if filters.Description != "" {
+		qs.Set("description", filters.Description)
+	}
+	if filters.PublishedFrom != nil {
+		qs.Set("publishedFrom", filters.PublishedFrom.Format(time.DateOnly))
+	}
+	if filters.PublishedTo != nil {
+		qs.Set("publishedTo", filters.PublishedTo.Format(time.DateOnly))
+	}
+	if filters.Page > 0 {
+		qs.Set("page", strconv.Itoa(filters.Page))
+	}
+	if filters.PageSize > 0 {
+		qs.Set("page_size", strconv.Itoa(filters.PageSize))
+	}
+	if len(filters.OrderBy) > 0 {
+		qs.Set("order_by", strings.Join(filters.OrderBy, ","))
+	}
+
+	return qs
+}
+
+// createAttempts is the number of times a create request is sent before giving up, when the
+// server cannot be reached or is unavailable.
+const createAttempts = 3
+
+// create posts the new record to the collection at the given path, and decodes the created record
+// into out. Every attempt carries the same idempotency key, so that the record is created once
+// however many attempts reach the server.
+func (c *Client) create(ctx context.Context, path string, body any, out any) error {
+	header := http.Header{}
+	header.Set(idempotency.Header, uuid.NewString())
+
+	var err error
+	for attempt := 1; attempt <= createAttempts; attempt++ {
+		_, err = c.send(ctx, http.MethodPost, path, nil, header, body, out)
+		if !retryable(ctx, err) || attempt == createAttempts {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Duration(attempt) * 500 * time.Millisecond):
+		}
+	}
+
+	return err
+}
+
+// retryable reports whether a request failed because the server could not be reached, or was
+// unavailable.
+func retryable(ctx context.Context, err error) bool {
+	if err == nil || ctx.Err() != nil {
+		return false
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		switch apiErr.StatusCode {
+		case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return true
+		}
+		return false
+	}
+
+	var urlErr *url.Error
+	return errors.As(err, &urlErr)
+}
+
+// ifMatch returns the If-Match header of a change to the resource at the given path. Changes
+// based on a known version of the resource are only made if it is still current. Other changes
+// are based on the version of the resource read right before them.
+func (c *Client) ifMatch(ctx context.Context, path string, updatedAt *time.Time) (http.Header, error) {
+	tag := rest.ETag(updatedAt)
+	if tag == "" {
+		res, err := c.send(ctx, http.MethodGet, path, nil, nil, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		tag = res.Get("ETag")
+	}
+
+	header := http.Header{}
+	header.Set("If-Match", tag)
+	return header, nil
+}
+
+// Authors
+
+func (c *Client) CreateAuthor(ctx context.Context, newAuthor types.NewAuthorData) (*uuid.UUID, error) {
+	var created types.Author
+	if err := c.create(ctx, "/authors", newAuthor, &created); err != nil {
+		return nil, err
+	}
+	return &created.ID, nil
+}
+
+func (c *Client) ReadAuthor(ctx context.Context, id uuid.UUID) (*types.Author, error) {
+	var author types.Author
+	if err := c.do(ctx, http.MethodGet, "/authors/"+id.String(), nil, nil, &author); err != nil {
+		return nil, err
+	}
+	return &author, nil
+}
+
+func (c *Client) ReadAllAuthors(ctx context.Context, filters data.Filters) ([]*types.Author, error) {
+	var authors []*types.Author
+	if err := c.do(ctx, http.MethodGet, "/authors", filterQuery(filters), nil, &authors); err != nil {
+		return nil, err
+	}
+	return authors, nil
+}
+
+func (c *Client) UpdateAuthor(ctx context.Context, author types.Author) (*types.Author, error) {
+	var updated types.Author
+	path := "/authors/" + author.ID.String()
+	header, err := c.ifMatch(ctx, path, author.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := c.send(ctx, http.MethodPatch, path, nil, header, author.Patch(), &updated); err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+func (c *Client) DeleteAuthor(ctx context.Context, id uuid.UUID) error {
+	path := "/authors/" + id.String()
+	header, err := c.ifMatch(ctx, path, nil)
+	if err != nil {
+		return err
+	}
+	_, err = c.send(ctx, http.MethodDelete, path, nil, header, nil, nil)
+	return err
+}
+
+func (c *Client) RestoreAuthor(ctx context.Context, id uuid.UUID) (*types.Author, error) {
+	var author types.Author
+	path := "/authors/" + id.String() + "/restore"
+	if err := c.do(ctx, http.MethodPost, path, nil, nil, &author); err != nil {
+		return nil, err
+	}
+	return &author, nil
+}
+
+func (c *Client) ReadAuthorHistory(
+	ctx context.Context,
+	id uuid.UUID,
+	filters data.Filters,
+) ([]*data.AuditEntry, error) {
+	var entries []*data.AuditEntry
+	path := "/authors/" + id.String() + "/history"
+	if err := c.do(ctx, http.MethodGet, path, filterQuery(filters), nil, &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// Series
+
+func (c *Client) CreateSeries(ctx context.Context, newSeries types.NewSeriesData) (*uuid.UUID, error) {
+	var created types.Series
+	if err := c.create(ctx, "/series", newSeries, &created); err != nil {
+		return nil, err
+	}
+	return &created.ID, nil
+}
+
+func (c *Client) ReadSeries(ctx context.Context, id uuid.UUID) (*types.Series, error) {
+	var series types.Series
+	if err := c.do(ctx, http.MethodGet, "/series/"+id.String(), nil, nil, &series); err != nil {
+		return nil, err
+	}
+	return &series, nil
+}
+
+func (c *Client) ReadAllSeries(ctx context.Context, filters data.Filters) ([]*types.Series, error) {
+	var series []*types.Series
+	if err := c.do(ctx, http.MethodGet, "/series", filterQuery(filters), nil, &series); err != nil {
+		return nil, err
+	}
+	return series, nil
+}
+
+func (c *Client) UpdateSeries(ctx context.Context, series types.Series) (*types.Series, error) {
+	var updated types.Series
+	path := "/series/" + series.ID.String()
+	header, err := c.ifMatch(ctx, path, series.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := c.send(ctx, http.MethodPatch, path, nil, header, series.Patch(), &updated); err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+func (c *Client) DeleteSeries(ctx context.Context, id uuid.UUID) error {
+	path := "/series/" + id.String()
+	header, err := c.ifMatch(ctx, path, nil)
+	if err != nil {
+		return err
+	}
+	_, err = c.send(ctx, http.MethodDelete, path, nil, header, nil, nil)
+	return err
+}
+
+func (c *Client) RestoreSeries(ctx context.Context, id uuid.UUID) (*types.Series, error) {
+	var series types.Series
+	path := "/series/" + id.String() + "/restore"
+	if err := c.do(ctx, http.MethodPost, path, nil, nil, &series); err != nil {
+		return nil, err
+	}
+	return &series, nil
+}
+
+func (c *Client) ReadSeriesHistory(
+	ctx context.Context,
+	id uuid.UUID,
+	filters data.Filters,
+) ([]*data.AuditEntry, error) {
+	var entries []*data.AuditEntry
+	path := "/series/" + id.String() + "/history"
+	if err := c.do(ctx, http.MethodGet, path, filterQuery(filters), nil, &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// Genres
+
+func (c *Client) CreateGenre(ctx context.Context, newGenre types.NewGenreData) (*uuid.UUID, error) {
+	var created types.Genre
+	if err := c.create(ctx, "/genre", newGenre, &created); err != nil {
+		return nil, err
+	}
+	return &created.ID, nil
+}
+
+func (c *Client) ReadGenre(ctx context.Context, id uuid.UUID) (*types.Genre, error) {
+	var genre types.Genre
+	if err := c.do(ctx, http.MethodGet, "/genre/"+id.String(), nil, nil, &genre); err != nil {
+		return nil, err
+	}
+	return &genre, nil
+}
+
+func (c *Client) ReadAllGenre(ctx context.Context, filters data.Filters) ([]*types.Genre, error) {
+	var genres []*types.Genre
+	if err := c.do(ctx, http.MethodGet, "/genre", filterQuery(filters), nil, &genres); err != nil {
+		return nil, err
+	}
+	return genres, nil
+}
+
+func (c *Client) UpdateGenre(ctx context.Context, genre types.Genre) (*types.Genre, error) {
+	var updated types.Genre
+	path := "/genre/" + genre.ID.String()
+	header, err := c.ifMatch(ctx, path, genre.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := c.send(ctx, http.MethodPatch, path, nil, header, genre.Patch(), &updated); err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+func (c *Client) DeleteGenre(ctx context.Context, id uuid.UUID) error {
+	path := "/genre/" + id.String()
+	header, err := c.ifMatch(ctx, path, nil)
+	if err != nil {
+		return err
+	}
+	_, err = c.send(ctx, http.MethodDelete, path, nil, header, nil, nil)
+	return err
+}
+
+func (c *Client) RestoreGenre(ctx context.Context, id uuid.UUID) (*types.Genre, error) {
+	var genre types.Genre
+	path := "/genre/" + id.String() + "/restore"
+	if err := c.do(ctx, http.MethodPost, path, nil, nil, &genre); err != nil {
+		return nil, err
+	}
+	return &genre, nil
+}
+
+func (c *Client) ReadGenreHistory(
+	ctx context.Context,
+	id uuid.UUID,
+	filters data.Filters,
+) ([]*data.AuditEntry, error) {
+	var entries []*data.AuditEntry
+	path := "/genre/" + id.String() + "/history"
+	if err := c.do(ctx, http.MethodGet, path, filterQuery(filters), nil, &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// Books
+
+func (c *Client) CreateBook(ctx context.Context, newBook types.Book) (*uuid.UUID, error) {
+	var created types.Book
+	if err := c.create(ctx, "/books", newBook, &created); err != nil {
+		return nil, err
+	}
+	return created.ID, nil
+}
+
+func (c *Client) ReadBook(ctx context.Context, id uuid.UUID) (*types.Book, error) {
+	var book types.Book
+	if err := c.do(ctx, http.MethodGet, "/books/"+id.String(), nil, nil, &book); err != nil {
+		return nil, err
+	}
+	return &book, nil
+}
+
+func (c *Client) ReadAllBook(ctx context.Context, filters data.Filters) ([]*types.Book, error) {
+	var books []*types.Book
+	if err := c.do(ctx, http.MethodGet, "/books", filterQuery(filters), nil, &books); err != nil {
+		return nil, err
+	}
+	return books, nil
+}
+
+func (c *Client) ReadBooksBySeries(ctx context.Context, seriesID uuid.UUID) ([]*types.Book, error) {
+	series, err := c.ReadSeries(ctx, seriesID)
+	if err != nil {
+		return nil, err
+	}
+	return series.Books, nil
+}
+
+func (c *Client) UpdateBook(ctx context.Context, book types.Book) (*types.Book, error) {
+	if book.ID == nil {
+		return nil, errors.New("book ID is required")
+	}
+
+	var updated types.Book
+	path := "/books/" + book.ID.String()
+	header, err := c.ifMatch(ctx, path, book.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := c.send(ctx, http.MethodPatch, path, nil, header, book.Patch(), &updated); err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+func (c *Client) DeleteBook(ctx context.Context, id uuid.UUID) error {
+	path := "/books/" + id.String()
+	header, err := c.ifMatch(ctx, path, nil)
+	if err != nil {
+		return err
+	}
+	_, err = c.send(ctx, http.MethodDelete, path, nil, header, nil, nil)
+	return err
+}
+
+func (c *Client) RestoreBook(ctx context.Context, id uuid.UUID) (*types.Book, error) {
+	var book types.Book
+	path := "/books/" + id.String() + "/restore"
+	if err := c.do(ctx, http.MethodPost, path, nil, nil, &book); err != nil {
+		return nil, err
+	}
+	return &book, nil
+}
+
+func (c *Client) ReadBookHistory(
+	ctx context.Context,
+	id uuid.UUID,
+	filters data.Filters,
+) ([]*data.AuditEntry, error) {
+	var entries []*data.AuditEntry
+	path := "/books/" + id.String() + "/history"
+	if err := c.do(ctx, http.MethodGet, path, filterQuery(filters), nil, &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// Book files
+
+func (c *Client) AttachBookFile(
+	ctx context.Context,
+	bookID uuid.UUID,
+	name string,
+	contentType string,
+	content []byte,
+) (*data.BookFile, error) {
+	var file data.BookFile
+	body := fileBody{name: name, contentType: contentType, content: content}
+	if err := c.create(ctx, "/books/"+bookID.String()+"/files", body, &file); err != nil {
+		return nil, err
+	}
+	return &file, nil
+}
+
+func (c *Client) ReadBookFiles(ctx context.Context, bookID uuid.UUID) ([]*data.BookFile, error) {
+	var files []*data.BookFile
+	path := "/books/" + bookID.String() + "/files"
+	if err := c.do(ctx, http.MethodGet, path, nil, nil, &files); err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+func (c *Client) ReadBookFileContent(
+	ctx context.Context,
+	id uuid.UUID,
+) (*data.BookFile, []byte, error) {
+	var file data.BookFile
+	if err := c.do(ctx, http.MethodGet, "/files/"+id.String(), nil, nil, &file); err != nil {
+		return nil, nil, err
+	}
+
+	var content []byte
+	path := "/files/" + id.String() + "/content"
+	if err := c.do(ctx, http.MethodGet, path, nil, nil, &content); err != nil {
+		return nil, nil, err
+	}
+	return &file, content, nil
+}
+
+func (c *Client) DeleteBookFile(ctx context.Context, id uuid.UUID) error {
+	return c.do(ctx, http.MethodDelete, "/files/"+id.String(), nil, nil, nil)
+}
+
+// Trash
+
+func (c *Client) ReadTrash(ctx context.Context, filters data.Filters) (*types.Trash, error) {
+	var trash types.Trash
+	if err := c.do(ctx, http.MethodGet, "/trash", filterQuery(filters), nil, &trash); err != nil {
+		return nil, err
+	}
+	return &trash, nil
+}
+
+func (c *Client) PurgeTrash(ctx context.Context, before time.Time) (*types.PurgedTrash, error) {
+	var purged types.PurgedTrash
+	qs := url.Values{}
+	qs.Set("before", before.UTC().Format("2006-01-02T15:04:05"))
+	if err := c.do(ctx, http.MethodDelete, "/trash", qs, nil, &purged); err != nil {
+		return nil, err
+	}
+	return &purged, nil
+}
@@ -0,0 +1,286 @@
+package cli_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/r3d5un/Bookshelf/cmd/bookshelf/cli"
+	"github.com/r3d5un/Bookshelf/internal/audit"
+	"github.com/r3d5un/Bookshelf/internal/books/data"
+	"github.com/r3d5un/Bookshelf/internal/books/types"
+	"github.com/r3d5un/Bookshelf/internal/idempotency"
+	"github.com/r3d5un/Bookshelf/internal/rest"
+	"github.com/r3d5un/Bookshelf/internal/system"
+)
+
+var _ system.Books = (*cli.Client)(nil)
+
+func TestClient(t *testing.T) {
+	authorID := uuid.New()
+	authorName := "Frank Herbert"
+	updatedAt := time.Date(2026, 1, 2, 3, 4, 5, 6000, time.UTC)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/books/authors/{id}", func(w http.ResponseWriter, r *http.Request) {
+		if r.PathValue("id") != authorID.String() {
+			rest.NotFoundResponse(w, r)
+			return
+		}
+		author := types.Author{ID: authorID, Name: &authorName, UpdatedAt: &updatedAt}
+		rest.Respond(w, r, http.StatusOK, author, rest.ETagHeader(&updatedAt))
+	})
+	mux.HandleFunc("PATCH /api/v1/books/authors/{id}", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-Match") != rest.ETag(&updatedAt) {
+			rest.PreconditionFailedResponse(w, r)
+			return
+		}
+		rest.Respond(w, r, http.StatusOK, types.Author{ID: authorID, Name: &authorName}, nil)
+	})
+	mux.HandleFunc("GET /api/v1/books/authors", func(w http.ResponseWriter, r *http.Request) {
+		qs := r.URL.Query()
+		if qs.Get("name") != "Frank" || qs.Get("page") != "2" || qs.Get("order_by") != "-name" {
+			rest.BadRequestResponse(w, r, "unexpected query string: "+r.URL.RawQuery)
+			return
+		}
+		rest.Respond(w, r, http.StatusOK, []types.Author{{ID: authorID, Name: &authorName}}, nil)
+	})
+	mux.HandleFunc("DELETE /api/v1/books/authors/{id}", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(audit.ActorHeader) != "cli:librarian" {
+			rest.BadRequestResponse(w, r, "unexpected actor: "+r.Header.Get(audit.ActorHeader))
+			return
+		}
+		if r.Header.Get("If-Match") != rest.ETag(&updatedAt) {
+			rest.PreconditionFailedResponse(w, r)
+			return
+		}
+		rest.Respond(w, r, http.StatusNoContent, nil, nil)
+	})
+	mux.HandleFunc("GET /api/v1/books/authors/{id}/history", func(w http.ResponseWriter, r *http.Request) {
+		entries := []data.AuditEntry{{
+			EntityType: "authors",
+			EntityID:   authorID,
+			Action:     "delete",
+			Actor:      "cli:librarian",
+			Changes:    []byte(`{"name": {"before": "Frank Herbert", "after": null}}`),
+		}}
+		rest.Respond(w, r, http.StatusOK, entries, nil)
+	})
+	mux.HandleFunc("POST /api/v1/books/authors/{id}/restore", func(w http.ResponseWriter, r *http.Request) {
+		rest.Respond(w, r, http.StatusOK, types.Author{ID: authorID, Name: &authorName}, nil)
+	})
+	mux.HandleFunc("DELETE /api/v1/books/trash", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("before") != "2026-01-02T03:04:05" {
+			rest.BadRequestResponse(w, r, "unexpected query string: "+r.URL.RawQuery)
+			return
+		}
+		rest.Respond(w, r, http.StatusOK, types.PurgedTrash{Books: 2, Authors: 1}, nil)
+	})
+	var createKeys []string
+	mux.HandleFunc("POST /api/v1/books/authors", func(w http.ResponseWriter, r *http.Request) {
+		createKeys = append(createKeys, r.Header.Get(idempotency.Header))
+		if len(createKeys) == 1 {
+			rest.ErrorResponse(w, r, http.StatusServiceUnavailable, "try again")
+			return
+		}
+		rest.Respond(w, r, http.StatusCreated, types.Author{ID: authorID, Name: &authorName}, nil)
+	})
+	mux.HandleFunc("POST /api/v1/books/genre", func(w http.ResponseWriter, r *http.Request) {
+		rest.BadRequestResponse(w, r, "name is required")
+	})
+	bookID := uuid.New()
+	fileID := uuid.New()
+	fileContent := []byte("In the week before their departure to Arrakis")
+	mux.HandleFunc("POST /api/v1/books/books/{id}/files", func(w http.ResponseWriter, r *http.Request) {
+		_, params, err := mime.ParseMediaType(r.Header.Get("Content-Disposition"))
+		if err != nil || params["filename"] != "dune.txt" {
+			rest.BadRequestResponse(w, r, "unexpected file name")
+			return
+		}
+		content, err := io.ReadAll(r.Body)
+		if err != nil || !bytes.Equal(content, fileContent) {
+			rest.BadRequestResponse(w, r, "unexpected file content")
+			return
+		}
+		file := data.BookFile{
+			ID:          fileID,
+			BookID:      bookID,
+			Name:        params["filename"],
+			ContentType: r.Header.Get("Content-Type"),
+			Size:        int64(len(content)),
+		}
+		rest.Respond(w, r, http.StatusCreated, file, nil)
+	})
+	mux.HandleFunc("GET /api/v1/books/files/{id}", func(w http.ResponseWriter, r *http.Request) {
+		rest.Respond(w, r, http.StatusOK, data.BookFile{ID: fileID, Name: "dune.txt"}, nil)
+	})
+	mux.HandleFunc("GET /api/v1/books/files/{id}/content", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		w.Write(fileContent)
+	})
+
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	client := cli.NewClient(srv.URL + "/")
+	ctx := context.Background()
+
+	t.Run("ReadAuthor", func(t *testing.T) {
+		author, err := client.ReadAuthor(ctx, authorID)
+		if err != nil {
+			t.Errorf("unable to read author: %s\n", err)
+			return
+		}
+		if author.Name == nil || *author.Name != authorName {
+			t.Errorf("expected author name %s, got %v\n", authorName, author.Name)
+			return
+		}
+	})
+
+	t.Run("ReadAuthorNotFound", func(t *testing.T) {
+		_, err := client.ReadAuthor(ctx, uuid.New())
+		if !errors.Is(err, data.ErrRecordNotFound) {
+			t.Errorf("expected %s, got %v\n", data.ErrRecordNotFound, err)
+			return
+		}
+	})
+
+	t.Run("ReadAllAuthors", func(t *testing.T) {
+		authors, err := client.ReadAllAuthors(ctx, data.Filters{
+			Name:     "Frank",
+			Page:     2,
+			PageSize: 10,
+			OrderBy:  []string{"-name"},
+		})
+		if err != nil {
+			t.Errorf("unable to read authors: %s\n", err)
+			return
+		}
+		if len(authors) != 1 || authors[0].ID != authorID {
+			t.Errorf("unexpected authors: %v\n", authors)
+			return
+		}
+	})
+
+	t.Run("UpdateAuthor", func(t *testing.T) {
+		_, err := client.UpdateAuthor(ctx, types.Author{ID: authorID, Name: &authorName})
+		if err != nil {
+			t.Errorf("unable to update author: %s\n", err)
+			return
+		}
+	})
+
+	t.Run("UpdateAuthorConflict", func(t *testing.T) {
+		stale := updatedAt.Add(-time.Second)
+		_, err := client.UpdateAuthor(ctx, types.Author{ID: authorID, UpdatedAt: &stale})
+		if !errors.Is(err, data.ErrEditConflict) {
+			t.Errorf("expected %s, got %v\n", data.ErrEditConflict, err)
+			return
+		}
+	})
+
+	t.Run("DeleteAuthorAsActor", func(t *testing.T) {
+		err := client.DeleteAuthor(audit.WithActor(ctx, "cli:librarian"), authorID)
+		if err != nil {
+			t.Errorf("unable to delete author: %s\n", err)
+			return
+		}
+	})
+
+	t.Run("ReadAuthorHistory", func(t *testing.T) {
+		entries, err := client.ReadAuthorHistory(ctx, authorID, data.Filters{Page: 1, PageSize: 10})
+		if err != nil {
+			t.Errorf("unable to read author history: %s\n", err)
+			return
+		}
+		if len(entries) != 1 || entries[0].Action != "delete" || entries[0].Actor != "cli:librarian" {
+			t.Errorf("unexpected history: %v\n", entries)
+			return
+		}
+	})
+
+	t.Run("RestoreAuthor", func(t *testing.T) {
+		author, err := client.RestoreAuthor(ctx, authorID)
+		if err != nil {
+			t.Errorf("unable to restore author: %s\n", err)
+			return
+		}
+		if author.ID != authorID {
+			t.Errorf("expected author %s, got %s\n", authorID, author.ID)
+			return
+		}
+	})
+
+	t.Run("PurgeTrash", func(t *testing.T) {
+		before := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+		purged, err := client.PurgeTrash(ctx, before)
+		if err != nil {
+			t.Errorf("unable to purge trash: %s\n", err)
+			return
+		}
+		if purged.Books != 2 || purged.Authors != 1 {
+			t.Errorf("unexpected purge counts: %+v\n", purged)
+			return
+		}
+	})
+
+	t.Run("CreateAuthorRetry", func(t *testing.T) {
+		id, err := client.CreateAuthor(ctx, types.NewAuthorData{Name: authorName})
+		if err != nil {
+			t.Errorf("unable to create author: %s\n", err)
+			return
+		}
+		if id == nil || *id != authorID {
+			t.Errorf("expected author ID %s, got %v\n", authorID, id)
+			return
+		}
+		if len(createKeys) != 2 || createKeys[0] == "" || createKeys[0] != createKeys[1] {
+			t.Errorf("expected two attempts with the same idempotency key, got %v\n", createKeys)
+			return
+		}
+	})
+
+	t.Run("AttachBookFile", func(t *testing.T) {
+		file, err := client.AttachBookFile(ctx, bookID, "dune.txt", "text/plain", fileContent)
+		if err != nil {
+			t.Errorf("unable to attach file: %s\n", err)
+			return
+		}
+		if file.ID != fileID || file.ContentType != "text/plain" {
+			t.Errorf("unexpected file: %+v\n", file)
+			return
+		}
+	})
+
+	t.Run("ReadBookFileContent", func(t *testing.T) {
+		file, content, err := client.ReadBookFileContent(ctx, fileID)
+		if err != nil {
+			t.Errorf("unable to read file: %s\n", err)
+			return
+		}
+		if file.Name != "dune.txt" || !bytes.Equal(content, fileContent) {
+			t.Errorf("unexpected file %+v with content %q\n", file, content)
+			return
+		}
+	})
+
+	t.Run("CreateGenreBadRequest", func(t *testing.T) {
+		_, err := client.CreateGenre(ctx, types.NewGenreData{})
+		var apiErr *cli.APIError
+		if !errors.As(err, &apiErr) {
+			t.Errorf("expected an API error, got %v\n", err)
+			return
+		}
+		if apiErr.StatusCode != http.StatusBadRequest {
+			t.Errorf("expected status %d, got %d\n", http.StatusBadRequest, apiErr.StatusCode)
+			return
+		}
+	})
+}
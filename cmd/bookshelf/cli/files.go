@@ -0,0 +1,180 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mime"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/r3d5un/Bookshelf/internal/books/data"
+	"github.com/r3d5un/Bookshelf/internal/books/types"
+)
+
+func attachBookFile(ctx context.Context, args []string) error {
+	cmd := newCommand("books attach")
+	path := cmd.flags.String("file", "", "`PATH` of the file to attach (required)")
+	name := cmd.flags.String("name", "", "name of the attached file (defaults to the file name)")
+	contentType := cmd.flags.String(
+		"type",
+		"",
+		"content type of the file (defaults to the type of the file extension, or the content)",
+	)
+	if err := cmd.parse(args); err != nil {
+		return err
+	}
+	id, err := cmd.id()
+	if err != nil {
+		return err
+	}
+	if *path == "" {
+		return errors.New("file is required")
+	}
+
+	info, err := os.Stat(*path)
+	if err != nil {
+		return err
+	}
+	if info.Size() > types.MaxFileSize {
+		return fmt.Errorf("%s is larger than %d bytes", *path, types.MaxFileSize)
+	}
+	content, err := os.ReadFile(*path)
+	if err != nil {
+		return err
+	}
+	if *name == "" {
+		*name = filepath.Base(*path)
+	}
+	if *contentType == "" {
+		*contentType = mime.TypeByExtension(filepath.Ext(*path))
+	}
+
+	catalog, closeCatalog, err := cmd.catalog(ctx)
+	if err != nil {
+		return err
+	}
+	defer closeCatalog()
+
+	file, err := catalog.AttachBookFile(ctx, id, *name, *contentType, content)
+	if err != nil {
+		return err
+	}
+
+	return cmd.print(file, bookFileDetails(file))
+}
+
+func listBookFiles(ctx context.Context, args []string) error {
+	cmd := newCommand("books files")
+	if err := cmd.parse(args); err != nil {
+		return err
+	}
+	id, err := cmd.id()
+	if err != nil {
+		return err
+	}
+
+	catalog, closeCatalog, err := cmd.catalog(ctx)
+	if err != nil {
+		return err
+	}
+	defer closeCatalog()
+
+	files, err := catalog.ReadBookFiles(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	t := table{header: []string{"ID", "NAME", "TYPE", "SIZE", "CREATED"}}
+	for _, file := range files {
+		t.rows = append(t.rows, []string{
+			file.ID.String(),
+			file.Name,
+			file.ContentType,
+			strconv.FormatInt(file.Size, 10),
+			timestamp(file.CreatedAt),
+		})
+	}
+
+	return cmd.print(files, t)
+}
+
+func downloadBookFile(ctx context.Context, args []string) error {
+	cmd := newCommand("books download")
+	out := cmd.flags.String(
+		"o",
+		"",
+		"`PATH` to write the file to, or - for standard output (defaults to the file name)",
+	)
+	if err := cmd.parse(args); err != nil {
+		return err
+	}
+	id, err := cmd.id()
+	if err != nil {
+		return err
+	}
+
+	catalog, closeCatalog, err := cmd.catalog(ctx)
+	if err != nil {
+		return err
+	}
+	defer closeCatalog()
+
+	file, content, err := catalog.ReadBookFileContent(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if *out == "-" {
+		_, err := cmd.stdout.Write(content)
+		return err
+	}
+	if *out == "" {
+		// The name is chosen by whoever attached the file, and must not lead outside the
+		// working directory
+		*out = filepath.Base(file.Name)
+	}
+	if err := os.WriteFile(*out, content, 0o644); err != nil {
+		return err
+	}
+
+	cmd.printMessage("downloaded file %s to %s", id, *out)
+	return nil
+}
+
+func detachBookFile(ctx context.Context, args []string) error {
+	cmd := newCommand("books detach")
+	if err := cmd.parse(args); err != nil {
+		return err
+	}
+	id, err := cmd.id()
+	if err != nil {
+		return err
+	}
+
+	catalog, closeCatalog, err := cmd.catalog(ctx)
+	if err != nil {
+		return err
+	}
+	defer closeCatalog()
+
+	if err := catalog.DeleteBookFile(ctx, id); err != nil {
+		return err
+	}
+
+	cmd.printMessage("detached file %s", id)
+	return nil
+}
+
+func bookFileDetails(file *data.BookFile) table {
+	return details(
+		[2]string{"ID", file.ID.String()},
+		[2]string{"Book", file.BookID.String()},
+		[2]string{"Name", file.Name},
+		[2]string{"Type", file.ContentType},
+		[2]string{"Size", strconv.FormatInt(file.Size, 10)},
+		[2]string{"Checksum", file.Checksum},
+		[2]string{"Created", timestamp(file.CreatedAt)},
+	)
+}
@@ -0,0 +1,163 @@
+package cli
+
+import (
+	"context"
+	"errors"
+
+	"github.com/r3d5un/Bookshelf/internal/books/types"
+)
+
+func listGenres(ctx context.Context, args []string) error {
+	cmd := newCommand("genres list")
+	name := cmd.flags.String("name", "", "filter by name")
+	lf := cmd.listFlags("name")
+	if err := cmd.parse(args); err != nil {
+		return err
+	}
+
+	filters, err := lf.filters(nameOrderBySafeList)
+	if err != nil {
+		return err
+	}
+	filters.Name = *name
+
+	catalog, closeCatalog, err := cmd.catalog(ctx)
+	if err != nil {
+		return err
+	}
+	defer closeCatalog()
+
+	genres, err := catalog.ReadAllGenre(ctx, filters)
+	if err != nil {
+		return err
+	}
+
+	t := table{header: []string{"ID", "NAME", "BOOKS"}}
+	for _, genre := range genres {
+		t.rows = append(t.rows, []string{
+			genre.ID.String(),
+			str(genre.Name),
+			bookCount(genre.Books),
+		})
+	}
+
+	return cmd.print(genres, t)
+}
+
+func showGenre(ctx context.Context, args []string) error {
+	cmd := newCommand("genres show")
+	if err := cmd.parse(args); err != nil {
+		return err
+	}
+	id, err := cmd.id()
+	if err != nil {
+		return err
+	}
+
+	catalog, closeCatalog, err := cmd.catalog(ctx)
+	if err != nil {
+		return err
+	}
+	defer closeCatalog()
+
+	genre, err := catalog.ReadGenre(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return cmd.print(genre, details(
+		[2]string{"ID", genre.ID.String()},
+		[2]string{"Name", str(genre.Name)},
+		[2]string{"Description", str(genre.Description)},
+		[2]string{"Books", bookTitles(genre.Books)},
+		[2]string{"Created", timestamp(genre.CreatedAt)},
+		[2]string{"Updated", timestamp(genre.UpdatedAt)},
+	))
+}
+
+func addGenre(ctx context.Context, args []string) error {
+	cmd := newCommand("genres add")
+	name := cmd.flags.String("name", "", "name of the genre (required)")
+	description := cmd.flags.String("description", "", "description of the genre")
+	if err := cmd.parse(args); err != nil {
+		return err
+	}
+	if *name == "" {
+		return errors.New("name is required")
+	}
+
+	catalog, closeCatalog, err := cmd.catalog(ctx)
+	if err != nil {
+		return err
+	}
+	defer closeCatalog()
+
+	id, err := catalog.CreateGenre(ctx, types.NewGenreData{
+		Name:        *name,
+		Description: cmd.optionalString("description", *description),
+	})
+	if err != nil {
+		return err
+	}
+
+	return cmd.printCreated("genre", id)
+}
+
+func editGenre(ctx context.Context, args []string) error {
+	cmd := newCommand("genres edit")
+	name := cmd.flags.String("name", "", "new name of the genre")
+	description := cmd.flags.String("description", "", "new description of the genre")
+	if err := cmd.parse(args); err != nil {
+		return err
+	}
+	id, err := cmd.id()
+	if err != nil {
+		return err
+	}
+
+	catalog, closeCatalog, err := cmd.catalog(ctx)
+	if err != nil {
+		return err
+	}
+	defer closeCatalog()
+
+	genre, err := catalog.UpdateGenre(ctx, types.Genre{
+		ID:          id,
+		Name:        cmd.optionalString("name", *name),
+		Description: cmd.optionalString("description", *description),
+	})
+	if err != nil {
+		return err
+	}
+
+	return cmd.print(genre, details(
+		[2]string{"ID", genre.ID.String()},
+		[2]string{"Name", str(genre.Name)},
+		[2]string{"Description", str(genre.Description)},
+		[2]string{"Updated", timestamp(genre.UpdatedAt)},
+	))
+}
+
+func deleteGenre(ctx context.Context, args []string) error {
+	cmd := newCommand("genres delete")
+	if err := cmd.parse(args); err != nil {
+		return err
+	}
+	id, err := cmd.id()
+	if err != nil {
+		return err
+	}
+
+	catalog, closeCatalog, err := cmd.catalog(ctx)
+	if err != nil {
+		return err
+	}
+	defer closeCatalog()
+
+	if err := catalog.DeleteGenre(ctx, id); err != nil {
+		return err
+	}
+
+	cmd.printMessage("deleted genre %s", id)
+	return nil
+}
@@ -0,0 +1,91 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os/user"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/r3d5un/Bookshelf/internal/books/data"
+	"github.com/r3d5un/Bookshelf/internal/system"
+)
+
+// historyReader reads the history of a record of a single resource from the catalog.
+type historyReader func(
+	catalog system.Books,
+	ctx context.Context,
+	id uuid.UUID,
+	filters data.Filters,
+) ([]*data.AuditEntry, error)
+
+// showHistory returns the history action of the resource, e.g. system.Books.ReadBookHistory for
+// books.
+func showHistory(resource string, read historyReader) action {
+	return func(ctx context.Context, args []string) error {
+		cmd := newCommand(resource + " history")
+		page := cmd.flags.Int("page", 1, "page number")
+		pageSize := cmd.flags.Int("page-size", 50, "number of changes per page")
+		if err := cmd.parse(args); err != nil {
+			return err
+		}
+		id, err := cmd.id()
+		if err != nil {
+			return err
+		}
+		if *page < 1 || *pageSize < 1 {
+			return errors.New("page and page size must be greater than zero")
+		}
+
+		catalog, closeCatalog, err := cmd.catalog(ctx)
+		if err != nil {
+			return err
+		}
+		defer closeCatalog()
+
+		entries, err := read(catalog, ctx, id, data.Filters{Page: *page, PageSize: *pageSize})
+		if err != nil {
+			return err
+		}
+
+		t := table{header: []string{"TIME", "ACTOR", "ACTION", "RECORD", "CHANGED"}}
+		for _, entry := range entries {
+			t.rows = append(t.rows, []string{
+				timestamp(entry.CreatedAt),
+				entry.Actor,
+				entry.Action,
+				entry.EntityType,
+				changedFields(entry.Changes),
+			})
+		}
+
+		return cmd.print(entries, t)
+	}
+}
+
+// changedFields lists the names of the fields changed by an audit log entry.
+func changedFields(changes json.RawMessage) string {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(changes, &fields); err != nil {
+		return ""
+	}
+
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	return strings.Join(names, ",")
+}
+
+// actor names the user running the command in the audit log.
+func actor() string {
+	u, err := user.Current()
+	if err != nil || u.Username == "" {
+		return "cli"
+	}
+	return "cli:" + u.Username
+}
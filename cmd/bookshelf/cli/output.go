@@ -0,0 +1,83 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// table is the tabular representation of command output.
+type table struct {
+	header []string
+	rows   [][]string
+}
+
+// print writes the value as indented JSON, or the table if the table output format is used.
+func (c *command) print(v any, t table) error {
+	if c.output == "json" {
+		enc := json.NewEncoder(c.stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	}
+
+	tw := tabwriter.NewWriter(c.stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join(t.header, "\t"))
+	for _, row := range t.rows {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
+
+// details is the table output of a single record, listing one field per row.
+func details(fields ...[2]string) table {
+	t := table{header: []string{"FIELD", "VALUE"}}
+	for _, field := range fields {
+		t.rows = append(t.rows, []string{field[0], field[1]})
+	}
+	return t
+}
+
+// printMessage writes an informational message, which is left out of JSON output.
+func (c *command) printMessage(format string, a ...any) {
+	if c.output == "json" {
+		return
+	}
+	fmt.Fprintf(c.stdout, format+"\n", a...)
+}
+
+func str(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func date(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(time.DateOnly)
+}
+
+func timestamp(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+// printCreated reports the ID of a created record.
+func (c *command) printCreated(resource string, id *uuid.UUID) error {
+	if c.output == "json" {
+		return c.print(struct {
+			ID *uuid.UUID `json:"id"`
+		}{ID: id}, table{})
+	}
+
+	c.printMessage("created %s %s", resource, id)
+	return nil
+}
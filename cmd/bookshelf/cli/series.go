@@ -0,0 +1,163 @@
+package cli
+
+import (
+	"context"
+	"errors"
+
+	"github.com/r3d5un/Bookshelf/internal/books/types"
+)
+
+func listSeries(ctx context.Context, args []string) error {
+	cmd := newCommand("series list")
+	name := cmd.flags.String("name", "", "filter by name")
+	lf := cmd.listFlags("name")
+	if err := cmd.parse(args); err != nil {
+		return err
+	}
+
+	filters, err := lf.filters(nameOrderBySafeList)
+	if err != nil {
+		return err
+	}
+	filters.Name = *name
+
+	catalog, closeCatalog, err := cmd.catalog(ctx)
+	if err != nil {
+		return err
+	}
+	defer closeCatalog()
+
+	seriesList, err := catalog.ReadAllSeries(ctx, filters)
+	if err != nil {
+		return err
+	}
+
+	t := table{header: []string{"ID", "NAME", "BOOKS"}}
+	for _, series := range seriesList {
+		t.rows = append(t.rows, []string{
+			series.ID.String(),
+			str(series.Name),
+			bookCount(series.Books),
+		})
+	}
+
+	return cmd.print(seriesList, t)
+}
+
+func showSeries(ctx context.Context, args []string) error {
+	cmd := newCommand("series show")
+	if err := cmd.parse(args); err != nil {
+		return err
+	}
+	id, err := cmd.id()
+	if err != nil {
+		return err
+	}
+
+	catalog, closeCatalog, err := cmd.catalog(ctx)
+	if err != nil {
+		return err
+	}
+	defer closeCatalog()
+
+	series, err := catalog.ReadSeries(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return cmd.print(series, details(
+		[2]string{"ID", series.ID.String()},
+		[2]string{"Name", str(series.Name)},
+		[2]string{"Description", str(series.Description)},
+		[2]string{"Books", bookTitles(series.Books)},
+		[2]string{"Created", timestamp(series.CreatedAt)},
+		[2]string{"Updated", timestamp(series.UpdatedAt)},
+	))
+}
+
+func addSeries(ctx context.Context, args []string) error {
+	cmd := newCommand("series add")
+	name := cmd.flags.String("name", "", "name of the series (required)")
+	description := cmd.flags.String("description", "", "description of the series")
+	if err := cmd.parse(args); err != nil {
+		return err
+	}
+	if *name == "" {
+		return errors.New("name is required")
+	}
+
+	catalog, closeCatalog, err := cmd.catalog(ctx)
+	if err != nil {
+		return err
+	}
+	defer closeCatalog()
+
+	id, err := catalog.CreateSeries(ctx, types.NewSeriesData{
+		Name:        *name,
+		Description: cmd.optionalString("description", *description),
+	})
+	if err != nil {
+		return err
+	}
+
+	return cmd.printCreated("series", id)
+}
+
+func editSeries(ctx context.Context, args []string) error {
+	cmd := newCommand("series edit")
+	name := cmd.flags.String("name", "", "new name of the series")
+	description := cmd.flags.String("description", "", "new description of the series")
+	if err := cmd.parse(args); err != nil {
+		return err
+	}
+	id, err := cmd.id()
+	if err != nil {
+		return err
+	}
+
+	catalog, closeCatalog, err := cmd.catalog(ctx)
+	if err != nil {
+		return err
+	}
+	defer closeCatalog()
+
+	series, err := catalog.UpdateSeries(ctx, types.Series{
+		ID:          id,
+		Name:        cmd.optionalString("name", *name),
+		Description: cmd.optionalString("description", *description),
+	})
+	if err != nil {
+		return err
+	}
+
+	return cmd.print(series, details(
+		[2]string{"ID", series.ID.String()},
+		[2]string{"Name", str(series.Name)},
+		[2]string{"Description", str(series.Description)},
+		[2]string{"Updated", timestamp(series.UpdatedAt)},
+	))
+}
+
+func deleteSeries(ctx context.Context, args []string) error {
+	cmd := newCommand("series delete")
+	if err := cmd.parse(args); err != nil {
+		return err
+	}
+	id, err := cmd.id()
+	if err != nil {
+		return err
+	}
+
+	catalog, closeCatalog, err := cmd.catalog(ctx)
+	if err != nil {
+		return err
+	}
+	defer closeCatalog()
+
+	if err := catalog.DeleteSeries(ctx, id); err != nil {
+		return err
+	}
+
+	cmd.printMessage("deleted series %s", id)
+	return nil
+}
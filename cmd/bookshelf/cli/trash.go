@@ -0,0 +1,128 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/r3d5un/Bookshelf/internal/books/data"
+	"github.com/r3d5un/Bookshelf/internal/system"
+)
+
+// restorer takes a record of a single resource out of the trash of the catalog.
+type restorer[T any] func(catalog system.Books, ctx context.Context, id uuid.UUID) (T, error)
+
+// restoreRecord returns the restore action of the resource, e.g. system.Books.RestoreBook for
+// books.
+func restoreRecord[T any](resource string, kind string, restore restorer[T]) action {
+	return func(ctx context.Context, args []string) error {
+		cmd := newCommand(resource + " restore")
+		if err := cmd.parse(args); err != nil {
+			return err
+		}
+		id, err := cmd.id()
+		if err != nil {
+			return err
+		}
+
+		catalog, closeCatalog, err := cmd.catalog(ctx)
+		if err != nil {
+			return err
+		}
+		defer closeCatalog()
+
+		record, err := restore(catalog, ctx, id)
+		if err != nil {
+			return err
+		}
+
+		if cmd.output == "json" {
+			return cmd.print(record, table{})
+		}
+		cmd.printMessage("restored %s %s", kind, id)
+		return nil
+	}
+}
+
+func listTrash(ctx context.Context, args []string) error {
+	cmd := newCommand("trash list")
+	page := cmd.flags.Int("page", 1, "page number")
+	pageSize := cmd.flags.Int("page-size", 50, "number of records of each kind per page")
+	if err := cmd.parse(args); err != nil {
+		return err
+	}
+	if *page < 1 || *pageSize < 1 {
+		return errors.New("page and page size must be greater than zero")
+	}
+
+	catalog, closeCatalog, err := cmd.catalog(ctx)
+	if err != nil {
+		return err
+	}
+	defer closeCatalog()
+
+	trash, err := catalog.ReadTrash(ctx, data.Filters{Page: *page, PageSize: *pageSize})
+	if err != nil {
+		return err
+	}
+
+	t := table{header: []string{"KIND", "ID", "NAME", "DELETED"}}
+	for _, book := range trash.Books {
+		t.rows = append(t.rows, []string{
+			"book", book.ID.String(), book.Title, timestamp(book.DeletedAt),
+		})
+	}
+	for _, author := range trash.Authors {
+		t.rows = append(t.rows, []string{
+			"author", author.ID.String(), str(author.Name), timestamp(author.DeletedAt),
+		})
+	}
+	for _, series := range trash.Series {
+		t.rows = append(t.rows, []string{
+			"series", series.ID.String(), str(series.Name), timestamp(series.DeletedAt),
+		})
+	}
+	for _, genre := range trash.Genres {
+		t.rows = append(t.rows, []string{
+			"genre", genre.ID.String(), str(genre.Name), timestamp(genre.DeletedAt),
+		})
+	}
+
+	return cmd.print(trash, t)
+}
+
+func purgeTrash(ctx context.Context, args []string) error {
+	cmd := newCommand("trash purge")
+	var before dateFlag
+	cmd.flags.Var(&before, "before", "only purge records deleted before the date (YYYY-MM-DD)")
+	if err := cmd.parse(args); err != nil {
+		return err
+	}
+	if before.date == nil {
+		now := time.Now()
+		before.date = &now
+	}
+
+	catalog, closeCatalog, err := cmd.catalog(ctx)
+	if err != nil {
+		return err
+	}
+	defer closeCatalog()
+
+	purged, err := catalog.PurgeTrash(ctx, *before.date)
+	if err != nil {
+		return err
+	}
+
+	return cmd.print(purged, table{
+		header: []string{"BOOKS", "AUTHORS", "SERIES", "GENRES"},
+		rows: [][]string{{
+			strconv.FormatInt(purged.Books, 10),
+			strconv.FormatInt(purged.Authors, 10),
+			strconv.FormatInt(purged.Series, 10),
+			strconv.FormatInt(purged.Genres, 10),
+		}},
+	})
+}
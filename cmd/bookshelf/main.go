@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
 	"net/http"
 	"os"
@@ -10,25 +11,32 @@ import (
 	"github.com/google/uuid"
 	_ "github.com/jackc/pgx/v5/stdlib"
 	"github.com/r3d5un/Bookshelf/cmd/bookshelf/books"
+	"github.com/r3d5un/Bookshelf/cmd/bookshelf/cli"
 	"github.com/r3d5un/Bookshelf/cmd/bookshelf/orchestrator"
 	"github.com/r3d5un/Bookshelf/cmd/bookshelf/ui"
 	"github.com/r3d5un/Bookshelf/internal/config"
 	"github.com/r3d5un/Bookshelf/internal/database"
+	"github.com/r3d5un/Bookshelf/internal/events"
 	"github.com/r3d5un/Bookshelf/internal/system"
+	"github.com/r3d5un/Bookshelf/migrations"
 )
 
 func main() {
-	if err := run(); err != nil {
+	if err := run(os.Args[1:]); err != nil {
 		slog.Error("an error occurred", "error", err)
+		os.Exit(1)
 	}
-
-	os.Exit(1)
 }
 
-func run() (err error) {
+func run(args []string) (err error) {
 	instanceID := uuid.New()
 	ctx := context.WithValue(context.Background(), "instanceID", instanceID)
 
+	// Catalog commands set up their own quiet logging, and may not need a database at all
+	if len(args) > 0 && cli.IsCommand(args[0]) {
+		return cli.Run(ctx, args)
+	}
+
 	handler := slog.NewJSONHandler(os.Stdout, nil)
 	logger := slog.New(handler).With(
 		slog.Group(
@@ -43,7 +51,7 @@ func run() (err error) {
 	logger.Info("loading configuration")
 	cfg, configErr := config.New()
 	if configErr != nil {
-		logger.Error("unable to load configuration", "error", err)
+		logger.Error("unable to load configuration", "error", configErr)
 		os.Exit(1)
 	}
 
@@ -57,10 +65,44 @@ func run() (err error) {
 		time.Duration(cfg.DB.Timeout)*time.Second,
 	)
 	if dbErr != nil {
-		logger.Error("error occurred while creating connection pool", "error", err)
+		logger.Error("error occurred while creating connection pool", "error", dbErr)
 		os.Exit(1)
 	}
 
+	migrator, err := database.NewMigrator(db, migrations.Files)
+	if err != nil {
+		logger.Error("unable to load migrations", "error", err)
+		return err
+	}
+
+	if len(args) > 0 {
+		switch args[0] {
+		case "migrate":
+			return runMigrate(ctx, migrator, args[1:])
+		default:
+			return fmt.Errorf("unknown command %q", args[0])
+		}
+	}
+
+	logger.Info("applying database migrations")
+	_, err = migrator.Up(ctx)
+	if err != nil {
+		logger.Error("unable to apply database migrations", "error", err)
+		return err
+	}
+
+	logger.Info("creating event bus")
+	bus := events.NewBus(instanceID)
+	if cfg.Events != nil && cfg.Events.Outbox {
+		logger.Info("storing events in the outbox")
+		timeout := time.Duration(cfg.DB.Timeout) * time.Second
+		err = bus.UseOutbox(ctx, events.NewOutbox(db, &timeout))
+		if err != nil {
+			logger.Error("unable to use event outbox", "error", err)
+			return err
+		}
+	}
+
 	app := system.NewMonolith(
 		ctx,
 		logger,
@@ -72,6 +114,7 @@ func run() (err error) {
 		},
 		db,
 		cfg,
+		bus,
 	)
 
 	app.Logger().Info("running module startup")
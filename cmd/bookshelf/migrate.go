@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/r3d5un/Bookshelf/internal/database"
+)
+
+const migrateUsage string = `usage: bookshelf migrate <command>
+
+commands:
+  up          apply all pending migrations
+  down [-n N] revert the N most recently applied migrations (default 1)
+  goto V      apply or revert migrations until version V is the newest applied
+  status      list migrations and when they were applied`
+
+// runMigrate runs the migrate subcommand, managing the database schema without starting
+// the server.
+func runMigrate(ctx context.Context, migrator *database.Migrator, args []string) error {
+	if len(args) < 1 {
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		return errors.New("missing migrate command")
+	}
+
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		if err != nil {
+			return err
+		}
+		for _, migration := range applied {
+			fmt.Printf("applied %s_%s\n", migration.Version, migration.Name)
+		}
+		return nil
+
+	case "down":
+		fs := flag.NewFlagSet("migrate down", flag.ContinueOnError)
+		steps := fs.Int("n", 1, "number of migrations to revert")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+		if *steps < 1 {
+			return errors.New("number of migrations to revert must be greater than zero")
+		}
+
+		reverted, err := migrator.Down(ctx, *steps)
+		if err != nil {
+			return err
+		}
+		for _, migration := range reverted {
+			fmt.Printf("reverted %s_%s\n", migration.Version, migration.Name)
+		}
+		return nil
+
+	case "goto":
+		if len(args) != 2 {
+			fmt.Fprintln(os.Stderr, migrateUsage)
+			return errors.New("migrate goto takes a single version")
+		}
+
+		applied, reverted, err := migrator.Goto(ctx, args[1])
+		for _, migration := range reverted {
+			fmt.Printf("reverted %s_%s\n", migration.Version, migration.Name)
+		}
+		for _, migration := range applied {
+			fmt.Printf("applied %s_%s\n", migration.Version, migration.Name)
+		}
+		return err
+
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+
+		tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "VERSION\tNAME\tAPPLIED AT")
+		for _, status := range statuses {
+			appliedAt := "pending"
+			switch {
+			case status.AppliedAt != nil:
+				appliedAt = status.AppliedAt.Format(time.RFC3339)
+			case status.Legacy:
+				appliedAt = "golang-migrate"
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\n", status.Version, status.Name, appliedAt)
+		}
+		return tw.Flush()
+
+	default:
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		return fmt.Errorf("unknown migrate command %q", args[0])
+	}
+}
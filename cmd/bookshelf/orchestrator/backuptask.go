@@ -0,0 +1,184 @@
+package orchestrator
+
+import (
+	"context"
+	"errors"
+	"os"
+	"time"
+
+	"github.com/r3d5un/Bookshelf/internal/backup"
+	"github.com/r3d5un/Bookshelf/internal/books/data"
+	"github.com/r3d5un/Bookshelf/internal/logging"
+	"github.com/r3d5un/Bookshelf/internal/orchestrator"
+	"github.com/r3d5un/Bookshelf/internal/orchestrator/types"
+)
+
+const BackupLibraryName string = "Backup Library"
+
+// backupPageSize is the number of records read per query while collecting the library.
+const backupPageSize int = 1_000
+
+// backupLibraryPayload holds the optional arguments of a backup run.
+type backupLibraryPayload struct {
+	// Directory to write the archive to instead of the configured backup directory. The
+	// retention policy is not applied to other directories.
+	Directory string `json:"directory"`
+}
+
+// backupLibrary writes the complete catalog to an archive in the configured backup
+// directory, then removes archives falling outside the configured retention policy.
+func (m *Module) backupLibrary(ctx context.Context) error {
+	run, ok := orchestrator.RunFromContext(ctx)
+	if !ok {
+		return orchestrator.ErrNoRun
+	}
+
+	logger, stopLogger := types.NewTaskLogger(
+		ctx, &m.models, BackupLibraryName, run.ID, m.retentionConfig().LogLines,
+	)
+	defer stopLogger()
+	ctx = context.WithValue(ctx, logging.LoggerKey, logger)
+
+	payload, err := orchestrator.DecodePayload[backupLibraryPayload](ctx)
+	if err != nil && !errors.Is(err, orchestrator.ErrNoPayload) {
+		logger.Error("unable to decode payload", "error", err)
+		return err
+	}
+
+	if payload.Directory == "" && (m.cfg.Backup == nil || m.cfg.Backup.Directory == "") {
+		logger.Error("backup directory not configured")
+		return errors.New("backup directory not configured")
+	}
+	directory := payload.Directory
+	if directory == "" {
+		directory = m.cfg.Backup.Directory
+	}
+
+	logger.Info("starting library backup", "directory", directory)
+
+	library, err := m.readLibrary(ctx)
+	if err != nil {
+		logger.Error("unable to read library", "error", err)
+		return err
+	}
+	logger.Info(
+		"library read",
+		"books", len(library.Books),
+		"authors", len(library.Authors),
+		"series", len(library.Series),
+		"genres", len(library.Genres),
+	)
+
+	path, err := backup.WriteArchiveFile(directory, *library, time.Now())
+	if err != nil {
+		logger.Error("unable to write backup archive", "error", err)
+		return err
+	}
+	logger.Info("backup archive written", "path", path)
+
+	if payload.Directory != "" {
+		logger.Info("library backup complete; retention policy not applied to given directory")
+		return nil
+	}
+	backupCfg := m.cfg.Backup
+
+	archives, err := backup.ListArchives(backupCfg.Directory)
+	if err != nil {
+		logger.Error("unable to list backup archives", "error", err)
+		return err
+	}
+
+	policy := backup.RetentionPolicy{
+		Daily:   backupCfg.Daily,
+		Weekly:  backupCfg.Weekly,
+		Monthly: backupCfg.Monthly,
+	}
+	expired := backup.Expired(archives, policy)
+	logger.Info(
+		"applying retention policy",
+		"policy", policy,
+		"archives", len(archives),
+		"expired", len(expired),
+	)
+
+	for _, archive := range expired {
+		logger.Info("removing expired backup archive", "archive", archive)
+		if err := os.Remove(archive.Path); err != nil {
+			logger.Error("unable to remove expired backup archive", "error", err)
+			return err
+		}
+	}
+
+	logger.Info("library backup complete")
+
+	return nil
+}
+
+// readLibrary reads every book, author, series and genre from the books module. Nested book
+// listings on authors and series are left out, as the books are already part of the backup.
+func (m *Module) readLibrary(ctx context.Context) (*backup.Library, error) {
+	library := backup.Library{}
+
+	for page := 1; ; page++ {
+		books, err := m.bookModule.ReadAllBook(ctx, backupFilters(page))
+		if err != nil {
+			return nil, err
+		}
+		library.Books = append(library.Books, books...)
+		if len(books) < backupPageSize {
+			break
+		}
+	}
+
+	for page := 1; ; page++ {
+		authors, err := m.bookModule.ReadAllAuthors(ctx, backupFilters(page))
+		if err != nil {
+			return nil, err
+		}
+		for _, author := range authors {
+			author.Books = nil
+		}
+		library.Authors = append(library.Authors, authors...)
+		if len(authors) < backupPageSize {
+			break
+		}
+	}
+
+	for page := 1; ; page++ {
+		series, err := m.bookModule.ReadAllSeries(ctx, backupFilters(page))
+		if err != nil {
+			return nil, err
+		}
+		for _, s := range series {
+			s.Books = nil
+		}
+		library.Series = append(library.Series, series...)
+		if len(series) < backupPageSize {
+			break
+		}
+	}
+
+	for page := 1; ; page++ {
+		genres, err := m.bookModule.ReadAllGenre(ctx, backupFilters(page))
+		if err != nil {
+			return nil, err
+		}
+		for _, genre := range genres {
+			genre.Books = nil
+		}
+		library.Genres = append(library.Genres, genres...)
+		if len(genres) < backupPageSize {
+			break
+		}
+	}
+
+	return &library, nil
+}
+
+func backupFilters(page int) data.Filters {
+	return data.Filters{
+		Page:     page,
+		PageSize: backupPageSize,
+		OrderBy:  []string{"id"},
+	}
+}
@@ -2,9 +2,8 @@ package orchestrator
 
 import (
 	"context"
-	"errors"
 
-	"github.com/google/uuid"
+	"github.com/r3d5un/Bookshelf/internal/orchestrator"
 	"github.com/r3d5un/Bookshelf/internal/orchestrator/types"
 )
 
@@ -13,12 +12,14 @@ const (
 )
 
 func (m *Module) helloWorld(ctx context.Context) error {
-	taskQueueID, ok := ctx.Value("taskQueueID").(uuid.UUID)
+	run, ok := orchestrator.RunFromContext(ctx)
 	if !ok {
-		return errors.New("unable to get task queue ID from context")
+		return orchestrator.ErrNoRun
 	}
 
-	logger, stop := types.NewTaskLogger(ctx, &m.models, HelloWorldName, taskQueueID)
+	logger, stop := types.NewTaskLogger(
+		ctx, &m.models, HelloWorldName, run.ID, m.retentionConfig().LogLines,
+	)
 	defer stop()
 
 	logger.InfoContext(ctx, "Hello, World!")
@@ -0,0 +1,37 @@
+package orchestrator
+
+import (
+	"context"
+
+	"github.com/r3d5un/Bookshelf/internal/idempotency"
+	"github.com/r3d5un/Bookshelf/internal/logging"
+	"github.com/r3d5un/Bookshelf/internal/orchestrator"
+	"github.com/r3d5un/Bookshelf/internal/orchestrator/types"
+)
+
+const PurgeIdempotencyKeysName string = "Purge Idempotency Keys"
+
+// purgeIdempotencyKeys deletes the idempotency keys, and the responses stored with them, that
+// have expired.
+func (m *Module) purgeIdempotencyKeys(ctx context.Context) error {
+	run, ok := orchestrator.RunFromContext(ctx)
+	if !ok {
+		return orchestrator.ErrNoRun
+	}
+
+	logger, stopLogger := types.NewTaskLogger(
+		ctx, &m.models, PurgeIdempotencyKeysName, run.ID, m.retentionConfig().LogLines,
+	)
+	defer stopLogger()
+	ctx = context.WithValue(ctx, logging.LoggerKey, logger)
+
+	logger.Info("purging idempotency keys", "ttl", idempotency.TTL)
+	purged, err := m.idempotencyKeys.Purge(ctx)
+	if err != nil {
+		logger.Error("unable to purge idempotency keys", "error", err)
+		return err
+	}
+	logger.Info("idempotency keys purged", "keys", purged)
+
+	return nil
+}
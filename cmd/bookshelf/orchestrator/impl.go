@@ -2,12 +2,187 @@ package orchestrator
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/r3d5un/Bookshelf/internal/logging"
 	"github.com/r3d5un/Bookshelf/internal/orchestrator/data"
 	"github.com/r3d5un/Bookshelf/internal/orchestrator/types"
 )
 
+func (m *Module) ReadTask(ctx context.Context, name string) (*types.Task, error) {
+	return types.ReadTask(ctx, &m.models, name)
+}
+
+func (m *Module) ReadAllTasks(
+	ctx context.Context,
+	filters data.Filters,
+) (*types.TaskCollection, error) {
+	return types.ReadAllTasks(ctx, &m.models, filters)
+}
+
+// UpdateTask updates the task overview, and notifies all instances to reschedule the task.
+func (m *Module) UpdateTask(ctx context.Context, task types.Task) (*types.Task, error) {
+	updatedTask, err := types.UpdateTask(ctx, &m.models, task)
+	if err != nil {
+		return nil, err
+	}
+	m.rescheduleTask(ctx, updatedTask.Name)
+
+	return updatedTask, nil
+}
+
+// RunTask enqueues a single run of the given task, independent of its schedule. The run starts
+// immediately, or at runAt if given. The payload is passed to the task as its arguments, and may
+// be empty.
+func (m *Module) RunTask(
+	ctx context.Context,
+	name string,
+	payload json.RawMessage,
+	runAt *time.Time,
+) (*types.ScheduledTask, error) {
+	task, err := types.ReadTask(ctx, &m.models, name)
+	if err != nil {
+		return nil, err
+	}
+	if !*task.Enabled {
+		return nil, types.ErrTaskDisabled
+	}
+	if runAt != nil {
+		// The run time is stored without a time zone, and compared to the database time in UTC
+		utc := runAt.UTC()
+		runAt = &utc
+	}
+
+	return m.scheduler.Enqueue(
+		ctx,
+		types.ScheduledTask{Name: &task.Name, Payload: payload, RunAt: runAt},
+	)
+}
+
+// ReadTaskSchedule returns the stored schedule with the given ID, and when it next runs.
+func (m *Module) ReadTaskSchedule(ctx context.Context, id uuid.UUID) (*types.TaskSchedule, error) {
+	schedule, err := types.ReadTaskSchedule(ctx, &m.models, id)
+	if err != nil {
+		return nil, err
+	}
+	m.setNextScheduleRun(schedule)
+
+	return schedule, nil
+}
+
+// ReadAllTaskSchedules returns the stored schedules matching the filters, and when each next
+// runs. The Name filter matches the task of the schedules.
+func (m *Module) ReadAllTaskSchedules(
+	ctx context.Context,
+	filters data.Filters,
+) (*types.TaskScheduleCollection, error) {
+	schedules, err := types.ReadAllTaskSchedules(ctx, &m.models, filters)
+	if err != nil {
+		return nil, err
+	}
+	for _, schedule := range schedules.Data {
+		m.setNextScheduleRun(schedule)
+	}
+
+	return schedules, nil
+}
+
+// CreateTaskSchedule stores a new schedule for an existing task, and notifies all instances to
+// reschedule the task. types.ErrScheduleTaskNotFound is returned if the task does not exist.
+func (m *Module) CreateTaskSchedule(
+	ctx context.Context,
+	schedule types.TaskSchedule,
+) (*types.TaskSchedule, error) {
+	_, err := types.ReadTask(ctx, &m.models, *schedule.TaskName)
+	if err != nil {
+		if errors.Is(err, data.ErrRecordNotFound) {
+			return nil, types.ErrScheduleTaskNotFound
+		}
+		return nil, err
+	}
+
+	createdSchedule, err := types.CreateTaskSchedule(ctx, &m.models, schedule)
+	if err != nil {
+		return nil, err
+	}
+	m.rescheduleTask(ctx, *createdSchedule.TaskName)
+
+	return createdSchedule, nil
+}
+
+// UpdateTaskSchedule changes a stored schedule, and notifies all instances to reschedule its
+// task.
+func (m *Module) UpdateTaskSchedule(
+	ctx context.Context,
+	schedule types.TaskSchedule,
+) (*types.TaskSchedule, error) {
+	updatedSchedule, err := types.UpdateTaskSchedule(ctx, &m.models, schedule)
+	if err != nil {
+		return nil, err
+	}
+	m.rescheduleTask(ctx, *updatedSchedule.TaskName)
+
+	return updatedSchedule, nil
+}
+
+// DeleteTaskSchedule deletes a stored schedule, and notifies all instances to reschedule its
+// task. Runs already enqueued by the schedule are kept.
+func (m *Module) DeleteTaskSchedule(
+	ctx context.Context,
+	id uuid.UUID,
+) (*types.TaskSchedule, error) {
+	deletedSchedule, err := types.DeleteTaskSchedule(ctx, &m.models, id)
+	if err != nil {
+		return nil, err
+	}
+	m.rescheduleTask(ctx, *deletedSchedule.TaskName)
+
+	return deletedSchedule, nil
+}
+
+// setNextScheduleRun sets when the schedule next runs, if it is added to the scheduler.
+func (m *Module) setNextScheduleRun(schedule *types.TaskSchedule) {
+	if next, ok := m.scheduler.NextSchedule(schedule.ID); ok {
+		schedule.NextRunAt = &next
+	}
+}
+
+func (m *Module) ReadWebhook(ctx context.Context, id uuid.UUID) (*types.Webhook, error) {
+	return types.ReadWebhook(ctx, &m.models, id)
+}
+
+func (m *Module) ReadAllWebhooks(
+	ctx context.Context,
+	filters data.Filters,
+) (*types.WebhookCollection, error) {
+	return types.ReadAllWebhooks(ctx, &m.models, filters)
+}
+
+func (m *Module) CreateWebhook(ctx context.Context, webhook types.Webhook) (*types.Webhook, error) {
+	return types.CreateWebhook(ctx, &m.models, webhook)
+}
+
+func (m *Module) UpdateWebhook(ctx context.Context, webhook types.Webhook) (*types.Webhook, error) {
+	return types.UpdateWebhook(ctx, &m.models, webhook)
+}
+
+// DeleteWebhook deletes the webhook and its delivery log. Deliveries already enqueued for the
+// webhook are dropped when they run.
+func (m *Module) DeleteWebhook(ctx context.Context, id uuid.UUID) (*types.Webhook, error) {
+	return types.DeleteWebhook(ctx, &m.models, id)
+}
+
+func (m *Module) ReadAllWebhookDeliveries(
+	ctx context.Context,
+	webhookID uuid.UUID,
+	filters data.Filters,
+) (*types.WebhookDeliveryCollection, error) {
+	return types.ReadAllWebhookDeliveries(ctx, &m.models, webhookID, filters)
+}
+
 func (m *Module) ReadScheduledTask(
 	ctx context.Context,
 	taskID uuid.UUID,
@@ -64,3 +239,133 @@ func (m *Module) DequeueScheduledTask(
 ) (*types.ScheduledTask, error) {
 	return types.DequeueScheduledTask(ctx, &m.models, taskID)
 }
+
+// CancelScheduledTask stops a waiting or blocked run from running. Runs chained after it are
+// skipped, and its parent is finished if it was the last unfinished child.
+func (m *Module) CancelScheduledTask(
+	ctx context.Context,
+	taskID uuid.UUID,
+) (*types.ScheduledTask, error) {
+	cancelledTask, err := types.CancelScheduledTask(ctx, &m.models, taskID)
+	if err != nil {
+		return nil, err
+	}
+	m.settleScheduledTask(ctx, taskID)
+
+	return cancelledTask, nil
+}
+
+// StopScheduledTask stops a task run. Waiting and blocked runs are cancelled directly, while
+// running tasks are asked to stop through a notification, as they may be running on another
+// instance. The running task is marked as stopped once it has returned.
+func (m *Module) StopScheduledTask(
+	ctx context.Context,
+	taskID uuid.UUID,
+) (*types.ScheduledTask, error) {
+	scheduledTask, err := types.ReadScheduledTask(ctx, &m.models, taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	if *scheduledTask.State == string(data.WaitingTaskState) ||
+		*scheduledTask.State == string(data.BlockedTaskState) {
+		cancelledTask, err := m.CancelScheduledTask(ctx, taskID)
+		if !errors.Is(err, types.ErrTaskNotWaiting) {
+			return cancelledTask, err
+		}
+
+		// The run was claimed after it was read
+		scheduledTask, err = types.ReadScheduledTask(ctx, &m.models, taskID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if *scheduledTask.State != string(data.RunningTaskState) {
+		return nil, types.ErrTaskNotRunning
+	}
+
+	err = m.models.TaskNotifications.NotifyTaskStop(
+		ctx,
+		data.TaskNotification{ID: scheduledTask.ID, Queue: *scheduledTask.Name},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return scheduledTask, nil
+}
+
+// ReplayScheduledTask puts a dead task back in the queue, and notifies the task runners.
+func (m *Module) ReplayScheduledTask(
+	ctx context.Context,
+	taskID uuid.UUID,
+) (*types.ScheduledTask, error) {
+	logger := logging.LoggerFromContext(ctx)
+
+	scheduledTask, err := types.ReplayScheduledTask(ctx, &m.models, taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = m.models.TaskNotifications.Notify(
+		ctx,
+		data.TaskNotification{ID: scheduledTask.ID, Queue: *scheduledTask.Name},
+	)
+	if err != nil {
+		// The task reminder picks up waiting tasks that were never announced
+		logger.Error("unable to notify listeners", "error", err)
+	}
+
+	return scheduledTask, nil
+}
+
+// ReadWorkflow returns the run with the given ID, and the status of all runs it started.
+func (m *Module) ReadWorkflow(ctx context.Context, taskID uuid.UUID) (*types.Workflow, error) {
+	return types.ReadWorkflow(ctx, &m.models, taskID)
+}
+
+func (m *Module) ReadScheduledTaskLogs(
+	ctx context.Context,
+	taskID uuid.UUID,
+) ([]*types.TaskLog, error) {
+	_, err := types.ReadScheduledTask(ctx, &m.models, taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	return types.ReadLogsByTaskQueueID(ctx, &m.models, taskID)
+}
+
+// ReadLeaderStatus returns the current leader, and whether this instance is the leader.
+func (m *Module) ReadLeaderStatus(ctx context.Context) (*types.LeaderStatus, error) {
+	leader, err := types.ReadLeader(ctx, &m.models)
+	if err != nil {
+		return nil, err
+	}
+
+	status := types.LeaderStatus{
+		Leader:     leader,
+		InstanceID: m.schedulerID,
+		IsLeader:   m.leader.IsLeader(),
+	}
+
+	return &status, nil
+}
+
+// ReadTaskStats returns the run statistics of every task, with the next time each task is
+// scheduled to run.
+func (m *Module) ReadTaskStats(ctx context.Context) ([]*types.TaskStats, error) {
+	stats, err := types.ReadTaskStats(ctx, &m.models)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, taskStats := range stats {
+		if next, ok := m.scheduler.Next(taskStats.Name); ok {
+			taskStats.NextRunAt = &next
+		}
+	}
+
+	return stats, nil
+}
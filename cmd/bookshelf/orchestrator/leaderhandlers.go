@@ -0,0 +1,26 @@
+package orchestrator
+
+import (
+	"net/http"
+
+	"github.com/r3d5un/Bookshelf/internal/logging"
+	"github.com/r3d5un/Bookshelf/internal/rest"
+)
+
+// GetLeaderHandler returns the instance currently elected to run the scheduler, and whether the
+// instance handling the request is the leader.
+func (m *Module) GetLeaderHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := logging.LoggerFromContext(ctx)
+
+	logger.Info("querying database for leader")
+	status, err := m.ReadLeaderStatus(ctx)
+	if err != nil {
+		logger.Error("unable to get leader", "error", err)
+		rest.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	logger.Info("writing response")
+	rest.Respond(w, r, http.StatusOK, status, nil)
+}
@@ -0,0 +1,69 @@
+package orchestrator
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/r3d5un/Bookshelf/internal/logging"
+	"github.com/r3d5un/Bookshelf/internal/orchestrator/types"
+)
+
+// StreamScheduledTaskLogs passes the logs of a run logged after the log with the sequence number
+// afterSeq to send, and keeps passing new logs as they are written. The stream ends when the run
+// has finished and all of its logs are sent, or when the context is cancelled, and returns the
+// run as it was last read.
+//
+// Keepalive is called when no logs have been sent for a while, letting the caller keep idle
+// connections open. An error returned from send or keepalive ends the stream.
+func (m *Module) StreamScheduledTaskLogs(
+	ctx context.Context,
+	taskID uuid.UUID,
+	afterSeq int64,
+	send func(log *types.TaskLog) error,
+	keepalive func() error,
+) (*types.ScheduledTask, error) {
+	logger := logging.LoggerFromContext(ctx).With(slog.Group("run", "id", taskID))
+
+	// Subscribing before reading any logs ensures no lines are missed in between
+	notifyCh, unsubscribe := m.logHub.Subscribe(taskID)
+	defer unsubscribe()
+
+	ticker := time.NewTicker(logStreamInterval)
+	defer ticker.Stop()
+
+	for {
+		// The run is read before its logs, as logs are written before a run finishes
+		run, err := types.ReadScheduledTask(ctx, &m.models, taskID)
+		if err != nil {
+			return nil, err
+		}
+
+		logs, err := types.ReadLogsByTaskQueueIDAfter(ctx, &m.models, taskID, afterSeq)
+		if err != nil {
+			return nil, err
+		}
+		for _, log := range logs {
+			if err := send(log); err != nil {
+				return run, err
+			}
+			afterSeq = log.Seq
+		}
+
+		if run.State != nil && types.IsFinished(*run.State) {
+			logger.Info("run finished; ending log stream", "state", *run.State)
+			return run, nil
+		}
+
+		select {
+		case <-notifyCh:
+		case <-ticker.C:
+			if err := keepalive(); err != nil {
+				return run, err
+			}
+		case <-ctx.Done():
+			return run, context.Cause(ctx)
+		}
+	}
+}
@@ -2,57 +2,58 @@ package orchestrator
 
 import (
 	"context"
-	"errors"
-	"time"
 
-	"github.com/google/uuid"
 	"github.com/r3d5un/Bookshelf/internal/logging"
+	"github.com/r3d5un/Bookshelf/internal/orchestrator"
 	"github.com/r3d5un/Bookshelf/internal/orchestrator/data"
 	"github.com/r3d5un/Bookshelf/internal/orchestrator/types"
 )
 
 const RemoveOldScheduledTask string = "Remove Old Scheduled Tasks"
 
+// removeOldScheduledTasks deletes finished runs that are older than their retention, in batches
+// so that a large backlog does not hold locks on the task queue for long.
 func (m *Module) removeOldScheduledTasks(ctx context.Context) error {
-	taskQueueID, ok := ctx.Value("taskQueueID").(uuid.UUID)
+	run, ok := orchestrator.RunFromContext(ctx)
 	if !ok {
-		return errors.New("unable to get task queue ID from context")
+		return orchestrator.ErrNoRun
 	}
 
-	logger, stopLogger := types.NewTaskLogger(ctx, &m.models, RemoveOldScheduledTask, taskQueueID)
+	retention := m.retentionConfig()
+
+	logger, stopLogger := types.NewTaskLogger(
+		ctx, &m.models, RemoveOldScheduledTask, run.ID, retention.LogLines,
+	)
 	defer stopLogger()
 	ctx = context.WithValue(ctx, logging.LoggerKey, logger)
 
-	logger.Info("starting task schedule maintenance task")
-
-	monthAgo := time.Now().AddDate(0, -1, 0)
-	logger.Info("checking for old tasks", "date", monthAgo)
-	oldScheduledTasks, err := types.ReadAllScheudledTasks(ctx, &m.models, data.Filters{
-		Page:     1,
-		PageSize: 50_000,
-		RunAtTo:  &monthAgo,
-	})
-	if err != nil {
-		logger.Info("unable to read tasks", "error", err)
-		return err
-	}
-	if oldScheduledTasks.TotalRecords < 1 {
-		logger.Info("no old tasks to delete")
-		return nil
-	}
-	logger.Info("old tasks retrieved", "totalTasks", oldScheduledTasks.TotalRecords)
+	logger.Info("starting task schedule maintenance task", "retention", retention)
+
+	policy := data.RetentionPolicy{Days: retention.Days, FailedDays: retention.FailedDays}
 
-	logger.Info("removing old completed runs")
-	for _, scheduledTask := range oldScheduledTasks.Data {
-		logger.Info("deleting old task", "scheduledTask", scheduledTask)
-		_, err := types.DeleteScheduledTask(ctx, &m.models, scheduledTask.ID)
+	var total int64
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Info("maintenance cancelled", "deleted", total)
+			return context.Cause(ctx)
+		default:
+		}
+
+		deleted, err := types.DeleteExpiredScheduledTasks(ctx, &m.models, policy, retention.BatchSize)
 		if err != nil {
-			logger.Error("unable to delete old task", "error", err)
+			logger.Error("unable to delete expired tasks", "error", err)
 			return err
 		}
+		total += deleted
+		logger.Info("expired tasks deleted", "deleted", deleted, "total", total)
+
+		if deleted < int64(retention.BatchSize) {
+			break
+		}
 	}
 
-	logger.Info("task schedule maintenance complete")
+	logger.Info("task schedule maintenance complete", "deleted", total)
 
 	return nil
 }
@@ -3,13 +3,17 @@ package orchestrator
 import (
 	"context"
 	"log/slog"
+	"net/http"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/r3d5un/Bookshelf/internal/config"
+	"github.com/r3d5un/Bookshelf/internal/events"
+	"github.com/r3d5un/Bookshelf/internal/idempotency"
 	"github.com/r3d5un/Bookshelf/internal/orchestrator"
 	"github.com/r3d5un/Bookshelf/internal/orchestrator/data"
 	"github.com/r3d5un/Bookshelf/internal/system"
@@ -17,18 +21,64 @@ import (
 
 const ModuleName string = "orchestrator"
 
+const (
+	// heartbeatInterval is how often running tasks are marked as alive
+	heartbeatInterval = 30 * time.Second
+	// orphanTimeout is how long a running task can go without a heartbeat before it is requeued
+	orphanTimeout = 2 * time.Minute
+	// defaultWorkers is the number of tasks run at the same time if no worker count is configured
+	defaultWorkers = 4
+	// reminderLimit is the number of waiting tasks reminded about at a time
+	reminderLimit = 500
+	// defaultRetentionDays is the number of days complete runs are kept if not configured
+	defaultRetentionDays = 30
+	// defaultFailedRetentionDays is the number of days failed runs are kept if not configured
+	defaultFailedRetentionDays = 90
+	// defaultRetentionBatchSize is the number of expired runs deleted at a time if not configured
+	defaultRetentionBatchSize = 1_000
+	// defaultLogLines is the number of log lines kept per run if not configured
+	defaultLogLines = 10_000
+	// defaultTrashDays is the number of days deleted records are kept if not configured
+	defaultTrashDays = 30
+	// leaderInterval is how often the leader lock is campaigned for, or checked while held
+	leaderInterval = 5 * time.Second
+	// logStreamInterval is how often followed logs are read without a notification, and a
+	// keepalive is sent to the follower
+	logStreamInterval = 15 * time.Second
+	// eventForwardInterval is how often the event outbox is read without a notification
+	eventForwardInterval = 30 * time.Second
+	// outboxRetention is how long forwarded events are kept in the event outbox
+	outboxRetention = 24 * time.Hour
+)
+
 type Module struct {
-	schedulerID         uuid.UUID
-	logger              *slog.Logger
-	cfg                 *config.Config
-	db                  *pgxpool.Pool
-	models              data.Models
-	scheduler           *orchestrator.CronScheduler
-	done                chan struct{}
-	taskNotificationCh  chan pgconn.Notification
-	taskCollection      orchestrator.Collection
-	wg                  sync.WaitGroup
-	isSchedulerMasterCh chan bool
+	schedulerID              uuid.UUID
+	logger                   *slog.Logger
+	mux                      *http.ServeMux
+	cfg                      *config.Config
+	db                       *pgxpool.Pool
+	models                   data.Models
+	scheduler                *orchestrator.CronScheduler
+	leader                   *orchestrator.LeaderElector
+	done                     chan struct{}
+	taskNotificationCh       chan pgconn.Notification
+	taskConfigNotificationCh chan pgconn.Notification
+	taskStopNotificationCh   chan pgconn.Notification
+	taskLogNotificationCh    chan pgconn.Notification
+	outboxNotificationCh     chan pgconn.Notification
+	logHub                   *orchestrator.LogHub
+	webhookClient            *http.Client
+	runsMu                   sync.Mutex
+	runs                     map[uuid.UUID]context.CancelCauseFunc
+	workers                  chan struct{}
+	backlogged               atomic.Bool
+	remindCh                 chan struct{}
+	taskCollection           orchestrator.Collection
+	wg                       sync.WaitGroup
+	bookModule               system.Books
+	events                   *events.Bus
+	idempotencyKeys          *idempotency.Store
+	unsubscribe              []func()
 }
 
 func (m *Module) Startup(ctx context.Context, mono system.Monolith) (err error) {
@@ -39,9 +89,20 @@ func (m *Module) Startup(ctx context.Context, mono system.Monolith) (err error)
 	m.schedulerID = system.InstanceFromContext(mono.Context())
 	m.logger.Info("scheduler instance ID set", "id", m.schedulerID)
 
-	m.logger.Info("injecting database connection")
+	m.logger.Info("injecting configuration")
 	m.cfg = mono.Config()
 
+	m.logger.Info("injecting mux")
+	m.mux = mono.Mux()
+
+	m.logger.Info("injecting data interface implementations", "requestedModule", "books")
+	m.bookModule = mono.Modules().Books
+
+	m.logger.Info("injecting event bus")
+	m.events = mono.Events()
+
+	m.logger.Info("injecting database connection")
+
 	dbConfig, err := pgxpool.ParseConfig(m.cfg.DB.DSN)
 	if err != nil {
 		m.logger.Error("unable to parse postgresql pool configuration", "error", err)
@@ -58,10 +119,19 @@ func (m *Module) Startup(ctx context.Context, mono system.Monolith) (err error)
 	m.wg = sync.WaitGroup{}
 	m.done = make(chan struct{})
 	m.taskNotificationCh = make(chan pgconn.Notification, 100)
-	m.isSchedulerMasterCh = make(chan bool, 1)
+	m.taskConfigNotificationCh = make(chan pgconn.Notification, 10)
+	m.taskStopNotificationCh = make(chan pgconn.Notification, 10)
+	m.taskLogNotificationCh = make(chan pgconn.Notification, 100)
+	m.outboxNotificationCh = make(chan pgconn.Notification, 100)
+	m.logHub = orchestrator.NewLogHub()
+	m.webhookClient = &http.Client{Timeout: webhookTimeout}
+	m.runs = make(map[uuid.UUID]context.CancelCauseFunc)
+	m.workers = make(chan struct{}, m.workerCount())
+	m.remindCh = make(chan struct{}, 1)
 
 	timeout := time.Duration(m.cfg.DB.Timeout) * time.Second
 	m.models = data.NewModels(m.db, &timeout)
+	m.idempotencyKeys = idempotency.NewStore(mono.DB(), &timeout)
 
 	m.logger.Info("creating task collection")
 	m.taskCollection = orchestrator.Collection{}
@@ -80,24 +150,67 @@ func (m *Module) Startup(ctx context.Context, mono system.Monolith) (err error)
 		return
 	}
 
+	m.logger.Info("subscribing webhooks to events")
+	m.subscribeWebhookEvents()
+
+	m.logger.Info("registering routes")
+	m.registerEndpoints(m.mux)
+
+	m.logger.Info("creating leader elector")
+	m.leader = orchestrator.NewLeaderElector(m.schedulerID, leaderInterval, m.db, &m.models)
+	m.leader.OnElected(func(term int64) {
+		m.logger.Info("elected leader; starting scheduler", "term", term)
+		m.scheduler.Start(term)
+	})
+	m.leader.OnDemoted(func() {
+		m.logger.Info("no longer leader; stopping scheduler")
+		m.scheduler.Stop()
+	})
+
 	m.wg.Add(1)
-	go func() { // Goroutine for checking the scheduler lock
+	go func() {
 		defer m.wg.Done()
-		m.checkSchedulerLock(ctx)
+		m.leader.Run(ctx, m.done)
 	}()
 
 	m.wg.Add(1)
 	go func() {
 		defer m.wg.Done()
-		m.manageScheduler(ctx)
+		m.taskReminder(ctx)
 	}()
 
 	m.wg.Add(1)
 	go func() {
 		defer m.wg.Done()
-		m.taskReminder(ctx)
+		m.taskConfigListener(ctx)
 	}()
 
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		m.taskStopListener(ctx)
+	}()
+
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		m.taskLogListener(ctx)
+	}()
+
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		m.orphanReaper(ctx)
+	}()
+
+	if m.events.Outbox() != nil {
+		m.wg.Add(1)
+		go func() {
+			defer m.wg.Done()
+			m.eventForwarder(ctx)
+		}()
+	}
+
 	m.logger.Info("startup complete")
 
 	return nil
@@ -106,6 +219,11 @@ func (m *Module) Startup(ctx context.Context, mono system.Monolith) (err error)
 func (m *Module) Shutdown() {
 	m.logger.Info("shutting down module")
 
+	m.logger.Info("unsubscribing from events")
+	for _, unsubscribe := range m.unsubscribe {
+		unsubscribe()
+	}
+
 	m.logger.Info("stopping scheduler")
 	m.scheduler.Stop()
 	m.wg.Done()
@@ -115,7 +233,6 @@ func (m *Module) Shutdown() {
 
 	m.logger.Info("sending stop signal")
 	close(m.done)
-	close(m.isSchedulerMasterCh)
 
 	m.logger.Info("waiting for scheduler background processes to complete")
 	m.wg.Wait()
@@ -135,6 +252,95 @@ func (m *Module) Shutdown() {
 	m.logger.Info("module shutdown complete")
 }
 
+// workerCount returns the configured number of workers, falling back to defaultWorkers.
+func (m *Module) workerCount() int {
+	if m.cfg.Orchestrator == nil || m.cfg.Orchestrator.Workers < 1 {
+		return defaultWorkers
+	}
+
+	return m.cfg.Orchestrator.Workers
+}
+
+// retentionConfig returns the configured retention of finished runs, falling back to the
+// defaults for values that are not set.
+func (m *Module) retentionConfig() config.RetentionConfig {
+	retention := config.RetentionConfig{
+		Days:       defaultRetentionDays,
+		FailedDays: defaultFailedRetentionDays,
+		BatchSize:  defaultRetentionBatchSize,
+		LogLines:   defaultLogLines,
+	}
+	if m.cfg.Orchestrator == nil || m.cfg.Orchestrator.Retention == nil {
+		return retention
+	}
+
+	configured := m.cfg.Orchestrator.Retention
+	if configured.Days > 0 {
+		retention.Days = configured.Days
+	}
+	if configured.FailedDays > 0 {
+		retention.FailedDays = configured.FailedDays
+	}
+	if configured.BatchSize > 0 {
+		retention.BatchSize = configured.BatchSize
+	}
+	if configured.LogLines > 0 {
+		retention.LogLines = configured.LogLines
+	}
+
+	return retention
+}
+
 func (m *Module) initModuleLogger(monoLogger *slog.Logger) {
 	m.logger = monoLogger.With(slog.Group("module", slog.String("name", ModuleName)))
 }
+
+type RouteDefinition struct {
+	Path    string
+	Handler http.HandlerFunc
+}
+
+type RouteDefinitionList []RouteDefinition
+
+func (m *Module) registerEndpoints(mux *http.ServeMux) {
+	routeDefinitions := RouteDefinitionList{
+		// Tasks
+		{"GET /api/v1/orchestrator/tasks", m.ListTaskHandler},
+		{"GET /api/v1/orchestrator/tasks/{name}", m.GetTaskHandler},
+		{"PATCH /api/v1/orchestrator/tasks/{name}", m.PatchTaskHandler},
+		{"POST /api/v1/orchestrator/tasks/{name}/run", m.PostTaskRunHandler},
+		// Task Schedules
+		{"GET /api/v1/orchestrator/schedules", m.ListTaskScheduleHandler},
+		{"POST /api/v1/orchestrator/schedules", m.PostTaskScheduleHandler},
+		{"GET /api/v1/orchestrator/schedules/{id}", m.GetTaskScheduleHandler},
+		{"PATCH /api/v1/orchestrator/schedules/{id}", m.PatchTaskScheduleHandler},
+		{"DELETE /api/v1/orchestrator/schedules/{id}", m.DeleteTaskScheduleHandler},
+		// Webhooks
+		{"GET /api/v1/orchestrator/webhooks", m.ListWebhookHandler},
+		{"POST /api/v1/orchestrator/webhooks", m.PostWebhookHandler},
+		{"GET /api/v1/orchestrator/webhooks/{id}", m.GetWebhookHandler},
+		{"PATCH /api/v1/orchestrator/webhooks/{id}", m.PatchWebhookHandler},
+		{"DELETE /api/v1/orchestrator/webhooks/{id}", m.DeleteWebhookHandler},
+		{"GET /api/v1/orchestrator/webhooks/{id}/deliveries", m.ListWebhookDeliveryHandler},
+		// Scheduled Tasks
+		{"GET /api/v1/orchestrator/scheduled-tasks", m.ListScheduledTaskHandler},
+		{"GET /api/v1/orchestrator/scheduled-tasks/{id}", m.GetScheduledTaskHandler},
+		{"POST /api/v1/orchestrator/scheduled-tasks/{id}/cancel", m.PostCancelScheduledTaskHandler},
+		{"POST /api/v1/orchestrator/scheduled-tasks/{id}/stop", m.PostStopScheduledTaskHandler},
+		{"POST /api/v1/orchestrator/scheduled-tasks/{id}/replay", m.PostReplayScheduledTaskHandler},
+		{"GET /api/v1/orchestrator/scheduled-tasks/{id}/workflow", m.GetWorkflowHandler},
+		{"GET /api/v1/orchestrator/scheduled-tasks/{id}/logs", m.ListScheduledTaskLogsHandler},
+		{"GET /api/v1/orchestrator/scheduled-tasks/{id}/logs/stream", m.StreamScheduledTaskLogsHandler},
+		// Leader
+		{"GET /api/v1/orchestrator/leader", m.GetLeaderHandler},
+		// Statistics
+		{"GET /api/v1/orchestrator/stats", m.ListTaskStatsHandler},
+		{"GET /metrics", m.MetricsHandler},
+	}
+
+	m.logger.Info("adding endpoints")
+	for _, d := range routeDefinitions {
+		m.logger.Info("adding route", "route", d.Path)
+		mux.Handle(d.Path, d.Handler)
+	}
+}
@@ -0,0 +1,386 @@
+package orchestrator
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+
+	"github.com/r3d5un/Bookshelf/internal/logging"
+	"github.com/r3d5un/Bookshelf/internal/orchestrator/data"
+	"github.com/r3d5un/Bookshelf/internal/orchestrator/types"
+	"github.com/r3d5un/Bookshelf/internal/rest"
+	"github.com/r3d5un/Bookshelf/internal/validator"
+)
+
+func (m *Module) ListScheduledTaskHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := logging.LoggerFromContext(ctx)
+
+	var input struct {
+		data.Filters
+	}
+
+	v := validator.New()
+
+	qs := r.URL.Query()
+
+	input.Filters.ID = rest.ReadQueryUUID(qs, "id", v)
+	if name := rest.ReadQueryString(qs, "queue", ""); name != "" {
+		input.Filters.Name = &name
+	}
+	if state := rest.ReadQueryString(qs, "state", ""); state != "" {
+		input.Filters.State = &state
+	}
+	input.Filters.CreatedAtFrom = rest.ReadQueryDate(qs, "createdAtFrom", v)
+	input.Filters.CreatedAtTo = rest.ReadQueryDate(qs, "createdAtTo", v)
+	input.Filters.UpdatedAtFrom = rest.ReadQueryDate(qs, "updatedAtFrom", v)
+	input.Filters.UpdatedAtTo = rest.ReadQueryDate(qs, "updatedAtTo", v)
+	input.Filters.RunAtFrom = rest.ReadQueryDate(qs, "runAtFrom", v)
+	input.Filters.RunAtTo = rest.ReadQueryDate(qs, "runAtTo", v)
+
+	input.Filters.Page = rest.ReadQueryInt(qs, "page", 1, v)
+	input.Filters.PageSize = rest.ReadQueryInt(qs, "page_size", 1_000, v)
+
+	input.Filters.OrderBy = rest.ReadQueryCommaSeperatedString(qs, "order_by", "-run_at")
+	input.Filters.OrderBySafeList = []string{
+		"id",
+		"name",
+		"state",
+		"created_at",
+		"updated_at",
+		"run_at",
+		"-id",
+		"-name",
+		"-state",
+		"-created_at",
+		"-updated_at",
+		"-run_at",
+	}
+	logger.InfoContext(ctx, "filters set", "filters", input)
+
+	if input.Filters.State != nil {
+		v.Check(slices.Contains(taskStates, *input.Filters.State), "state", "invalid task state")
+	}
+	if data.ValidateFilters(v, input.Filters); !v.Valid() {
+		logger.Info("invalid query parameters", "errors", v.Errors)
+		rest.FailedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	logger.Info("querying database for scheduled tasks")
+	scheduledTasks, err := types.ReadAllScheudledTasks(ctx, &m.models, input.Filters)
+	if err != nil {
+		logger.Error("unable to read scheduled tasks", "error", err)
+		rest.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	logger.Info("writing response")
+	rest.Respond(w, r, http.StatusOK, scheduledTasks, nil)
+}
+
+func (m *Module) GetScheduledTaskHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := logging.LoggerFromContext(ctx)
+
+	logger.Info("parsing ID")
+	id, err := rest.ReadUUIDParam("id", r)
+	if err != nil {
+		logger.Info("unable to read id", "id", id, "error", err)
+		rest.NotFoundResponse(w, r)
+		return
+	}
+	logger.Info("ID parsed", slog.String("id", id.String()))
+
+	logger.Info("querying database for scheduled task")
+	scheduledTask, err := types.ReadScheduledTask(ctx, &m.models, *id)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			logger.Info("scheduled task not found", "id", id)
+			rest.NotFoundResponse(w, r)
+		default:
+			logger.Error("unable to get scheduled task", "id", id, "error", err)
+			rest.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	logger.Info("writing response")
+	rest.Respond(w, r, http.StatusOK, scheduledTask, nil)
+}
+
+// PostCancelScheduledTaskHandler stops a waiting or blocked task from running.
+func (m *Module) PostCancelScheduledTaskHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := logging.LoggerFromContext(ctx)
+
+	logger.Info("parsing ID")
+	id, err := rest.ReadUUIDParam("id", r)
+	if err != nil {
+		logger.Info("unable to read id", "id", id, "error", err)
+		rest.NotFoundResponse(w, r)
+		return
+	}
+	logger.Info("ID parsed", slog.String("id", id.String()))
+
+	logger.Info("cancelling scheduled task")
+	scheduledTask, err := m.CancelScheduledTask(ctx, *id)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			logger.Info("scheduled task not found", "id", id)
+			rest.NotFoundResponse(w, r)
+		case errors.Is(err, types.ErrTaskNotWaiting):
+			logger.Info("scheduled task not waiting", "id", id)
+			rest.ConflictResponse(w, r, "only waiting or blocked tasks can be cancelled")
+		default:
+			logger.Error("unable to cancel scheduled task", "id", id, "error", err)
+			rest.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+	logger.Info("scheduled task cancelled", "scheduledTask", scheduledTask)
+
+	logger.Info("writing response")
+	rest.Respond(w, r, http.StatusOK, scheduledTask, nil)
+}
+
+// PostStopScheduledTaskHandler stops a waiting or running task. Running tasks are stopped
+// asynchronously, and the request is accepted before the task has stopped.
+func (m *Module) PostStopScheduledTaskHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := logging.LoggerFromContext(ctx)
+
+	logger.Info("parsing ID")
+	id, err := rest.ReadUUIDParam("id", r)
+	if err != nil {
+		logger.Info("unable to read id", "id", id, "error", err)
+		rest.NotFoundResponse(w, r)
+		return
+	}
+	logger.Info("ID parsed", slog.String("id", id.String()))
+
+	logger.Info("stopping scheduled task")
+	scheduledTask, err := m.StopScheduledTask(ctx, *id)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			logger.Info("scheduled task not found", "id", id)
+			rest.NotFoundResponse(w, r)
+		case errors.Is(err, types.ErrTaskNotRunning):
+			logger.Info("scheduled task not running", "id", id)
+			rest.ConflictResponse(w, r, "only waiting or running tasks can be stopped")
+		default:
+			logger.Error("unable to stop scheduled task", "id", id, "error", err)
+			rest.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+	logger.Info("scheduled task stop requested", "scheduledTask", scheduledTask)
+
+	status := http.StatusOK
+	if *scheduledTask.State == string(data.RunningTaskState) {
+		status = http.StatusAccepted
+	}
+
+	logger.Info("writing response")
+	rest.Respond(w, r, status, scheduledTask, nil)
+}
+
+// PostReplayScheduledTaskHandler puts a dead task back in the queue to run immediately.
+func (m *Module) PostReplayScheduledTaskHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := logging.LoggerFromContext(ctx)
+
+	logger.Info("parsing ID")
+	id, err := rest.ReadUUIDParam("id", r)
+	if err != nil {
+		logger.Info("unable to read id", "id", id, "error", err)
+		rest.NotFoundResponse(w, r)
+		return
+	}
+	logger.Info("ID parsed", slog.String("id", id.String()))
+
+	logger.Info("replaying scheduled task")
+	scheduledTask, err := m.ReplayScheduledTask(ctx, *id)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			logger.Info("scheduled task not found", "id", id)
+			rest.NotFoundResponse(w, r)
+		case errors.Is(err, types.ErrTaskNotDead):
+			logger.Info("scheduled task not dead", "id", id)
+			rest.ConflictResponse(w, r, "only dead tasks can be replayed")
+		default:
+			logger.Error("unable to replay scheduled task", "id", id, "error", err)
+			rest.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+	logger.Info("scheduled task replayed", "scheduledTask", scheduledTask)
+
+	logger.Info("writing response")
+	rest.Respond(w, r, http.StatusOK, scheduledTask, nil)
+}
+
+// GetWorkflowHandler returns a run together with all of the runs it started, and a summary of
+// their states.
+func (m *Module) GetWorkflowHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := logging.LoggerFromContext(ctx)
+
+	logger.Info("parsing ID")
+	id, err := rest.ReadUUIDParam("id", r)
+	if err != nil {
+		logger.Info("unable to read id", "id", id, "error", err)
+		rest.NotFoundResponse(w, r)
+		return
+	}
+	logger.Info("ID parsed", slog.String("id", id.String()))
+
+	logger.Info("querying database for workflow")
+	workflow, err := m.ReadWorkflow(ctx, *id)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			logger.Info("scheduled task not found", "id", id)
+			rest.NotFoundResponse(w, r)
+		default:
+			logger.Error("unable to get workflow", "id", id, "error", err)
+			rest.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	logger.Info("writing response")
+	rest.Respond(w, r, http.StatusOK, workflow, nil)
+}
+
+func (m *Module) ListScheduledTaskLogsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := logging.LoggerFromContext(ctx)
+
+	logger.Info("parsing ID")
+	id, err := rest.ReadUUIDParam("id", r)
+	if err != nil {
+		logger.Info("unable to read id", "id", id, "error", err)
+		rest.NotFoundResponse(w, r)
+		return
+	}
+	logger.Info("ID parsed", slog.String("id", id.String()))
+
+	logger.Info("querying database for scheduled task logs")
+	logs, err := m.ReadScheduledTaskLogs(ctx, *id)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			logger.Info("scheduled task not found", "id", id)
+			rest.NotFoundResponse(w, r)
+		default:
+			logger.Error("unable to get scheduled task logs", "id", id, "error", err)
+			rest.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	logger.Info("writing response")
+	rest.Respond(w, r, http.StatusOK, logs, nil)
+}
+
+// StreamScheduledTaskLogsHandler streams the logs of a run as server-sent events. Existing logs
+// are sent first, followed by new logs as they are written, each as a log event with the
+// sequence number of the log as the event ID. Clients reconnecting with the Last-Event-ID header
+// only receive logs written after that log. An end event with the run is sent once the run has
+// finished and all of its logs are sent.
+func (m *Module) StreamScheduledTaskLogsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := logging.LoggerFromContext(ctx)
+
+	logger.Info("parsing ID")
+	id, err := rest.ReadUUIDParam("id", r)
+	if err != nil {
+		logger.Info("unable to read id", "id", id, "error", err)
+		rest.NotFoundResponse(w, r)
+		return
+	}
+	logger.Info("ID parsed", slog.String("id", id.String()))
+
+	var afterSeq int64
+	if lastEventID := r.Header.Get("Last-Event-ID"); lastEventID != "" {
+		afterSeq, err = strconv.ParseInt(lastEventID, 10, 64)
+		if err != nil {
+			rest.BadRequestResponse(w, r, "Last-Event-ID must be a log sequence number")
+			return
+		}
+	}
+
+	logger.Info("querying database for scheduled task")
+	_, err = m.ReadScheduledTask(ctx, *id)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			logger.Info("scheduled task not found", "id", id)
+			rest.NotFoundResponse(w, r)
+		default:
+			logger.Error("unable to get scheduled task", "id", id, "error", err)
+			rest.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	logger.Info("starting event stream", "afterSeq", afterSeq)
+	stream, err := rest.NewEventStream(w)
+	if err != nil {
+		logger.Error("unable to start event stream", "error", err)
+		rest.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	send := func(log *types.TaskLog) error {
+		js, err := json.Marshal(log)
+		if err != nil {
+			return err
+		}
+		return stream.Send(strconv.FormatInt(log.Seq, 10), "log", string(js))
+	}
+	keepalive := func() error {
+		return stream.Comment("keepalive")
+	}
+
+	run, err := m.StreamScheduledTaskLogs(ctx, *id, afterSeq, send, keepalive)
+	if err != nil {
+		// The response has already started, so errors can only be logged
+		if ctx.Err() == nil {
+			logger.Error("log stream ended", "id", id, "error", err)
+		}
+		return
+	}
+
+	js, err := json.Marshal(run)
+	if err != nil {
+		logger.Error("unable to encode run", "error", err)
+		return
+	}
+	err = stream.Send("", "end", string(js))
+	if err != nil {
+		logger.Error("unable to send end event", "error", err)
+		return
+	}
+	logger.Info("log stream complete")
+}
+
+var taskStates = []string{
+	string(data.WaitingTaskState),
+	string(data.RunningTaskState),
+	string(data.CompleteTaskState),
+	string(data.StoppedTaskState),
+	string(data.ErrorTaskState),
+	string(data.SkippedTaskState),
+	string(data.DeadTaskState),
+	string(data.BlockedTaskState),
+	string(data.AwaitingTaskState),
+}
@@ -4,13 +4,16 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"log/slog"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/r3d5un/Bookshelf/internal/logging"
+	"github.com/r3d5un/Bookshelf/internal/orchestrator"
 	"github.com/r3d5un/Bookshelf/internal/orchestrator/data"
 	"github.com/r3d5un/Bookshelf/internal/orchestrator/types"
+	"github.com/r3d5un/Bookshelf/internal/webhooks"
 )
 
 // addTasks is where any tasks the scheduler is resposible for queueing
@@ -19,11 +22,42 @@ func (m *Module) addTasks(ctx context.Context) error {
 	logger := logging.LoggerFromContext(ctx)
 
 	logger.Info("adding tasks")
+	// Backups are retried, as a failed backup is otherwise not made up for until the next day.
+	// Only one backup runs at a time, so concurrent runs do not race over archive retention.
+	backupTask := types.NewTask(BackupLibraryName, "0 2 * * *", false, time.Now(), m.backupLibrary)
+	backupAttempts, backupBackoff, backupConcurrency := 3, 5*60, 1
+	backupTask.MaxAttempts = &backupAttempts
+	backupTask.RetryBackoff = &backupBackoff
+	backupTask.MaxConcurrency = &backupConcurrency
+
+	// Webhook deliveries are enqueued when events occur, and are retried for about an hour
+	// before being given up on. Deliveries are enabled by default, as they only run for the
+	// webhooks that have been registered.
+	deliverWebhookTask := types.NewTask(DeliverWebhookName, "", true, time.Now(), m.deliverWebhook)
+	deliveryAttempts, deliveryBackoff, deliveryJitter := 6, 60, 30
+	deliverWebhookTask.MaxAttempts = &deliveryAttempts
+	deliverWebhookTask.RetryBackoff = &deliveryBackoff
+	deliverWebhookTask.RetryJitter = &deliveryJitter
+
 	tasks := []types.Task{
 		types.NewTask("Hello, World!", "* * * * *", false, time.Now(), m.helloWorld),
 		types.NewTask(
 			RemoveOldScheduledTask, "* * * * *", false, time.Now(), m.removeOldScheduledTasks,
 		),
+		backupTask,
+		// Weekly maintenance, backing up the library once the task queue has been cleaned up
+		types.NewTask(
+			LibraryMaintenanceName,
+			"0 3 * * 0",
+			false,
+			time.Now(),
+			m.workflow(RemoveOldScheduledTask, BackupLibraryName),
+		),
+		deliverWebhookTask,
+		types.NewTask(PurgeTrashName, "0 4 * * *", false, time.Now(), m.purgeTrash),
+		types.NewTask(
+			PurgeIdempotencyKeysName, "0 * * * *", false, time.Now(), m.purgeIdempotencyKeys,
+		),
 	}
 
 	logger.Info("syncing task with database")
@@ -38,15 +72,129 @@ func (m *Module) addTasks(ctx context.Context) error {
 		m.logger.Info("adding task to runner", "task", task)
 		m.taskCollection.Add(task.Name, task.Job)
 
-		m.logger.Info("adding task to scheduler", "task", task)
-		m.scheduler.AddCronJob(ctx, *task.CronExpr, types.ScheduledTask{
-			Name: &task.Name,
-		})
+		err := m.scheduleTask(ctx, task.Name)
+		if err != nil {
+			logger.Error("unable to schedule task", "task", task, "error", err)
+			return err
+		}
 	}
 
 	return nil
 }
 
+// scheduleTask adds or replaces the cron jobs of the task, using the cron expression from the
+// task overview, as the overview is where the schedule of a task is maintained, and the stored
+// schedules of the task. Disabled tasks are removed from the scheduler with all their schedules.
+func (m *Module) scheduleTask(ctx context.Context, name string) error {
+	logger := logging.LoggerFromContext(ctx).With(slog.String("taskName", name))
+
+	if _, err := m.taskCollection.Get(name); err != nil {
+		logger.Info("task not added to runner; not scheduling")
+		return nil
+	}
+
+	logger.Info("reading task from overview")
+	task, err := types.ReadTask(ctx, &m.models, name)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			logger.Info("task not found in overview; removing from scheduler")
+			m.unscheduleTask(ctx, name)
+			return nil
+		default:
+			logger.Error("unable to read task", "error", err)
+			return err
+		}
+	}
+
+	if !*task.Enabled {
+		logger.Info("task disabled; removing from scheduler")
+		m.unscheduleTask(ctx, name)
+		return nil
+	}
+
+	// Tasks without a cron expression only run when enqueued, such as webhook deliveries
+	if task.CronExpr == nil || *task.CronExpr == "" {
+		logger.Info("task has no cron expression; not adding to scheduler")
+		m.scheduler.RemoveCronJob(name)
+	} else {
+		logger.Info("adding task to scheduler", "task", task)
+		err = m.scheduler.AddCronJob(ctx, *task.CronExpr, types.ScheduledTask{Name: &task.Name})
+		if err != nil {
+			return err
+		}
+	}
+
+	logger.Info("reading task schedules")
+	schedules, err := types.ReadTaskSchedulesByTaskName(ctx, &m.models, name)
+	if err != nil {
+		logger.Error("unable to read task schedules", "error", err)
+		return err
+	}
+
+	logger.Info("adding task schedules to scheduler", "length", len(schedules))
+	return m.scheduler.SetSchedules(ctx, name, schedules)
+}
+
+// unscheduleTask removes the cron job and all stored schedules of the task from the scheduler.
+func (m *Module) unscheduleTask(ctx context.Context, name string) {
+	m.scheduler.RemoveCronJob(name)
+	if err := m.scheduler.SetSchedules(ctx, name, nil); err != nil {
+		// Removing schedules does not add any cron jobs, and cannot fail
+		m.logger.Error("unable to remove task schedules", "error", err)
+	}
+}
+
+// rescheduleTask notifies all instances to reschedule the task after a change to the task or its
+// schedules. If the notification cannot be sent, only this instance is rescheduled, and the
+// remaining instances pick up the change on their next startup.
+func (m *Module) rescheduleTask(ctx context.Context, name string) {
+	logger := logging.LoggerFromContext(ctx).With(slog.String("taskName", name))
+
+	err := m.models.TaskNotifications.NotifyTaskConfig(
+		ctx,
+		data.TaskConfigNotification{Name: name},
+	)
+	if err != nil {
+		logger.Error("unable to notify listeners of task change", "error", err)
+		if err := m.scheduleTask(ctx, name); err != nil {
+			logger.Error("unable to reschedule task", "error", err)
+		}
+	}
+}
+
+// taskConfigListener reschedules tasks when notified about changes to the task overview. Every
+// instance keeps its cron jobs up to date, so that an instance elected leader
+// uses the current schedule.
+func (m *Module) taskConfigListener(ctx context.Context) {
+	go m.models.TaskNotifications.ListenTaskConfig(ctx, m.taskConfigNotificationCh, m.done)
+
+	for {
+		select {
+		case notification := <-m.taskConfigNotificationCh:
+			m.logger.Info("received task configuration change", "notification", notification)
+			var notificationPayload data.TaskConfigNotification
+			if err := json.Unmarshal([]byte(notification.Payload), &notificationPayload); err != nil {
+				m.logger.Error("unable to decode notification payload", "error", err)
+				continue
+			}
+
+			err := m.scheduleTask(ctx, notificationPayload.Name)
+			if err != nil {
+				m.logger.Error(
+					"unable to reschedule task", "name", notificationPayload.Name, "error", err,
+				)
+			}
+
+		case <-m.done:
+			m.logger.Info("done signal received, stopping task configuration listener")
+			return
+		}
+	}
+}
+
+// taskRunner runs tasks as notifications about them are received, using up to the configured
+// number of workers. Notifications received while all workers are busy are dropped.
 func (m *Module) taskRunner(ctx context.Context) {
 	defer m.wg.Done()
 	go m.models.TaskNotifications.Listen(ctx, m.taskNotificationCh, m.done)
@@ -66,9 +214,18 @@ func (m *Module) taskRunner(ctx context.Context) {
 				continue
 			}
 
-			go func() {
-				m.runTaskByID(ctx, notificationPayload.ID)
-			}()
+			select {
+			case m.workers <- struct{}{}:
+				go func() {
+					defer func() { <-m.workers }()
+					m.runTaskByID(ctx, notificationPayload.ID)
+				}()
+			default:
+				// The task is left waiting in the queue, and is picked up again when the
+				// reminder notifies about waiting tasks
+				m.logger.Info("all workers busy; leaving task in queue", "id", notificationPayload.ID)
+				m.backlogged.Store(true)
+			}
 
 		case <-m.done:
 			m.logger.Info("done signal received, stopping task runner")
@@ -93,16 +250,22 @@ func (m *Module) runTaskByID(ctx context.Context, id uuid.UUID) {
 				"not able to find task; assuming taking by other worker",
 				"error", err,
 			)
+		case errors.Is(err, data.ErrConcurrencyLimit):
+			logger.Info("task concurrency limit reached; leaving task in queue")
 		default:
 			logger.Info("error occurred while consuming ID", "error", err)
 		}
 		return
 	}
 	logger.Info("scheduled task claimed", "scheduledTask", scheduledTask)
+	defer m.remindBacklog()
+	// Runs of the same task refused by its concurrency limit are left waiting, so the next in
+	// line is notified about once this run is done with its slot
+	defer m.notifyNextWaiting(ctx, *scheduledTask.Name)
 
 	skippedState := string(data.SkippedTaskState)
 	errorState := string(data.ErrorTaskState)
-	completeState := string(data.CompleteTaskState)
+	stoppedState := string(data.StoppedTaskState)
 
 	logger.Info("checking if task is enabled in the overview")
 	task, err := types.ReadTask(ctx, &m.models, *scheduledTask.Name)
@@ -112,139 +275,346 @@ func (m *Module) runTaskByID(ctx context.Context, id uuid.UUID) {
 		_, err := types.UpdateScheduledTask(ctx, &m.models, *scheduledTask)
 		if err != nil {
 			logger.Info("unable to set the scheduled task state", "error", err)
+			return
 		}
+		m.settleScheduledTask(ctx, id)
 		return
 	}
-	if !*task.Enabled {
+	// Child runs are part of the run of their parent, and run even if their own task is disabled
+	if !*task.Enabled && scheduledTask.ParentID == nil {
 		logger.Info("task not enabled; skipping run")
 		scheduledTask.State = &skippedState
 		_, err := types.UpdateScheduledTask(ctx, &m.models, *scheduledTask)
 		if err != nil {
 			logger.Info("unable to set the scheduled task state", "error", err)
+			return
 		}
+		m.settleScheduledTask(ctx, id)
 		return
 	}
 	logger.Info("task enabled", "task", task)
 
-	logger.Info("embedding task ID in context")
-	ctx = context.WithValue(ctx, "taskQueueID", id)
+	attempt := 1
+	if scheduledTask.Attempt != nil {
+		attempt = *scheduledTask.Attempt
+	}
+	logger.Info("embedding run in context")
+	ctx = orchestrator.WithRun(ctx, orchestrator.Run{
+		ID:      id,
+		Name:    *scheduledTask.Name,
+		Attempt: attempt,
+		Payload: scheduledTask.Payload,
+	})
+
+	runCtx, finishRun := m.startRun(ctx, id, task)
+	defer finishRun()
 
 	logger.Info("running scheduled task", "scheduledTask", scheduledTask, "task", task)
-	err = m.taskCollection.Run(ctx, *scheduledTask.Name)
+	err = m.taskCollection.Run(runCtx, *scheduledTask.Name)
+
+	cause := context.Cause(runCtx)
+	if errors.Is(cause, types.ErrTaskStopped) {
+		logger.Info("scheduled task stopped", "error", err)
+		scheduledTask.State = &stoppedState
+		_, err := types.UpdateScheduledTask(ctx, &m.models, *scheduledTask)
+		if err != nil {
+			logger.Info("unable to set the scheduled task state", "error", err)
+			return
+		}
+		m.settleScheduledTask(ctx, id)
+		return
+	}
 	if err != nil {
+		if errors.Is(cause, types.ErrTaskTimedOut) {
+			err = fmt.Errorf("%w: %w", cause, err)
+		}
 		m.logger.Info("an error occurred while running the task", "error", err)
-		scheduledTask.State = &errorState
-		_, err := types.UpdateScheduledTask(ctx, &m.models, *scheduledTask)
+		failedTask, err := types.FailScheduledTask(ctx, &m.models, *scheduledTask, *task, err)
 		if err != nil {
 			logger.Info("unable to set the scheduled task state", "error", err)
+			return
+		}
+		logger.Info("scheduled task failed", "scheduledTask", failedTask)
+		if types.IsFinished(*failedTask.State) {
+			m.settleScheduledTask(ctx, id)
+			m.emitTaskEvent(ctx, webhooks.TaskFailed, failedTask)
 		}
 		return
 	}
-	scheduledTask.State = &completeState
-	scheduledTask, err = types.UpdateScheduledTask(ctx, &m.models, *scheduledTask)
+	completedTask, ready, err := types.CompleteScheduledTask(ctx, &m.models, id)
 	if err != nil {
 		logger.Info("unable to set the scheduled task state", "error", err)
 		return
 	}
+	m.notifyReady(ctx, ready)
+	m.emitTaskEvent(ctx, webhooks.TaskCompleted, completedTask)
 
-	logger.Info("scheduled task completed", "scheduledTask", scheduledTask, "task", task)
+	logger.Info("scheduled task completed", "scheduledTask", completedTask, "task", task)
 }
 
-// checkSchedulerLock attempts to acquire the scheduler lock in a continuous loop.
-// The current state of the lock is communicated through the m.isSchedulerMasterCh,
-// which is responsible for managing the task scheduler
-func (m *Module) checkSchedulerLock(ctx context.Context) {
-	ticker := time.NewTicker(5 * time.Second)
+// startRun registers the run so that it can be stopped, and sends heartbeats for it until the
+// returned finish function is called. The returned context is cancelled with ErrTaskStopped
+// when the run is stopped, or with ErrTaskTimedOut when the task exceeds its max runtime.
+func (m *Module) startRun(
+	ctx context.Context,
+	id uuid.UUID,
+	task *types.Task,
+) (runCtx context.Context, finish func()) {
+	runCtx, cancel := context.WithCancelCause(ctx)
+
+	stopTimer := func() bool { return false }
+	if task.MaxRuntime != nil && *task.MaxRuntime > 0 {
+		maxRuntime := time.Duration(*task.MaxRuntime) * time.Second
+		stopTimer = time.AfterFunc(maxRuntime, func() { cancel(types.ErrTaskTimedOut) }).Stop
+	}
+
+	m.runsMu.Lock()
+	m.runs[id] = cancel
+	m.runsMu.Unlock()
+
+	heartbeatDone := make(chan struct{})
+	go m.heartbeat(ctx, id, heartbeatDone)
+
+	return runCtx, func() {
+		stopTimer()
+		close(heartbeatDone)
+
+		m.runsMu.Lock()
+		delete(m.runs, id)
+		m.runsMu.Unlock()
+
+		cancel(nil)
+	}
+}
+
+// stopRun cancels the run with the given ID, if it is running on this instance.
+func (m *Module) stopRun(id uuid.UUID) (found bool) {
+	m.runsMu.Lock()
+	cancel, found := m.runs[id]
+	m.runsMu.Unlock()
+
+	if found {
+		cancel(types.ErrTaskStopped)
+	}
+
+	return found
+}
+
+// heartbeat marks the run as alive until the done channel is closed.
+func (m *Module) heartbeat(ctx context.Context, id uuid.UUID, done <-chan struct{}) {
+	logger := logging.LoggerFromContext(ctx).With(slog.String("taskId", id.String()))
+
+	ticker := time.NewTicker(heartbeatInterval)
 	defer ticker.Stop()
 
-	for range ticker.C {
+	for {
 		select {
-		case <-m.done:
-			m.logger.Info("received done signal; stopping scheduler")
+		case <-done:
 			return
-		default:
-			acquired, err := m.models.SchedulerLock.AcquireLock(ctx, m.schedulerID)
+		case <-ticker.C:
+			err := types.HeartbeatScheduledTask(ctx, &m.models, id)
 			if err != nil {
-				m.logger.Error("error occurred while acquiring scheduler lock", "error", err)
+				logger.Error("unable to send heartbeat", "error", err)
 			}
-			m.isSchedulerMasterCh <- acquired
 		}
 	}
 }
 
-// manageScheduler is responsible for starting and stopping the scheduler
-// based on the state and value of the m.isSchedulerMasterCh.
-//
-// If the current intance acquires the lock, attempts to maintain the lock will
-// occur on each subsequent signal through the m.isSchedulerMasterCh channel.
-func (m *Module) manageScheduler(ctx context.Context) {
+// taskStopListener stops runs on this instance when notified about stop requests.
+func (m *Module) taskStopListener(ctx context.Context) {
+	go m.models.TaskNotifications.ListenTaskStop(ctx, m.taskStopNotificationCh, m.done)
+
 	for {
 		select {
+		case notification := <-m.taskStopNotificationCh:
+			m.logger.Info("received stop request", "notification", notification)
+			var notificationPayload data.TaskNotification
+			if err := json.Unmarshal([]byte(notification.Payload), &notificationPayload); err != nil {
+				m.logger.Error("unable to decode notification payload", "error", err)
+				continue
+			}
+
+			if m.stopRun(notificationPayload.ID) {
+				m.logger.Info("run stopped", "id", notificationPayload.ID)
+			}
+
 		case <-m.done:
-			m.logger.Info("received done signal; no longer maintaining scheduler lock")
+			m.logger.Info("done signal received, stopping task stop listener")
 			return
-		case active, ok := <-m.isSchedulerMasterCh:
-			if !ok {
-				m.logger.Info("scheduler lock channel closed")
-				return
-			}
-			if !active {
-				m.logger.Info("unable to acquire scheduler lock")
-				m.scheduler.Stop()
-			} else {
-				m.logger.Info("scheduler lock acquired")
-				m.scheduler.Start()
-				err := m.models.SchedulerLock.MaintainLock(ctx, m.schedulerID)
-				if err != nil {
-					m.logger.Info("unable to maintain scheduler lock", "error", err)
-					m.scheduler.Stop()
-					continue
-				}
+		}
+	}
+}
+
+// taskLogListener passes notifications about new log lines to the followers of the run on
+// this instance.
+func (m *Module) taskLogListener(ctx context.Context) {
+	go m.models.TaskNotifications.ListenTaskLog(ctx, m.taskLogNotificationCh, m.done)
+
+	for {
+		select {
+		case notification := <-m.taskLogNotificationCh:
+			var notificationPayload data.TaskLogNotification
+			if err := json.Unmarshal([]byte(notification.Payload), &notificationPayload); err != nil {
+				m.logger.Error("unable to decode notification payload", "error", err)
+				continue
 			}
+
+			m.logHub.Publish(notificationPayload.TaskID)
+
+		case <-m.done:
+			m.logger.Info("done signal received, stopping task log listener")
+			return
 		}
 	}
 }
 
-func (m *Module) taskReminder(ctx context.Context) {
-	waitingStateFilter := string(data.WaitingTaskState)
+// eventForwarder delivers events stored in the event outbox to the subscribers on this instance
+// as they are announced, and reads the outbox regularly in case a notification was missed.
+// Events older than the outbox retention are removed along the way.
+func (m *Module) eventForwarder(ctx context.Context) {
+	go m.models.TaskNotifications.ListenEventOutbox(ctx, m.outboxNotificationCh, m.done)
+
+	forwardTicker := time.NewTicker(eventForwardInterval)
+	defer forwardTicker.Stop()
+	pruneTicker := time.NewTicker(time.Hour)
+	defer pruneTicker.Stop()
 
+	forward := func() {
+		if _, err := m.events.Forward(ctx); err != nil {
+			m.logger.Error("unable to forward events", "error", err)
+		}
+	}
+	forward()
+
+	for {
+		select {
+		case <-m.outboxNotificationCh:
+			forward()
+		case <-forwardTicker.C:
+			forward()
+		case <-pruneTicker.C:
+			_, err := m.events.Outbox().DeleteBefore(ctx, time.Now().Add(-outboxRetention))
+			if err != nil {
+				m.logger.Error("unable to remove old events from outbox", "error", err)
+			}
+		case <-m.done:
+			m.logger.Info("done signal received, stopping event forwarder")
+			return
+		}
+	}
+}
+
+// orphanReaper puts running tasks without a recent heartbeat back in the queue, or in the dead
+// state if they have no attempts left. These are left behind when an instance stops while running
+// tasks.
+func (m *Module) orphanReaper(ctx context.Context) {
 	ticker := time.NewTicker(1 * time.Minute)
 	defer ticker.Stop()
 
 	for range ticker.C {
 		select {
 		case <-m.done:
-			m.logger.Info("received done signal; stopping scheduler")
+			m.logger.Info("received done signal; stopping orphan reaper")
 			return
 		default:
-			timestamp := time.Now()
-			logger := logging.LoggerFromContext(ctx).
-				With(slog.Group(
-					"reminderLoop",
-					slog.String("id", uuid.New().String()),
-					slog.Time("timestamp", timestamp),
-				))
-
-			logger.Info("querying for tasks needing reminders")
-			staleTasks, err := types.ReadAllScheudledTasks(ctx, &m.models, data.Filters{
-				Page:     1,
-				PageSize: 500,
-				State:    &waitingStateFilter,
-				RunAtTo:  &timestamp,
-			})
+			staleBefore := time.Now().Add(-orphanTimeout)
+			requeuedTasks, err := types.RequeueOrphanedScheduledTasks(ctx, &m.models, staleBefore)
 			if err != nil {
-				logger.Error("unable to read stale tasks", "error", err)
+				m.logger.Error("unable to requeue orphaned tasks", "error", err)
+				continue
 			}
 
-			for _, scheduledTask := range staleTasks.Data {
-				logger.Info(
-					"sending reminder notification for scheduled task",
-					"scheduledTask", scheduledTask,
-				)
+			for _, scheduledTask := range requeuedTasks {
+				if types.IsFinished(*scheduledTask.State) {
+					m.logger.Info("orphaned task has no attempts left", "scheduledTask", scheduledTask)
+					m.settleScheduledTask(ctx, scheduledTask.ID)
+					m.emitTaskEvent(ctx, webhooks.TaskFailed, scheduledTask)
+					continue
+				}
+
+				m.logger.Info("orphaned task requeued", "scheduledTask", scheduledTask)
 				err := m.models.TaskNotifications.Notify(
 					ctx,
 					data.TaskNotification{ID: scheduledTask.ID, Queue: *scheduledTask.Name},
 				)
+				if err != nil {
+					m.logger.Error("unable to notify listeners", "error", err)
+				}
+			}
+		}
+	}
+}
+
+// remindBacklog triggers the reminder if tasks were left in the queue while all workers were
+// busy, so that they are picked up without waiting for the next reminder.
+func (m *Module) remindBacklog() {
+	if !m.backlogged.Swap(false) {
+		return
+	}
+
+	select {
+	case m.remindCh <- struct{}{}:
+	default:
+	}
+}
+
+// notifyNextWaiting notifies the task runners about the waiting run of the named task that is
+// next in line to run, if any.
+func (m *Module) notifyNextWaiting(ctx context.Context, name string) {
+	logger := logging.LoggerFromContext(ctx).With(slog.String("name", name))
+
+	scheduledTask, err := types.ReadNextRunnableScheduledTask(ctx, &m.models, name)
+	if err != nil {
+		if !errors.Is(err, data.ErrRecordNotFound) {
+			// The task reminder picks up waiting tasks that were never announced
+			logger.Error("unable to read next waiting task", "error", err)
+		}
+		return
+	}
+
+	m.notifyReady(ctx, []*types.ScheduledTask{scheduledTask})
+}
+
+// taskReminder notifies about waiting tasks that are due every minute, or when triggered by
+// remindBacklog. Tasks with a higher priority are notified about first.
+func (m *Module) taskReminder(ctx context.Context) {
+	ticker := time.NewTicker(1 * time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-m.done:
+			m.logger.Info("received done signal; stopping scheduler")
+			return
+		case <-ticker.C:
+		case <-m.remindCh:
+		}
+
+		timestamp := time.Now()
+		logger := logging.LoggerFromContext(ctx).
+			With(slog.Group(
+				"reminderLoop",
+				slog.String("id", uuid.New().String()),
+				slog.Time("timestamp", timestamp),
+			))
+
+		logger.Info("querying for tasks needing reminders")
+		staleTasks, err := types.ReadRunnableScheduledTasks(ctx, &m.models, reminderLimit)
+		if err != nil {
+			logger.Error("unable to read stale tasks", "error", err)
+			continue
+		}
+
+		for _, scheduledTask := range staleTasks {
+			logger.Info(
+				"sending reminder notification for scheduled task",
+				"scheduledTask", scheduledTask,
+			)
+			err := m.models.TaskNotifications.Notify(
+				ctx,
+				data.TaskNotification{ID: scheduledTask.ID, Queue: *scheduledTask.Name},
+			)
+			if err != nil {
 				logger.Error("unable to send reminder notification", "error", err)
 			}
 		}
@@ -0,0 +1,56 @@
+package orchestrator
+
+import (
+	"net/http"
+
+	"github.com/r3d5un/Bookshelf/internal/logging"
+	"github.com/r3d5un/Bookshelf/internal/orchestrator"
+	"github.com/r3d5un/Bookshelf/internal/rest"
+)
+
+// ListTaskStatsHandler returns the run statistics of every task.
+func (m *Module) ListTaskStatsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := logging.LoggerFromContext(ctx)
+
+	logger.Info("querying database for task stats")
+	stats, err := m.ReadTaskStats(ctx)
+	if err != nil {
+		logger.Error("unable to read task stats", "error", err)
+		rest.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	logger.Info("writing response")
+	rest.Respond(w, r, http.StatusOK, stats, nil)
+}
+
+// MetricsHandler exposes the task statistics and the state of the instance as Prometheus
+// metrics.
+func (m *Module) MetricsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := logging.LoggerFromContext(ctx)
+
+	logger.Info("querying database for task stats")
+	stats, err := m.ReadTaskStats(ctx)
+	if err != nil {
+		logger.Error("unable to read task stats", "error", err)
+		rest.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	metrics := orchestrator.Metrics{
+		Tasks:       stats,
+		Leader:      m.leader.IsLeader(),
+		Workers:     cap(m.workers),
+		BusyWorkers: len(m.workers),
+	}
+
+	logger.Info("writing response")
+	w.Header().Set("Content-Type", orchestrator.MetricsContentType)
+	w.WriteHeader(http.StatusOK)
+	err = orchestrator.WriteMetrics(w, metrics)
+	if err != nil {
+		logger.Error("unable to write metrics", "error", err)
+	}
+}
@@ -0,0 +1,203 @@
+package orchestrator
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/r3d5un/Bookshelf/internal/logging"
+	"github.com/r3d5un/Bookshelf/internal/orchestrator/data"
+	"github.com/r3d5un/Bookshelf/internal/orchestrator/types"
+	"github.com/r3d5un/Bookshelf/internal/rest"
+	"github.com/r3d5un/Bookshelf/internal/validator"
+)
+
+func (m *Module) ListTaskHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := logging.LoggerFromContext(ctx)
+
+	var input struct {
+		data.Filters
+	}
+
+	v := validator.New()
+
+	qs := r.URL.Query()
+
+	input.Filters.Enabled = rest.ReadQueryBool(qs, "enabled", v)
+	input.Filters.UpdatedAtFrom = rest.ReadQueryDate(qs, "updatedAtFrom", v)
+	input.Filters.UpdatedAtTo = rest.ReadQueryDate(qs, "updatedAtTo", v)
+
+	input.Filters.Page = rest.ReadQueryInt(qs, "page", 1, v)
+	input.Filters.PageSize = rest.ReadQueryInt(qs, "page_size", 1_000, v)
+
+	input.Filters.OrderBy = rest.ReadQueryCommaSeperatedString(qs, "order_by", "name")
+	input.Filters.OrderBySafeList = []string{
+		"name",
+		"cron_expr",
+		"enabled",
+		"updated_at",
+		"priority",
+		"-name",
+		"-cron_expr",
+		"-enabled",
+		"-updated_at",
+		"-priority",
+	}
+	logger.InfoContext(ctx, "filters set", "filters", input)
+
+	if data.ValidateFilters(v, input.Filters); !v.Valid() {
+		logger.Info("invalid query parameters", "errors", v.Errors)
+		rest.FailedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	logger.Info("querying database for tasks")
+	tasks, err := types.ReadAllTasks(ctx, &m.models, input.Filters)
+	if err != nil {
+		logger.Error("unable to read tasks", "error", err)
+		rest.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	logger.Info("writing response")
+	rest.Respond(w, r, http.StatusOK, tasks, nil)
+}
+
+func (m *Module) GetTaskHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := logging.LoggerFromContext(ctx)
+
+	logger.Info("parsing name")
+	name, err := rest.ReadStringParam("name", r)
+	if err != nil {
+		logger.Info("unable to read name", "error", err)
+		rest.NotFoundResponse(w, r)
+		return
+	}
+	logger.Info("name parsed", slog.String("name", *name))
+
+	logger.Info("querying database for task")
+	task, err := types.ReadTask(ctx, &m.models, *name)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			logger.Info("task not found", "name", *name)
+			rest.NotFoundResponse(w, r)
+		default:
+			logger.Error("unable to get task", "name", *name, "error", err)
+			rest.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	logger.Info("writing response")
+	rest.Respond(w, r, http.StatusOK, task, nil)
+}
+
+// PatchTaskHandler enables or disables a task, or changes its cron expression.
+func (m *Module) PatchTaskHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := logging.LoggerFromContext(ctx)
+
+	logger.Info("parsing name")
+	name, err := rest.ReadStringParam("name", r)
+	if err != nil {
+		logger.Info("unable to read name", "error", err)
+		rest.NotFoundResponse(w, r)
+		return
+	}
+	logger.Info("name parsed", slog.String("name", *name))
+
+	logger.Info("parsing request body")
+	var updateData types.Task
+	err = rest.ReadJSON(r, &updateData)
+	if err != nil {
+		logger.Info("unable to read request body", "error", err)
+		rest.BadRequestResponse(w, r, fmt.Sprintf("unable to read request body: %s\n", err))
+		return
+	}
+	updateData.Name = *name
+	updateData.UpdatedAt = nil
+
+	v := validator.New()
+	if types.ValidateTask(v, updateData); !v.Valid() {
+		logger.Info("invalid task", "errors", v.Errors)
+		rest.FailedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	logger.Info("updating task", "task", updateData)
+	task, err := m.UpdateTask(ctx, updateData)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			logger.Info("task not found", "name", *name)
+			rest.NotFoundResponse(w, r)
+		default:
+			logger.Error("unable to update task", "name", *name, "error", err)
+			rest.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+	logger.Info("task updated", "task", task)
+
+	logger.Info("writing response")
+	rest.Respond(w, r, http.StatusOK, task, nil)
+}
+
+// PostTaskRunHandler enqueues a single run of the task. The request body is optional, and may
+// hold a payload with arguments for the task, and a runAt time to delay the run until. Without
+// runAt, the run starts immediately.
+func (m *Module) PostTaskRunHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := logging.LoggerFromContext(ctx)
+
+	logger.Info("parsing name")
+	name, err := rest.ReadStringParam("name", r)
+	if err != nil {
+		logger.Info("unable to read name", "error", err)
+		rest.NotFoundResponse(w, r)
+		return
+	}
+	logger.Info("name parsed", slog.String("name", *name))
+
+	logger.Info("parsing request body")
+	var input struct {
+		Payload json.RawMessage `json:"payload"`
+		RunAt   *time.Time      `json:"runAt"`
+	}
+	err = rest.ReadJSON(r, &input)
+	if err != nil && !errors.Is(err, io.EOF) {
+		logger.Info("unable to read request body", "error", err)
+		rest.BadRequestResponse(w, r, fmt.Sprintf("unable to read request body: %s\n", err))
+		return
+	}
+
+	logger.Info("enqueuing task run", "payload", input.Payload, "runAt", input.RunAt)
+	scheduledTask, err := m.RunTask(ctx, *name, input.Payload, input.RunAt)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			logger.Info("task not found", "name", *name)
+			rest.NotFoundResponse(w, r)
+		case errors.Is(err, types.ErrTaskDisabled):
+			logger.Info("task disabled", "name", *name)
+			rest.ConflictResponse(w, r, "the task is disabled")
+		default:
+			logger.Error("unable to enqueue task run", "name", *name, "error", err)
+			rest.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+	logger.Info("task run enqueued", "scheduledTask", scheduledTask)
+
+	logger.Info("writing response")
+	headers := http.Header{}
+	headers.Set("Location", "/api/v1/orchestrator/scheduled-tasks/"+scheduledTask.ID.String())
+	rest.Respond(w, r, http.StatusCreated, scheduledTask, headers)
+}
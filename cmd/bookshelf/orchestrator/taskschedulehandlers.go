@@ -0,0 +1,236 @@
+package orchestrator
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/r3d5un/Bookshelf/internal/logging"
+	"github.com/r3d5un/Bookshelf/internal/orchestrator/data"
+	"github.com/r3d5un/Bookshelf/internal/orchestrator/types"
+	"github.com/r3d5un/Bookshelf/internal/rest"
+	"github.com/r3d5un/Bookshelf/internal/validator"
+)
+
+func (m *Module) ListTaskScheduleHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := logging.LoggerFromContext(ctx)
+
+	var input struct {
+		data.Filters
+	}
+
+	v := validator.New()
+
+	qs := r.URL.Query()
+
+	input.Filters.ID = rest.ReadQueryUUID(qs, "id", v)
+	if taskName := rest.ReadQueryString(qs, "taskName", ""); taskName != "" {
+		input.Filters.Name = &taskName
+	}
+	input.Filters.Enabled = rest.ReadQueryBool(qs, "enabled", v)
+	input.Filters.CreatedAtFrom = rest.ReadQueryDate(qs, "createdAtFrom", v)
+	input.Filters.CreatedAtTo = rest.ReadQueryDate(qs, "createdAtTo", v)
+
+	input.Filters.Page = rest.ReadQueryInt(qs, "page", 1, v)
+	input.Filters.PageSize = rest.ReadQueryInt(qs, "page_size", 1_000, v)
+
+	input.Filters.OrderBy = rest.ReadQueryCommaSeperatedString(qs, "order_by", "name")
+	input.Filters.OrderBySafeList = []string{
+		"name",
+		"task_name",
+		"enabled",
+		"created_at",
+		"updated_at",
+		"-name",
+		"-task_name",
+		"-enabled",
+		"-created_at",
+		"-updated_at",
+	}
+	logger.InfoContext(ctx, "filters set", "filters", input)
+
+	if data.ValidateFilters(v, input.Filters); !v.Valid() {
+		logger.Info("invalid query parameters", "errors", v.Errors)
+		rest.FailedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	logger.Info("querying database for task schedules")
+	schedules, err := m.ReadAllTaskSchedules(ctx, input.Filters)
+	if err != nil {
+		logger.Error("unable to read task schedules", "error", err)
+		rest.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	logger.Info("writing response")
+	rest.Respond(w, r, http.StatusOK, schedules, nil)
+}
+
+func (m *Module) GetTaskScheduleHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := logging.LoggerFromContext(ctx)
+
+	logger.Info("parsing ID")
+	id, err := rest.ReadUUIDParam("id", r)
+	if err != nil {
+		logger.Info("unable to read id", "id", id, "error", err)
+		rest.NotFoundResponse(w, r)
+		return
+	}
+	logger.Info("ID parsed", slog.String("id", id.String()))
+
+	logger.Info("querying database for task schedule")
+	schedule, err := m.ReadTaskSchedule(ctx, *id)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			logger.Info("task schedule not found", "id", id)
+			rest.NotFoundResponse(w, r)
+		default:
+			logger.Error("unable to get task schedule", "id", id, "error", err)
+			rest.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	logger.Info("writing response")
+	rest.Respond(w, r, http.StatusOK, schedule, nil)
+}
+
+// PostTaskScheduleHandler adds a named cron schedule to a task, enqueuing runs of the task with
+// the payload of the schedule.
+func (m *Module) PostTaskScheduleHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := logging.LoggerFromContext(ctx)
+
+	logger.Info("parsing request body")
+	var newSchedule types.TaskSchedule
+	err := rest.ReadJSON(r, &newSchedule)
+	if err != nil {
+		logger.Info("unable to read request body", "error", err)
+		rest.BadRequestResponse(w, r, fmt.Sprintf("unable to read request body: %s\n", err))
+		return
+	}
+
+	v := validator.New()
+	if types.ValidateNewTaskSchedule(v, newSchedule); !v.Valid() {
+		logger.Info("invalid task schedule", "errors", v.Errors)
+		rest.FailedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	logger.Info("creating task schedule", "schedule", newSchedule)
+	schedule, err := m.CreateTaskSchedule(ctx, newSchedule)
+	if err != nil {
+		switch {
+		case errors.Is(err, types.ErrScheduleTaskNotFound):
+			logger.Info("task not found", "name", *newSchedule.TaskName)
+			rest.FailedValidationResponse(
+				w, r, map[string]string{"taskName": "must be the name of an existing task"},
+			)
+		case errors.Is(err, data.ErrDuplicateRecord):
+			logger.Info("task schedule name in use", "name", *newSchedule.Name)
+			rest.ConflictResponse(w, r, "a schedule with the name already exists")
+		default:
+			logger.Error("unable to create task schedule", "error", err)
+			rest.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+	logger.Info("task schedule created", "schedule", schedule)
+
+	logger.Info("writing response")
+	headers := http.Header{}
+	headers.Set("Location", "/api/v1/orchestrator/schedules/"+schedule.ID.String())
+	rest.Respond(w, r, http.StatusCreated, schedule, headers)
+}
+
+// PatchTaskScheduleHandler renames, enables or disables a schedule, or changes its cron
+// expression or payload. The task of a schedule cannot be changed.
+func (m *Module) PatchTaskScheduleHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := logging.LoggerFromContext(ctx)
+
+	logger.Info("parsing ID")
+	id, err := rest.ReadUUIDParam("id", r)
+	if err != nil {
+		logger.Info("unable to read id", "id", id, "error", err)
+		rest.NotFoundResponse(w, r)
+		return
+	}
+	logger.Info("ID parsed", slog.String("id", id.String()))
+
+	logger.Info("parsing request body")
+	var updateData types.TaskSchedule
+	err = rest.ReadJSON(r, &updateData)
+	if err != nil {
+		logger.Info("unable to read request body", "error", err)
+		rest.BadRequestResponse(w, r, fmt.Sprintf("unable to read request body: %s\n", err))
+		return
+	}
+	updateData.ID = *id
+
+	v := validator.New()
+	v.Check(updateData.TaskName == nil, "taskName", "cannot be changed")
+	if types.ValidateTaskSchedule(v, updateData); !v.Valid() {
+		logger.Info("invalid task schedule", "errors", v.Errors)
+		rest.FailedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	logger.Info("updating task schedule", "schedule", updateData)
+	schedule, err := m.UpdateTaskSchedule(ctx, updateData)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			logger.Info("task schedule not found", "id", id)
+			rest.NotFoundResponse(w, r)
+		case errors.Is(err, data.ErrDuplicateRecord):
+			logger.Info("task schedule name in use", "name", *updateData.Name)
+			rest.ConflictResponse(w, r, "a schedule with the name already exists")
+		default:
+			logger.Error("unable to update task schedule", "id", id, "error", err)
+			rest.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+	logger.Info("task schedule updated", "schedule", schedule)
+
+	logger.Info("writing response")
+	rest.Respond(w, r, http.StatusOK, schedule, nil)
+}
+
+func (m *Module) DeleteTaskScheduleHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := logging.LoggerFromContext(ctx)
+
+	logger.Info("parsing ID")
+	id, err := rest.ReadUUIDParam("id", r)
+	if err != nil {
+		logger.Info("unable to read id", "id", id, "error", err)
+		rest.NotFoundResponse(w, r)
+		return
+	}
+	logger.Info("ID parsed", slog.String("id", id.String()))
+
+	logger.Info("deleting task schedule")
+	schedule, err := m.DeleteTaskSchedule(ctx, *id)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			logger.Info("task schedule not found", "id", id)
+			rest.NotFoundResponse(w, r)
+		default:
+			logger.Error("unable to delete task schedule", "id", id, "error", err)
+			rest.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+	logger.Info("task schedule deleted", "schedule", schedule)
+
+	logger.Info("writing response")
+	rest.Respond(w, r, http.StatusOK, schedule, nil)
+}
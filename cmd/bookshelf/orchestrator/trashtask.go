@@ -0,0 +1,52 @@
+package orchestrator
+
+import (
+	"context"
+	"time"
+
+	"github.com/r3d5un/Bookshelf/internal/audit"
+	"github.com/r3d5un/Bookshelf/internal/logging"
+	"github.com/r3d5un/Bookshelf/internal/orchestrator"
+	"github.com/r3d5un/Bookshelf/internal/orchestrator/types"
+)
+
+const PurgeTrashName string = "Purge Trash"
+
+// purgeTrash permanently deletes the books, authors, series and genres that have been in the
+// trash for longer than the configured number of days.
+func (m *Module) purgeTrash(ctx context.Context) error {
+	run, ok := orchestrator.RunFromContext(ctx)
+	if !ok {
+		return orchestrator.ErrNoRun
+	}
+
+	logger, stopLogger := types.NewTaskLogger(
+		ctx, &m.models, PurgeTrashName, run.ID, m.retentionConfig().LogLines,
+	)
+	defer stopLogger()
+	ctx = context.WithValue(ctx, logging.LoggerKey, logger)
+	// The purge is recorded in the audit log as made by the task
+	ctx = audit.WithRequestID(audit.WithActor(ctx, "task:"+PurgeTrashName), run.ID)
+
+	days := defaultTrashDays
+	if m.cfg.Books != nil && m.cfg.Books.TrashDays > 0 {
+		days = m.cfg.Books.TrashDays
+	}
+	before := time.Now().AddDate(0, 0, -days)
+
+	logger.Info("purging trash", "days", days, "before", before)
+	purged, err := m.bookModule.PurgeTrash(ctx, before)
+	if err != nil {
+		logger.Error("unable to purge trash", "error", err)
+		return err
+	}
+	logger.Info(
+		"trash purged",
+		"books", purged.Books,
+		"authors", purged.Authors,
+		"series", purged.Series,
+		"genres", purged.Genres,
+	)
+
+	return nil
+}
@@ -0,0 +1,294 @@
+package orchestrator
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/r3d5un/Bookshelf/internal/logging"
+	"github.com/r3d5un/Bookshelf/internal/orchestrator/data"
+	"github.com/r3d5un/Bookshelf/internal/orchestrator/types"
+	"github.com/r3d5un/Bookshelf/internal/rest"
+	"github.com/r3d5un/Bookshelf/internal/validator"
+)
+
+func (m *Module) ListWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := logging.LoggerFromContext(ctx)
+
+	var input struct {
+		data.Filters
+	}
+
+	v := validator.New()
+
+	qs := r.URL.Query()
+
+	input.Filters.ID = rest.ReadQueryUUID(qs, "id", v)
+	if event := rest.ReadQueryString(qs, "event", ""); event != "" {
+		input.Filters.Name = &event
+	}
+	input.Filters.Enabled = rest.ReadQueryBool(qs, "enabled", v)
+	input.Filters.CreatedAtFrom = rest.ReadQueryDate(qs, "createdAtFrom", v)
+	input.Filters.CreatedAtTo = rest.ReadQueryDate(qs, "createdAtTo", v)
+
+	input.Filters.Page = rest.ReadQueryInt(qs, "page", 1, v)
+	input.Filters.PageSize = rest.ReadQueryInt(qs, "page_size", 1_000, v)
+
+	input.Filters.OrderBy = rest.ReadQueryCommaSeperatedString(qs, "order_by", "created_at")
+	input.Filters.OrderBySafeList = []string{
+		"url",
+		"enabled",
+		"created_at",
+		"updated_at",
+		"-url",
+		"-enabled",
+		"-created_at",
+		"-updated_at",
+	}
+	logger.InfoContext(ctx, "filters set", "filters", input)
+
+	if data.ValidateFilters(v, input.Filters); !v.Valid() {
+		logger.Info("invalid query parameters", "errors", v.Errors)
+		rest.FailedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	logger.Info("querying database for webhooks")
+	webhookList, err := m.ReadAllWebhooks(ctx, input.Filters)
+	if err != nil {
+		logger.Error("unable to read webhooks", "error", err)
+		rest.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	logger.Info("writing response")
+	rest.Respond(w, r, http.StatusOK, webhookList, nil)
+}
+
+func (m *Module) GetWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := logging.LoggerFromContext(ctx)
+
+	logger.Info("parsing ID")
+	id, err := rest.ReadUUIDParam("id", r)
+	if err != nil {
+		logger.Info("unable to read id", "id", id, "error", err)
+		rest.NotFoundResponse(w, r)
+		return
+	}
+	logger.Info("ID parsed", slog.String("id", id.String()))
+
+	logger.Info("querying database for webhook")
+	webhook, err := m.ReadWebhook(ctx, *id)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			logger.Info("webhook not found", "id", id)
+			rest.NotFoundResponse(w, r)
+		default:
+			logger.Error("unable to get webhook", "id", id, "error", err)
+			rest.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	logger.Info("writing response")
+	rest.Respond(w, r, http.StatusOK, webhook, nil)
+}
+
+// PostWebhookHandler registers a URL to receive the given events, signed with the given secret.
+func (m *Module) PostWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := logging.LoggerFromContext(ctx)
+
+	logger.Info("parsing request body")
+	var newWebhook types.Webhook
+	err := rest.ReadJSON(r, &newWebhook)
+	if err != nil {
+		logger.Info("unable to read request body", "error", err)
+		rest.BadRequestResponse(w, r, fmt.Sprintf("unable to read request body: %s\n", err))
+		return
+	}
+
+	v := validator.New()
+	if types.ValidateNewWebhook(v, newWebhook); !v.Valid() {
+		logger.Info("invalid webhook", "errors", v.Errors)
+		rest.FailedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	logger.Info("creating webhook", "url", *newWebhook.URL, "events", newWebhook.Events)
+	webhook, err := m.CreateWebhook(ctx, newWebhook)
+	if err != nil {
+		logger.Error("unable to create webhook", "error", err)
+		rest.ServerErrorResponse(w, r, err)
+		return
+	}
+	logger.Info("webhook created", "webhook", webhook)
+
+	logger.Info("writing response")
+	headers := http.Header{}
+	headers.Set("Location", "/api/v1/orchestrator/webhooks/"+webhook.ID.String())
+	rest.Respond(w, r, http.StatusCreated, webhook, headers)
+}
+
+// PatchWebhookHandler changes the URL, secret or events of a webhook, or enables or disables it.
+func (m *Module) PatchWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := logging.LoggerFromContext(ctx)
+
+	logger.Info("parsing ID")
+	id, err := rest.ReadUUIDParam("id", r)
+	if err != nil {
+		logger.Info("unable to read id", "id", id, "error", err)
+		rest.NotFoundResponse(w, r)
+		return
+	}
+	logger.Info("ID parsed", slog.String("id", id.String()))
+
+	logger.Info("parsing request body")
+	var updateData types.Webhook
+	err = rest.ReadJSON(r, &updateData)
+	if err != nil {
+		logger.Info("unable to read request body", "error", err)
+		rest.BadRequestResponse(w, r, fmt.Sprintf("unable to read request body: %s\n", err))
+		return
+	}
+	updateData.ID = *id
+
+	v := validator.New()
+	if types.ValidateWebhook(v, updateData); !v.Valid() {
+		logger.Info("invalid webhook", "errors", v.Errors)
+		rest.FailedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	logger.Info("updating webhook", "id", id)
+	webhook, err := m.UpdateWebhook(ctx, updateData)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			logger.Info("webhook not found", "id", id)
+			rest.NotFoundResponse(w, r)
+		default:
+			logger.Error("unable to update webhook", "id", id, "error", err)
+			rest.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+	logger.Info("webhook updated", "webhook", webhook)
+
+	logger.Info("writing response")
+	rest.Respond(w, r, http.StatusOK, webhook, nil)
+}
+
+func (m *Module) DeleteWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := logging.LoggerFromContext(ctx)
+
+	logger.Info("parsing ID")
+	id, err := rest.ReadUUIDParam("id", r)
+	if err != nil {
+		logger.Info("unable to read id", "id", id, "error", err)
+		rest.NotFoundResponse(w, r)
+		return
+	}
+	logger.Info("ID parsed", slog.String("id", id.String()))
+
+	logger.Info("deleting webhook")
+	webhook, err := m.DeleteWebhook(ctx, *id)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			logger.Info("webhook not found", "id", id)
+			rest.NotFoundResponse(w, r)
+		default:
+			logger.Error("unable to delete webhook", "id", id, "error", err)
+			rest.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+	logger.Info("webhook deleted", "webhook", webhook)
+
+	logger.Info("writing response")
+	rest.Respond(w, r, http.StatusOK, webhook, nil)
+}
+
+// ListWebhookDeliveryHandler returns the delivery log of a webhook, with one entry per attempt.
+func (m *Module) ListWebhookDeliveryHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := logging.LoggerFromContext(ctx)
+
+	logger.Info("parsing ID")
+	id, err := rest.ReadUUIDParam("id", r)
+	if err != nil {
+		logger.Info("unable to read id", "id", id, "error", err)
+		rest.NotFoundResponse(w, r)
+		return
+	}
+	logger.Info("ID parsed", slog.String("id", id.String()))
+
+	var input struct {
+		data.Filters
+	}
+
+	v := validator.New()
+
+	qs := r.URL.Query()
+
+	input.Filters.ID = rest.ReadQueryUUID(qs, "taskId", v)
+	if event := rest.ReadQueryString(qs, "event", ""); event != "" {
+		input.Filters.Name = &event
+	}
+	input.Filters.CreatedAtFrom = rest.ReadQueryDate(qs, "createdAtFrom", v)
+	input.Filters.CreatedAtTo = rest.ReadQueryDate(qs, "createdAtTo", v)
+
+	input.Filters.Page = rest.ReadQueryInt(qs, "page", 1, v)
+	input.Filters.PageSize = rest.ReadQueryInt(qs, "page_size", 1_000, v)
+
+	input.Filters.OrderBy = rest.ReadQueryCommaSeperatedString(qs, "order_by", "-created_at")
+	input.Filters.OrderBySafeList = []string{
+		"event",
+		"attempt",
+		"status_code",
+		"created_at",
+		"-event",
+		"-attempt",
+		"-status_code",
+		"-created_at",
+	}
+	logger.InfoContext(ctx, "filters set", "filters", input)
+
+	if data.ValidateFilters(v, input.Filters); !v.Valid() {
+		logger.Info("invalid query parameters", "errors", v.Errors)
+		rest.FailedValidationResponse(w, r, v.Errors)
+		return
+	}
+
+	logger.Info("checking that webhook exists")
+	_, err = m.ReadWebhook(ctx, *id)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			logger.Info("webhook not found", "id", id)
+			rest.NotFoundResponse(w, r)
+		default:
+			logger.Error("unable to get webhook", "id", id, "error", err)
+			rest.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	logger.Info("querying database for webhook deliveries")
+	deliveries, err := m.ReadAllWebhookDeliveries(ctx, *id, input.Filters)
+	if err != nil {
+		logger.Error("unable to read webhook deliveries", "error", err)
+		rest.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	logger.Info("writing response")
+	rest.Respond(w, r, http.StatusOK, deliveries, nil)
+}
@@ -0,0 +1,214 @@
+package orchestrator
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	bookTypes "github.com/r3d5un/Bookshelf/internal/books/types"
+	"github.com/r3d5un/Bookshelf/internal/events"
+	"github.com/r3d5un/Bookshelf/internal/logging"
+	"github.com/r3d5un/Bookshelf/internal/orchestrator"
+	"github.com/r3d5un/Bookshelf/internal/orchestrator/data"
+	"github.com/r3d5un/Bookshelf/internal/orchestrator/types"
+	"github.com/r3d5un/Bookshelf/internal/webhooks"
+)
+
+const DeliverWebhookName string = "Deliver Webhook"
+
+// webhookConsumer is the consumer claiming the events sent to webhooks
+const webhookConsumer = "webhooks"
+
+// webhookTimeout is how long a webhook receiver has to answer a delivery
+const webhookTimeout = 10 * time.Second
+
+// deliverWebhookPayload holds the event a delivery run posts to a webhook.
+type deliverWebhookPayload struct {
+	WebhookID  uuid.UUID       `json:"webhookId"`
+	Event      string          `json:"event"`
+	OccurredAt time.Time       `json:"occurredAt"`
+	Data       json.RawMessage `json:"data"`
+}
+
+// Emit enqueues a delivery of the event to every enabled webhook subscribed to it. Each delivery
+// is a run of the Deliver Webhook task, and is retried by the retry policy of the task.
+//
+// Errors are logged rather than returned, as a failure to deliver an event must not fail the
+// change it describes.
+func (m *Module) Emit(ctx context.Context, event string, eventData any) {
+	logger := logging.LoggerFromContext(ctx).With("event", event)
+
+	logger.Info("reading webhooks subscribed to event")
+	subscribed, err := types.ReadSubscribedWebhooks(ctx, &m.models, event)
+	if err != nil {
+		logger.Error("unable to read webhooks", "error", err)
+		return
+	}
+	if len(subscribed) == 0 {
+		logger.Info("no webhooks subscribed to event")
+		return
+	}
+
+	body, err := json.Marshal(eventData)
+	if err != nil {
+		logger.Error("unable to encode event", "error", err)
+		return
+	}
+	occurredAt := time.Now().UTC()
+
+	name := DeliverWebhookName
+	for _, webhook := range subscribed {
+		payload, err := json.Marshal(deliverWebhookPayload{
+			WebhookID:  webhook.ID,
+			Event:      event,
+			OccurredAt: occurredAt,
+			Data:       body,
+		})
+		if err != nil {
+			logger.Error("unable to encode delivery", "webhookId", webhook.ID, "error", err)
+			continue
+		}
+
+		_, err = m.scheduler.Enqueue(ctx, types.ScheduledTask{Name: &name, Payload: payload})
+		if err != nil {
+			logger.Error("unable to enqueue delivery", "webhookId", webhook.ID, "error", err)
+			continue
+		}
+	}
+	logger.Info("webhook deliveries enqueued", "webhooks", len(subscribed))
+}
+
+// deliverWebhook posts the event of the run to its webhook, and records the attempt in the
+// delivery log of the webhook. Failed deliveries return an error, so that they are retried.
+func (m *Module) deliverWebhook(ctx context.Context) error {
+	run, ok := orchestrator.RunFromContext(ctx)
+	if !ok {
+		return orchestrator.ErrNoRun
+	}
+
+	logger, stopLogger := types.NewTaskLogger(
+		ctx, &m.models, DeliverWebhookName, run.ID, m.retentionConfig().LogLines,
+	)
+	defer stopLogger()
+	ctx = context.WithValue(ctx, logging.LoggerKey, logger)
+
+	payload, err := orchestrator.DecodePayload[deliverWebhookPayload](ctx)
+	if err != nil {
+		logger.Error("unable to decode payload", "error", err)
+		return err
+	}
+	logger = logger.With("webhookId", payload.WebhookID, "event", payload.Event)
+
+	logger.Info("reading webhook")
+	webhook, err := m.models.Webhooks.Get(ctx, payload.WebhookID)
+	if err != nil {
+		if errors.Is(err, data.ErrRecordNotFound) {
+			logger.Info("webhook deleted; dropping delivery")
+			return nil
+		}
+		logger.Error("unable to read webhook", "error", err)
+		return err
+	}
+	if !*webhook.Enabled {
+		logger.Info("webhook disabled; dropping delivery")
+		return nil
+	}
+
+	logger.Info("delivering event", "url", *webhook.URL, "attempt", run.Attempt)
+	start := time.Now()
+	statusCode, sendErr := webhooks.Send(
+		ctx,
+		m.webhookClient,
+		*webhook.URL,
+		*webhook.Secret,
+		webhooks.Delivery{
+			ID:         run.ID,
+			Event:      payload.Event,
+			OccurredAt: payload.OccurredAt,
+			Data:       payload.Data,
+		},
+	)
+
+	delivery := types.WebhookDelivery{
+		WebhookID:  webhook.ID,
+		TaskID:     run.ID,
+		Event:      payload.Event,
+		Attempt:    run.Attempt,
+		DurationMS: int(time.Since(start).Milliseconds()),
+	}
+	if statusCode != 0 {
+		delivery.StatusCode = &statusCode
+	}
+	if sendErr != nil {
+		errMsg := sendErr.Error()
+		delivery.Error = &errMsg
+	}
+	if _, err := types.CreateWebhookDelivery(ctx, &m.models, delivery); err != nil {
+		logger.Error("unable to record delivery", "error", err)
+	}
+
+	if sendErr != nil {
+		logger.Error("unable to deliver event", "statusCode", statusCode, "error", sendErr)
+		return sendErr
+	}
+	logger.Info("event delivered", "statusCode", statusCode)
+
+	return nil
+}
+
+// subscribeWebhookEvents sends the events of other modules to the webhooks subscribed to them.
+// Each event is sent once however many instances receive it, whichever instance or command
+// published it, as events forwarded from the outbox are claimed by a single instance.
+func (m *Module) subscribeWebhookEvents() {
+	m.unsubscribe = append(
+		m.unsubscribe,
+		events.SubscribeOnce(
+			m.events,
+			webhookConsumer,
+			func(ctx context.Context, e bookTypes.BookCreated) error {
+				m.Emit(ctx, webhooks.BookCreated, e.Book)
+				return nil
+			},
+		),
+		events.SubscribeOnce(
+			m.events,
+			webhookConsumer,
+			func(ctx context.Context, e bookTypes.BookUpdated) error {
+				m.Emit(ctx, webhooks.BookUpdated, e.Book)
+				return nil
+			},
+		),
+		events.SubscribeOnce(
+			m.events,
+			webhookConsumer,
+			func(ctx context.Context, e bookTypes.BookDeleted) error {
+				m.Emit(ctx, webhooks.BookDeleted, e)
+				return nil
+			},
+		),
+		events.SubscribeOnce(
+			m.events,
+			webhookConsumer,
+			func(ctx context.Context, e bookTypes.BookRestored) error {
+				m.Emit(ctx, webhooks.BookRestored, e.Book)
+				return nil
+			},
+		),
+	)
+}
+
+// emitTaskEvent sends the outcome of a finished run to the webhooks subscribed to it. Deliveries
+// are left out, so that a failing webhook subscribed to failed tasks does not feed itself.
+func (m *Module) emitTaskEvent(
+	ctx context.Context,
+	event string,
+	scheduledTask *types.ScheduledTask,
+) {
+	if scheduledTask.Name != nil && *scheduledTask.Name == DeliverWebhookName {
+		return
+	}
+
+	m.Emit(ctx, event, scheduledTask)
+}
@@ -0,0 +1,108 @@
+package orchestrator_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/r3d5un/Bookshelf/cmd/bookshelf/orchestrator"
+	bookTypes "github.com/r3d5un/Bookshelf/internal/books/types"
+	"github.com/r3d5un/Bookshelf/internal/orchestrator/types"
+	"github.com/r3d5un/Bookshelf/internal/webhooks"
+)
+
+func TestDeliverWebhook(t *testing.T) {
+	t.Run("TaskEnabled", func(t *testing.T) {
+		task, err := mod.ReadTask(context.Background(), orchestrator.DeliverWebhookName)
+		if err != nil {
+			t.Errorf("unable to read task: %s\n", err)
+			return
+		}
+		if task.Enabled == nil || !*task.Enabled {
+			t.Errorf("expected %s to be enabled\n", orchestrator.DeliverWebhookName)
+			return
+		}
+	})
+
+	t.Run("Delivered", func(t *testing.T) {
+		secret := "a-very-secret-signing-key"
+		type request struct {
+			verified bool
+			event    string
+			delivery webhooks.Delivery
+		}
+		received := make(chan request, 1)
+
+		receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			req := request{
+				verified: webhooks.Verify(secret, body, r.Header.Get(webhooks.SignatureHeader)),
+				event:    r.Header.Get(webhooks.EventHeader),
+			}
+			_ = json.Unmarshal(body, &req.delivery)
+			w.WriteHeader(http.StatusNoContent)
+
+			select {
+			case received <- req:
+			default:
+			}
+		}))
+		defer receiver.Close()
+
+		enabled := true
+		_, err := mod.CreateWebhook(context.Background(), types.Webhook{
+			URL:     &receiver.URL,
+			Secret:  &secret,
+			Events:  []string{webhooks.BookCreated},
+			Enabled: &enabled,
+		})
+		if err != nil {
+			t.Errorf("unable to create webhook: %s\n", err)
+			return
+		}
+
+		id := uuid.New()
+		title := "The Fellowship of the Ring"
+		err = bus.Publish(
+			context.Background(),
+			bookTypes.BookCreated{Book: bookTypes.Book{ID: &id, Title: &title}},
+		)
+		if err != nil {
+			t.Errorf("unable to publish event: %s\n", err)
+			return
+		}
+
+		select {
+		case req := <-received:
+			if !req.verified {
+				t.Error("expected the signature to be verified with the secret")
+				return
+			}
+			if req.event != webhooks.BookCreated || req.delivery.Event != webhooks.BookCreated {
+				t.Errorf("expected event %s, got %+v\n", webhooks.BookCreated, req)
+				return
+			}
+			var book bookTypes.Book
+			if err := json.Unmarshal(req.delivery.Data, &book); err != nil {
+				t.Errorf("unable to unmarshal delivered book: %s\n", err)
+				return
+			}
+			if book.ID == nil || *book.ID != id {
+				t.Errorf("expected book %s, got %+v\n", id, book)
+				return
+			}
+		case <-time.After(30 * time.Second):
+			t.Error("webhook was not delivered")
+			return
+		}
+	})
+}
@@ -0,0 +1,108 @@
+package orchestrator
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+
+	"github.com/google/uuid"
+	"github.com/r3d5un/Bookshelf/internal/logging"
+	"github.com/r3d5un/Bookshelf/internal/orchestrator"
+	"github.com/r3d5un/Bookshelf/internal/orchestrator/data"
+	"github.com/r3d5un/Bookshelf/internal/orchestrator/types"
+)
+
+const LibraryMaintenanceName string = "Library Maintenance"
+
+// EnqueueChildren adds a run of the named task for each payload as children of the run in the
+// context. The current run is not completed before all of its children have finished, which
+// lets a task fan out work, e.g. one run per book.
+func (m *Module) EnqueueChildren(
+	ctx context.Context,
+	name string,
+	payloads ...json.RawMessage,
+) ([]*types.ScheduledTask, error) {
+	run, ok := orchestrator.RunFromContext(ctx)
+	if !ok {
+		return nil, orchestrator.ErrNoRun
+	}
+
+	children := make([]types.ScheduledTask, 0, len(payloads))
+	for _, payload := range payloads {
+		children = append(children, types.ScheduledTask{Name: &name, Payload: payload})
+	}
+
+	return m.enqueueChildren(ctx, run.ID, false, children)
+}
+
+// workflow returns a task function running the given tasks as a chain of child runs, where each
+// step starts once the step before it has completed. The payload of the workflow run is passed
+// on to every step, and the workflow run completes when the last step has finished.
+func (m *Module) workflow(steps ...string) func(context.Context) error {
+	return func(ctx context.Context) error {
+		run, ok := orchestrator.RunFromContext(ctx)
+		if !ok {
+			return orchestrator.ErrNoRun
+		}
+
+		children := make([]types.ScheduledTask, 0, len(steps))
+		for _, step := range steps {
+			children = append(children, types.ScheduledTask{Name: &step, Payload: run.Payload})
+		}
+
+		_, err := m.enqueueChildren(ctx, run.ID, true, children)
+		return err
+	}
+}
+
+func (m *Module) enqueueChildren(
+	ctx context.Context,
+	parentID uuid.UUID,
+	chained bool,
+	children []types.ScheduledTask,
+) ([]*types.ScheduledTask, error) {
+	logger := logging.LoggerFromContext(ctx).With(slog.String("parentId", parentID.String()))
+
+	logger.Info("enqueuing child runs", "count", len(children), "chained", chained)
+	tasks, ready, err := types.EnqueueChildScheduledTasks(ctx, &m.models, parentID, chained, children)
+	if err != nil {
+		logger.Error("unable to enqueue child runs", "error", err)
+		return nil, err
+	}
+
+	m.notifyReady(ctx, ready)
+
+	return tasks, nil
+}
+
+// settleScheduledTask updates the runs related to a finished run, and notifies the task runners
+// about runs that became ready as a result.
+func (m *Module) settleScheduledTask(ctx context.Context, id uuid.UUID) {
+	logger := logging.LoggerFromContext(ctx).With(slog.String("taskId", id.String()))
+
+	ready, err := types.SettleScheduledTask(ctx, &m.models, id)
+	if err != nil {
+		// Chained runs left blocked can be cancelled, and the parent replayed
+		logger.Error("unable to settle scheduled task", "error", err)
+		return
+	}
+
+	m.notifyReady(ctx, ready)
+}
+
+// notifyReady notifies the task runners about runs that are ready to run.
+func (m *Module) notifyReady(ctx context.Context, ready []*types.ScheduledTask) {
+	logger := logging.LoggerFromContext(ctx)
+
+	for _, scheduledTask := range ready {
+		logger.Info("notifying about ready task", "scheduledTask", scheduledTask)
+		err := m.models.TaskNotifications.Notify(
+			ctx,
+			data.TaskNotification{ID: scheduledTask.ID, Queue: *scheduledTask.Name},
+		)
+		if err != nil {
+			// The task reminder picks up waiting tasks that were never announced
+			logger.Error("unable to notify listeners", "error", err)
+		}
+	}
+}
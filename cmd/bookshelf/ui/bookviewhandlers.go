@@ -2,8 +2,12 @@ package ui
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"net/http"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/r3d5un/Bookshelf/internal/books/data"
 	"github.com/r3d5un/Bookshelf/internal/logging"
@@ -164,3 +168,129 @@ func (m *Module) BookSeriesAccordionHandler(w http.ResponseWriter, r *http.Reque
 	logger.Info("rendering UI component")
 	m.renderPartial(w, http.StatusOK, "bookSeriesAccordion.tmpl", &data)
 }
+
+// bookHistoryPageSize is the number of recent changes shown in the history tab of a book.
+const bookHistoryPageSize int = 100
+
+// bookHistoryEntry is an audit log entry of a book, or of a link to it, with its changes
+// prepared for display.
+type bookHistoryEntry struct {
+	CreatedAt *time.Time         `json:"createdAt,omitempty"`
+	Actor     string             `json:"actor"`
+	Action    string             `json:"action"`
+	Record    string             `json:"record"`
+	RequestID string             `json:"requestId,omitempty"`
+	Changes   []bookHistoryField `json:"changes,omitempty"`
+}
+
+type bookHistoryField struct {
+	Name   string `json:"name"`
+	Before string `json:"before"`
+	After  string `json:"after"`
+}
+
+// historyRecords names the records changed by audit log entries of a book.
+var historyRecords = map[string]string{
+	"books":        "Book",
+	"book_authors": "Author",
+	"book_genres":  "Genre",
+	"book_series":  "Series",
+	"book_files":   "File",
+}
+
+// historyActions describes the actions of audit log entries, with links to the book being added
+// and removed rather than created and deleted.
+var historyActions = map[string][2]string{
+	"insert": {"created", "added"},
+	"update": {"updated", "changed"},
+	"delete": {"deleted", "removed"},
+}
+
+func (m *Module) BookHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := logging.LoggerFromContext(ctx)
+
+	bookID, err := rest.ReadUUIDParam("id", r)
+	if err != nil {
+		logger.Info("unable to parse parameter", "error", err)
+		rest.BadRequestResponse(w, r, fmt.Sprintf("unable to parse parameter: %s", err.Error()))
+		return
+	}
+	logger.Info("parameter parsed", "parameter", bookID)
+
+	filters := data.Filters{Page: 1, PageSize: bookHistoryPageSize}
+
+	logger.Info("retrieving book history", "bookId", bookID, "filters", filters)
+	entries, err := m.bookModule.ReadBookHistory(ctx, *bookID, filters)
+	if err != nil {
+		logger.Error("unable to retrieve book history", "error", err, "bookId", bookID)
+		rest.ServerErrorResponse(w, r, err)
+		return
+	}
+	logger.Info("book history retrieved", "length", len(entries))
+
+	history := make([]bookHistoryEntry, 0, len(entries))
+	for _, entry := range entries {
+		history = append(history, newBookHistoryEntry(entry))
+	}
+
+	logger.Info("rendering UI component")
+	m.renderPartial(w, http.StatusOK, "bookHistory.tmpl", &templateData{BookHistory: history})
+}
+
+func newBookHistoryEntry(entry *data.AuditEntry) bookHistoryEntry {
+	historyEntry := bookHistoryEntry{
+		CreatedAt: entry.CreatedAt,
+		Actor:     entry.Actor,
+		Action:    entry.Action,
+		Record:    historyRecords[entry.EntityType],
+	}
+	if historyEntry.Record == "" {
+		historyEntry.Record = entry.EntityType
+	}
+	if actions, ok := historyActions[entry.Action]; ok {
+		historyEntry.Action = actions[0]
+		if entry.RelatedID != nil {
+			historyEntry.Action = actions[1]
+		}
+	}
+	if entry.RequestID != nil {
+		historyEntry.RequestID = entry.RequestID.String()
+	}
+
+	var changes map[string]struct {
+		Before json.RawMessage `json:"before"`
+		After  json.RawMessage `json:"after"`
+	}
+	if err := json.Unmarshal(entry.Changes, &changes); err != nil {
+		return historyEntry
+	}
+
+	for name, change := range changes {
+		historyEntry.Changes = append(historyEntry.Changes, bookHistoryField{
+			Name:   name,
+			Before: historyValue(change.Before),
+			After:  historyValue(change.After),
+		})
+	}
+	slices.SortFunc(historyEntry.Changes, func(a, b bookHistoryField) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	return historyEntry
+}
+
+// historyValue formats a JSON value of a changed field, showing strings without quotes and null
+// as empty.
+func historyValue(raw json.RawMessage) string {
+	if len(raw) == 0 || string(raw) == "null" {
+		return ""
+	}
+
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s
+	}
+
+	return string(raw)
+}
@@ -13,11 +13,12 @@ import (
 const ModuleName string = "ui"
 
 type Module struct {
-	logger        *slog.Logger
-	mux           *http.ServeMux
-	cfg           *config.Config
-	templateCache map[string]*template.Template
-	bookModule    system.Books
+	logger             *slog.Logger
+	mux                *http.ServeMux
+	cfg                *config.Config
+	templateCache      map[string]*template.Template
+	bookModule         system.Books
+	orchestratorModule system.Orchestrator
 }
 
 func (m *Module) Startup(ctx context.Context, mono system.Monolith) (err error) {
@@ -37,6 +38,9 @@ func (m *Module) Startup(ctx context.Context, mono system.Monolith) (err error)
 	m.logger.Info("injecting data interface implementations", "requestedModule", "books")
 	m.bookModule = mono.Modules().Books
 
+	m.logger.Info("injecting data interface implementations", "requestedModule", "orchestrator")
+	m.orchestratorModule = mono.Modules().Orchestrator
+
 	m.logger.Info("injecting mux")
 	m.mux = mono.Mux()
 	m.logger.Info("registering routes")
@@ -70,6 +74,7 @@ func (m *Module) registerEndpoints(mux *http.ServeMux) {
 		{"GET /authors", m.Authors},
 		{"GET /authors/{id}", m.AuthorViewHandler},
 		{"GET /series", m.Series},
+		{"GET /tasks", m.Tasks},
 		// UI Components
 		{"GET /ui/currentlyreading", m.CurrentlyReading},
 		{"GET /ui/finishedreading", m.FinishedReading},
@@ -77,6 +82,7 @@ func (m *Module) registerEndpoints(mux *http.ServeMux) {
 		{"GET /ui/discovermenu/{category}", m.DiscoverCategoryMenuHandler},
 		{"GET /ui/discovercontent/{category}", m.DiscoverContentHandler},
 		{"GET /ui/book/bookseriesaccordion/{id}", m.BookSeriesAccordionHandler},
+		{"GET /ui/book/history/{id}", m.BookHistoryHandler},
 		{"GET /ui/new/series", m.NewSeriesModal},
 		{"POST /ui/new/series/form", m.ParseNewSeriesForm},
 		{"GET /ui/new/author", m.NewAuthorModal},
@@ -88,6 +94,16 @@ func (m *Module) registerEndpoints(mux *http.ServeMux) {
 		{"POST /ui/{bookID}/add/author", m.AddAuthorToBookHandler},
 		{"GET /ui/new/book", m.NewBookModal},
 		{"POST /ui/new/book/form", m.ParseNewBookForm},
+		{"GET /ui/tasks/list", m.TaskListHandler},
+		{"POST /ui/tasks/{name}/enabled", m.SetTaskEnabledHandler},
+		{"POST /ui/tasks/{name}/cron", m.SetTaskCronHandler},
+		{"POST /ui/tasks/{name}/run", m.RunTaskHandler},
+		{"GET /ui/tasks/runs", m.TaskRunListHandler},
+		{"POST /ui/tasks/runs/{id}/cancel", m.CancelTaskRunHandler},
+		{"POST /ui/tasks/runs/{id}/stop", m.StopTaskRunHandler},
+		{"POST /ui/tasks/runs/{id}/replay", m.ReplayTaskRunHandler},
+		{"GET /ui/tasks/runs/{id}/logs", m.TaskRunLogsHandler},
+		{"GET /ui/tasks/runs/{id}/logs/stream", m.TaskRunLogsStreamHandler},
 	}
 
 	m.logger.Info("adding protected endpoints")
@@ -0,0 +1,529 @@
+package ui
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/r3d5un/Bookshelf/internal/logging"
+	"github.com/r3d5un/Bookshelf/internal/orchestrator/data"
+	"github.com/r3d5un/Bookshelf/internal/orchestrator/types"
+	"github.com/r3d5un/Bookshelf/internal/rest"
+	"github.com/r3d5un/Bookshelf/internal/validator"
+)
+
+// taskLogEntry is a task log record split into the standard slog fields, and the remaining
+// attributes as indented JSON.
+type taskLogEntry struct {
+	Time       string `json:"time"`
+	Level      string `json:"level"`
+	Message    string `json:"msg"`
+	Attributes string `json:"attributes,omitempty"`
+}
+
+type taskLogFilter struct {
+	RunID  string `json:"runId"`
+	Level  string `json:"level,omitempty"`
+	Query  string `json:"query,omitempty"`
+	Follow bool   `json:"follow,omitempty"`
+}
+
+// match parses a task log record, and reports whether it passes the filter.
+func (f taskLogFilter) match(raw string) (taskLogEntry, bool) {
+	if f.Query != "" && !strings.Contains(strings.ToLower(raw), strings.ToLower(f.Query)) {
+		return taskLogEntry{}, false
+	}
+	entry := parseTaskLog(raw)
+	if f.Level != "" && !strings.EqualFold(entry.Level, f.Level) {
+		return taskLogEntry{}, false
+	}
+
+	return entry, true
+}
+
+// taskRunPageSize is the number of recent runs shown on the tasks page.
+const taskRunPageSize int = 25
+
+func (m *Module) Tasks(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := logging.LoggerFromContext(ctx)
+
+	logger.Info("rendering page")
+	m.render(w, http.StatusOK, "tasks.tmpl", &templateData{})
+}
+
+func (m *Module) TaskListHandler(w http.ResponseWriter, r *http.Request) {
+	m.renderTaskList(w, r, "")
+}
+
+func (m *Module) SetTaskEnabledHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := logging.LoggerFromContext(ctx)
+
+	logger.Info("parsing task name from path")
+	name, err := rest.ReadStringParam("name", r)
+	if err != nil {
+		logger.Info("unable to read name parameter", "error", err)
+		rest.BadRequestResponse(w, r, "unable to read name parameter")
+		return
+	}
+
+	logger.Info("parsing form")
+	err = r.ParseForm()
+	if err != nil {
+		logger.Error("unable to parse form", "error", err)
+		rest.ServerErrorResponse(w, r, err)
+		return
+	}
+	// Unchecked checkboxes are not part of the submitted form
+	enabled := r.FormValue("enabled") == "true"
+	logger.Info("form parsed", "name", *name, "enabled", enabled)
+
+	logger.Info("updating task")
+	_, err = m.orchestratorModule.UpdateTask(ctx, types.Task{Name: *name, Enabled: &enabled})
+	if err != nil {
+		logger.Error("unable to update task", "error", err)
+		m.renderTaskList(w, r, "Unable to update task: "+err.Error())
+		return
+	}
+
+	m.renderTaskList(w, r, "")
+}
+
+func (m *Module) SetTaskCronHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := logging.LoggerFromContext(ctx)
+
+	logger.Info("parsing task name from path")
+	name, err := rest.ReadStringParam("name", r)
+	if err != nil {
+		logger.Info("unable to read name parameter", "error", err)
+		rest.BadRequestResponse(w, r, "unable to read name parameter")
+		return
+	}
+
+	logger.Info("parsing form")
+	err = r.ParseForm()
+	if err != nil {
+		logger.Error("unable to parse form", "error", err)
+		rest.ServerErrorResponse(w, r, err)
+		return
+	}
+	cronExpr := strings.TrimSpace(r.FormValue("cronExpr"))
+	logger.Info("form parsed", "name", *name, "cronExpr", cronExpr)
+
+	task := types.Task{Name: *name, CronExpr: &cronExpr}
+	v := validator.New()
+	if types.ValidateTask(v, task); !v.Valid() {
+		logger.Info("invalid cron expression", "errors", v.Errors)
+		m.renderTaskList(w, r, "Invalid cron expression: "+cronExpr)
+		return
+	}
+
+	logger.Info("updating task")
+	_, err = m.orchestratorModule.UpdateTask(ctx, task)
+	if err != nil {
+		logger.Error("unable to update task", "error", err)
+		m.renderTaskList(w, r, "Unable to update task: "+err.Error())
+		return
+	}
+
+	m.renderTaskList(w, r, "")
+}
+
+func (m *Module) RunTaskHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := logging.LoggerFromContext(ctx)
+
+	logger.Info("parsing task name from path")
+	name, err := rest.ReadStringParam("name", r)
+	if err != nil {
+		logger.Info("unable to read name parameter", "error", err)
+		rest.BadRequestResponse(w, r, "unable to read name parameter")
+		return
+	}
+
+	logger.Info("enqueuing task run", "name", *name)
+	_, err = m.orchestratorModule.RunTask(ctx, *name, nil, nil)
+	if err != nil {
+		switch {
+		case errors.Is(err, types.ErrTaskDisabled):
+			logger.Info("task disabled", "name", *name)
+			m.renderTaskRunList(w, r, "Enable the task before running it.")
+		default:
+			logger.Error("unable to enqueue task run", "error", err)
+			m.renderTaskRunList(w, r, "Unable to run task: "+err.Error())
+		}
+		return
+	}
+
+	m.renderTaskRunList(w, r, "")
+}
+
+func (m *Module) TaskRunListHandler(w http.ResponseWriter, r *http.Request) {
+	m.renderTaskRunList(w, r, "")
+}
+
+func (m *Module) CancelTaskRunHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := logging.LoggerFromContext(ctx)
+
+	logger.Info("parsing run ID from path")
+	id, err := rest.ReadUUIDParam("id", r)
+	if err != nil {
+		logger.Info("unable to read id parameter", "error", err)
+		rest.BadRequestResponse(w, r, "unable to read id parameter")
+		return
+	}
+
+	logger.Info("cancelling task run", "id", id)
+	_, err = m.orchestratorModule.CancelScheduledTask(ctx, *id)
+	if err != nil {
+		switch {
+		case errors.Is(err, types.ErrTaskNotWaiting):
+			logger.Info("task run not waiting", "id", id)
+			m.renderTaskRunList(w, r, "Only waiting or blocked runs can be cancelled.")
+		default:
+			logger.Error("unable to cancel task run", "error", err)
+			m.renderTaskRunList(w, r, "Unable to cancel run: "+err.Error())
+		}
+		return
+	}
+
+	m.renderTaskRunList(w, r, "")
+}
+
+func (m *Module) StopTaskRunHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := logging.LoggerFromContext(ctx)
+
+	logger.Info("parsing run ID from path")
+	id, err := rest.ReadUUIDParam("id", r)
+	if err != nil {
+		logger.Info("unable to read id parameter", "error", err)
+		rest.BadRequestResponse(w, r, "unable to read id parameter")
+		return
+	}
+
+	logger.Info("stopping task run", "id", id)
+	_, err = m.orchestratorModule.StopScheduledTask(ctx, *id)
+	if err != nil {
+		switch {
+		case errors.Is(err, types.ErrTaskNotRunning):
+			logger.Info("task run not running", "id", id)
+			m.renderTaskRunList(w, r, "The run has already finished.")
+		default:
+			logger.Error("unable to stop task run", "error", err)
+			m.renderTaskRunList(w, r, "Unable to stop run: "+err.Error())
+		}
+		return
+	}
+
+	m.renderTaskRunList(w, r, "")
+}
+
+func (m *Module) ReplayTaskRunHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := logging.LoggerFromContext(ctx)
+
+	logger.Info("parsing run ID from path")
+	id, err := rest.ReadUUIDParam("id", r)
+	if err != nil {
+		logger.Info("unable to read id parameter", "error", err)
+		rest.BadRequestResponse(w, r, "unable to read id parameter")
+		return
+	}
+
+	logger.Info("replaying task run", "id", id)
+	_, err = m.orchestratorModule.ReplayScheduledTask(ctx, *id)
+	if err != nil {
+		switch {
+		case errors.Is(err, types.ErrTaskNotDead):
+			logger.Info("task run not dead", "id", id)
+			m.renderTaskRunList(w, r, "Only dead runs can be replayed.")
+		default:
+			logger.Error("unable to replay task run", "error", err)
+			m.renderTaskRunList(w, r, "Unable to replay run: "+err.Error())
+		}
+		return
+	}
+
+	m.renderTaskRunList(w, r, "")
+}
+
+func (m *Module) TaskRunLogsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := logging.LoggerFromContext(ctx)
+
+	logger.Info("parsing run ID from path")
+	id, err := rest.ReadUUIDParam("id", r)
+	if err != nil {
+		logger.Info("unable to read id parameter", "error", err)
+		rest.BadRequestResponse(w, r, "unable to read id parameter")
+		return
+	}
+
+	qs := r.URL.Query()
+	filter := taskLogFilter{
+		RunID:  id.String(),
+		Level:  rest.ReadQueryString(qs, "level", ""),
+		Query:  rest.ReadQueryString(qs, "q", ""),
+		Follow: qs.Get("follow") == "true",
+	}
+	logger.Info("filter parsed", "filter", filter)
+
+	if filter.Follow {
+		// Logs are streamed by the browser once the component is rendered
+		logger.Info("retrieving task run")
+		_, err := m.orchestratorModule.ReadScheduledTask(ctx, *id)
+		if err != nil {
+			switch {
+			case errors.Is(err, data.ErrRecordNotFound):
+				logger.Info("task run not found", "id", id)
+				rest.NotFoundResponse(w, r)
+			default:
+				logger.Error("unable to retrieve task run", "error", err)
+				rest.ServerErrorResponse(w, r, err)
+			}
+			return
+		}
+
+		logger.Info("rendering UI component")
+		m.renderPartial(w, http.StatusOK, "taskRunLogs.tmpl", &templateData{
+			TaskLogFilter: filter,
+		})
+		return
+	}
+
+	logger.Info("retrieving task run logs")
+	logs, err := m.orchestratorModule.ReadScheduledTaskLogs(ctx, *id)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			logger.Info("task run not found", "id", id)
+			rest.NotFoundResponse(w, r)
+		default:
+			logger.Error("unable to retrieve task run logs", "error", err)
+			rest.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+	logger.Info("task run logs retrieved", "length", len(logs))
+
+	entries := []taskLogEntry{}
+	for _, log := range logs {
+		if entry, ok := filter.match(log.Log); ok {
+			entries = append(entries, entry)
+		}
+	}
+
+	logger.Info("rendering UI component")
+	m.renderPartial(w, http.StatusOK, "taskRunLogs.tmpl", &templateData{
+		TaskLogs:      entries,
+		TaskLogFilter: filter,
+	})
+}
+
+// TaskRunLogsStreamHandler streams the logs of a task run matching the filter as server-sent
+// events, with each log rendered as a log entry component. A last event is sent with the state
+// of the run once it has finished.
+func (m *Module) TaskRunLogsStreamHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	logger := logging.LoggerFromContext(ctx)
+
+	logger.Info("parsing run ID from path")
+	id, err := rest.ReadUUIDParam("id", r)
+	if err != nil {
+		logger.Info("unable to read id parameter", "error", err)
+		rest.BadRequestResponse(w, r, "unable to read id parameter")
+		return
+	}
+
+	qs := r.URL.Query()
+	filter := taskLogFilter{
+		RunID: id.String(),
+		Level: rest.ReadQueryString(qs, "level", ""),
+		Query: rest.ReadQueryString(qs, "q", ""),
+	}
+	logger.Info("filter parsed", "filter", filter)
+
+	var afterSeq int64
+	if lastEventID := r.Header.Get("Last-Event-ID"); lastEventID != "" {
+		afterSeq, err = strconv.ParseInt(lastEventID, 10, 64)
+		if err != nil {
+			rest.BadRequestResponse(w, r, "Last-Event-ID must be a log sequence number")
+			return
+		}
+	}
+
+	logger.Info("retrieving task run")
+	_, err = m.orchestratorModule.ReadScheduledTask(ctx, *id)
+	if err != nil {
+		switch {
+		case errors.Is(err, data.ErrRecordNotFound):
+			logger.Info("task run not found", "id", id)
+			rest.NotFoundResponse(w, r)
+		default:
+			logger.Error("unable to retrieve task run", "error", err)
+			rest.ServerErrorResponse(w, r, err)
+		}
+		return
+	}
+
+	logger.Info("starting event stream", "afterSeq", afterSeq)
+	stream, err := rest.NewEventStream(w)
+	if err != nil {
+		logger.Error("unable to start event stream", "error", err)
+		rest.ServerErrorResponse(w, r, err)
+		return
+	}
+
+	send := func(log *types.TaskLog) error {
+		entry, ok := filter.match(log.Log)
+		if !ok {
+			return nil
+		}
+
+		html, err := m.renderString("taskLogEntry.tmpl", entry)
+		if err != nil {
+			return err
+		}
+		return stream.Send(strconv.FormatInt(log.Seq, 10), "log", html)
+	}
+	keepalive := func() error {
+		return stream.Comment("keepalive")
+	}
+
+	run, err := m.orchestratorModule.StreamScheduledTaskLogs(ctx, *id, afterSeq, send, keepalive)
+	if err != nil {
+		if ctx.Err() == nil {
+			logger.Error("log stream ended", "id", id, "error", err)
+		}
+		return
+	}
+
+	state := ""
+	if run.State != nil {
+		state = *run.State
+	}
+	html, err := m.renderString("taskRunLogsEnd.tmpl", state)
+	if err != nil {
+		logger.Error("unable to render end of stream", "error", err)
+		return
+	}
+	err = stream.Send("", "end", html)
+	if err != nil {
+		logger.Error("unable to send end event", "error", err)
+		return
+	}
+	logger.Info("log stream complete")
+}
+
+func (m *Module) renderTaskList(w http.ResponseWriter, r *http.Request, taskError string) {
+	ctx := r.Context()
+	logger := logging.LoggerFromContext(ctx)
+
+	filters := data.Filters{Page: 1, PageSize: 1_000, OrderBy: []string{"name"}}
+	logger.Info("retrieving tasks", "filters", filters)
+	tasks, err := m.orchestratorModule.ReadAllTasks(ctx, filters)
+	if err != nil {
+		logger.Error("error occurred while retrieving tasks", "error", err)
+		rest.ServerErrorResponse(w, r, err)
+		return
+	}
+	logger.Info("tasks retrieved", "length", len(tasks.Tasks))
+
+	logger.Info("rendering UI component")
+	m.renderPartial(w, http.StatusOK, "taskList.tmpl", &templateData{
+		Tasks:     tasks.Tasks,
+		TaskError: taskError,
+	})
+}
+
+func (m *Module) renderTaskRunList(w http.ResponseWriter, r *http.Request, taskError string) {
+	ctx := r.Context()
+	logger := logging.LoggerFromContext(ctx)
+
+	filters := data.Filters{
+		Page:     1,
+		PageSize: taskRunPageSize,
+		OrderBy:  []string{"-updated_at"},
+	}
+	logger.Info("retrieving task runs", "filters", filters)
+	runs, err := m.orchestratorModule.ReadAllScheduledTasks(ctx, filters)
+	if err != nil {
+		logger.Error("error occurred while retrieving task runs", "error", err)
+		rest.ServerErrorResponse(w, r, err)
+		return
+	}
+	logger.Info("task runs retrieved", "length", len(runs.Data))
+
+	logger.Info("rendering UI component")
+	m.renderPartial(w, http.StatusOK, "taskRunList.tmpl", &templateData{
+		TaskRuns:  runs.Data,
+		TaskError: taskError,
+	})
+}
+
+// parseTaskLog splits a JSON task log record into its standard fields and attributes. Records
+// that are not valid JSON are shown as the message.
+func parseTaskLog(raw string) taskLogEntry {
+	var record map[string]any
+	if err := json.Unmarshal([]byte(raw), &record); err != nil {
+		return taskLogEntry{Message: raw}
+	}
+
+	entry := taskLogEntry{}
+	entry.Time, _ = record[slog.TimeKey].(string)
+	entry.Level, _ = record[slog.LevelKey].(string)
+	entry.Message, _ = record[slog.MessageKey].(string)
+	delete(record, slog.TimeKey)
+	delete(record, slog.LevelKey)
+	delete(record, slog.MessageKey)
+
+	if len(record) > 0 {
+		attributes, err := json.MarshalIndent(record, "", "  ")
+		if err == nil {
+			entry.Attributes = string(attributes)
+		}
+	}
+
+	return entry
+}
+
+func taskStateColour(state string) string {
+	switch data.TaskState(state) {
+	case data.WaitingTaskState, data.BlockedTaskState:
+		return "secondary"
+	case data.RunningTaskState, data.AwaitingTaskState:
+		return "primary"
+	case data.CompleteTaskState:
+		return "success"
+	case data.StoppedTaskState:
+		return "warning"
+	case data.ErrorTaskState:
+		return "danger"
+	case data.SkippedTaskState:
+		return "info"
+	case data.DeadTaskState:
+		return "dark"
+	default:
+		return "light"
+	}
+}
+
+func logLevelColour(level string) string {
+	switch strings.ToUpper(level) {
+	case "DEBUG":
+		return "secondary"
+	case "WARN":
+		return "warning"
+	case "ERROR":
+		return "danger"
+	default:
+		return "info"
+	}
+}
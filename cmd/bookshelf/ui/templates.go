@@ -7,11 +7,15 @@ import (
 	"html/template"
 	"io/fs"
 	"net/http"
+	"net/url"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/r3d5un/Bookshelf/internal/books/types"
+	orchestratorTypes "github.com/r3d5un/Bookshelf/internal/orchestrator/types"
 )
 
 //go:embed "html" "static" "static"
@@ -58,14 +62,31 @@ var functions = template.FuncMap{
 	"sub": func(a, b int) int {
 		return a - b
 	},
-	"paragraphify": paragraphify,
+	"paragraphify":    paragraphify,
+	"humanTime":       humanTime,
+	"pathEscape":      url.PathEscape,
+	"deref":           deref,
+	"taskStateColour": taskStateColour,
+	"logLevelColour":  logLevelColour,
+	"logLevels": func() []string {
+		return []string{"DEBUG", "INFO", "WARN", "ERROR"}
+	},
+	// idempotencyKey returns a new key for each rendered form, so that a resubmitted form
+	// creates a single record
+	"idempotencyKey": uuid.NewString,
 }
 
 type templateData struct {
-	MyLibraryBooks            []*types.Book               `json:"myLibraryBooks,omitempty"`
-	SelectedCategory          string                      `json:"selectedCategory,omitempty"`
-	SeriesAccordionCollection []SeriesAccordionCollection `json:"seriesAccordionCollection,omitempty"`
-	BookData                  types.Book                  `json:"bookData,omitempty"`
+	MyLibraryBooks            []*types.Book                      `json:"myLibraryBooks,omitempty"`
+	SelectedCategory          string                             `json:"selectedCategory,omitempty"`
+	SeriesAccordionCollection []SeriesAccordionCollection        `json:"seriesAccordionCollection,omitempty"`
+	BookData                  types.Book                         `json:"bookData,omitempty"`
+	Tasks                     []*orchestratorTypes.Task          `json:"tasks,omitempty"`
+	TaskRuns                  []*orchestratorTypes.ScheduledTask `json:"taskRuns,omitempty"`
+	TaskLogs                  []taskLogEntry                     `json:"taskLogs,omitempty"`
+	TaskLogFilter             taskLogFilter                      `json:"taskLogFilter,omitempty"`
+	TaskError                 string                             `json:"taskError,omitempty"`
+	BookHistory               []bookHistoryEntry                 `json:"bookHistory,omitempty"`
 }
 
 type SeriesAccordionCollection struct {
@@ -158,6 +179,25 @@ func (m *Module) renderPartial(
 	buffer.WriteTo(w)
 }
 
+// renderString executes the root template of a template file, returning the output as a string.
+// Intended for components that are not written directly to a response, such as server-sent
+// events.
+func (m *Module) renderString(templateName string, data any) (string, error) {
+	templates, ok := m.templateCache[templateName]
+	if !ok {
+		return "", ErrTemplateNotFound
+	}
+
+	buffer := new(bytes.Buffer)
+
+	err := templates.Execute(buffer, data)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(buffer.String()), nil
+}
+
 // Writes a response to a request with a status and the body as a string. Intended for use in smaller
 // responses where a dedicated template is overkill.
 func (m *Module) rawResponse(w http.ResponseWriter, status int, responseBody string) {
@@ -177,6 +217,27 @@ func humanDate(t time.Time) string {
 	}
 	return t.UTC().Format("2006-01-02")
 }
+
+func humanTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format("2006-01-02 15:04:05")
+}
+
+// deref returns the value a pointer points to, allowing pointer fields to be compared and
+// passed to functions in templates.
+func deref(p any) any {
+	v := reflect.ValueOf(p)
+	if v.Kind() != reflect.Pointer {
+		return p
+	}
+	if v.IsNil() {
+		return reflect.Zero(v.Type().Elem()).Interface()
+	}
+	return v.Elem().Interface()
+}
+
 func paragraphify(text string) template.HTML {
 	paragraphs := strings.Split(text, "\n\n")
 	var result string
@@ -0,0 +1,50 @@
+// Package audit carries who made a change, and as part of which request, from the edge of the
+// application down to the queries recording the change.
+package audit
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// ActorHeader is the request header naming the actor making the request. It is meant to be set
+// by an authenticating proxy in front of the application, or by the CLI.
+const ActorHeader string = "X-Bookshelf-Actor"
+
+// RequestIDHeader is the response header carrying the ID of the request, as recorded in the
+// audit log.
+const RequestIDHeader string = "X-Request-Id"
+
+// Anonymous is the actor of requests without an ActorHeader.
+const Anonymous string = "anonymous"
+
+type contextKey string
+
+const (
+	actorKey     contextKey = "auditActor"
+	requestIDKey contextKey = "auditRequestID"
+)
+
+// WithActor embeds the actor making changes in the given context.
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey, actor)
+}
+
+// ActorFromContext returns the actor embedded in the given context, or an empty string if none
+// is set.
+func ActorFromContext(ctx context.Context) string {
+	actor, _ := ctx.Value(actorKey).(string)
+	return actor
+}
+
+// WithRequestID embeds the ID of the request changes are made as part of in the given context.
+func WithRequestID(ctx context.Context, id uuid.UUID) context.Context {
+	return context.WithValue(ctx, requestIDKey, id)
+}
+
+// RequestIDFromContext returns the request ID embedded in the given context, if any.
+func RequestIDFromContext(ctx context.Context) (uuid.UUID, bool) {
+	id, ok := ctx.Value(requestIDKey).(uuid.UUID)
+	return id, ok
+}
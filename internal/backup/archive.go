@@ -0,0 +1,134 @@
+package backup
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/r3d5un/Bookshelf/internal/books/types"
+)
+
+const (
+	archivePrefix    string = "bookshelf-backup-"
+	archiveExtension string = ".tar.gz"
+	archiveTimestamp string = "20060102T150405Z"
+	ArchiveVersion   int    = 1
+)
+
+// Library contains the complete catalog written to a backup archive.
+type Library struct {
+	Books   []*types.Book   `json:"books"`
+	Authors []*types.Author `json:"authors"`
+	Series  []*types.Series `json:"series"`
+	Genres  []*types.Genre  `json:"genres"`
+}
+
+// Manifest describes the contents of a backup archive.
+type Manifest struct {
+	Version   int       `json:"version"`
+	CreatedAt time.Time `json:"createdAt"`
+	Books     int       `json:"books"`
+	Authors   int       `json:"authors"`
+	Series    int       `json:"series"`
+	Genres    int       `json:"genres"`
+}
+
+// ArchiveName returns the file name of an archive created at the given time.
+func ArchiveName(createdAt time.Time) string {
+	return archivePrefix + createdAt.UTC().Format(archiveTimestamp) + archiveExtension
+}
+
+// WriteArchive writes the library as a gzipped tarball containing a manifest and
+// one JSON document per catalog entity.
+func WriteArchive(w io.Writer, library Library, createdAt time.Time) error {
+	gzw := gzip.NewWriter(w)
+	tw := tar.NewWriter(gzw)
+
+	manifest := Manifest{
+		Version:   ArchiveVersion,
+		CreatedAt: createdAt.UTC(),
+		Books:     len(library.Books),
+		Authors:   len(library.Authors),
+		Series:    len(library.Series),
+		Genres:    len(library.Genres),
+	}
+
+	entries := []struct {
+		name    string
+		content any
+	}{
+		{"manifest.json", manifest},
+		{"books.json", library.Books},
+		{"authors.json", library.Authors},
+		{"series.json", library.Series},
+		{"genres.json", library.Genres},
+	}
+
+	for _, entry := range entries {
+		js, err := json.MarshalIndent(entry.content, "", "  ")
+		if err != nil {
+			return fmt.Errorf("unable to encode %s: %w", entry.name, err)
+		}
+
+		header := &tar.Header{
+			Name:    entry.name,
+			Mode:    0o644,
+			Size:    int64(len(js)),
+			ModTime: createdAt,
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		if _, err := tw.Write(js); err != nil {
+			return err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+
+	return gzw.Close()
+}
+
+// WriteArchiveFile writes the library to a new archive in the given directory. The archive
+// is written to a temporary file first, and renamed once complete, so that a partially
+// written archive is never picked up as a valid backup.
+func WriteArchiveFile(dir string, library Library, createdAt time.Time) (path string, err error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+
+	tmp, err := os.CreateTemp(dir, ".bookshelf-backup-*.tmp")
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		if err != nil {
+			tmp.Close()
+			os.Remove(tmp.Name())
+		}
+	}()
+
+	if err = WriteArchive(tmp, library, createdAt); err != nil {
+		return "", err
+	}
+	if err = tmp.Sync(); err != nil {
+		return "", err
+	}
+	if err = tmp.Close(); err != nil {
+		return "", err
+	}
+
+	path = filepath.Join(dir, ArchiveName(createdAt))
+	if err = os.Rename(tmp.Name(), path); err != nil {
+		return "", err
+	}
+
+	return path, nil
+}
@@ -0,0 +1,116 @@
+package backup_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/r3d5un/Bookshelf/internal/backup"
+	"github.com/r3d5un/Bookshelf/internal/books/types"
+)
+
+func TestArchive(t *testing.T) {
+	timestamp := time.Date(2024, 9, 20, 2, 0, 0, 0, time.UTC)
+	title := "TestBookTitle"
+
+	t.Run("ArchiveName", func(t *testing.T) {
+		name := backup.ArchiveName(timestamp)
+
+		parsed, err := backup.ParseArchiveName(name)
+		if err != nil {
+			t.Errorf("unable to parse archive name: %s\n", err)
+			return
+		}
+		if !parsed.Equal(timestamp) {
+			t.Errorf("expected %s, got %s\n", timestamp, parsed)
+			return
+		}
+	})
+
+	t.Run("WriteArchive", func(t *testing.T) {
+		var buf bytes.Buffer
+		library := backup.Library{Books: []*types.Book{{Title: &title}}}
+
+		if err := backup.WriteArchive(&buf, library, timestamp); err != nil {
+			t.Errorf("unable to write archive: %s\n", err)
+			return
+		}
+
+		gzr, err := gzip.NewReader(&buf)
+		if err != nil {
+			t.Errorf("unable to read gzip stream: %s\n", err)
+			return
+		}
+		tr := tar.NewReader(gzr)
+
+		var files []string
+		for {
+			header, err := tr.Next()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				t.Errorf("unable to read archive: %s\n", err)
+				return
+			}
+			files = append(files, header.Name)
+		}
+
+		if len(files) != 5 {
+			t.Errorf("expected 5 files in archive, got %d: %v\n", len(files), files)
+			return
+		}
+	})
+}
+
+func TestRetention(t *testing.T) {
+	start := time.Date(2024, 9, 20, 2, 0, 0, 0, time.UTC)
+
+	// One archive per day for a year, plus an additional archive on the newest day
+	var archives []backup.Archive
+	for i := 0; i < 365; i++ {
+		createdAt := start.AddDate(0, 0, -i)
+		archives = append(archives, backup.Archive{
+			Path:      fmt.Sprintf("%d", i),
+			CreatedAt: createdAt,
+		})
+	}
+	archives = append(archives, backup.Archive{Path: "extra", CreatedAt: start.Add(-time.Hour)})
+
+	t.Run("Expired", func(t *testing.T) {
+		policy := backup.RetentionPolicy{Daily: 7, Weekly: 4, Monthly: 12}
+
+		expired := backup.Expired(archives, policy)
+		kept := len(archives) - len(expired)
+
+		// 7 daily archives, with the weekly and monthly archives partially overlapping
+		if kept < 12 || kept > 7+4+12 {
+			t.Errorf("expected between 12 and 23 archives to be kept, got %d\n", kept)
+			return
+		}
+
+		for _, archive := range expired {
+			if archive.Path == "0" {
+				t.Errorf("newest archive was expired")
+				return
+			}
+			if archive.Path != "extra" && start.Sub(archive.CreatedAt) < 7*24*time.Hour {
+				t.Errorf("archive within the daily retention was expired: %v\n", archive)
+				return
+			}
+		}
+	})
+
+	t.Run("KeepNewest", func(t *testing.T) {
+		expired := backup.Expired(archives, backup.RetentionPolicy{})
+		if len(expired) != len(archives)-1 {
+			t.Errorf("expected %d expired archives, got %d\n", len(archives)-1, len(expired))
+			return
+		}
+	})
+}
@@ -0,0 +1,124 @@
+package backup
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// RetentionPolicy describes a grandfather-father-son rotation scheme. Each value
+// is the number of periods for which the newest archive of the period is kept.
+type RetentionPolicy struct {
+	Daily   int `json:"daily"`
+	Weekly  int `json:"weekly"`
+	Monthly int `json:"monthly"`
+}
+
+// Archive is a backup archive found on disk.
+type Archive struct {
+	Path      string    `json:"path"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// ParseArchiveName parses the creation time from an archive file name created by
+// ArchiveName.
+func ParseArchiveName(name string) (time.Time, error) {
+	if !strings.HasPrefix(name, archivePrefix) || !strings.HasSuffix(name, archiveExtension) {
+		return time.Time{}, fmt.Errorf("%s is not a backup archive", name)
+	}
+
+	timestamp := strings.TrimSuffix(strings.TrimPrefix(name, archivePrefix), archiveExtension)
+
+	return time.Parse(archiveTimestamp, timestamp)
+}
+
+// ListArchives returns the backup archives in the given directory, newest first.
+// Files not matching the archive naming scheme are ignored.
+func ListArchives(dir string) ([]Archive, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var archives []Archive
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		createdAt, err := ParseArchiveName(entry.Name())
+		if err != nil {
+			continue
+		}
+
+		archives = append(archives, Archive{
+			Path:      filepath.Join(dir, entry.Name()),
+			CreatedAt: createdAt,
+		})
+	}
+
+	sortNewestFirst(archives)
+
+	return archives, nil
+}
+
+// Expired returns the archives that fall outside the retention policy.
+//
+// For each of the daily, weekly and monthly periods, the newest archive within each of
+// the N most recent periods is kept. An archive kept by any of the periods is retained.
+// The newest archive is always retained, regardless of the policy.
+func Expired(archives []Archive, policy RetentionPolicy) []Archive {
+	sorted := slices.Clone(archives)
+	sortNewestFirst(sorted)
+
+	keep := make(map[string]bool, len(sorted))
+	if len(sorted) > 0 {
+		keep[sorted[0].Path] = true
+	}
+
+	keepNewestPerPeriod := func(periods int, period func(time.Time) string) {
+		seen := make(map[string]bool, periods)
+		for _, archive := range sorted {
+			if len(seen) >= periods {
+				return
+			}
+
+			key := period(archive.CreatedAt)
+			if seen[key] {
+				continue
+			}
+
+			seen[key] = true
+			keep[archive.Path] = true
+		}
+	}
+
+	keepNewestPerPeriod(policy.Daily, func(t time.Time) string {
+		return t.UTC().Format("2006-01-02")
+	})
+	keepNewestPerPeriod(policy.Weekly, func(t time.Time) string {
+		year, week := t.UTC().ISOWeek()
+		return fmt.Sprintf("%d-W%02d", year, week)
+	})
+	keepNewestPerPeriod(policy.Monthly, func(t time.Time) string {
+		return t.UTC().Format("2006-01")
+	})
+
+	var expired []Archive
+	for _, archive := range sorted {
+		if !keep[archive.Path] {
+			expired = append(expired, archive)
+		}
+	}
+
+	return expired
+}
+
+func sortNewestFirst(archives []Archive) {
+	slices.SortFunc(archives, func(a, b Archive) int {
+		return b.CreatedAt.Compare(a.CreatedAt)
+	})
+}
@@ -0,0 +1,265 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/r3d5un/Bookshelf/internal/audit"
+	"github.com/r3d5un/Bookshelf/internal/database"
+	"github.com/r3d5un/Bookshelf/internal/logging"
+)
+
+// AuditEntry is a change to a record of the books module, recorded by the audit log triggers of
+// its table.
+type AuditEntry struct {
+	ID int64 `json:"id"`
+	// EntityType is the table the change was made to, e.g. books or book_authors
+	EntityType string `json:"entityType"`
+	// EntityID is the changed record, or the book of a changed link
+	EntityID uuid.UUID `json:"entityId"`
+	// RelatedID is the author, genre or series of a changed link
+	RelatedID *uuid.UUID      `json:"relatedId,omitempty"`
+	Action    string          `json:"action"`
+	Actor     string          `json:"actor"`
+	RequestID *uuid.UUID      `json:"requestId,omitempty"`
+	Before    json.RawMessage `json:"before,omitempty"`
+	After     json.RawMessage `json:"after,omitempty"`
+	// Changes holds the before and after value of each changed column
+	Changes   json.RawMessage `json:"changes"`
+	CreatedAt *time.Time      `json:"createdAt"`
+}
+
+type AuditLogModel struct {
+	DB      *sql.DB
+	Timeout *time.Duration
+}
+
+// GetAll returns the changes to the record with the given ID, and to the links to it, newest
+// first.
+func (m *AuditLogModel) GetAll(
+	ctx context.Context,
+	entityID uuid.UUID,
+	filters Filters,
+) (entries []*AuditEntry, totalResults *int, err error) {
+	logger := logging.LoggerFromContext(ctx)
+
+	query := `
+SELECT id,
+       entity_type,
+       entity_id,
+       related_id,
+       action,
+       actor,
+       request_id,
+       before,
+       after,
+       changes,
+       created_at
+FROM books.audit_log
+WHERE (entity_id = $1::uuid OR related_id = $1::uuid)
+  AND ($2::timestamp IS NULL OR created_at >= $2::timestamp)
+  AND ($3::timestamp IS NULL OR created_at < $3::timestamp)
+ORDER BY id DESC
+OFFSET $4 FETCH NEXT $5 ROWS ONLY;
+`
+
+	qCtx, cancel := context.WithTimeout(ctx, *m.Timeout)
+	defer cancel()
+
+	logger = logger.With(
+		slog.Group(
+			"query",
+			slog.String("statement", database.MinifySQL(query)),
+			slog.String("entityId", entityID.String()),
+			"filters", filters,
+		),
+	)
+
+	entries = []*AuditEntry{}
+
+	logger.Info("performing query")
+	rows, err := m.DB.QueryContext(
+		qCtx,
+		query,
+		entityID,
+		filters.CreatedAtFrom,
+		filters.CreatedAtTo,
+		filters.offset(),
+		filters.limit(),
+	)
+	if err != nil {
+		logger.Error("error performing query", "error", err)
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var entry AuditEntry
+		var before, after, changes []byte
+
+		err := rows.Scan(
+			&entry.ID,
+			&entry.EntityType,
+			&entry.EntityID,
+			&entry.RelatedID,
+			&entry.Action,
+			&entry.Actor,
+			&entry.RequestID,
+			&before,
+			&after,
+			&changes,
+			&entry.CreatedAt,
+		)
+		if err != nil {
+			return nil, nil, err
+		}
+		entry.Before = before
+		entry.After = after
+		entry.Changes = changes
+		entries = append(entries, &entry)
+	}
+	if err = rows.Err(); err != nil {
+		logger.Error("an error occurred while parsing query results", "error", err)
+		return nil, nil, err
+	}
+	numberOfRecords := len(entries)
+
+	logger.Info("returning records", slog.Int("records", numberOfRecords))
+	return entries, &numberOfRecords, nil
+}
+
+// txKey is the context key of the transaction started by Models.Transaction
+type txKey struct{}
+
+// txFromContext returns the transaction changes made with the context are part of, if any.
+func txFromContext(ctx context.Context) (*sql.Tx, bool) {
+	tx, ok := ctx.Value(txKey{}).(*sql.Tx)
+	return tx, ok
+}
+
+// queryRowAudited performs a query changing a single record in a transaction carrying the actor
+// and request ID of the context, so that the audit log triggers record who made the change. The
+// transaction is committed once the returned row is scanned, unless the query is made in the
+// transaction of the context, which is left to its owner.
+func queryRowAudited(ctx context.Context, db *sql.DB, query string, args ...any) *auditedRow {
+	if tx, ok := txFromContext(ctx); ok {
+		return &auditedRow{ctx: ctx, row: tx.QueryRowContext(ctx, query, args...)}
+	}
+
+	tx, err := beginAudited(ctx, db)
+	if err != nil {
+		return &auditedRow{ctx: ctx, err: err}
+	}
+
+	return &auditedRow{ctx: ctx, tx: tx, row: tx.QueryRowContext(ctx, query, args...)}
+}
+
+// execAudited performs a query changing any number of records in a transaction carrying the
+// actor and request ID of the context, as queryRowAudited does.
+func execAudited(
+	ctx context.Context,
+	db *sql.DB,
+	query string,
+	args ...any,
+) (result sql.Result, err error) {
+	err = inAudited(ctx, db, func(tx *sql.Tx) error {
+		result, err = tx.ExecContext(ctx, query, args...)
+		return err
+	})
+
+	return result, err
+}
+
+// inAudited runs fn in the transaction of the context, or in a transaction of its own begun by
+// beginAudited, which is committed if fn returns nil and rolled back otherwise.
+func inAudited(ctx context.Context, db *sql.DB, fn func(tx *sql.Tx) error) (err error) {
+	if tx, ok := txFromContext(ctx); ok {
+		return fn(tx)
+	}
+
+	tx, err := beginAudited(ctx, db)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			if rbErr := tx.Rollback(); rbErr != nil {
+				logging.LoggerFromContext(ctx).Error(
+					"unable to roll back transaction", "error", rbErr,
+				)
+			}
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	return fn(tx)
+}
+
+// beginAudited begins a transaction, and sets the actor and request ID of the context as the
+// bookshelf.actor and bookshelf.request_id settings of the transaction read by the audit log
+// triggers.
+func beginAudited(ctx context.Context, db *sql.DB) (*sql.Tx, error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var requestID string
+	if id, ok := audit.RequestIDFromContext(ctx); ok {
+		requestID = id.String()
+	}
+	_, err = tx.ExecContext(
+		ctx,
+		"SELECT set_config('bookshelf.actor', $1, TRUE), set_config('bookshelf.request_id', $2, TRUE);",
+		audit.ActorFromContext(ctx),
+		requestID,
+	)
+	if err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			logging.LoggerFromContext(ctx).Error("unable to roll back transaction", "error", rbErr)
+		}
+		return nil, err
+	}
+
+	return tx, nil
+}
+
+// auditedRow is the result of queryRowAudited.
+type auditedRow struct {
+	ctx context.Context
+	// tx is the transaction begun for the query, which is nil for queries made in the
+	// transaction of the context
+	tx  *sql.Tx
+	row *sql.Row
+	err error
+}
+
+// Scan copies the columns of the row into dest, and ends the transaction of the query. The
+// transaction is committed if the row is scanned, and rolled back otherwise, so that
+// sql.ErrNoRows changes nothing. Transactions of the context are left open.
+func (r *auditedRow) Scan(dest ...any) (err error) {
+	if r.err != nil {
+		return r.err
+	}
+	if r.tx == nil {
+		return r.row.Scan(dest...)
+	}
+	defer func() {
+		if err != nil {
+			if rbErr := r.tx.Rollback(); rbErr != nil {
+				logging.LoggerFromContext(r.ctx).Error(
+					"unable to roll back transaction", "error", rbErr,
+				)
+			}
+			return
+		}
+		err = r.tx.Commit()
+	}()
+
+	return r.row.Scan(dest...)
+}
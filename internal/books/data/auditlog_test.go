@@ -0,0 +1,113 @@
+package data_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/r3d5un/Bookshelf/internal/audit"
+	"github.com/r3d5un/Bookshelf/internal/books/data"
+)
+
+func TestAuditLogModel(t *testing.T) {
+	requestID := uuid.New()
+	ctx := audit.WithRequestID(audit.WithActor(context.Background(), "librarian"), requestID)
+
+	description := "A description that will be cleared by accident"
+	book := data.Book{ID: uuid.New(), Title: "TestAuditLogModel", Description: &description}
+	name := "Author TestAuditLogModel"
+	author := data.Author{ID: uuid.New(), Name: &name}
+
+	if _, err := models.Books.Insert(ctx, book); err != nil {
+		t.Errorf("unable to insert book: %v\n", err)
+		return
+	}
+	if _, err := models.Authors.Insert(ctx, author); err != nil {
+		t.Errorf("unable to insert author: %v\n", err)
+		return
+	}
+	if _, err := models.BookAuthors.Insert(ctx, book.ID, author.ID); err != nil {
+		t.Errorf("unable to insert book author: %v\n", err)
+		return
+	}
+	title := "TestAuditLogModel Revised"
+	if _, err := models.Books.Update(ctx, book.ID, data.BookPatch{Title: data.OptionalOf(&title)}, nil); err != nil {
+		t.Errorf("unable to update book: %v\n", err)
+		return
+	}
+	if _, err := models.Books.Delete(context.Background(), book.ID, nil); err != nil {
+		t.Errorf("unable to delete book: %v\n", err)
+		return
+	}
+	if _, err := models.Books.Purge(context.Background(), time.Now().Add(time.Minute)); err != nil {
+		t.Errorf("unable to purge books: %v\n", err)
+		return
+	}
+
+	filters := data.Filters{Page: 1, PageSize: 100}
+
+	t.Run("GetAllByBook", func(t *testing.T) {
+		entries, _, err := models.AuditLog.GetAll(context.Background(), book.ID, filters)
+		if err != nil {
+			t.Errorf("error occurred while reading audit log: %s\n", err)
+			return
+		}
+
+		// Newest first: the purged book, the link deleted by the cascade, the book moved to the
+		// trash, the update, the inserted link and the inserted book
+		expected := []struct{ entityType, action string }{
+			{"books", "purge"},
+			{"book_authors", "delete"},
+			{"books", "delete"},
+			{"books", "update"},
+			{"book_authors", "insert"},
+			{"books", "insert"},
+		}
+		if len(entries) != len(expected) {
+			t.Errorf("expected %d entries, got %d\n", len(expected), len(entries))
+			return
+		}
+		for i, e := range expected {
+			if entries[i].EntityType != e.entityType || entries[i].Action != e.action {
+				t.Errorf(
+					"expected entry %d to be %s %s, got %s %s\n",
+					i, e.action, e.entityType, entries[i].Action, entries[i].EntityType,
+				)
+				return
+			}
+		}
+
+		update := entries[3]
+		if update.Actor != "librarian" {
+			t.Errorf("expected actor librarian, got %s\n", update.Actor)
+			return
+		}
+		if update.RequestID == nil || *update.RequestID != requestID {
+			t.Errorf("expected request ID %s, got %v\n", requestID, update.RequestID)
+			return
+		}
+		if string(update.Changes) == "" || update.Before == nil || update.After == nil {
+			t.Error("expected the update to record its changes")
+			return
+		}
+
+		if entries[0].Actor == "librarian" {
+			t.Error("expected changes without an actor to be recorded as the database user")
+			return
+		}
+	})
+
+	t.Run("GetAllByAuthor", func(t *testing.T) {
+		entries, _, err := models.AuditLog.GetAll(context.Background(), author.ID, filters)
+		if err != nil {
+			t.Errorf("error occurred while reading audit log: %s\n", err)
+			return
+		}
+		// The inserted author, and the link inserted and deleted
+		if len(entries) != 3 {
+			t.Errorf("expected 3 entries, got %d\n", len(entries))
+			return
+		}
+	})
+}
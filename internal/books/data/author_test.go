@@ -62,7 +62,12 @@ func TestAuthorModel(t *testing.T) {
 		updateName := "Jane Doe"
 		newAuthor.Name = &updateName
 
-		res, err := models.Authors.Update(context.Background(), newAuthor)
+		res, err := models.Authors.Update(
+			context.Background(),
+			newAuthor.ID,
+			data.AuthorPatch{Name: data.OptionalOf(&updateName)},
+			nil,
+		)
 		if err != nil {
 			t.Errorf("unable to retrieve result: %v\n", err)
 			return
@@ -91,7 +96,7 @@ func TestAuthorModel(t *testing.T) {
 	})
 
 	t.Run("Delete", func(t *testing.T) {
-		_, err := models.Authors.Delete(context.Background(), newAuthor.ID)
+		_, err := models.Authors.Delete(context.Background(), newAuthor.ID, nil)
 		if err != nil {
 			t.Errorf("unable to delete data: %v\n", err)
 			return
@@ -19,6 +19,7 @@ type Author struct {
 	Website     *string    `json:"website"`
 	CreatedAt   *time.Time `json:"createdAt"`
 	UpdatedAt   *time.Time `json:"updatedAt"`
+	DeletedAt   *time.Time `json:"deletedAt,omitempty"`
 }
 
 type AuthorModel struct {
@@ -37,7 +38,8 @@ SELECT id,
        created_at,
        updated_at
 FROM books.authors
-WHERE id = $1;
+WHERE id = $1
+  AND deleted_at IS NULL;
 `
 
 	qCtx, cancel := context.WithTimeout(ctx, *m.Timeout)
@@ -89,7 +91,8 @@ SELECT id,
        description,
        website,
        created_at,
-       updated_at
+       updated_at,
+       deleted_at
 FROM books.authors
 WHERE ($1::uuid IS NULL OR id = $1::uuid)
   AND ($2::text = '' OR name LIKE '%' || $2::text || '%')
@@ -99,6 +102,7 @@ WHERE ($1::uuid IS NULL OR id = $1::uuid)
   AND ($6::timestamp IS NULL OR created_at < $6::timestamp)
   AND ($7::timestamp IS NULL OR updated_at >= $7::timestamp)
   AND ($8::timestamp IS NULL OR updated_at < $8::timestamp)
+  AND (deleted_at IS NOT NULL) = COALESCE($11::boolean, FALSE)
 ` + database.CreateOrderByClause(filters.OrderBy) + `
 OFFSET $9 FETCH NEXT $10 ROWS ONLY;
 `
@@ -130,6 +134,7 @@ OFFSET $9 FETCH NEXT $10 ROWS ONLY;
 		filters.UpdatedAtTo,
 		filters.offset(),
 		filters.limit(),
+		filters.Deleted,
 	)
 	if err != nil {
 		logger.Error("error performing query", "error", err)
@@ -147,6 +152,7 @@ OFFSET $9 FETCH NEXT $10 ROWS ONLY;
 			&author.Website,
 			&author.CreatedAt,
 			&author.UpdatedAt,
+			&author.DeletedAt,
 		)
 		if err != nil {
 			return nil, nil, err
@@ -202,8 +208,9 @@ RETURNING
 	author = &Author{}
 
 	logger.Info("performing query")
-	err = m.DB.QueryRowContext(
+	err = queryRowAudited(
 		qCtx,
+		m.DB,
 		query,
 		newAuthor.ID,
 		newAuthor.Name,
@@ -226,17 +233,29 @@ RETURNING
 	return author, nil
 }
 
-func (m *AuthorModel) Update(ctx context.Context, newAuthor Author) (author *Author, err error) {
+// Update applies the patch to the author, setting the columns of the fields set by the patch and
+// leaving the rest unchanged. If version is set, the author is only changed if it has not been
+// updated since, and ErrEditConflict is returned otherwise.
+func (m *AuthorModel) Update(
+	ctx context.Context,
+	id uuid.UUID,
+	patch AuthorPatch,
+	version *time.Time,
+) (author *Author, err error) {
 	logger := logging.LoggerFromContext(ctx)
 
+	update := newPatchUpdate(id, version)
+	setOptional(update, "name", patch.Name)
+	setOptional(update, "description", patch.Description)
+	setOptional(update, "website", patch.Website)
+	setOptional(update, "created_at", patch.CreatedAt)
+
 	query := `
 UPDATE books.authors
-SET name        = COALESCE($2, name),
-    description = COALESCE($3, description),
-    website     = COALESCE($4, website),
-    created_at  = COALESCE($5, created_at),
-    updated_at  = NOW()
+` + update.set() + `
 WHERE id = $1
+  AND deleted_at IS NULL
+  AND ($2::timestamp IS NULL OR updated_at = $2::timestamp)
 RETURNING
     id,
     name,
@@ -253,22 +272,15 @@ RETURNING
 		slog.Group(
 			"query",
 			slog.String("statement", database.MinifySQL(query)),
-			"newAuthor", newAuthor,
+			slog.String("id", id.String()),
+			"patch", patch,
 		),
 	)
 
 	author = &Author{}
 
 	logger.Info("performing query")
-	err = m.DB.QueryRowContext(
-		qCtx,
-		query,
-		newAuthor.ID,
-		newAuthor.Name,
-		newAuthor.Description,
-		newAuthor.Website,
-		newAuthor.CreatedAt,
-	).Scan(
+	err = queryRowAudited(qCtx, m.DB, query, update.args...).Scan(
 		&author.ID,
 		&author.Name,
 		&author.Description,
@@ -280,7 +292,7 @@ RETURNING
 		switch {
 		case errors.Is(err, sql.ErrNoRows):
 			logger.Info("no record found", "error", err)
-			return nil, ErrRecordNotFound
+			return nil, notFoundOrConflict(ctx, m.Get, id, version)
 		default:
 			logger.Error("unable to perform query", "error", err)
 			return nil, err
@@ -336,8 +348,9 @@ RETURNING id,
 	author = &Author{}
 
 	logger.Info("performing query")
-	err = m.DB.QueryRowContext(
+	err = queryRowAudited(
 		qCtx,
+		m.DB,
 		query,
 		newAuthor.ID,
 		newAuthor.Name,
@@ -361,19 +374,31 @@ RETURNING id,
 	return author, nil
 }
 
-func (m *AuthorModel) Delete(ctx context.Context, id uuid.UUID) (author *Author, err error) {
+// Delete moves the author to the trash, leaving it out of every query but GetAll with
+// Filters.Deleted set until it is restored or purged. If version is set, the author is only moved
+// if it has not been updated since, and ErrEditConflict is returned otherwise.
+func (m *AuthorModel) Delete(
+	ctx context.Context,
+	id uuid.UUID,
+	version *time.Time,
+) (author *Author, err error) {
 	logger := logging.LoggerFromContext(ctx)
 
 	query := `
-DELETE FROM books.authors
+UPDATE books.authors
+SET deleted_at = NOW(),
+    updated_at = NOW()
 WHERE id = $1
+  AND deleted_at IS NULL
+  AND ($2::timestamp IS NULL OR updated_at = $2::timestamp)
 RETURNING
 	id,
 	name,
 	description,
 	website,
 	created_at,
-	updated_at;
+	updated_at,
+	deleted_at;
 `
 	qCtx, cancel := context.WithTimeout(ctx, *m.Timeout)
 	defer cancel()
@@ -389,19 +414,20 @@ RETURNING
 	author = &Author{}
 
 	logger.Info("performing query")
-	err = m.DB.QueryRowContext(qCtx, query, id.String()).Scan(
+	err = queryRowAudited(qCtx, m.DB, query, id.String(), version).Scan(
 		&author.ID,
 		&author.Name,
 		&author.Description,
 		&author.Website,
 		&author.CreatedAt,
 		&author.UpdatedAt,
+		&author.DeletedAt,
 	)
 	if err != nil {
 		switch {
 		case errors.Is(err, sql.ErrNoRows):
 			logger.Info("no rows found", "id", id.String())
-			return nil, ErrRecordNotFound
+			return nil, notFoundOrConflict(ctx, m.Get, id, version)
 		default:
 			logger.Info("an error occurred while performing query", "error", err)
 			return nil, err
@@ -412,6 +438,105 @@ RETURNING
 	return author, nil
 }
 
+// Restore takes the author out of the trash. ErrRecordNotFound is returned if it is not in the
+// trash.
+func (m *AuthorModel) Restore(ctx context.Context, id uuid.UUID) (author *Author, err error) {
+	logger := logging.LoggerFromContext(ctx)
+
+	query := `
+UPDATE books.authors
+SET deleted_at = NULL,
+    updated_at = NOW()
+WHERE id = $1
+  AND deleted_at IS NOT NULL
+RETURNING
+	id,
+	name,
+	description,
+	website,
+	created_at,
+	updated_at,
+	deleted_at;
+`
+	qCtx, cancel := context.WithTimeout(ctx, *m.Timeout)
+	defer cancel()
+
+	logger = logger.With(
+		slog.Group(
+			"query",
+			slog.String("statement", database.MinifySQL(query)),
+			slog.String("id", id.String()),
+		),
+	)
+
+	author = &Author{}
+
+	logger.Info("performing query")
+	err = queryRowAudited(qCtx, m.DB, query, id.String()).Scan(
+		&author.ID,
+		&author.Name,
+		&author.Description,
+		&author.Website,
+		&author.CreatedAt,
+		&author.UpdatedAt,
+		&author.DeletedAt,
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			logger.Info("no rows found", "id", id.String())
+			return nil, ErrRecordNotFound
+		default:
+			logger.Info("an error occurred while performing query", "error", err)
+			return nil, err
+		}
+	}
+
+	logger.Info("returning restored author")
+	return author, nil
+}
+
+// Purge permanently deletes the authors moved to the trash before the given time, along with
+// their links, and returns the number of authors deleted.
+func (m *AuthorModel) Purge(ctx context.Context, before time.Time) (int64, error) {
+	logger := logging.LoggerFromContext(ctx)
+
+	query := `
+DELETE
+FROM books.authors
+WHERE deleted_at < $1::timestamp;
+`
+
+	qCtx, cancel := context.WithTimeout(ctx, *m.Timeout)
+	defer cancel()
+
+	// Timestamps are stored without a time zone, in UTC
+	before = before.UTC()
+
+	logger = logger.With(
+		slog.Group(
+			"query",
+			slog.String("statement", database.MinifySQL(query)),
+			slog.Time("before", before),
+		),
+	)
+
+	logger.Info("performing query")
+	result, err := execAudited(qCtx, m.DB, query, before)
+	if err != nil {
+		logger.Error("an error occurred while performing query", "error", err)
+		return 0, err
+	}
+
+	purged, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	logger.Info("authors purged", "purged", purged)
+	return purged, nil
+}
+
 func (m *AuthorModel) GetByBookID(
 	ctx context.Context,
 	id uuid.UUID,
@@ -431,6 +556,7 @@ FROM books.authors a
          INNER JOIN
      books.books b ON b.id = ba.book_id
 WHERE b.id = $1
+  AND a.deleted_at IS NULL
 ORDER BY b.id;
 `
 	qCtx, cancel := context.WithTimeout(ctx, *m.Timeout)
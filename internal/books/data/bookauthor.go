@@ -21,6 +21,7 @@ type BookAuthorModel struct {
 	Timeout *time.Duration
 }
 
+// Insert links the book to the author. ErrDuplicateRecord is returned if they are already linked.
 func (m BookAuthorModel) Insert(
 	ctx context.Context,
 	bookID uuid.UUID,
@@ -52,8 +53,9 @@ RETURNING book_id,
 	ba = &BookAuthor{}
 
 	logger.Info("performing query")
-	err = m.DB.QueryRowContext(
+	err = queryRowAudited(
 		qCtx,
+		m.DB,
 		query,
 		bookID,
 		authorID,
@@ -63,6 +65,10 @@ RETURNING book_id,
 	)
 
 	if err != nil {
+		if isUniqueViolation(err) {
+			logger.Info("book already linked to author", "error", err)
+			return nil, ErrDuplicateRecord
+		}
 		logger.Error("unable to insert record", "error", err)
 		return nil, err
 	}
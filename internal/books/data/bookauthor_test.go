@@ -51,10 +51,49 @@ func TestBookAuthorModel(t *testing.T) {
 	}
 
 	t.Run("Insert", func(t *testing.T) {
-		_, err := models.BookAuthors.Insert(context.Background(), newBook.ID, newAuthor.ID)
+		bookBefore, err := models.Books.Get(context.Background(), newBook.ID)
+		if err != nil {
+			t.Errorf("unable to retrieve book: %v\n", err)
+			return
+		}
+		authorBefore, err := models.Authors.Get(context.Background(), newAuthor.ID)
+		if err != nil {
+			t.Errorf("unable to retrieve author: %v\n", err)
+			return
+		}
+
+		_, err = models.BookAuthors.Insert(context.Background(), newBook.ID, newAuthor.ID)
 		if err != nil {
 			t.Errorf("unable to insert data: %v\n", err)
 			return
 		}
+
+		// Linking changes the version of both records, as each is read with the other
+		bookAfter, err := models.Books.Get(context.Background(), newBook.ID)
+		if err != nil {
+			t.Errorf("unable to retrieve book: %v\n", err)
+			return
+		}
+		if !bookAfter.UpdatedAt.After(*bookBefore.UpdatedAt) {
+			t.Errorf(
+				"expected the book to be updated after %s, got %s\n",
+				bookBefore.UpdatedAt,
+				bookAfter.UpdatedAt,
+			)
+			return
+		}
+		authorAfter, err := models.Authors.Get(context.Background(), newAuthor.ID)
+		if err != nil {
+			t.Errorf("unable to retrieve author: %v\n", err)
+			return
+		}
+		if !authorAfter.UpdatedAt.After(*authorBefore.UpdatedAt) {
+			t.Errorf(
+				"expected the author to be updated after %s, got %s\n",
+				authorBefore.UpdatedAt,
+				authorAfter.UpdatedAt,
+			)
+			return
+		}
 	})
 }
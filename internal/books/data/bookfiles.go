@@ -0,0 +1,340 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/r3d5un/Bookshelf/internal/database"
+	"github.com/r3d5un/Bookshelf/internal/logging"
+)
+
+// BookFile is a file attached to a book, such as the ebook itself. The content is stored apart
+// from the file, and only read by GetContent.
+type BookFile struct {
+	ID          uuid.UUID  `json:"id"`
+	BookID      uuid.UUID  `json:"bookId"`
+	Name        string     `json:"name"`
+	ContentType string     `json:"contentType"`
+	Size        int64      `json:"size"`
+	Checksum    string     `json:"checksum"`
+	CreatedAt   *time.Time `json:"createdAt,omitempty"`
+}
+
+type BookFileModel struct {
+	DB      *sql.DB
+	Timeout *time.Duration
+}
+
+// Get returns the file with the given ID. Files of books in the trash are not found.
+func (m *BookFileModel) Get(ctx context.Context, id uuid.UUID) (f *BookFile, err error) {
+	logger := logging.LoggerFromContext(ctx)
+
+	query := `
+SELECT f.id,
+       f.book_id,
+       f.name,
+       f.content_type,
+       f.size,
+       f.checksum,
+       f.created_at
+FROM books.book_files f
+         INNER JOIN
+     books.books b ON b.id = f.book_id
+WHERE f.id = $1
+  AND b.deleted_at IS NULL;
+`
+
+	qCtx, cancel := context.WithTimeout(ctx, *m.Timeout)
+	defer cancel()
+
+	logger = logger.With(
+		slog.Group(
+			"query",
+			slog.String("statement", database.MinifySQL(query)),
+			slog.String("id", id.String()),
+		),
+	)
+
+	f = &BookFile{}
+
+	logger.Info("performing query")
+	err = m.DB.QueryRowContext(qCtx, query, id).Scan(
+		&f.ID,
+		&f.BookID,
+		&f.Name,
+		&f.ContentType,
+		&f.Size,
+		&f.Checksum,
+		&f.CreatedAt,
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			logger.Info("no rows found", "id", id.String())
+			return nil, ErrRecordNotFound
+		default:
+			logger.Info("an error occurred while performing query", "error", err)
+			return nil, err
+		}
+	}
+
+	logger.Info("returning book file")
+	return f, nil
+}
+
+// GetByBookID returns the files attached to the book, oldest first.
+func (m *BookFileModel) GetByBookID(
+	ctx context.Context,
+	bookID uuid.UUID,
+) (files []*BookFile, err error) {
+	logger := logging.LoggerFromContext(ctx)
+
+	query := `
+SELECT id,
+       book_id,
+       name,
+       content_type,
+       size,
+       checksum,
+       created_at
+FROM books.book_files
+WHERE book_id = $1
+ORDER BY created_at, id;
+`
+
+	qCtx, cancel := context.WithTimeout(ctx, *m.Timeout)
+	defer cancel()
+
+	logger = logger.With(
+		slog.Group(
+			"query",
+			slog.String("statement", database.MinifySQL(query)),
+			slog.String("bookId", bookID.String()),
+		),
+	)
+
+	files = []*BookFile{}
+
+	logger.Info("performing query")
+	rows, err := m.DB.QueryContext(qCtx, query, bookID)
+	if err != nil {
+		logger.Error("error performing query", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var f BookFile
+
+		err := rows.Scan(
+			&f.ID,
+			&f.BookID,
+			&f.Name,
+			&f.ContentType,
+			&f.Size,
+			&f.Checksum,
+			&f.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, &f)
+	}
+	if err = rows.Err(); err != nil {
+		logger.Error("an error occurred while parsing query results", "error", err)
+		return nil, err
+	}
+
+	logger.Info("returning records", slog.Int("records", len(files)))
+	return files, nil
+}
+
+// GetContent returns the content of the file with the given ID. Files of books in the trash are
+// not found.
+func (m *BookFileModel) GetContent(ctx context.Context, id uuid.UUID) (content []byte, err error) {
+	logger := logging.LoggerFromContext(ctx)
+
+	query := `
+SELECT c.content
+FROM books.book_file_contents c
+         INNER JOIN
+     books.book_files f ON f.id = c.file_id
+         INNER JOIN
+     books.books b ON b.id = f.book_id
+WHERE c.file_id = $1
+  AND b.deleted_at IS NULL;
+`
+
+	qCtx, cancel := context.WithTimeout(ctx, *m.Timeout)
+	defer cancel()
+
+	logger = logger.With(
+		slog.Group(
+			"query",
+			slog.String("statement", database.MinifySQL(query)),
+			slog.String("id", id.String()),
+		),
+	)
+
+	logger.Info("performing query")
+	err = m.DB.QueryRowContext(qCtx, query, id).Scan(&content)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			logger.Info("no rows found", "id", id.String())
+			return nil, ErrRecordNotFound
+		default:
+			logger.Info("an error occurred while performing query", "error", err)
+			return nil, err
+		}
+	}
+
+	logger.Info("returning book file content", slog.Int("size", len(content)))
+	return content, nil
+}
+
+// Insert attaches the file with the given content to its book. ErrRecordNotFound is returned if
+// the book does not exist, or is in the trash.
+func (m *BookFileModel) Insert(
+	ctx context.Context,
+	newFile BookFile,
+	content []byte,
+) (f *BookFile, err error) {
+	logger := logging.LoggerFromContext(ctx)
+
+	query := `
+INSERT INTO books.book_files (book_id, name, content_type, size, checksum)
+SELECT id, $2, $3, $4, $5
+FROM books.books
+WHERE id = $1
+  AND deleted_at IS NULL
+RETURNING id,
+          book_id,
+          name,
+          content_type,
+          size,
+          checksum,
+          created_at;
+`
+	contentQuery := `
+INSERT INTO books.book_file_contents (file_id, content)
+VALUES ($1, $2);
+`
+
+	qCtx, cancel := context.WithTimeout(ctx, *m.Timeout)
+	defer cancel()
+
+	logger = logger.With(
+		slog.Group(
+			"query",
+			slog.String("statement", database.MinifySQL(query)),
+			slog.String("bookId", newFile.BookID.String()),
+			slog.String("name", newFile.Name),
+			slog.Int64("size", newFile.Size),
+		),
+	)
+
+	f = &BookFile{}
+
+	err = inAudited(qCtx, m.DB, func(tx *sql.Tx) error {
+		logger.Info("performing query")
+		err := tx.QueryRowContext(
+			qCtx,
+			query,
+			newFile.BookID,
+			newFile.Name,
+			newFile.ContentType,
+			newFile.Size,
+			newFile.Checksum,
+		).Scan(
+			&f.ID,
+			&f.BookID,
+			&f.Name,
+			&f.ContentType,
+			&f.Size,
+			&f.Checksum,
+			&f.CreatedAt,
+		)
+		if err != nil {
+			return err
+		}
+
+		logger.Info("storing file content", "id", f.ID)
+		_, err = tx.ExecContext(qCtx, contentQuery, f.ID, content)
+		return err
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			logger.Info("book not found")
+			return nil, ErrRecordNotFound
+		}
+		logger.Error("unable to insert book file", "error", err)
+		return nil, err
+	}
+
+	logger.Info("returning inserted book file")
+	return f, nil
+}
+
+// Delete removes the file and its content. ErrRecordNotFound is returned if it does not exist,
+// or its book is in the trash.
+func (m *BookFileModel) Delete(ctx context.Context, id uuid.UUID) (f *BookFile, err error) {
+	logger := logging.LoggerFromContext(ctx)
+
+	query := `
+DELETE
+FROM books.book_files f
+    USING books.books b
+WHERE f.id = $1
+  AND b.id = f.book_id
+  AND b.deleted_at IS NULL
+RETURNING f.id,
+          f.book_id,
+          f.name,
+          f.content_type,
+          f.size,
+          f.checksum,
+          f.created_at;
+`
+
+	qCtx, cancel := context.WithTimeout(ctx, *m.Timeout)
+	defer cancel()
+
+	logger = logger.With(
+		slog.Group(
+			"query",
+			slog.String("statement", database.MinifySQL(query)),
+			slog.String("id", id.String()),
+		),
+	)
+
+	f = &BookFile{}
+
+	logger.Info("performing query")
+	err = queryRowAudited(qCtx, m.DB, query, id).Scan(
+		&f.ID,
+		&f.BookID,
+		&f.Name,
+		&f.ContentType,
+		&f.Size,
+		&f.Checksum,
+		&f.CreatedAt,
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			logger.Info("no rows found", "id", id.String())
+			return nil, ErrRecordNotFound
+		default:
+			logger.Info("an error occurred while performing query", "error", err)
+			return nil, err
+		}
+	}
+
+	logger.Info("returning deleted book file")
+	return f, nil
+}
@@ -0,0 +1,102 @@
+package data_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/r3d5un/Bookshelf/internal/books/data"
+)
+
+func TestBookFileModel(t *testing.T) {
+	timestamp := time.Now()
+	book, err := models.Books.Insert(context.Background(), data.Book{
+		ID:        uuid.New(),
+		Title:     "TestBookFileTitle",
+		CreatedAt: &timestamp,
+		UpdatedAt: &timestamp,
+	})
+	if err != nil {
+		t.Errorf("unable to insert test data: %v\n", err)
+		return
+	}
+
+	content := []byte("Call me Ishmael.")
+	var file *data.BookFile
+
+	t.Run("Insert", func(t *testing.T) {
+		file, err = models.BookFiles.Insert(context.Background(), data.BookFile{
+			BookID:      book.ID,
+			Name:        "moby-dick.txt",
+			ContentType: "text/plain",
+			Size:        int64(len(content)),
+			Checksum:    "3f0d0c6e7a8c1e7d1f9a1c3b5e7d9f1a3c5e7d9f1b3d5f7a9c1e3d5f7a9b1c3d",
+		}, content)
+		if err != nil {
+			t.Errorf("unable to insert data: %v\n", err)
+			return
+		}
+	})
+
+	t.Run("InsertMissingBook", func(t *testing.T) {
+		_, err := models.BookFiles.Insert(context.Background(), data.BookFile{
+			BookID:      uuid.New(),
+			Name:        "missing.txt",
+			ContentType: "text/plain",
+			Checksum:    "3f0d0c6e7a8c1e7d1f9a1c3b5e7d9f1a3c5e7d9f1b3d5f7a9c1e3d5f7a9b1c3d",
+		}, nil)
+		if !errors.Is(err, data.ErrRecordNotFound) {
+			t.Errorf("expected %s, got %v\n", data.ErrRecordNotFound, err)
+			return
+		}
+	})
+
+	t.Run("Get", func(t *testing.T) {
+		_, err := models.BookFiles.Get(context.Background(), file.ID)
+		if err != nil {
+			t.Errorf("unable to retrieve result: %v\n", err)
+			return
+		}
+	})
+
+	t.Run("GetByBookID", func(t *testing.T) {
+		files, err := models.BookFiles.GetByBookID(context.Background(), book.ID)
+		if err != nil {
+			t.Errorf("unable to retrieve result: %v\n", err)
+			return
+		}
+		if len(files) != 1 {
+			t.Errorf("expected 1 file, got %d\n", len(files))
+			return
+		}
+	})
+
+	t.Run("GetContent", func(t *testing.T) {
+		stored, err := models.BookFiles.GetContent(context.Background(), file.ID)
+		if err != nil {
+			t.Errorf("unable to retrieve result: %v\n", err)
+			return
+		}
+		if !bytes.Equal(stored, content) {
+			t.Errorf("expected content %q, got %q\n", content, stored)
+			return
+		}
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		_, err := models.BookFiles.Delete(context.Background(), file.ID)
+		if err != nil {
+			t.Errorf("unable to delete data: %v\n", err)
+			return
+		}
+
+		_, err = models.BookFiles.Get(context.Background(), file.ID)
+		if !errors.Is(err, data.ErrRecordNotFound) {
+			t.Errorf("expected %s, got %v\n", data.ErrRecordNotFound, err)
+			return
+		}
+	})
+}
@@ -19,6 +19,7 @@ type Book struct {
 	Published   *time.Time `json:"published,omitempty"`
 	CreatedAt   *time.Time `json:"createdAt,omitempty"`
 	UpdatedAt   *time.Time `json:"updatedAt,omitempty"`
+	DeletedAt   *time.Time `json:"deletedAt,omitempty"`
 }
 
 type BookModel struct {
@@ -37,7 +38,8 @@ SELECT id,
        created_at,
        updated_at
 FROM books.books
-WHERE id = $1;
+WHERE id = $1
+  AND deleted_at IS NULL;
 `
 
 	qCtx, cancel := context.WithTimeout(ctx, *m.Timeout)
@@ -89,7 +91,8 @@ SELECT id,
        description,
        published,
        created_at,
-       updated_at
+       updated_at,
+       deleted_at
 FROM books.books
 WHERE ($1::uuid IS NULL OR id = $1::uuid)
   AND ($2::text = '' OR title LIKE '%' || $2::text || '%')
@@ -100,6 +103,7 @@ WHERE ($1::uuid IS NULL OR id = $1::uuid)
   AND ($7::timestamp IS NULL OR created_at < $7::timestamp)
   AND ($8::timestamp IS NULL OR updated_at >= $8::timestamp)
   AND ($9::timestamp IS NULL OR updated_at < $9::timestamp)
+  AND (deleted_at IS NOT NULL) = COALESCE($12::boolean, FALSE)
 ` + database.CreateOrderByClause(filters.OrderBy) + `
 OFFSET $10 FETCH NEXT $11 ROWS ONLY;
 `
@@ -132,6 +136,7 @@ OFFSET $10 FETCH NEXT $11 ROWS ONLY;
 		filters.UpdatedAtTo,
 		filters.offset(),
 		filters.limit(),
+		filters.Deleted,
 	)
 	if err != nil {
 		logger.Error("error performing query", "error", err)
@@ -149,6 +154,7 @@ OFFSET $10 FETCH NEXT $11 ROWS ONLY;
 			&book.Published,
 			&book.CreatedAt,
 			&book.UpdatedAt,
+			&book.DeletedAt,
 		)
 		if err != nil {
 			return nil, nil, err
@@ -203,8 +209,9 @@ RETURNING
 	b = &Book{}
 
 	logger.Info("performing query")
-	err = m.DB.QueryRowContext(
+	err = queryRowAudited(
 		qCtx,
+		m.DB,
 		query,
 		newBook.ID,
 		newBook.Title,
@@ -227,17 +234,29 @@ RETURNING
 	return b, nil
 }
 
-func (m *BookModel) Update(ctx context.Context, newBook Book) (b *Book, err error) {
+// Update applies the patch to the book, setting the columns of the fields set by the patch and
+// leaving the rest unchanged. If version is set, the book is only changed if it has not been
+// updated since, and ErrEditConflict is returned otherwise.
+func (m *BookModel) Update(
+	ctx context.Context,
+	id uuid.UUID,
+	patch BookPatch,
+	version *time.Time,
+) (b *Book, err error) {
 	logger := logging.LoggerFromContext(ctx)
 
+	update := newPatchUpdate(id, version)
+	setOptional(update, "title", patch.Title)
+	setOptional(update, "description", patch.Description)
+	setOptional(update, "published", patch.Published)
+	setOptional(update, "created_at", patch.CreatedAt)
+
 	query := `
 UPDATE books.books
-SET title       = CASE WHEN $2 = '' THEN title ELSE COALESCE($2, title) END,
-    description = COALESCE($3, description),
-    published   = COALESCE($4, published),
-    created_at  = COALESCE($5, created_at),
-    updated_at  = NOW()
+` + update.set() + `
 WHERE id = $1
+  AND deleted_at IS NULL
+  AND ($2::timestamp IS NULL OR updated_at = $2::timestamp)
 RETURNING
     id,
     title,
@@ -254,22 +273,15 @@ RETURNING
 		slog.Group(
 			"query",
 			slog.String("statement", database.MinifySQL(query)),
-			"newBook", newBook,
+			slog.String("id", id.String()),
+			"patch", patch,
 		),
 	)
 
 	b = &Book{}
 
 	logger.Info("performing query")
-	err = m.DB.QueryRowContext(
-		qCtx,
-		query,
-		newBook.ID,
-		newBook.Title,
-		newBook.Description,
-		newBook.Published,
-		newBook.CreatedAt,
-	).Scan(
+	err = queryRowAudited(qCtx, m.DB, query, update.args...).Scan(
 		&b.ID,
 		&b.Title,
 		&b.Description,
@@ -281,7 +293,7 @@ RETURNING
 		switch {
 		case errors.Is(err, sql.ErrNoRows):
 			logger.Info("no record found", "error", err)
-			return nil, ErrRecordNotFound
+			return nil, notFoundOrConflict(ctx, m.Get, id, version)
 		default:
 			logger.Error("unable to perform query", "error", err)
 			return nil, err
@@ -337,8 +349,9 @@ RETURNING id,
 	b = &Book{}
 
 	logger.Info("performing query")
-	err = m.DB.QueryRowContext(
+	err = queryRowAudited(
 		qCtx,
+		m.DB,
 		query,
 		newBook.ID,
 		newBook.Title,
@@ -361,19 +374,31 @@ RETURNING id,
 	return b, nil
 }
 
-func (m *BookModel) Delete(ctx context.Context, id uuid.UUID) (b *Book, err error) {
+// Delete moves the book to the trash, leaving it out of every query but GetAll with Filters.Deleted
+// set until it is restored or purged. If version is set, the book is only moved if it has not been
+// updated since, and ErrEditConflict is returned otherwise.
+func (m *BookModel) Delete(
+	ctx context.Context,
+	id uuid.UUID,
+	version *time.Time,
+) (b *Book, err error) {
 	logger := logging.LoggerFromContext(ctx)
 
 	query := `
-DELETE FROM books.books
+UPDATE books.books
+SET deleted_at = NOW(),
+    updated_at = NOW()
 WHERE id = $1
+  AND deleted_at IS NULL
+  AND ($2::timestamp IS NULL OR updated_at = $2::timestamp)
 RETURNING
 	id,
 	title,
 	description,
 	published,
 	created_at,
-	updated_at;
+	updated_at,
+	deleted_at;
 `
 	qCtx, cancel := context.WithTimeout(ctx, *m.Timeout)
 	defer cancel()
@@ -389,19 +414,20 @@ RETURNING
 	b = &Book{}
 
 	logger.Info("performing query")
-	err = m.DB.QueryRowContext(qCtx, query, id.String()).Scan(
+	err = queryRowAudited(qCtx, m.DB, query, id.String(), version).Scan(
 		&b.ID,
 		&b.Title,
 		&b.Description,
 		&b.Published,
 		&b.CreatedAt,
 		&b.UpdatedAt,
+		&b.DeletedAt,
 	)
 	if err != nil {
 		switch {
 		case errors.Is(err, sql.ErrNoRows):
 			logger.Info("no rows found", "group_id", id.String())
-			return nil, ErrRecordNotFound
+			return nil, notFoundOrConflict(ctx, m.Get, id, version)
 		default:
 			logger.Info("an error occurred while performing query", "error", err)
 			return nil, err
@@ -412,6 +438,105 @@ RETURNING
 	return b, nil
 }
 
+// Restore takes the book out of the trash. ErrRecordNotFound is returned if it is not in the
+// trash.
+func (m *BookModel) Restore(ctx context.Context, id uuid.UUID) (b *Book, err error) {
+	logger := logging.LoggerFromContext(ctx)
+
+	query := `
+UPDATE books.books
+SET deleted_at = NULL,
+    updated_at = NOW()
+WHERE id = $1
+  AND deleted_at IS NOT NULL
+RETURNING
+	id,
+	title,
+	description,
+	published,
+	created_at,
+	updated_at,
+	deleted_at;
+`
+	qCtx, cancel := context.WithTimeout(ctx, *m.Timeout)
+	defer cancel()
+
+	logger = logger.With(
+		slog.Group(
+			"query",
+			slog.String("statement", database.MinifySQL(query)),
+			slog.String("id", id.String()),
+		),
+	)
+
+	b = &Book{}
+
+	logger.Info("performing query")
+	err = queryRowAudited(qCtx, m.DB, query, id.String()).Scan(
+		&b.ID,
+		&b.Title,
+		&b.Description,
+		&b.Published,
+		&b.CreatedAt,
+		&b.UpdatedAt,
+		&b.DeletedAt,
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			logger.Info("no rows found", "group_id", id.String())
+			return nil, ErrRecordNotFound
+		default:
+			logger.Info("an error occurred while performing query", "error", err)
+			return nil, err
+		}
+	}
+
+	logger.Info("returning restored book")
+	return b, nil
+}
+
+// Purge permanently deletes the books moved to the trash before the given time, along with
+// their links, and returns the number of books deleted.
+func (m *BookModel) Purge(ctx context.Context, before time.Time) (int64, error) {
+	logger := logging.LoggerFromContext(ctx)
+
+	query := `
+DELETE
+FROM books.books
+WHERE deleted_at < $1::timestamp;
+`
+
+	qCtx, cancel := context.WithTimeout(ctx, *m.Timeout)
+	defer cancel()
+
+	// Timestamps are stored without a time zone, in UTC
+	before = before.UTC()
+
+	logger = logger.With(
+		slog.Group(
+			"query",
+			slog.String("statement", database.MinifySQL(query)),
+			slog.Time("before", before),
+		),
+	)
+
+	logger.Info("performing query")
+	result, err := execAudited(qCtx, m.DB, query, before)
+	if err != nil {
+		logger.Error("an error occurred while performing query", "error", err)
+		return 0, err
+	}
+
+	purged, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	logger.Info("books purged", "purged", purged)
+	return purged, nil
+}
+
 func (m *BookModel) GetByAuthorID(
 	ctx context.Context,
 	id uuid.UUID,
@@ -431,6 +556,7 @@ FROM books.books b
          INNER JOIN
      books.authors a ON a.id = ba.book_id
 WHERE a.id = $1
+  AND b.deleted_at IS NULL
 ORDER BY a.id;
 `
 	qCtx, cancel := context.WithTimeout(ctx, *m.Timeout)
@@ -503,6 +629,7 @@ FROM books.books b
          INNER JOIN
      books.series s ON s.id = bs.series_id
 WHERE s.id = $1
+  AND b.deleted_at IS NULL
 ORDER BY bs.series_order;
 `
 	qCtx, cancel := context.WithTimeout(ctx, *m.Timeout)
@@ -575,6 +702,7 @@ FROM books.books b
          INNER JOIN
      books.genres s ON s.id = bg.genres_id
 WHERE s.id = $1
+  AND b.deleted_at IS NULL
 ORDER BY b.published;
 `
 	qCtx, cancel := context.WithTimeout(ctx, *m.Timeout)
@@ -2,6 +2,8 @@ package data_test
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"testing"
 	"time"
 
@@ -60,7 +62,12 @@ func TestBookModel(t *testing.T) {
 	t.Run("Update", func(t *testing.T) {
 		newBook.Title = "NewTitle!"
 
-		res, err := models.Books.Update(context.Background(), newBook)
+		res, err := models.Books.Update(
+			context.Background(),
+			id,
+			data.BookPatch{Title: data.OptionalOf(&newBook.Title)},
+			nil,
+		)
 		if err != nil {
 			t.Errorf("unable to retrieve result: %v\n", err)
 			return
@@ -72,6 +79,54 @@ func TestBookModel(t *testing.T) {
 		}
 	})
 
+	t.Run("UpdateClear", func(t *testing.T) {
+		var patch data.BookPatch
+		if err := json.Unmarshal([]byte(`{"description": null}`), &patch); err != nil {
+			t.Errorf("unable to decode patch: %v\n", err)
+			return
+		}
+
+		res, err := models.Books.Update(context.Background(), id, patch, nil)
+		if err != nil {
+			t.Errorf("unable to retrieve result: %v\n", err)
+			return
+		}
+
+		if res.Description != nil {
+			t.Errorf("expected no description, got %s", *res.Description)
+			return
+		}
+		if res.Title != newBook.Title {
+			t.Errorf("expected %s, got %s", newBook.Title, res.Title)
+			return
+		}
+		if res.Published == nil {
+			t.Error("expected the publication date to be unchanged, got none")
+			return
+		}
+	})
+
+	t.Run("UpdateVersion", func(t *testing.T) {
+		current, err := models.Books.Get(context.Background(), id)
+		if err != nil {
+			t.Errorf("unable to retrieve result: %v\n", err)
+			return
+		}
+
+		stale := current.UpdatedAt.Add(-time.Second)
+		_, err = models.Books.Update(context.Background(), id, data.BookPatch{}, &stale)
+		if !errors.Is(err, data.ErrEditConflict) {
+			t.Errorf("expected %s, got %v\n", data.ErrEditConflict, err)
+			return
+		}
+
+		_, err = models.Books.Update(context.Background(), id, data.BookPatch{}, current.UpdatedAt)
+		if err != nil {
+			t.Errorf("unable to update the current version: %v\n", err)
+			return
+		}
+	})
+
 	t.Run("Upsert", func(t *testing.T) {
 		description := "Upserted description..."
 		newBook.Description = &description
@@ -89,10 +144,72 @@ func TestBookModel(t *testing.T) {
 	})
 
 	t.Run("Delete", func(t *testing.T) {
-		_, err := models.Books.Delete(context.Background(), newBook.ID)
+		_, err := models.Books.Delete(context.Background(), newBook.ID, nil)
 		if err != nil {
 			t.Errorf("unable to delete data: %v\n", err)
 			return
 		}
+
+		_, err = models.Books.Get(context.Background(), id)
+		if !errors.Is(err, data.ErrRecordNotFound) {
+			t.Errorf("expected %s, got %v\n", data.ErrRecordNotFound, err)
+			return
+		}
+	})
+
+	t.Run("GetAllDeleted", func(t *testing.T) {
+		deleted := true
+		filters := data.Filters{Page: 1, PageSize: 10, ID: &id, Deleted: &deleted}
+
+		books, _, err := models.Books.GetAll(context.Background(), filters)
+		if err != nil {
+			t.Errorf("unable to retrieve result: %v\n", err)
+			return
+		}
+		if len(books) != 1 || books[0].DeletedAt == nil {
+			t.Errorf("expected the book in the trash, got %v\n", books)
+			return
+		}
+	})
+
+	t.Run("Restore", func(t *testing.T) {
+		res, err := models.Books.Restore(context.Background(), id)
+		if err != nil {
+			t.Errorf("unable to restore data: %v\n", err)
+			return
+		}
+		if res.DeletedAt != nil {
+			t.Errorf("expected the book to be restored, got deleted at %v\n", res.DeletedAt)
+			return
+		}
+
+		_, err = models.Books.Restore(context.Background(), id)
+		if !errors.Is(err, data.ErrRecordNotFound) {
+			t.Errorf("expected %s, got %v\n", data.ErrRecordNotFound, err)
+			return
+		}
+	})
+
+	t.Run("Purge", func(t *testing.T) {
+		if _, err := models.Books.Delete(context.Background(), id, nil); err != nil {
+			t.Errorf("unable to delete data: %v\n", err)
+			return
+		}
+
+		purged, err := models.Books.Purge(context.Background(), time.Now().Add(time.Minute))
+		if err != nil {
+			t.Errorf("unable to purge data: %v\n", err)
+			return
+		}
+		if purged < 1 {
+			t.Error("expected the deleted book to be purged")
+			return
+		}
+
+		_, err = models.Books.Restore(context.Background(), id)
+		if !errors.Is(err, data.ErrRecordNotFound) {
+			t.Errorf("expected %s, got %v\n", data.ErrRecordNotFound, err)
+			return
+		}
 	})
 }
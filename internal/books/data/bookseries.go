@@ -22,6 +22,8 @@ type BookSeriesModel struct {
 	Timeout *time.Duration
 }
 
+// Insert links the book to the series at the given position. ErrDuplicateRecord is returned if
+// they are already linked.
 func (m BookSeriesModel) Insert(
 	ctx context.Context,
 	bookID uuid.UUID,
@@ -57,8 +59,9 @@ RETURNING book_id,
 	bs = &BookSeries{}
 
 	logger.Info("performing query")
-	err = m.DB.QueryRowContext(
+	err = queryRowAudited(
 		qCtx,
+		m.DB,
 		query,
 		bookID,
 		seriesID,
@@ -70,6 +73,10 @@ RETURNING book_id,
 	)
 
 	if err != nil {
+		if isUniqueViolation(err) {
+			logger.Info("book already linked to series", "error", err)
+			return nil, ErrDuplicateRecord
+		}
 		logger.Error("unable to insert record", "error", err)
 		return nil, err
 	}
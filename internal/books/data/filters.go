@@ -18,6 +18,7 @@ type Filters struct {
 	PageSize        int        `json:"pageSize,omitempty"`
 	StartIndex      int        `json:"startIndex,omitempty"`
 	Count           int        `json:"count,omitempty"`
+	Deleted         *bool      `json:"deleted,omitempty"`
 	ID              *uuid.UUID `json:"id,omitempty"`
 	AuthorID        *uuid.UUID `json:"authorId,omitempty"`
 	Title           string     `json:"title,omitempty"`
@@ -18,6 +18,7 @@ type Genre struct {
 	Description *string    `json:"description"`
 	CreatedAt   *time.Time `json:"created_at"`
 	UpdatedAt   *time.Time `json:"updated_at"`
+	DeletedAt   *time.Time `json:"deleted_at,omitempty"`
 }
 
 type GenreModel struct {
@@ -35,7 +36,8 @@ SELECT id,
        created_at,
        updated_at
 FROM books.genres
-WHERE id = $1;
+WHERE id = $1
+  AND deleted_at IS NULL;
 `
 
 	qCtx, cancel := context.WithTimeout(ctx, *m.Timeout)
@@ -85,7 +87,8 @@ SELECT id,
        name,
        description,
        created_at,
-       updated_at
+       updated_at,
+       deleted_at
 FROM books.genres
 WHERE ($1::uuid IS NULL OR id = $1::uuid)
   AND ($2::text = '' OR name LIKE '%' || $2::text || '%')
@@ -94,6 +97,7 @@ WHERE ($1::uuid IS NULL OR id = $1::uuid)
   AND ($5::timestamp IS NULL OR created_at < $5::timestamp)
   AND ($6::timestamp IS NULL OR updated_at >= $6::timestamp)
   AND ($7::timestamp IS NULL OR updated_at < $7::timestamp)
+  AND (deleted_at IS NOT NULL) = COALESCE($10::boolean, FALSE)
 ` + database.CreateOrderByClause(filters.OrderBy) + `
 OFFSET $8 FETCH NEXT $9 ROWS ONLY;
 `
@@ -124,6 +128,7 @@ OFFSET $8 FETCH NEXT $9 ROWS ONLY;
 		filters.UpdatedAtTo,
 		filters.offset(),
 		filters.limit(),
+		filters.Deleted,
 	)
 	if err != nil {
 		logger.Error("error performing query", "error", err)
@@ -140,6 +145,7 @@ OFFSET $8 FETCH NEXT $9 ROWS ONLY;
 			&genre.Description,
 			&genre.CreatedAt,
 			&genre.UpdatedAt,
+			&genre.DeletedAt,
 		)
 		if err != nil {
 			return nil, nil, err
@@ -192,8 +198,9 @@ RETURNING
 	genre = &Genre{}
 
 	logger.Info("performing query")
-	err = m.DB.QueryRowContext(
+	err = queryRowAudited(
 		qCtx,
+		m.DB,
 		query,
 		newGenre.ID,
 		newGenre.Name,
@@ -214,17 +221,29 @@ RETURNING
 	return genre, nil
 }
 
-func (m *GenreModel) Update(ctx context.Context, newGenre Genre) (genre *Genre, err error) {
+// Update applies the patch to the genre, setting the columns of the fields set by the patch and
+// leaving the rest unchanged. If version is set, the genre is only changed if it has not been
+// updated since, and ErrEditConflict is returned otherwise. ErrDuplicateRecord is returned if the
+// new name is already in use.
+func (m *GenreModel) Update(
+	ctx context.Context,
+	id uuid.UUID,
+	patch GenrePatch,
+	version *time.Time,
+) (genre *Genre, err error) {
 	logger := logging.LoggerFromContext(ctx)
 
+	update := newPatchUpdate(id, version)
+	setOptional(update, "name", patch.Name)
+	setOptional(update, "description", patch.Description)
+	setOptional(update, "created_at", patch.CreatedAt)
+
 	query := `
 UPDATE books.genres
-SET id          = COALESCE($1, id),
-    name        = COALESCE($2, name),
-    description = COALESCE($3, description),
-    created_at  = COALESCE($4, created_at),
-    updated_at  = NOW()
+` + update.set() + `
 WHERE id = $1
+  AND deleted_at IS NULL
+  AND ($2::timestamp IS NULL OR updated_at = $2::timestamp)
 RETURNING
     id,
     name,
@@ -240,21 +259,15 @@ RETURNING
 		slog.Group(
 			"query",
 			slog.String("statement", database.MinifySQL(query)),
-			"newGenre", newGenre,
+			slog.String("id", id.String()),
+			"patch", patch,
 		),
 	)
 
 	genre = &Genre{}
 
 	logger.Info("performing query")
-	err = m.DB.QueryRowContext(
-		qCtx,
-		query,
-		newGenre.ID,
-		newGenre.Name,
-		newGenre.Description,
-		newGenre.CreatedAt,
-	).Scan(
+	err = queryRowAudited(qCtx, m.DB, query, update.args...).Scan(
 		&genre.ID,
 		&genre.Name,
 		&genre.Description,
@@ -265,7 +278,10 @@ RETURNING
 		switch {
 		case errors.Is(err, sql.ErrNoRows):
 			logger.Info("no record found", "error", err)
-			return nil, ErrRecordNotFound
+			return nil, notFoundOrConflict(ctx, m.Get, id, version)
+		case isUniqueViolation(err):
+			logger.Info("genre name already in use", "error", err)
+			return nil, ErrDuplicateRecord
 		default:
 			logger.Error("unable to perform query", "error", err)
 			return nil, err
@@ -317,8 +333,9 @@ RETURNING id,
 	genre = &Genre{}
 
 	logger.Info("performing query")
-	err = m.DB.QueryRowContext(
+	err = queryRowAudited(
 		qCtx,
+		m.DB,
 		query,
 		newGenre.ID,
 		newGenre.Name,
@@ -340,18 +357,30 @@ RETURNING id,
 	return genre, nil
 }
 
-func (m *GenreModel) Delete(ctx context.Context, id uuid.UUID) (genre *Genre, err error) {
+// Delete moves the genre to the trash, leaving it out of every query but GetAll with
+// Filters.Deleted set until it is restored or purged. If version is set, the genre is only moved if
+// it has not been updated since, and ErrEditConflict is returned otherwise.
+func (m *GenreModel) Delete(
+	ctx context.Context,
+	id uuid.UUID,
+	version *time.Time,
+) (genre *Genre, err error) {
 	logger := logging.LoggerFromContext(ctx)
 
 	query := `
-DELETE FROM books.genres
+UPDATE books.genres
+SET deleted_at = NOW(),
+    updated_at = NOW()
 WHERE id = $1
+  AND deleted_at IS NULL
+  AND ($2::timestamp IS NULL OR updated_at = $2::timestamp)
 RETURNING
 	id,
 	name,
 	description,
 	created_at,
-	updated_at;
+	updated_at,
+	deleted_at;
 `
 	qCtx, cancel := context.WithTimeout(ctx, *m.Timeout)
 	defer cancel()
@@ -367,18 +396,19 @@ RETURNING
 	genre = &Genre{}
 
 	logger.Info("performing query")
-	err = m.DB.QueryRowContext(qCtx, query, id.String()).Scan(
+	err = queryRowAudited(qCtx, m.DB, query, id.String(), version).Scan(
 		&genre.ID,
 		&genre.Name,
 		&genre.Description,
 		&genre.CreatedAt,
 		&genre.UpdatedAt,
+		&genre.DeletedAt,
 	)
 	if err != nil {
 		switch {
 		case errors.Is(err, sql.ErrNoRows):
 			logger.Info("no rows found", "group_id", id.String())
-			return nil, ErrRecordNotFound
+			return nil, notFoundOrConflict(ctx, m.Get, id, version)
 		default:
 			logger.Info("an error occurred while performing query", "error", err)
 			return nil, err
@@ -389,6 +419,106 @@ RETURNING
 	return genre, nil
 }
 
+// Restore takes the genre out of the trash. ErrRecordNotFound is returned if it is not in the
+// trash, and ErrDuplicateRecord if another genre has taken its name since it was deleted.
+func (m *GenreModel) Restore(ctx context.Context, id uuid.UUID) (genre *Genre, err error) {
+	logger := logging.LoggerFromContext(ctx)
+
+	query := `
+UPDATE books.genres
+SET deleted_at = NULL,
+    updated_at = NOW()
+WHERE id = $1
+  AND deleted_at IS NOT NULL
+RETURNING
+	id,
+	name,
+	description,
+	created_at,
+	updated_at,
+	deleted_at;
+`
+	qCtx, cancel := context.WithTimeout(ctx, *m.Timeout)
+	defer cancel()
+
+	logger = logger.With(
+		slog.Group(
+			"query",
+			slog.String("statement", database.MinifySQL(query)),
+			slog.String("id", id.String()),
+		),
+	)
+
+	genre = &Genre{}
+
+	logger.Info("performing query")
+	err = queryRowAudited(qCtx, m.DB, query, id.String()).Scan(
+		&genre.ID,
+		&genre.Name,
+		&genre.Description,
+		&genre.CreatedAt,
+		&genre.UpdatedAt,
+		&genre.DeletedAt,
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			logger.Info("no rows found", "group_id", id.String())
+			return nil, ErrRecordNotFound
+		case isUniqueViolation(err):
+			logger.Info("genre name already in use")
+			return nil, ErrDuplicateRecord
+		default:
+			logger.Info("an error occurred while performing query", "error", err)
+			return nil, err
+		}
+	}
+
+	logger.Info("returning restored genre")
+	return genre, nil
+}
+
+// Purge permanently deletes the genres moved to the trash before the given time, along with
+// their links, and returns the number of genres deleted.
+func (m *GenreModel) Purge(ctx context.Context, before time.Time) (int64, error) {
+	logger := logging.LoggerFromContext(ctx)
+
+	query := `
+DELETE
+FROM books.genres
+WHERE deleted_at < $1::timestamp;
+`
+
+	qCtx, cancel := context.WithTimeout(ctx, *m.Timeout)
+	defer cancel()
+
+	// Timestamps are stored without a time zone, in UTC
+	before = before.UTC()
+
+	logger = logger.With(
+		slog.Group(
+			"query",
+			slog.String("statement", database.MinifySQL(query)),
+			slog.Time("before", before),
+		),
+	)
+
+	logger.Info("performing query")
+	result, err := execAudited(qCtx, m.DB, query, before)
+	if err != nil {
+		logger.Error("an error occurred while performing query", "error", err)
+		return 0, err
+	}
+
+	purged, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	logger.Info("genres purged", "purged", purged)
+	return purged, nil
+}
+
 func (m *GenreModel) GetByBookID(
 	ctx context.Context,
 	id uuid.UUID,
@@ -407,6 +537,7 @@ FROM books.genres g
          INNER JOIN
      books.books b ON b.id = bg.book_id
 WHERE b.id = $1
+  AND g.deleted_at IS NULL
 ORDER BY b.id;
 `
 	qCtx, cancel := context.WithTimeout(ctx, *m.Timeout)
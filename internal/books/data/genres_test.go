@@ -60,7 +60,12 @@ func TestGenresModel(t *testing.T) {
 		newGenreName := "NewNameOfGenres!"
 		newGenre.Name = &newGenreName
 
-		res, err := models.Genres.Update(context.Background(), newGenre)
+		res, err := models.Genres.Update(
+			context.Background(),
+			newGenre.ID,
+			data.GenrePatch{Name: data.OptionalOf(&newGenreName)},
+			nil,
+		)
 		if err != nil {
 			t.Errorf("unable to retrieve result: %v\n", err)
 			return
@@ -89,7 +94,7 @@ func TestGenresModel(t *testing.T) {
 	})
 
 	t.Run("Delete", func(t *testing.T) {
-		_, err := models.Genres.Delete(context.Background(), newGenre.ID)
+		_, err := models.Genres.Delete(context.Background(), newGenre.ID, nil)
 		if err != nil {
 			t.Errorf("unable to delete data: %v\n", err)
 			return
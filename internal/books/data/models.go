@@ -1,13 +1,23 @@
 package data
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
 )
 
 var (
 	ErrRecordNotFound = errors.New("record not found")
+	// ErrDuplicateRecord is returned when a record cannot be written, as another record has the
+	// same unique value
+	ErrDuplicateRecord = errors.New("duplicate record")
+	// ErrEditConflict is returned when a record cannot be changed, as it has been changed since
+	// the version the change was based on
+	ErrEditConflict = errors.New("edit conflict")
 )
 
 var (
@@ -15,23 +25,69 @@ var (
 )
 
 type Models struct {
+	AuditLog    AuditLogModel
 	Authors     AuthorModel
 	Books       BookModel
 	BookAuthors BookAuthorModel
+	BookFiles   BookFileModel
 	BookGenres  BookGenreModel
 	BookSeries  BookSeriesModel
 	Genres      GenreModel
 	Series      SeriesModel
+	db          *sql.DB
 }
 
+// uniqueViolationCode is the PostgreSQL error code of unique constraint violations
+const uniqueViolationCode = "23505"
+
 func NewModels(db *sql.DB, timeout *time.Duration) Models {
 	return Models{
+		AuditLog:    AuditLogModel{DB: db, Timeout: timeout},
 		Authors:     AuthorModel{DB: db, Timeout: timeout},
 		Books:       BookModel{DB: db, Timeout: timeout},
 		BookAuthors: BookAuthorModel{DB: db, Timeout: timeout},
+		BookFiles:   BookFileModel{DB: db, Timeout: timeout},
 		BookGenres:  BookGenreModel{DB: db, Timeout: timeout},
 		BookSeries:  BookSeriesModel{DB: db, Timeout: timeout},
 		Genres:      GenreModel{DB: db, Timeout: timeout},
 		Series:      SeriesModel{DB: db, Timeout: timeout},
+		db:          db,
 	}
 }
+
+// Transaction runs fn in a single audited transaction. Changes made through the models with the
+// context given to fn are part of the transaction, which is committed if fn returns nil and
+// rolled back otherwise. Reads are made outside of the transaction, and do not see its changes
+// until it is committed. Transactions begun within fn join the transaction already begun.
+func (m *Models) Transaction(
+	ctx context.Context,
+	fn func(ctx context.Context, tx *sql.Tx) error,
+) error {
+	return inAudited(ctx, m.db, func(tx *sql.Tx) error {
+		return fn(context.WithValue(ctx, txKey{}, tx), tx)
+	})
+}
+
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == uniqueViolationCode
+}
+
+// notFoundOrConflict returns the error of a change to the record with the given ID that matched
+// no rows. The change conflicts if it was based on a version of a record that still exists, and
+// the record was not found otherwise.
+func notFoundOrConflict[T any](
+	ctx context.Context,
+	get func(context.Context, uuid.UUID) (T, error),
+	id uuid.UUID,
+	version *time.Time,
+) error {
+	if version == nil {
+		return ErrRecordNotFound
+	}
+	if _, err := get(ctx, id); err != nil {
+		return ErrRecordNotFound
+	}
+
+	return ErrEditConflict
+}
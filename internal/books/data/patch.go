@@ -0,0 +1,191 @@
+package data
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/r3d5un/Bookshelf/internal/validator"
+)
+
+// Optional is a field of a JSON Merge Patch (RFC 7396). A field left out of the patch is not
+// Set, and leaves the column unchanged. A field set to null is Set with a nil Value, and clears
+// the column.
+type Optional[T any] struct {
+	Set   bool
+	Value *T
+}
+
+// OptionalOf returns an Optional setting the value, or leaving the field unchanged if the value
+// is nil.
+func OptionalOf[T any](value *T) Optional[T] {
+	if value == nil {
+		return Optional[T]{}
+	}
+	return Optional[T]{Set: true, Value: value}
+}
+
+func (o *Optional[T]) UnmarshalJSON(b []byte) error {
+	o.Set = true
+	if bytes.Equal(bytes.TrimSpace(b), []byte("null")) {
+		o.Value = nil
+		return nil
+	}
+
+	var value T
+	if err := json.Unmarshal(b, &value); err != nil {
+		return err
+	}
+	o.Value = &value
+
+	return nil
+}
+
+func (o Optional[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(o.Value)
+}
+
+// isNull reports whether the patch sets the field to null.
+func (o Optional[T]) isNull() bool {
+	return o.Set && o.Value == nil
+}
+
+// patchField is a field of a patch, as encoded by marshalPatch.
+type patchField interface {
+	json.Marshaler
+	isSet() bool
+}
+
+func (o Optional[T]) isSet() bool {
+	return o.Set
+}
+
+// marshalPatch encodes the set fields of a patch by their JSON name, leaving out the rest.
+func marshalPatch(fields map[string]patchField) ([]byte, error) {
+	set := make(map[string]json.Marshaler, len(fields))
+	for name, field := range fields {
+		if field.isSet() {
+			set[name] = field
+		}
+	}
+	return json.Marshal(set)
+}
+
+// BookPatch is a JSON Merge Patch of a book.
+type BookPatch struct {
+	Title       Optional[string]    `json:"title"`
+	Description Optional[string]    `json:"description"`
+	Published   Optional[time.Time] `json:"published"`
+	CreatedAt   Optional[time.Time] `json:"createdAt"`
+}
+
+func (p BookPatch) MarshalJSON() ([]byte, error) {
+	return marshalPatch(map[string]patchField{
+		"title":       p.Title,
+		"description": p.Description,
+		"published":   p.Published,
+		"createdAt":   p.CreatedAt,
+	})
+}
+
+func ValidateBookPatch(v *validator.Validator, p BookPatch) {
+	v.Check(!p.Title.isNull(), "title", "must not be null")
+	v.Check(p.Title.Value == nil || *p.Title.Value != "", "title", "must not be empty")
+	v.Check(!p.CreatedAt.isNull(), "createdAt", "must not be null")
+}
+
+// AuthorPatch is a JSON Merge Patch of an author.
+type AuthorPatch struct {
+	Name        Optional[string]    `json:"name"`
+	Description Optional[string]    `json:"description"`
+	Website     Optional[string]    `json:"website"`
+	CreatedAt   Optional[time.Time] `json:"createdAt"`
+}
+
+func (p AuthorPatch) MarshalJSON() ([]byte, error) {
+	return marshalPatch(map[string]patchField{
+		"name":        p.Name,
+		"description": p.Description,
+		"website":     p.Website,
+		"createdAt":   p.CreatedAt,
+	})
+}
+
+func ValidateAuthorPatch(v *validator.Validator, p AuthorPatch) {
+	v.Check(!p.Name.isNull(), "name", "must not be null")
+	v.Check(p.Name.Value == nil || *p.Name.Value != "", "name", "must not be empty")
+	if p.Website.Value != nil && *p.Website.Value != "" {
+		v.CheckURL(*p.Website.Value, "website")
+	}
+	v.Check(!p.CreatedAt.isNull(), "createdAt", "must not be null")
+}
+
+// SeriesPatch is a JSON Merge Patch of a series.
+type SeriesPatch struct {
+	Name        Optional[string]    `json:"name"`
+	Description Optional[string]    `json:"description"`
+	CreatedAt   Optional[time.Time] `json:"created_at"`
+}
+
+func (p SeriesPatch) MarshalJSON() ([]byte, error) {
+	return marshalPatch(map[string]patchField{
+		"name":        p.Name,
+		"description": p.Description,
+		"created_at":  p.CreatedAt,
+	})
+}
+
+func ValidateSeriesPatch(v *validator.Validator, p SeriesPatch) {
+	v.Check(!p.Name.isNull(), "name", "must not be null")
+	v.Check(p.Name.Value == nil || *p.Name.Value != "", "name", "must not be empty")
+	v.Check(!p.CreatedAt.isNull(), "created_at", "must not be null")
+}
+
+// GenrePatch is a JSON Merge Patch of a genre.
+type GenrePatch struct {
+	Name        Optional[string]    `json:"name"`
+	Description Optional[string]    `json:"description"`
+	CreatedAt   Optional[time.Time] `json:"created_at"`
+}
+
+func (p GenrePatch) MarshalJSON() ([]byte, error) {
+	return marshalPatch(map[string]patchField{
+		"name":        p.Name,
+		"description": p.Description,
+		"created_at":  p.CreatedAt,
+	})
+}
+
+func ValidateGenrePatch(v *validator.Validator, p GenrePatch) {
+	v.Check(!p.Name.isNull(), "name", "must not be null")
+	v.Check(p.Name.Value == nil || *p.Name.Value != "", "name", "must not be empty")
+	v.Check(!p.CreatedAt.isNull(), "created_at", "must not be null")
+}
+
+// patchUpdate collects the assignments of an UPDATE applying a patch. The first arguments are
+// given when it is created, and the values of the set fields follow them.
+type patchUpdate struct {
+	assignments []string
+	args        []any
+}
+
+func newPatchUpdate(args ...any) *patchUpdate {
+	return &patchUpdate{args: args}
+}
+
+// setOptional assigns the value of the field to the column, if the field is set.
+func setOptional[T any](u *patchUpdate, column string, field Optional[T]) {
+	if !field.Set {
+		return
+	}
+	u.args = append(u.args, field.Value)
+	u.assignments = append(u.assignments, fmt.Sprintf("%s = $%d", column, len(u.args)))
+}
+
+// set returns the SET clause of the UPDATE, which always sets updated_at, so that every applied
+// patch gives the record a new version.
+func (u *patchUpdate) set() string {
+	return "SET " + strings.Join(append(u.assignments, "updated_at = NOW()"), ",\n    ")
+}
@@ -18,6 +18,7 @@ type Series struct {
 	Description *string    `json:"description"`
 	CreatedAt   *time.Time `json:"created_at"`
 	UpdatedAt   *time.Time `json:"updated_at"`
+	DeletedAt   *time.Time `json:"deleted_at,omitempty"`
 }
 
 type SeriesModel struct {
@@ -35,7 +36,8 @@ SELECT id,
        created_at,
        updated_at
 FROM books.series
-WHERE id = $1;
+WHERE id = $1
+  AND deleted_at IS NULL;
 `
 
 	qCtx, cancel := context.WithTimeout(ctx, *m.Timeout)
@@ -85,7 +87,8 @@ SELECT id,
        name,
        description,
        created_at,
-       updated_at
+       updated_at,
+       deleted_at
 FROM books.series
 WHERE ($1::uuid IS NULL OR id = $1::uuid)
   AND ($2::text = '' OR name LIKE '%' || $2::text || '%')
@@ -94,6 +97,7 @@ WHERE ($1::uuid IS NULL OR id = $1::uuid)
   AND ($5::timestamp IS NULL OR created_at < $5::timestamp)
   AND ($6::timestamp IS NULL OR updated_at >= $6::timestamp)
   AND ($7::timestamp IS NULL OR updated_at < $7::timestamp)
+  AND (deleted_at IS NOT NULL) = COALESCE($10::boolean, FALSE)
 ` + database.CreateOrderByClause(filters.OrderBy) + `
 OFFSET $8 FETCH NEXT $9 ROWS ONLY;
 `
@@ -124,6 +128,7 @@ OFFSET $8 FETCH NEXT $9 ROWS ONLY;
 		filters.UpdatedAtTo,
 		filters.offset(),
 		filters.limit(),
+		filters.Deleted,
 	)
 	if err != nil {
 		logger.Error("error performing query", "error", err)
@@ -140,6 +145,7 @@ OFFSET $8 FETCH NEXT $9 ROWS ONLY;
 			&s.Description,
 			&s.CreatedAt,
 			&s.UpdatedAt,
+			&s.DeletedAt,
 		)
 		if err != nil {
 			return nil, nil, err
@@ -192,8 +198,9 @@ RETURNING
 	series = &Series{}
 
 	logger.Info("performing query")
-	err = m.DB.QueryRowContext(
+	err = queryRowAudited(
 		qCtx,
+		m.DB,
 		query,
 		newSeries.ID,
 		newSeries.Name,
@@ -214,16 +221,28 @@ RETURNING
 	return series, nil
 }
 
-func (m *SeriesModel) Update(ctx context.Context, newSeries Series) (series *Series, err error) {
+// Update applies the patch to the series, setting the columns of the fields set by the patch and
+// leaving the rest unchanged. If version is set, the series is only changed if it has not been
+// updated since, and ErrEditConflict is returned otherwise.
+func (m *SeriesModel) Update(
+	ctx context.Context,
+	id uuid.UUID,
+	patch SeriesPatch,
+	version *time.Time,
+) (series *Series, err error) {
 	logger := logging.LoggerFromContext(ctx)
 
+	update := newPatchUpdate(id, version)
+	setOptional(update, "name", patch.Name)
+	setOptional(update, "description", patch.Description)
+	setOptional(update, "created_at", patch.CreatedAt)
+
 	query := `
 UPDATE books.series
-SET name        = COALESCE($2, name),
-    description = COALESCE($3, description),
-    created_at  = COALESCE($4, created_at),
-    updated_at  = NOW()
+` + update.set() + `
 WHERE id = $1
+  AND deleted_at IS NULL
+  AND ($2::timestamp IS NULL OR updated_at = $2::timestamp)
 RETURNING
     id,
     name,
@@ -239,21 +258,15 @@ RETURNING
 		slog.Group(
 			"query",
 			slog.String("statement", database.MinifySQL(query)),
-			"newSeries", newSeries,
+			slog.String("id", id.String()),
+			"patch", patch,
 		),
 	)
 
 	series = &Series{}
 
 	logger.Info("performing query")
-	err = m.DB.QueryRowContext(
-		qCtx,
-		query,
-		newSeries.ID,
-		newSeries.Name,
-		newSeries.Description,
-		newSeries.CreatedAt,
-	).Scan(
+	err = queryRowAudited(qCtx, m.DB, query, update.args...).Scan(
 		&series.ID,
 		&series.Name,
 		&series.Description,
@@ -264,7 +277,7 @@ RETURNING
 		switch {
 		case errors.Is(err, sql.ErrNoRows):
 			logger.Info("no record found", "error", err)
-			return nil, ErrRecordNotFound
+			return nil, notFoundOrConflict(ctx, m.Get, id, version)
 		default:
 			logger.Error("unable to perform query", "error", err)
 			return nil, err
@@ -316,8 +329,9 @@ RETURNING id,
 	defer cancel()
 
 	logger.Info("performing query")
-	err = m.DB.QueryRowContext(
+	err = queryRowAudited(
 		qCtx,
+		m.DB,
 		query,
 		newSeries.ID,
 		newSeries.Name,
@@ -339,18 +353,30 @@ RETURNING id,
 	return series, nil
 }
 
-func (m *SeriesModel) Delete(ctx context.Context, id uuid.UUID) (series *Series, err error) {
+// Delete moves the series to the trash, leaving it out of every query but GetAll with
+// Filters.Deleted set until it is restored or purged. If version is set, the series is only moved
+// if it has not been updated since, and ErrEditConflict is returned otherwise.
+func (m *SeriesModel) Delete(
+	ctx context.Context,
+	id uuid.UUID,
+	version *time.Time,
+) (series *Series, err error) {
 	logger := logging.LoggerFromContext(ctx)
 
 	query := `
-DELETE FROM books.series
+UPDATE books.series
+SET deleted_at = NOW(),
+    updated_at = NOW()
 WHERE id = $1
+  AND deleted_at IS NULL
+  AND ($2::timestamp IS NULL OR updated_at = $2::timestamp)
 RETURNING
 	id,
 	name,
 	description,
 	created_at,
-	updated_at;
+	updated_at,
+	deleted_at;
 `
 	qCtx, cancel := context.WithTimeout(ctx, *m.Timeout)
 	defer cancel()
@@ -366,18 +392,19 @@ RETURNING
 	series = &Series{}
 
 	logger.Info("performing query")
-	err = m.DB.QueryRowContext(qCtx, query, id.String()).Scan(
+	err = queryRowAudited(qCtx, m.DB, query, id.String(), version).Scan(
 		&series.ID,
 		&series.Name,
 		&series.Description,
 		&series.CreatedAt,
 		&series.UpdatedAt,
+		&series.DeletedAt,
 	)
 	if err != nil {
 		switch {
 		case errors.Is(err, sql.ErrNoRows):
 			logger.Info("no rows found", "group_id", id.String())
-			return nil, ErrRecordNotFound
+			return nil, notFoundOrConflict(ctx, m.Get, id, version)
 		default:
 			logger.Info("an error occurred while performing query", "error", err)
 			return nil, err
@@ -388,6 +415,103 @@ RETURNING
 	return series, nil
 }
 
+// Restore takes the series out of the trash. ErrRecordNotFound is returned if it is not in the
+// trash.
+func (m *SeriesModel) Restore(ctx context.Context, id uuid.UUID) (series *Series, err error) {
+	logger := logging.LoggerFromContext(ctx)
+
+	query := `
+UPDATE books.series
+SET deleted_at = NULL,
+    updated_at = NOW()
+WHERE id = $1
+  AND deleted_at IS NOT NULL
+RETURNING
+	id,
+	name,
+	description,
+	created_at,
+	updated_at,
+	deleted_at;
+`
+	qCtx, cancel := context.WithTimeout(ctx, *m.Timeout)
+	defer cancel()
+
+	logger = logger.With(
+		slog.Group(
+			"query",
+			slog.String("statement", database.MinifySQL(query)),
+			slog.String("id", id.String()),
+		),
+	)
+
+	series = &Series{}
+
+	logger.Info("performing query")
+	err = queryRowAudited(qCtx, m.DB, query, id.String()).Scan(
+		&series.ID,
+		&series.Name,
+		&series.Description,
+		&series.CreatedAt,
+		&series.UpdatedAt,
+		&series.DeletedAt,
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			logger.Info("no rows found", "group_id", id.String())
+			return nil, ErrRecordNotFound
+		default:
+			logger.Info("an error occurred while performing query", "error", err)
+			return nil, err
+		}
+	}
+
+	logger.Info("returning restored series")
+	return series, nil
+}
+
+// Purge permanently deletes the series moved to the trash before the given time, along with
+// their links, and returns the number of series deleted.
+func (m *SeriesModel) Purge(ctx context.Context, before time.Time) (int64, error) {
+	logger := logging.LoggerFromContext(ctx)
+
+	query := `
+DELETE
+FROM books.series
+WHERE deleted_at < $1::timestamp;
+`
+
+	qCtx, cancel := context.WithTimeout(ctx, *m.Timeout)
+	defer cancel()
+
+	// Timestamps are stored without a time zone, in UTC
+	before = before.UTC()
+
+	logger = logger.With(
+		slog.Group(
+			"query",
+			slog.String("statement", database.MinifySQL(query)),
+			slog.Time("before", before),
+		),
+	)
+
+	logger.Info("performing query")
+	result, err := execAudited(qCtx, m.DB, query, before)
+	if err != nil {
+		logger.Error("an error occurred while performing query", "error", err)
+		return 0, err
+	}
+
+	purged, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	logger.Info("series purged", "purged", purged)
+	return purged, nil
+}
+
 func (m *SeriesModel) GetByBookID(
 	ctx context.Context,
 	id uuid.UUID,
@@ -406,6 +530,7 @@ FROM books.series s
          INNER JOIN
      books.books b ON b.id = bs.book_id
 WHERE b.id = $1
+  AND s.deleted_at IS NULL
 ORDER BY bs.series_order;
 `
 	qCtx, cancel := context.WithTimeout(ctx, *m.Timeout)
@@ -60,7 +60,12 @@ func TestSeriesModel(t *testing.T) {
 		newSeriesName := "NewNameOfSeries!"
 		newSeries.Name = &newSeriesName
 
-		res, err := models.Series.Update(context.Background(), newSeries)
+		res, err := models.Series.Update(
+			context.Background(),
+			newSeries.ID,
+			data.SeriesPatch{Name: data.OptionalOf(&newSeriesName)},
+			nil,
+		)
 		if err != nil {
 			t.Errorf("unable to retrieve result: %v\n", err)
 			return
@@ -89,7 +94,7 @@ func TestSeriesModel(t *testing.T) {
 	})
 
 	t.Run("Delete", func(t *testing.T) {
-		_, err := models.Series.Delete(context.Background(), newSeries.ID)
+		_, err := models.Series.Delete(context.Background(), newSeries.ID, nil)
 		if err != nil {
 			t.Errorf("unable to delete data: %v\n", err)
 			return
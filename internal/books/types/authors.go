@@ -148,15 +148,27 @@ func ReadAllAuthors(
 	return authors, nil
 }
 
-func UpdateAuthor(ctx context.Context, models *data.Models, newAuthorData Author) (*Author, error) {
-	authorRecord := data.Author{
-		ID:          newAuthorData.ID,
-		Name:        newAuthorData.Name,
-		Description: newAuthorData.Description,
-		Website:     newAuthorData.Website,
+// Patch returns the patch setting the fields of the author that are not nil, leaving the rest
+// unchanged.
+func (a Author) Patch() data.AuthorPatch {
+	return data.AuthorPatch{
+		Name:        data.OptionalOf(a.Name),
+		Description: data.OptionalOf(a.Description),
+		Website:     data.OptionalOf(a.Website),
+		CreatedAt:   data.OptionalOf(a.CreatedAt),
 	}
+}
 
-	updatedAuthor, err := models.Authors.Update(ctx, authorRecord)
+// UpdateAuthor applies the patch to the author. If version is set, the author is only changed if it
+// has not been updated since, and data.ErrEditConflict is returned otherwise.
+func UpdateAuthor(
+	ctx context.Context,
+	models *data.Models,
+	id uuid.UUID,
+	patch data.AuthorPatch,
+	version *time.Time,
+) (*Author, error) {
+	updatedAuthor, err := models.Authors.Update(ctx, id, patch, version)
 	if err != nil {
 		return nil, err
 	}
@@ -169,8 +181,15 @@ func UpdateAuthor(ctx context.Context, models *data.Models, newAuthorData Author
 	return updatedAuthorData, nil
 }
 
-func DeleteAuthor(ctx context.Context, models *data.Models, id uuid.UUID) error {
-	_, err := models.Authors.Delete(ctx, id)
+// DeleteAuthor moves the author to the trash. If version is set, the author is only moved if it has not
+// been updated since, and data.ErrEditConflict is returned otherwise.
+func DeleteAuthor(
+	ctx context.Context,
+	models *data.Models,
+	id uuid.UUID,
+	version *time.Time,
+) error {
+	_, err := models.Authors.Delete(ctx, id, version)
 	if err != nil {
 		return err
 	}
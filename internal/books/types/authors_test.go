@@ -63,7 +63,13 @@ func TestComplexAuthorTypes(t *testing.T) {
 			Description: &newDescription,
 		}
 
-		_, err := types.UpdateAuthor(context.Background(), models, newAuthorData)
+		_, err := types.UpdateAuthor(
+			context.Background(),
+			models,
+			*id,
+			newAuthorData.Patch(),
+			nil,
+		)
 		if err != nil {
 			t.Errorf("unable to update author: %s\n", err)
 			return
@@ -71,7 +77,7 @@ func TestComplexAuthorTypes(t *testing.T) {
 	})
 
 	t.Run("TestDeleteAuthor", func(t *testing.T) {
-		if err := types.DeleteAuthor(context.Background(), models, *id); err != nil {
+		if err := types.DeleteAuthor(context.Background(), models, *id, nil); err != nil {
 			t.Errorf("unable to delete author: %s\n", err)
 			return
 		}
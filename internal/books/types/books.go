@@ -2,11 +2,15 @@ package types
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"sync"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/r3d5un/Bookshelf/internal/books/data"
+	"github.com/r3d5un/Bookshelf/internal/events"
+	"github.com/r3d5un/Bookshelf/internal/logging"
 )
 
 type Book struct {
@@ -179,96 +183,228 @@ func getBookGenreData(
 	genreCh <- genreDataResult{genres: data, err: err}
 }
 
-func CreateBook(ctx context.Context, models *data.Models, newBook Book) (*uuid.UUID, error) {
-	insertedBook, err := models.Books.Insert(ctx, data.Book{
-		ID:          uuid.New(),
-		Title:       *newBook.Title,
-		Description: newBook.Description,
-		Published:   newBook.Published,
-		CreatedAt:   newBook.CreatedAt,
-		UpdatedAt:   newBook.UpdatedAt,
+// CreateBook inserts the book along with its authors, genres and series, and publishes
+// BookCreated in the same transaction. The publisher may be nil.
+func CreateBook(
+	ctx context.Context,
+	models *data.Models,
+	publisher events.Publisher,
+	newBook Book,
+) (*uuid.UUID, error) {
+	var insertedBook *data.Book
+	err := changeAndPublish(ctx, models, publisher, func(ctx context.Context) (events.Event, error) {
+		var err error
+		insertedBook, err = models.Books.Insert(ctx, data.Book{
+			ID:          uuid.New(),
+			Title:       *newBook.Title,
+			Description: newBook.Description,
+			Published:   newBook.Published,
+			CreatedAt:   newBook.CreatedAt,
+			UpdatedAt:   newBook.UpdatedAt,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		// Links are inserted one at a time, as the statements of a transaction share a connection
+		for _, genre := range newBook.Genres {
+			if _, err := models.BookGenres.Insert(ctx, insertedBook.ID, genre.ID); err != nil {
+				return nil, err
+			}
+		}
+		for _, series := range newBook.BookSeries {
+			_, err := models.BookSeries.Insert(
+				ctx, insertedBook.ID, series.SeriesID, series.SeriesOrder,
+			)
+			if err != nil {
+				return nil, err
+			}
+		}
+		for _, author := range newBook.Authors {
+			if _, err := models.BookAuthors.Insert(ctx, insertedBook.ID, author.ID); err != nil {
+				return nil, err
+			}
+		}
+
+		newBook.ID = &insertedBook.ID
+		newBook.CreatedAt = insertedBook.CreatedAt
+		newBook.UpdatedAt = insertedBook.UpdatedAt
+		return BookCreated{Book: newBook}, nil
 	})
 	if err != nil {
 		return nil, err
 	}
 
-	var wg sync.WaitGroup
-	errChanLength := len(newBook.Genres) + len(newBook.Series) + len(newBook.Authors)
-	errCh := make(chan error, errChanLength)
+	return &insertedBook.ID, nil
+}
 
-	for _, genre := range newBook.Genres {
-		wg.Add(1)
-		go func(genre *data.Genre) {
-			defer wg.Done()
-			if _, err := models.BookGenres.Insert(ctx, insertedBook.ID, genre.ID); err != nil {
-				errCh <- err
-			}
-		}(genre)
+// Patch returns the patch setting the fields of the book that are not nil, leaving the rest
+// unchanged.
+func (b Book) Patch() data.BookPatch {
+	return data.BookPatch{
+		Title:       data.OptionalOf(b.Title),
+		Description: data.OptionalOf(b.Description),
+		Published:   data.OptionalOf(b.Published),
+		CreatedAt:   data.OptionalOf(b.CreatedAt),
 	}
+}
 
-	for _, series := range newBook.BookSeries {
-		wg.Add(1)
-		go func(series *data.BookSeries) {
-			defer wg.Done()
-			if _, err := models.BookSeries.Insert(ctx, insertedBook.ID, series.SeriesID, series.SeriesOrder); err != nil {
-				errCh <- err
-			}
-		}(series)
-	}
+// UpdateBook applies the patch to the book, and publishes BookUpdated with the updated book in
+// the same transaction. The publisher may be nil. If version is set, the book is only changed if
+// it has not been updated since, and data.ErrEditConflict is returned otherwise.
+func UpdateBook(
+	ctx context.Context,
+	models *data.Models,
+	publisher events.Publisher,
+	id uuid.UUID,
+	patch data.BookPatch,
+	version *time.Time,
+) (*Book, error) {
+	var updatedBook *Book
+	err := changeAndPublish(ctx, models, publisher, func(ctx context.Context) (events.Event, error) {
+		bookRow, err := models.Books.Update(ctx, id, patch, version)
+		if err != nil {
+			return nil, err
+		}
 
-	for _, author := range newBook.Authors {
-		wg.Add(1)
-		go func(author *data.Author) {
-			defer wg.Done()
-			if _, err := models.BookAuthors.Insert(ctx, insertedBook.ID, author.ID); err != nil {
-				errCh <- err
-			}
-		}(author)
+		updatedBook, err = bookWithRelations(ctx, models, bookRow)
+		if err != nil {
+			return nil, err
+		}
+
+		return BookUpdated{Book: *updatedBook}, nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	go func() {
-		wg.Wait()
-		close(errCh)
-	}()
+	return updatedBook, nil
+}
 
-	for err := range errCh {
+// DeleteBook moves the book to the trash, and publishes BookDeleted with the ID of the book in
+// the same transaction. The publisher may be nil. If version is set, the book is only moved if
+// it has not been updated since, and data.ErrEditConflict is returned otherwise.
+func DeleteBook(
+	ctx context.Context,
+	models *data.Models,
+	publisher events.Publisher,
+	id uuid.UUID,
+	version *time.Time,
+) error {
+	return changeAndPublish(ctx, models, publisher, func(ctx context.Context) (events.Event, error) {
+		if _, err := models.Books.Delete(ctx, id, version); err != nil {
+			return nil, err
+		}
+
+		return BookDeleted{ID: id}, nil
+	})
+}
+
+// RestoreBook takes the book out of the trash, and publishes BookRestored with the restored book
+// in the same transaction. The publisher may be nil.
+func RestoreBook(
+	ctx context.Context,
+	models *data.Models,
+	publisher events.Publisher,
+	id uuid.UUID,
+) (*Book, error) {
+	var restoredBook *Book
+	err := changeAndPublish(ctx, models, publisher, func(ctx context.Context) (events.Event, error) {
+		bookRow, err := models.Books.Restore(ctx, id)
 		if err != nil {
 			return nil, err
 		}
+
+		restoredBook, err = bookWithRelations(ctx, models, bookRow)
+		if err != nil {
+			return nil, err
+		}
+
+		return BookRestored{Book: *restoredBook}, nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	return &insertedBook.ID, nil
+	return restoredBook, nil
 }
 
-func UpdateBook(ctx context.Context, models *data.Models, newBookData Book) (*Book, error) {
-	bookRecord := data.Book{
-		ID:          *newBookData.ID,
-		Title:       *newBookData.Title,
-		Description: newBookData.Description,
-		Published:   newBookData.Published,
-		CreatedAt:   newBookData.CreatedAt,
-		UpdatedAt:   newBookData.UpdatedAt,
+// bookWithRelations builds the book from the given row, along with its authors, genres and
+// series. The relations are read as committed, so that the book can be built from a row changed
+// in a transaction that is still open.
+func bookWithRelations(ctx context.Context, models *data.Models, bookRow *data.Book) (*Book, error) {
+	authors, _, err := models.Authors.GetByBookID(ctx, bookRow.ID)
+	if err != nil {
+		return nil, err
 	}
-
-	updatedBook, err := models.Books.Update(ctx, bookRecord)
+	series, _, err := models.Series.GetByBookID(ctx, bookRow.ID)
 	if err != nil {
 		return nil, err
 	}
-
-	updatedBookData, err := ReadBook(ctx, models, updatedBook.ID)
+	genres, _, err := models.Genres.GetByBookID(ctx, bookRow.ID)
 	if err != nil {
 		return nil, err
 	}
 
-	return updatedBookData, nil
+	return &Book{
+		ID:          &bookRow.ID,
+		Title:       &bookRow.Title,
+		Description: bookRow.Description,
+		Published:   bookRow.Published,
+		CreatedAt:   bookRow.CreatedAt,
+		UpdatedAt:   bookRow.UpdatedAt,
+		Authors:     authors,
+		Series:      series,
+		Genres:      genres,
+	}, nil
 }
 
-func DeleteBook(ctx context.Context, models *data.Models, id uuid.UUID) error {
-	_, err := models.Books.Delete(ctx, id)
+// changeAndPublish makes the change in a transaction, and publishes the event describing it. If
+// the publisher has an outbox, the event is stored in it as part of the transaction, so that the
+// event is stored if and only if the change is committed. Otherwise the event is delivered once
+// the change has been committed, and failures to deliver it are logged rather than returned.
+func changeAndPublish(
+	ctx context.Context,
+	models *data.Models,
+	publisher events.Publisher,
+	change func(ctx context.Context) (events.Event, error),
+) error {
+	var event events.Event
+	stored := false
+
+	err := models.Transaction(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		var err error
+		event, err = change(ctx)
+		if err != nil {
+			return err
+		}
+		if publisher == nil {
+			return nil
+		}
+
+		err = publisher.PublishTx(ctx, tx, event)
+		switch {
+		case err == nil:
+			stored = true
+			return nil
+		case errors.Is(err, events.ErrNoOutbox):
+			return nil
+		default:
+			return err
+		}
+	})
 	if err != nil {
 		return err
 	}
 
+	if publisher != nil && !stored {
+		if err := publisher.Publish(ctx, event); err != nil {
+			logging.LoggerFromContext(ctx).Error(
+				"unable to publish event", "event", event.EventName(), "error", err,
+			)
+		}
+	}
+
 	return nil
 }
 
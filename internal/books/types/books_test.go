@@ -90,7 +90,7 @@ func TestComplexBookTypes(t *testing.T) {
 	var insertedBook *data.Book
 
 	t.Run("TestCreateBook", func(t *testing.T) {
-		_, err := types.CreateBook(context.Background(), models, book)
+		_, err := types.CreateBook(context.Background(), models, nil, book)
 		if err != nil {
 			t.Errorf("error occurred when registering new book: %s\n", err)
 			return
@@ -147,7 +147,7 @@ func TestComplexBookTypes(t *testing.T) {
 	})
 
 	t.Run("TestDeleteBook", func(t *testing.T) {
-		if err := types.DeleteBook(context.Background(), models, insertedBook.ID); err != nil {
+		if err := types.DeleteBook(context.Background(), models, nil, insertedBook.ID, nil); err != nil {
 			t.Errorf("unable to delete book: %s\n", err)
 			return
 		}
@@ -0,0 +1,31 @@
+package types
+
+import "github.com/google/uuid"
+
+// BookCreated is published once a book has been stored along with its authors, genres and series.
+type BookCreated struct {
+	Book Book `json:"book"`
+}
+
+func (BookCreated) EventName() string { return "book.created" }
+
+// BookUpdated is published with the book as it is after the update.
+type BookUpdated struct {
+	Book Book `json:"book"`
+}
+
+func (BookUpdated) EventName() string { return "book.updated" }
+
+// BookDeleted is published with the ID of the deleted book.
+type BookDeleted struct {
+	ID uuid.UUID `json:"id"`
+}
+
+func (BookDeleted) EventName() string { return "book.deleted" }
+
+// BookRestored is published with the book as it is once taken out of the trash.
+type BookRestored struct {
+	Book Book `json:"book"`
+}
+
+func (BookRestored) EventName() string { return "book.restored" }
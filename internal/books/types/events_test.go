@@ -0,0 +1,113 @@
+package types_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/r3d5un/Bookshelf/internal/books/data"
+	"github.com/r3d5un/Bookshelf/internal/books/types"
+	"github.com/r3d5un/Bookshelf/internal/events"
+)
+
+// failingPublisher stores events in the outbox of the bus, and then fails, so that the change
+// the event describes is rolled back after the event has been stored.
+type failingPublisher struct {
+	*events.Bus
+}
+
+func (p failingPublisher) PublishTx(ctx context.Context, tx *sql.Tx, event events.Event) error {
+	if err := p.Bus.PublishTx(ctx, tx, event); err != nil {
+		return err
+	}
+
+	return errors.New("unable to publish event")
+}
+
+func TestPublishInTransaction(t *testing.T) {
+	timeout := 5 * time.Second
+	outbox := events.NewOutbox(db, &timeout)
+	bus := events.NewBus(uuid.New())
+	if err := bus.UseOutbox(context.Background(), outbox); err != nil {
+		t.Errorf("unable to use outbox: %s\n", err)
+		return
+	}
+
+	timestamp := time.Now()
+
+	t.Run("Committed", func(t *testing.T) {
+		lastID, err := outbox.LastID(context.Background())
+		if err != nil {
+			t.Errorf("unable to read last event ID: %s\n", err)
+			return
+		}
+
+		title := "TestCommittedBookTitle"
+		_, err = types.CreateBook(context.Background(), models, bus, types.Book{
+			Title:     &title,
+			CreatedAt: &timestamp,
+			UpdatedAt: &timestamp,
+		})
+		if err != nil {
+			t.Errorf("unable to create book: %s\n", err)
+			return
+		}
+
+		stored, err := outbox.After(context.Background(), lastID, 10)
+		if err != nil {
+			t.Errorf("unable to read stored events: %s\n", err)
+			return
+		}
+		name := types.BookCreated{}.EventName()
+		if len(stored) != 1 || stored[0].Name != name {
+			t.Errorf("expected a single %s event, got %+v\n", name, stored)
+			return
+		}
+	})
+
+	t.Run("RolledBack", func(t *testing.T) {
+		lastID, err := outbox.LastID(context.Background())
+		if err != nil {
+			t.Errorf("unable to read last event ID: %s\n", err)
+			return
+		}
+
+		id := uuid.New()
+		title := "TestRolledBackBookTitle"
+		bookRecord, err := models.Books.Insert(context.Background(), data.Book{
+			ID:        id,
+			Title:     title,
+			CreatedAt: &timestamp,
+			UpdatedAt: &timestamp,
+		})
+		if err != nil {
+			t.Errorf("unable to insert book: %s\n", err)
+			return
+		}
+
+		err = types.DeleteBook(
+			context.Background(), models, failingPublisher{Bus: bus}, bookRecord.ID, nil,
+		)
+		if err == nil {
+			t.Error("expected the failing publisher to fail the change")
+			return
+		}
+
+		stored, err := outbox.After(context.Background(), lastID, 10)
+		if err != nil {
+			t.Errorf("unable to read stored events: %s\n", err)
+			return
+		}
+		if len(stored) != 0 {
+			t.Errorf("expected no events to be stored, got %+v\n", stored)
+			return
+		}
+		if _, err := models.Books.Get(context.Background(), bookRecord.ID); err != nil {
+			t.Errorf("expected the book to be left out of the trash, got %s\n", err)
+			return
+		}
+	})
+}
@@ -0,0 +1,82 @@
+package types
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/r3d5un/Bookshelf/internal/books/data"
+)
+
+// MaxFileSize is the size in bytes of the largest file that can be attached to a book.
+const MaxFileSize int64 = 64 << 20
+
+// AttachBookFile attaches the file with the given name and content to the book. The content type
+// is detected from the content if empty. ErrRecordNotFound is returned if the book does not exist.
+func AttachBookFile(
+	ctx context.Context,
+	models *data.Models,
+	bookID uuid.UUID,
+	name string,
+	contentType string,
+	content []byte,
+) (*data.BookFile, error) {
+	if contentType == "" {
+		contentType = http.DetectContentType(content)
+	}
+	checksum := sha256.Sum256(content)
+
+	return models.BookFiles.Insert(ctx, data.BookFile{
+		BookID:      bookID,
+		Name:        name,
+		ContentType: contentType,
+		Size:        int64(len(content)),
+		Checksum:    hex.EncodeToString(checksum[:]),
+	}, content)
+}
+
+// ReadBookFiles returns the files attached to the book, oldest first. ErrRecordNotFound is
+// returned if the book does not exist.
+func ReadBookFiles(
+	ctx context.Context,
+	models *data.Models,
+	bookID uuid.UUID,
+) ([]*data.BookFile, error) {
+	if _, err := models.Books.Get(ctx, bookID); err != nil {
+		return nil, err
+	}
+
+	return models.BookFiles.GetByBookID(ctx, bookID)
+}
+
+// ReadBookFile returns the file with the given ID, without its content.
+func ReadBookFile(ctx context.Context, models *data.Models, id uuid.UUID) (*data.BookFile, error) {
+	return models.BookFiles.Get(ctx, id)
+}
+
+// ReadBookFileContent returns the file with the given ID along with its content.
+func ReadBookFileContent(
+	ctx context.Context,
+	models *data.Models,
+	id uuid.UUID,
+) (*data.BookFile, []byte, error) {
+	file, err := models.BookFiles.Get(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	content, err := models.BookFiles.GetContent(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return file, content, nil
+}
+
+// DeleteBookFile removes the file with the given ID from its book.
+func DeleteBookFile(ctx context.Context, models *data.Models, id uuid.UUID) error {
+	_, err := models.BookFiles.Delete(ctx, id)
+	return err
+}
@@ -144,16 +144,26 @@ func ReadAllGenre(
 	return genres, nil
 }
 
-func UpdateGenre(ctx context.Context, models *data.Models, newGenreData Genre) (*Genre, error) {
-	genreRecord := data.Genre{
-		ID:          newGenreData.ID,
-		Name:        newGenreData.Name,
-		Description: newGenreData.Description,
-		CreatedAt:   newGenreData.CreatedAt,
-		UpdatedAt:   newGenreData.UpdatedAt,
+// Patch returns the patch setting the fields of the genre that are not nil, leaving the rest
+// unchanged.
+func (g Genre) Patch() data.GenrePatch {
+	return data.GenrePatch{
+		Name:        data.OptionalOf(g.Name),
+		Description: data.OptionalOf(g.Description),
+		CreatedAt:   data.OptionalOf(g.CreatedAt),
 	}
+}
 
-	updatedGenre, err := models.Genres.Update(ctx, genreRecord)
+// UpdateGenre applies the patch to the genre. If version is set, the genre is only changed if it
+// has not been updated since, and data.ErrEditConflict is returned otherwise.
+func UpdateGenre(
+	ctx context.Context,
+	models *data.Models,
+	id uuid.UUID,
+	patch data.GenrePatch,
+	version *time.Time,
+) (*Genre, error) {
+	updatedGenre, err := models.Genres.Update(ctx, id, patch, version)
 	if err != nil {
 		return nil, err
 	}
@@ -166,8 +176,15 @@ func UpdateGenre(ctx context.Context, models *data.Models, newGenreData Genre) (
 	return updatedGenreData, nil
 }
 
-func DeleteGenre(ctx context.Context, models *data.Models, id uuid.UUID) error {
-	_, err := models.Genres.Delete(ctx, id)
+// DeleteGenre moves the genre to the trash. If version is set, the genre is only moved if it has not
+// been updated since, and data.ErrEditConflict is returned otherwise.
+func DeleteGenre(
+	ctx context.Context,
+	models *data.Models,
+	id uuid.UUID,
+	version *time.Time,
+) error {
+	_, err := models.Genres.Delete(ctx, id, version)
 	if err != nil {
 		return err
 	}
@@ -61,7 +61,13 @@ func TestComplexGenreTypes(t *testing.T) {
 			Description: &newDescription,
 		}
 
-		_, err := types.UpdateGenre(context.Background(), models, newGenreData)
+		_, err := types.UpdateGenre(
+			context.Background(),
+			models,
+			*id,
+			newGenreData.Patch(),
+			nil,
+		)
 		if err != nil {
 			t.Errorf("unable to update genre: %s\n", err)
 			return
@@ -69,7 +75,7 @@ func TestComplexGenreTypes(t *testing.T) {
 	})
 
 	t.Run("TestDeleteGenre", func(t *testing.T) {
-		if err := types.DeleteGenre(context.Background(), models, *id); err != nil {
+		if err := types.DeleteGenre(context.Background(), models, *id, nil); err != nil {
 			t.Errorf("unable to delete genre: %s\n", err)
 			return
 		}
@@ -0,0 +1,25 @@
+package types
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/r3d5un/Bookshelf/internal/books/data"
+)
+
+// ReadHistory returns the recorded changes to the book, author, series or genre with the given
+// ID, including changes to its links, newest first. The history of deleted records is kept, so
+// no error is returned for IDs that do not exist.
+func ReadHistory(
+	ctx context.Context,
+	models *data.Models,
+	id uuid.UUID,
+	filters data.Filters,
+) ([]*data.AuditEntry, error) {
+	entries, _, err := models.AuditLog.GetAll(ctx, id, filters)
+	if err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
@@ -144,16 +144,26 @@ func ReadAllSeries(
 	return series, nil
 }
 
-func UpdateSeries(ctx context.Context, models *data.Models, newSeriesData Series) (*Series, error) {
-	seriesRecord := data.Series{
-		ID:          newSeriesData.ID,
-		Name:        newSeriesData.Name,
-		Description: newSeriesData.Description,
-		CreatedAt:   newSeriesData.CreatedAt,
-		UpdatedAt:   newSeriesData.UpdatedAt,
+// Patch returns the patch setting the fields of the series that are not nil, leaving the rest
+// unchanged.
+func (s Series) Patch() data.SeriesPatch {
+	return data.SeriesPatch{
+		Name:        data.OptionalOf(s.Name),
+		Description: data.OptionalOf(s.Description),
+		CreatedAt:   data.OptionalOf(s.CreatedAt),
 	}
+}
 
-	updatedSeries, err := models.Series.Update(ctx, seriesRecord)
+// UpdateSeries applies the patch to the series. If version is set, the series is only changed if it
+// has not been updated since, and data.ErrEditConflict is returned otherwise.
+func UpdateSeries(
+	ctx context.Context,
+	models *data.Models,
+	id uuid.UUID,
+	patch data.SeriesPatch,
+	version *time.Time,
+) (*Series, error) {
+	updatedSeries, err := models.Series.Update(ctx, id, patch, version)
 	if err != nil {
 		return nil, err
 	}
@@ -166,8 +176,15 @@ func UpdateSeries(ctx context.Context, models *data.Models, newSeriesData Series
 	return updatedSeriesData, nil
 }
 
-func DeleteSeries(ctx context.Context, models *data.Models, id uuid.UUID) error {
-	_, err := models.Series.Delete(ctx, id)
+// DeleteSeries moves the series to the trash. If version is set, the series is only moved if it has not
+// been updated since, and data.ErrEditConflict is returned otherwise.
+func DeleteSeries(
+	ctx context.Context,
+	models *data.Models,
+	id uuid.UUID,
+	version *time.Time,
+) error {
+	_, err := models.Series.Delete(ctx, id, version)
 	if err != nil {
 		return err
 	}
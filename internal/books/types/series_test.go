@@ -61,7 +61,13 @@ func TestComplexSeriesTypes(t *testing.T) {
 			Description: &newDescription,
 		}
 
-		_, err := types.UpdateSeries(context.Background(), models, newSeriesData)
+		_, err := types.UpdateSeries(
+			context.Background(),
+			models,
+			*id,
+			newSeriesData.Patch(),
+			nil,
+		)
 		if err != nil {
 			t.Errorf("unable to update series: %s\n", err)
 			return
@@ -69,7 +75,7 @@ func TestComplexSeriesTypes(t *testing.T) {
 	})
 
 	t.Run("TestDeleteSeries", func(t *testing.T) {
-		if err := types.DeleteSeries(context.Background(), models, *id); err != nil {
+		if err := types.DeleteSeries(context.Background(), models, *id, nil); err != nil {
 			t.Errorf("unable to delete series: %s\n", err)
 			return
 		}
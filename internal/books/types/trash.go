@@ -0,0 +1,99 @@
+package types
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/r3d5un/Bookshelf/internal/books/data"
+)
+
+// Trash lists the books, authors, series and genres that have been deleted, but not yet purged,
+// most recently deleted first.
+type Trash struct {
+	Books   []*data.Book   `json:"books"`
+	Authors []*data.Author `json:"authors"`
+	Series  []*data.Series `json:"series"`
+	Genres  []*data.Genre  `json:"genres"`
+}
+
+// PurgedTrash holds the number of records of each kind purged from the trash.
+type PurgedTrash struct {
+	Books   int64 `json:"books"`
+	Authors int64 `json:"authors"`
+	Series  int64 `json:"series"`
+	Genres  int64 `json:"genres"`
+}
+
+// ReadTrash reads the records in the trash. The paging of the filters applies to each kind of
+// record separately.
+func ReadTrash(ctx context.Context, models *data.Models, filters data.Filters) (*Trash, error) {
+	deleted := true
+	filters.Deleted = &deleted
+	filters.OrderBy = []string{"-deleted_at", "id"}
+
+	books, _, err := models.Books.GetAll(ctx, filters)
+	if err != nil {
+		return nil, err
+	}
+	authors, _, err := models.Authors.GetAll(ctx, filters)
+	if err != nil {
+		return nil, err
+	}
+	series, _, err := models.Series.GetAll(ctx, filters)
+	if err != nil {
+		return nil, err
+	}
+	genres, _, err := models.Genres.GetAll(ctx, filters)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Trash{Books: books, Authors: authors, Series: series, Genres: genres}, nil
+}
+
+// PurgeTrash permanently deletes the records moved to the trash before the given time, along
+// with their links to other records.
+func PurgeTrash(ctx context.Context, models *data.Models, before time.Time) (*PurgedTrash, error) {
+	var purged PurgedTrash
+	var err error
+
+	if purged.Books, err = models.Books.Purge(ctx, before); err != nil {
+		return nil, err
+	}
+	if purged.Authors, err = models.Authors.Purge(ctx, before); err != nil {
+		return nil, err
+	}
+	if purged.Series, err = models.Series.Purge(ctx, before); err != nil {
+		return nil, err
+	}
+	if purged.Genres, err = models.Genres.Purge(ctx, before); err != nil {
+		return nil, err
+	}
+
+	return &purged, nil
+}
+
+func RestoreAuthor(ctx context.Context, models *data.Models, id uuid.UUID) (*Author, error) {
+	if _, err := models.Authors.Restore(ctx, id); err != nil {
+		return nil, err
+	}
+
+	return ReadAuthor(ctx, models, id)
+}
+
+func RestoreSeries(ctx context.Context, models *data.Models, id uuid.UUID) (*Series, error) {
+	if _, err := models.Series.Restore(ctx, id); err != nil {
+		return nil, err
+	}
+
+	return ReadSeries(ctx, models, id)
+}
+
+func RestoreGenre(ctx context.Context, models *data.Models, id uuid.UUID) (*Genre, error) {
+	if _, err := models.Genres.Restore(ctx, id); err != nil {
+		return nil, err
+	}
+
+	return ReadGenre(ctx, models, id)
+}
@@ -3,7 +3,11 @@ package config
 import "github.com/spf13/viper"
 
 type Config struct {
-	DB *DatabaseConfig `json:"db"`
+	DB           *DatabaseConfig     `json:"db"`
+	Backup       *BackupConfig       `json:"backup"`
+	Orchestrator *OrchestratorConfig `json:"orchestrator"`
+	Events       *EventsConfig       `json:"events"`
+	Books        *BooksConfig        `json:"books"`
 }
 
 type DatabaseConfig struct {
@@ -14,6 +18,45 @@ type DatabaseConfig struct {
 	Timeout      int    `json:"timeout"`
 }
 
+// BackupConfig configures the library backup task. The Daily, Weekly and Monthly
+// values are the number of archives kept for each period.
+type BackupConfig struct {
+	Directory string `json:"directory"`
+	Daily     int    `json:"daily"`
+	Weekly    int    `json:"weekly"`
+	Monthly   int    `json:"monthly"`
+}
+
+// OrchestratorConfig configures the task runner. Workers is the number of tasks each instance
+// runs at the same time.
+type OrchestratorConfig struct {
+	Workers   int              `json:"workers"`
+	Retention *RetentionConfig `json:"retention"`
+}
+
+// RetentionConfig configures how long finished task runs are kept. Days applies to complete and
+// skipped runs, and FailedDays to stopped, failed and dead runs, unless a task sets its own
+// retention. Expired runs are deleted BatchSize at a time, and each run keeps at most LogLines
+// log lines.
+type RetentionConfig struct {
+	Days       int `json:"days"`
+	FailedDays int `json:"failedDays"`
+	BatchSize  int `json:"batchSize"`
+	LogLines   int `json:"logLines"`
+}
+
+// EventsConfig configures the event bus. With Outbox set, events are stored in the database and
+// delivered to the subscribers of every instance, rather than only on the publishing instance.
+type EventsConfig struct {
+	Outbox bool `json:"outbox"`
+}
+
+// BooksConfig configures the catalog. Deleted records are kept in the trash for TrashDays days
+// before being purged by the Purge Trash task.
+type BooksConfig struct {
+	TrashDays int `json:"trashDays"`
+}
+
 func New() (*Config, error) {
 	viper.AutomaticEnv()
 	viper.AllowEmptyEnv(false)
@@ -22,6 +65,18 @@ func New() (*Config, error) {
 	viper.AddConfigPath("$HOME/.config/bookshelf")
 	viper.AddConfigPath(".")
 
+	viper.SetDefault("backup.directory", "./backups")
+	viper.SetDefault("backup.daily", 7)
+	viper.SetDefault("backup.weekly", 4)
+	viper.SetDefault("backup.monthly", 12)
+	viper.SetDefault("orchestrator.workers", 4)
+	viper.SetDefault("orchestrator.retention.days", 30)
+	viper.SetDefault("orchestrator.retention.failedDays", 90)
+	viper.SetDefault("orchestrator.retention.batchSize", 1_000)
+	viper.SetDefault("orchestrator.retention.logLines", 10_000)
+	viper.SetDefault("events.outbox", false)
+	viper.SetDefault("books.trashDays", 30)
+
 	err := viper.ReadInConfig()
 	if err != nil {
 		return nil, err
@@ -0,0 +1,566 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/r3d5un/Bookshelf/internal/logging"
+)
+
+// migrationLockKey is the key of the advisory lock held while migrating, preventing
+// multiple instances from migrating the same database concurrently.
+const migrationLockKey int64 = 7_347_220_114
+
+var (
+	ErrInvalidMigration = errors.New("invalid migration")
+	ErrNoDownMigration  = errors.New("migration has no down script")
+)
+
+// Migration is a versioned set of SQL scripts. The version is the timestamp prefix of the
+// migration file name, e.g. 2024-07-03T103755 in 2024-07-03T103755_create_books_schema.up.sql.
+type Migration struct {
+	Version string `json:"version"`
+	Name    string `json:"name"`
+	Up      string `json:"-"`
+	Down    string `json:"-"`
+}
+
+// MigrationStatus describes whether a migration has been applied to the database.
+type MigrationStatus struct {
+	Version   string     `json:"version"`
+	Name      string     `json:"name"`
+	AppliedAt *time.Time `json:"appliedAt,omitempty"`
+	// Legacy is set for migrations applied by golang-migrate, which are recorded as applied the
+	// next time the database is migrated
+	Legacy bool `json:"legacy,omitempty"`
+}
+
+type Migrator struct {
+	db         *sql.DB
+	migrations []Migration
+}
+
+// NewMigrator creates a migrator for the migrations found in the given filesystem.
+func NewMigrator(db *sql.DB, fsys fs.FS) (*Migrator, error) {
+	migrations, err := ParseMigrations(fsys)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migrator{db: db, migrations: migrations}, nil
+}
+
+// ParseMigrations reads all migration scripts from the root of the given filesystem. Scripts
+// are expected to be named <version>_<name>.up.sql and <version>_<name>.down.sql. The returned
+// migrations are sorted by version.
+func ParseMigrations(fsys fs.FS) ([]Migration, error) {
+	files, err := fs.Glob(fsys, "*.sql")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[string]*Migration)
+
+	for _, file := range files {
+		var direction string
+		switch {
+		case strings.HasSuffix(file, ".up.sql"):
+			direction = "up"
+		case strings.HasSuffix(file, ".down.sql"):
+			direction = "down"
+		default:
+			return nil, fmt.Errorf("%w: %s is neither an up or down script", ErrInvalidMigration, file)
+		}
+
+		version, name, found := strings.Cut(strings.TrimSuffix(file, "."+direction+".sql"), "_")
+		if !found || version == "" {
+			return nil, fmt.Errorf("%w: %s has no version prefix", ErrInvalidMigration, file)
+		}
+
+		content, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, err
+		}
+
+		migration, exists := byVersion[version]
+		if !exists {
+			migration = &Migration{Version: version, Name: name}
+			byVersion[version] = migration
+		}
+		if migration.Name != name {
+			return nil, fmt.Errorf(
+				"%w: version %s used by both %s and %s",
+				ErrInvalidMigration, version, migration.Name, name,
+			)
+		}
+
+		if direction == "up" {
+			migration.Up = string(content)
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" {
+			return nil, fmt.Errorf("%w: %s has no up script", ErrInvalidMigration, migration.Version)
+		}
+		migrations = append(migrations, *migration)
+	}
+
+	slices.SortFunc(migrations, func(a, b Migration) int {
+		return strings.Compare(a.Version, b.Version)
+	})
+
+	return migrations, nil
+}
+
+// Up applies all pending migrations in order, returning the applied migrations. Each
+// migration is applied in its own transaction.
+func (m *Migrator) Up(ctx context.Context) (applied []Migration, err error) {
+	logger := logging.LoggerFromContext(ctx)
+
+	err = m.withLock(ctx, func(conn *sql.Conn) error {
+		appliedVersions, err := m.appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for _, migration := range m.migrations {
+			if _, ok := appliedVersions[migration.Version]; ok {
+				continue
+			}
+			if err := m.up(ctx, conn, migration); err != nil {
+				return err
+			}
+			applied = append(applied, migration)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return applied, err
+	}
+
+	logger.Info("migrations applied", slog.Int("applied", len(applied)))
+	return applied, nil
+}
+
+// Down reverts the given number of most recently applied migrations, returning the reverted
+// migrations.
+func (m *Migrator) Down(ctx context.Context, steps int) (reverted []Migration, err error) {
+	logger := logging.LoggerFromContext(ctx)
+
+	err = m.withLock(ctx, func(conn *sql.Conn) error {
+		appliedVersions, err := m.appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for i := len(m.migrations) - 1; i >= 0 && len(reverted) < steps; i-- {
+			migration := m.migrations[i]
+			if _, ok := appliedVersions[migration.Version]; !ok {
+				continue
+			}
+			if err := m.down(ctx, conn, migration); err != nil {
+				return err
+			}
+			reverted = append(reverted, migration)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return reverted, err
+	}
+
+	logger.Info("migrations reverted", slog.Int("reverted", len(reverted)))
+	return reverted, nil
+}
+
+// Goto migrates the database to the given version, reverting the applied migrations newer than
+// it, newest first, and applying the pending migrations up to and including it, oldest first.
+// ErrInvalidMigration is returned if no migration has the version.
+func (m *Migrator) Goto(
+	ctx context.Context,
+	version string,
+) (applied []Migration, reverted []Migration, err error) {
+	logger := logging.LoggerFromContext(ctx)
+
+	known := slices.ContainsFunc(m.migrations, func(migration Migration) bool {
+		return migration.Version == version
+	})
+	if !known {
+		return nil, nil, fmt.Errorf("%w: unknown version %s", ErrInvalidMigration, version)
+	}
+
+	err = m.withLock(ctx, func(conn *sql.Conn) error {
+		appliedVersions, err := m.appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for i := len(m.migrations) - 1; i >= 0; i-- {
+			migration := m.migrations[i]
+			if migration.Version <= version {
+				break
+			}
+			if _, ok := appliedVersions[migration.Version]; !ok {
+				continue
+			}
+			if err := m.down(ctx, conn, migration); err != nil {
+				return err
+			}
+			reverted = append(reverted, migration)
+		}
+
+		for _, migration := range m.migrations {
+			if migration.Version > version {
+				break
+			}
+			if _, ok := appliedVersions[migration.Version]; ok {
+				continue
+			}
+			if err := m.up(ctx, conn, migration); err != nil {
+				return err
+			}
+			applied = append(applied, migration)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return applied, reverted, err
+	}
+
+	logger.Info(
+		"migrated to version",
+		slog.String("version", version),
+		slog.Int("applied", len(applied)),
+		slog.Int("reverted", len(reverted)),
+	)
+	return applied, reverted, nil
+}
+
+// Status lists all known migrations, and when they were applied. The database is only read, so
+// that the status can be checked while other instances migrate. Migrations applied by
+// golang-migrate that have yet to be adopted by Up, Down or Goto are listed as such.
+func (m *Migrator) Status(ctx context.Context) (statuses []MigrationStatus, err error) {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	var versionTable sql.NullString
+	err = conn.QueryRowContext(
+		ctx,
+		`SELECT to_regclass('public.bookshelf_migrations')::TEXT;`,
+	).Scan(&versionTable)
+	if err != nil {
+		return nil, err
+	}
+
+	appliedVersions := map[string]time.Time{}
+	if versionTable.Valid {
+		appliedVersions, err = m.appliedVersions(ctx, conn)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var legacyVersion int64
+	legacy := false
+	if len(appliedVersions) == 0 {
+		legacyVersion, legacy, err = m.legacyVersion(ctx, conn)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, migration := range m.migrations {
+		status := MigrationStatus{Version: migration.Version, Name: migration.Name}
+		if appliedAt, ok := appliedVersions[migration.Version]; ok {
+			status.AppliedAt = &appliedAt
+		}
+		status.Legacy = legacy && appliedByLegacy(migration.Version, legacyVersion)
+		statuses = append(statuses, status)
+	}
+
+	return statuses, nil
+}
+
+// withLock runs the given function on a dedicated connection holding the migration advisory
+// lock. The version table is created if it does not already exist, and seeded from
+// golang-migrate's version table if the database was migrated with it.
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	logger := logging.LoggerFromContext(ctx)
+
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	logger.Info("acquiring migration lock")
+	if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock($1);`, migrationLockKey); err != nil {
+		return fmt.Errorf("unable to acquire migration lock: %w", err)
+	}
+	defer func() {
+		// The context may already be cancelled, but the lock must still be released before
+		// the connection is returned to the pool.
+		_, err := conn.ExecContext(
+			context.Background(),
+			`SELECT pg_advisory_unlock($1);`,
+			migrationLockKey,
+		)
+		if err != nil {
+			logger.Error("unable to release migration lock", "error", err)
+		}
+	}()
+	logger.Info("migration lock acquired")
+
+	_, err = conn.ExecContext(ctx, `
+CREATE TABLE IF NOT EXISTS public.bookshelf_migrations
+(
+    version    VARCHAR(64)  PRIMARY KEY,
+    name       VARCHAR(256) NOT NULL,
+    applied_at TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+`)
+	if err != nil {
+		return fmt.Errorf("unable to create migration version table: %w", err)
+	}
+
+	if err := m.seedFromLegacy(ctx, conn); err != nil {
+		return fmt.Errorf("unable to adopt golang-migrate version: %w", err)
+	}
+
+	return fn(conn)
+}
+
+// up applies the migration, and records it in the version table.
+func (m *Migrator) up(ctx context.Context, conn *sql.Conn, migration Migration) error {
+	logging.LoggerFromContext(ctx).Info(
+		"applying migration",
+		slog.String("version", migration.Version),
+		slog.String("name", migration.Name),
+	)
+	err := m.apply(ctx, conn, migration.Up, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(
+			ctx,
+			`INSERT INTO public.bookshelf_migrations (version, name) VALUES ($1, $2);`,
+			migration.Version,
+			migration.Name,
+		)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("unable to apply migration %s: %w", migration.Version, err)
+	}
+
+	return nil
+}
+
+// down reverts the migration, and removes it from the version table.
+func (m *Migrator) down(ctx context.Context, conn *sql.Conn, migration Migration) error {
+	if migration.Down == "" {
+		return fmt.Errorf("%w: %s", ErrNoDownMigration, migration.Version)
+	}
+
+	logging.LoggerFromContext(ctx).Info(
+		"reverting migration",
+		slog.String("version", migration.Version),
+		slog.String("name", migration.Name),
+	)
+	err := m.apply(ctx, conn, migration.Down, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(
+			ctx,
+			`DELETE FROM public.bookshelf_migrations WHERE version = $1;`,
+			migration.Version,
+		)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("unable to revert migration %s: %w", migration.Version, err)
+	}
+
+	return nil
+}
+
+// seedFromLegacy adopts databases migrated by golang-migrate before the built-in runner
+// existed. If the version table is empty and golang-migrate's schema_migrations table is
+// present, every migration up to its version is recorded as applied, so that the baseline
+// scripts are not run again.
+func (m *Migrator) seedFromLegacy(ctx context.Context, conn *sql.Conn) error {
+	logger := logging.LoggerFromContext(ctx)
+
+	var tracked bool
+	err := conn.QueryRowContext(
+		ctx,
+		`SELECT EXISTS (SELECT 1 FROM public.bookshelf_migrations);`,
+	).Scan(&tracked)
+	if err != nil {
+		return err
+	}
+	if tracked {
+		return nil
+	}
+
+	legacyVersion, found, err := m.legacyVersion(ctx, conn)
+	if err != nil || !found {
+		return err
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	seeded := 0
+	for _, migration := range m.migrations {
+		if !appliedByLegacy(migration.Version, legacyVersion) {
+			break
+		}
+		_, err := tx.ExecContext(
+			ctx,
+			`INSERT INTO public.bookshelf_migrations (version, name) VALUES ($1, $2);`,
+			migration.Version,
+			migration.Name,
+		)
+		if err != nil {
+			return err
+		}
+		seeded++
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	logger.Info(
+		"adopted golang-migrate version",
+		slog.Int64("version", legacyVersion),
+		slog.Int("seeded", seeded),
+	)
+	return nil
+}
+
+// legacyVersion returns the version recorded in golang-migrate's schema_migrations table, and
+// whether one is recorded. ErrInvalidMigration is returned if the version is marked dirty.
+func (m *Migrator) legacyVersion(ctx context.Context, conn *sql.Conn) (int64, bool, error) {
+	var legacyTable sql.NullString
+	err := conn.QueryRowContext(
+		ctx,
+		`SELECT to_regclass('public.schema_migrations')::TEXT;`,
+	).Scan(&legacyTable)
+	if err != nil {
+		return 0, false, err
+	}
+	if !legacyTable.Valid {
+		return 0, false, nil
+	}
+
+	var version int64
+	var dirty bool
+	err = conn.QueryRowContext(
+		ctx,
+		`SELECT version, dirty FROM public.schema_migrations LIMIT 1;`,
+	).Scan(&version, &dirty)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	if dirty {
+		return 0, false, fmt.Errorf(
+			"%w: golang-migrate version %d is dirty, and must be fixed manually",
+			ErrInvalidMigration, version,
+		)
+	}
+
+	return version, true, nil
+}
+
+// appliedByLegacy reports whether golang-migrate had applied the migration with the given
+// version. golang-migrate stores the version as the number formed by the digits of the
+// version prefix, e.g. 20240703103755 for 2024-07-03T103755. Only as many leading digits as
+// the stored version has are compared.
+func appliedByLegacy(version string, legacyVersion int64) bool {
+	legacy := strconv.FormatInt(legacyVersion, 10)
+
+	digits := strings.Map(func(r rune) rune {
+		if r < '0' || r > '9' {
+			return -1
+		}
+		return r
+	}, version)
+	if len(digits) > len(legacy) {
+		digits = digits[:len(legacy)]
+	}
+
+	// Equal-length decimal strings compare the same as the numbers they hold
+	return len(digits) == len(legacy) && digits <= legacy
+}
+
+func (m *Migrator) appliedVersions(
+	ctx context.Context,
+	conn *sql.Conn,
+) (map[string]time.Time, error) {
+	rows, err := conn.QueryContext(ctx, `SELECT version, applied_at FROM public.bookshelf_migrations;`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	versions := make(map[string]time.Time)
+	for rows.Next() {
+		var version string
+		var appliedAt time.Time
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, err
+		}
+		versions[version] = appliedAt
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return versions, nil
+}
+
+// apply executes a migration script and records the change in the version table within a
+// single transaction.
+func (m *Migrator) apply(
+	ctx context.Context,
+	conn *sql.Conn,
+	script string,
+	record func(tx *sql.Tx) error,
+) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, script); err != nil {
+		return err
+	}
+
+	if err := record(tx); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
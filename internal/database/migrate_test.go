@@ -0,0 +1,72 @@
+package database_test
+
+import (
+	"errors"
+	"testing"
+	"testing/fstest"
+
+	"github.com/r3d5un/Bookshelf/internal/database"
+	"github.com/r3d5un/Bookshelf/migrations"
+)
+
+func TestParseMigrations(t *testing.T) {
+	t.Run("Embedded", func(t *testing.T) {
+		parsed, err := database.ParseMigrations(migrations.Files)
+		if err != nil {
+			t.Errorf("unable to parse embedded migrations: %s\n", err)
+			return
+		}
+		if len(parsed) < 1 {
+			t.Error("no migrations parsed")
+			return
+		}
+
+		for i := 1; i < len(parsed); i++ {
+			if parsed[i-1].Version >= parsed[i].Version {
+				t.Errorf(
+					"migrations out of order: %s before %s\n",
+					parsed[i-1].Version, parsed[i].Version,
+				)
+				return
+			}
+		}
+	})
+
+	t.Run("Ordering", func(t *testing.T) {
+		fsys := fstest.MapFS{
+			"2024-07-04T210139_second.up.sql":   {Data: []byte("SELECT 2;")},
+			"2024-07-04T210139_second.down.sql": {Data: []byte("SELECT -2;")},
+			"2024-07-03T103755_first.up.sql":    {Data: []byte("SELECT 1;")},
+		}
+
+		parsed, err := database.ParseMigrations(fsys)
+		if err != nil {
+			t.Errorf("unable to parse migrations: %s\n", err)
+			return
+		}
+		if len(parsed) != 2 {
+			t.Errorf("expected 2 migrations, got %d\n", len(parsed))
+			return
+		}
+		if parsed[0].Name != "first" || parsed[1].Name != "second" {
+			t.Errorf("unexpected migration order: %v\n", parsed)
+			return
+		}
+		if parsed[1].Down != "SELECT -2;" {
+			t.Errorf("expected down script, got %s\n", parsed[1].Down)
+			return
+		}
+	})
+
+	t.Run("MissingUpScript", func(t *testing.T) {
+		fsys := fstest.MapFS{
+			"2024-07-03T103755_first.down.sql": {Data: []byte("SELECT 1;")},
+		}
+
+		_, err := database.ParseMigrations(fsys)
+		if !errors.Is(err, database.ErrInvalidMigration) {
+			t.Errorf("expected %s, got %v\n", database.ErrInvalidMigration, err)
+			return
+		}
+	})
+}
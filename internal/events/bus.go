@@ -0,0 +1,269 @@
+// Package events is an in-process publish/subscribe bus, letting modules react to changes made
+// by other modules without depending on them.
+package events
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/r3d5un/Bookshelf/internal/logging"
+)
+
+// forwardBatchSize is the number of stored events read at a time while forwarding
+const forwardBatchSize = 500
+
+// ErrNoOutbox is returned when publishing as part of a transaction without an outbox
+var ErrNoOutbox = errors.New("event bus has no outbox")
+
+// Event is a change other modules can subscribe to. Events are value types, and EventName
+// returns the same name for every value of the type, as the name identifies the type of stored
+// events across instances.
+type Event interface {
+	EventName() string
+}
+
+// Publisher publishes events, and is what code raising events depends on.
+type Publisher interface {
+	Publish(ctx context.Context, event Event) error
+	PublishTx(ctx context.Context, tx *sql.Tx, event Event) error
+}
+
+type handler struct {
+	// consumer names handlers called once per event across instances, and is empty for handlers
+	// called on every instance
+	consumer string
+	fn       func(context.Context, Event) error
+}
+
+// Bus delivers published events to the handlers subscribed to their type.
+//
+// Without an outbox, handlers are called before Publish returns, and only on the instance the
+// event was published on. With an outbox, events are stored in the database and delivered to the
+// handlers of every instance as they are forwarded from the outbox.
+type Bus struct {
+	instance  uuid.UUID
+	mu        sync.RWMutex
+	handlers  map[string][]*handler
+	decoders  map[string]func(json.RawMessage) (Event, error)
+	outbox    *Outbox
+	forwardMu sync.Mutex
+	cursor    int64
+}
+
+// NewBus creates a bus for the given instance, without an outbox.
+func NewBus(instance uuid.UUID) *Bus {
+	return &Bus{
+		instance: instance,
+		handlers: make(map[string][]*handler),
+		decoders: make(map[string]func(json.RawMessage) (Event, error)),
+	}
+}
+
+// Subscribe calls the handler for every event of type T. Errors returned by the handler are
+// logged, and do not fail the publisher. Returns a function ending the subscription.
+func Subscribe[T Event](bus *Bus, fn func(context.Context, T) error) (unsubscribe func()) {
+	return subscribe(bus, fn, "")
+}
+
+// SubscribeOnce calls the handler once for every event of type T, however many instances receive
+// it, for handlers such as sending notifications. Events forwarded from the outbox are claimed
+// for the consumer before the handler is called, and are only handled by the instance claiming
+// them. The consumer names the subscription, and must not be shared by other subscriptions to T.
+func SubscribeOnce[T Event](
+	bus *Bus,
+	consumer string,
+	fn func(context.Context, T) error,
+) (unsubscribe func()) {
+	return subscribe(bus, fn, consumer)
+}
+
+func subscribe[T Event](bus *Bus, fn func(context.Context, T) error, consumer string) func() {
+	var zero T
+	name := zero.EventName()
+
+	h := &handler{
+		consumer: consumer,
+		fn: func(ctx context.Context, event Event) error {
+			return fn(ctx, event.(T))
+		},
+	}
+
+	bus.mu.Lock()
+	defer bus.mu.Unlock()
+
+	bus.handlers[name] = append(bus.handlers[name], h)
+	bus.decoders[name] = func(payload json.RawMessage) (Event, error) {
+		var event T
+		err := json.Unmarshal(payload, &event)
+		return event, err
+	}
+
+	return func() {
+		bus.mu.Lock()
+		defer bus.mu.Unlock()
+
+		handlers := bus.handlers[name]
+		for i, existing := range handlers {
+			if existing == h {
+				bus.handlers[name] = append(handlers[:i:i], handlers[i+1:]...)
+				break
+			}
+		}
+	}
+}
+
+// UseOutbox stores published events in the outbox from now on. Events stored before the outbox
+// is used are not forwarded to this instance.
+func (b *Bus) UseOutbox(ctx context.Context, outbox *Outbox) error {
+	lastID, err := outbox.LastID(ctx)
+	if err != nil {
+		return err
+	}
+
+	b.forwardMu.Lock()
+	defer b.forwardMu.Unlock()
+
+	b.outbox = outbox
+	b.cursor = lastID
+
+	return nil
+}
+
+// Outbox returns the outbox of the bus, or nil if events are only delivered in-process.
+func (b *Bus) Outbox() *Outbox {
+	b.forwardMu.Lock()
+	defer b.forwardMu.Unlock()
+
+	return b.outbox
+}
+
+// Publish delivers the event to its subscribers, or stores it in the outbox if the bus has one.
+// Only failures to store the event are returned.
+func (b *Bus) Publish(ctx context.Context, event Event) error {
+	outbox := b.Outbox()
+	if outbox == nil {
+		b.dispatch(ctx, event, nil, 0)
+		return nil
+	}
+
+	return b.store(ctx, outbox, nil, event)
+}
+
+// PublishTx stores the event in the outbox as part of the transaction, so that the event is only
+// delivered if the transaction is committed. ErrNoOutbox is returned if the bus has no outbox.
+func (b *Bus) PublishTx(ctx context.Context, tx *sql.Tx, event Event) error {
+	outbox := b.Outbox()
+	if outbox == nil {
+		return ErrNoOutbox
+	}
+
+	return b.store(ctx, outbox, tx, event)
+}
+
+func (b *Bus) store(ctx context.Context, outbox *Outbox, tx *sql.Tx, event Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("unable to encode event %s: %w", event.EventName(), err)
+	}
+
+	return outbox.Store(ctx, tx, StoredEvent{
+		Name:       event.EventName(),
+		Payload:    payload,
+		InstanceID: b.instance,
+	})
+}
+
+// Forward delivers the events stored in the outbox since the last forward to the subscribers on
+// this instance, and returns the number of events read. Events without subscribers on this
+// instance are skipped.
+func (b *Bus) Forward(ctx context.Context) (int, error) {
+	logger := logging.LoggerFromContext(ctx)
+
+	b.forwardMu.Lock()
+	defer b.forwardMu.Unlock()
+
+	if b.outbox == nil {
+		return 0, ErrNoOutbox
+	}
+
+	total := 0
+	for {
+		storedEvents, err := b.outbox.After(ctx, b.cursor, forwardBatchSize)
+		if err != nil {
+			return total, err
+		}
+
+		for _, stored := range storedEvents {
+			b.cursor = stored.ID
+			total++
+
+			b.mu.RLock()
+			decode, found := b.decoders[stored.Name]
+			b.mu.RUnlock()
+			if !found {
+				continue
+			}
+
+			event, err := decode(stored.Payload)
+			if err != nil {
+				logger.Error(
+					"unable to decode stored event",
+					"id", stored.ID,
+					"name", stored.Name,
+					"error", err,
+				)
+				continue
+			}
+			b.dispatch(ctx, event, b.outbox, stored.ID)
+		}
+
+		if len(storedEvents) < forwardBatchSize {
+			return total, nil
+		}
+	}
+}
+
+// dispatch calls the handlers of the event in the order they subscribed. Events forwarded from
+// the outbox carry their stored ID, and are claimed for handlers called once per event, which are
+// left out if another instance has claimed the event.
+func (b *Bus) dispatch(ctx context.Context, event Event, outbox *Outbox, id int64) {
+	logger := logging.LoggerFromContext(ctx).With("event", event.EventName())
+
+	b.mu.RLock()
+	handlers := make([]*handler, len(b.handlers[event.EventName()]))
+	copy(handlers, b.handlers[event.EventName()])
+	b.mu.RUnlock()
+
+	for _, h := range handlers {
+		if h.consumer != "" && outbox != nil {
+			claimed, err := outbox.Claim(ctx, id, h.consumer)
+			if err != nil {
+				logger.Error("unable to claim event", "id", id, "consumer", h.consumer, "error", err)
+				continue
+			}
+			if !claimed {
+				continue
+			}
+		}
+		if err := call(ctx, h, event); err != nil {
+			logger.Error("event handler failed", "error", err)
+		}
+	}
+}
+
+// call runs the handler, returning a panic in the handler as an error, so that one handler
+// cannot stop the others or the publisher.
+func call(ctx context.Context, h *handler, event Event) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("event handler panicked: %v", r)
+		}
+	}()
+
+	return h.fn(ctx, event)
+}
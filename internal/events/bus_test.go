@@ -0,0 +1,130 @@
+package events_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/r3d5un/Bookshelf/internal/events"
+)
+
+type bookShelved struct {
+	Title string `json:"title"`
+}
+
+func (bookShelved) EventName() string { return "test.book_shelved" }
+
+type bookLent struct {
+	Title string `json:"title"`
+}
+
+func (bookLent) EventName() string { return "test.book_lent" }
+
+func TestBus(t *testing.T) {
+	bus := events.NewBus(uuid.New())
+
+	var shelved []string
+	var lent int
+	unsubscribe := events.Subscribe(bus, func(ctx context.Context, e bookShelved) error {
+		shelved = append(shelved, e.Title)
+		return nil
+	})
+	events.Subscribe(bus, func(ctx context.Context, e bookLent) error {
+		lent++
+		return nil
+	})
+
+	t.Run("Publish", func(t *testing.T) {
+		err := bus.Publish(context.Background(), bookShelved{Title: "Dune"})
+		if err != nil {
+			t.Errorf("error occurred while publishing event: %s\n", err)
+			return
+		}
+		if len(shelved) != 1 || shelved[0] != "Dune" {
+			t.Errorf("expected the event to be delivered once, got %v\n", shelved)
+			return
+		}
+		if lent != 0 {
+			t.Errorf("expected no delivery to subscribers of other events, got %d\n", lent)
+			return
+		}
+	})
+
+	t.Run("FailingHandlers", func(t *testing.T) {
+		unsubscribeFailing := events.Subscribe(bus, func(ctx context.Context, e bookShelved) error {
+			return errors.New("unable to reindex")
+		})
+		defer unsubscribeFailing()
+		unsubscribePanicking := events.Subscribe(bus, func(ctx context.Context, e bookShelved) error {
+			panic("thumbnail missing")
+		})
+		defer unsubscribePanicking()
+
+		var after int
+		unsubscribeAfter := events.Subscribe(bus, func(ctx context.Context, e bookShelved) error {
+			after++
+			return nil
+		})
+		defer unsubscribeAfter()
+
+		err := bus.Publish(context.Background(), bookShelved{Title: "Emma"})
+		if err != nil {
+			t.Errorf("expected handler failures not to fail the publisher, got %s\n", err)
+			return
+		}
+		if after != 1 {
+			t.Errorf("expected handlers after a failing handler to be called, got %d\n", after)
+			return
+		}
+	})
+
+	t.Run("Unsubscribe", func(t *testing.T) {
+		unsubscribe()
+
+		err := bus.Publish(context.Background(), bookShelved{Title: "Ulysses"})
+		if err != nil {
+			t.Errorf("error occurred while publishing event: %s\n", err)
+			return
+		}
+		if len(shelved) != 2 {
+			t.Errorf("expected no delivery after unsubscribing, got %v\n", shelved)
+			return
+		}
+	})
+
+	t.Run("SubscribeOnce", func(t *testing.T) {
+		var once int
+		unsubscribeOnce := events.SubscribeOnce(
+			bus,
+			"test",
+			func(ctx context.Context, e bookLent) error {
+				once++
+				return nil
+			},
+		)
+		defer unsubscribeOnce()
+
+		err := bus.Publish(context.Background(), bookLent{Title: "Persuasion"})
+		if err != nil {
+			t.Errorf("error occurred while publishing event: %s\n", err)
+			return
+		}
+		if once != 1 {
+			t.Errorf("expected the event to be handled once, got %d\n", once)
+			return
+		}
+	})
+
+	t.Run("WithoutOutbox", func(t *testing.T) {
+		err := bus.PublishTx(context.Background(), nil, bookLent{Title: "Dune"})
+		if !errors.Is(err, events.ErrNoOutbox) {
+			t.Errorf("expected %s, got %v\n", events.ErrNoOutbox, err)
+			return
+		}
+		if _, err := bus.Forward(context.Background()); !errors.Is(err, events.ErrNoOutbox) {
+			t.Errorf("expected %s, got %v\n", events.ErrNoOutbox, err)
+			return
+		}
+	})
+}
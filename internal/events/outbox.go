@@ -0,0 +1,295 @@
+package events
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/r3d5un/Bookshelf/internal/database"
+	"github.com/r3d5un/Bookshelf/internal/logging"
+)
+
+// OutboxChannel is the PostgreSQL channel announcing new events in the outbox. The payload is
+// the ID of the stored event.
+const OutboxChannel string = "event_outbox_notification"
+
+// outboxLockKey is the advisory lock serializing writes to the outbox
+const outboxLockKey int64 = 7_406_925_114
+
+// StoredEvent is an event in the outbox.
+type StoredEvent struct {
+	ID      int64           `json:"id"`
+	Name    string          `json:"name"`
+	Payload json.RawMessage `json:"payload"`
+	// InstanceID is the instance the event was published on
+	InstanceID uuid.UUID  `json:"instanceId"`
+	CreatedAt  *time.Time `json:"createdAt"`
+}
+
+// Outbox stores published events, so that they can be forwarded to every instance.
+type Outbox struct {
+	DB      *sql.DB
+	Timeout *time.Duration
+}
+
+func NewOutbox(db *sql.DB, timeout *time.Duration) *Outbox {
+	return &Outbox{DB: db, Timeout: timeout}
+}
+
+// Store adds the event to the outbox and notifies listeners on the OutboxChannel, as part of the
+// transaction if one is given. Notifications are sent by PostgreSQL once the transaction commits,
+// and events are never announced for transactions that are rolled back.
+//
+// Writes to the outbox are serialized until the writing transaction ends, so that events become
+// visible in the order of their IDs, and readers following the IDs do not skip events committed
+// late.
+func (m *Outbox) Store(ctx context.Context, tx *sql.Tx, event StoredEvent) (err error) {
+	logger := logging.LoggerFromContext(ctx)
+
+	query := `
+INSERT INTO events.outbox (name, payload, instance_id)
+VALUES ($1::TEXT, $2::JSONB, $3::UUID)
+RETURNING id;
+`
+
+	logger = logger.With(
+		slog.Group(
+			"query",
+			slog.String("statement", database.MinifySQL(query)),
+			slog.String("name", event.Name),
+		),
+	)
+
+	if tx == nil {
+		logger.Info("beginning transaction")
+		tx, err = m.DB.BeginTx(ctx, nil)
+		if err != nil {
+			logger.Error("unable to begin transaction", "error", err)
+			return err
+		}
+		defer func() {
+			if err != nil {
+				if rbErr := tx.Rollback(); rbErr != nil {
+					logger.Error("unable to roll back transaction", "error", rbErr)
+				}
+				return
+			}
+			err = tx.Commit()
+		}()
+	}
+
+	qCtx, cancel := context.WithTimeout(ctx, *m.Timeout)
+	defer cancel()
+
+	logger.Info("locking outbox")
+	_, err = tx.ExecContext(qCtx, "SELECT pg_advisory_xact_lock($1);", outboxLockKey)
+	if err != nil {
+		logger.Error("unable to lock outbox", "error", err)
+		return err
+	}
+
+	var id int64
+	logger.Info("performing query")
+	err = tx.QueryRowContext(
+		qCtx,
+		query,
+		event.Name,
+		string(event.Payload),
+		event.InstanceID,
+	).Scan(&id)
+	if err != nil {
+		logger.Error("an error occurred while performing query", "error", err)
+		return err
+	}
+
+	logger.Info("notifying listeners", "id", id)
+	_, err = tx.ExecContext(
+		qCtx, "SELECT pg_notify($1, $2);", OutboxChannel, strconv.FormatInt(id, 10),
+	)
+	if err != nil {
+		logger.Error("unable to execute notification statement", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+// After returns up to limit events with an ID above the given ID, ordered by ID.
+func (m *Outbox) After(ctx context.Context, afterID int64, limit int) ([]*StoredEvent, error) {
+	logger := logging.LoggerFromContext(ctx)
+
+	query := `
+SELECT id,
+       name,
+       payload,
+       instance_id,
+       created_at
+FROM events.outbox
+WHERE id > $1
+ORDER BY id
+LIMIT $2;
+`
+
+	qCtx, cancel := context.WithTimeout(ctx, *m.Timeout)
+	defer cancel()
+
+	logger = logger.With(
+		slog.Group(
+			"query",
+			slog.String("statement", database.MinifySQL(query)),
+			slog.Int64("afterId", afterID),
+			slog.Int("limit", limit),
+		),
+	)
+
+	logger.Info("performing query")
+	rows, err := m.DB.QueryContext(qCtx, query, afterID, limit)
+	if err != nil {
+		logger.Error("an error occurred while performing query", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	storedEvents := []*StoredEvent{}
+	for rows.Next() {
+		var event StoredEvent
+		var payload []byte
+
+		err := rows.Scan(
+			&event.ID,
+			&event.Name,
+			&payload,
+			&event.InstanceID,
+			&event.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		event.Payload = payload
+		storedEvents = append(storedEvents, &event)
+	}
+	if err = rows.Err(); err != nil {
+		logger.Error("an error occurred while parsing query results", "error", err)
+		return nil, err
+	}
+
+	logger.Info("returning events", "length", len(storedEvents))
+	return storedEvents, nil
+}
+
+// Claim claims the stored event for the consumer, and returns whether it was claimed by this call.
+// An event is claimed once per consumer, so that the consumer handles it on a single instance.
+func (m *Outbox) Claim(ctx context.Context, id int64, consumer string) (bool, error) {
+	logger := logging.LoggerFromContext(ctx)
+
+	query := `
+INSERT INTO events.outbox_claims (event_id, consumer)
+VALUES ($1, $2)
+ON CONFLICT (event_id, consumer) DO NOTHING
+RETURNING event_id;
+`
+
+	qCtx, cancel := context.WithTimeout(ctx, *m.Timeout)
+	defer cancel()
+
+	logger = logger.With(
+		slog.Group(
+			"query",
+			slog.String("statement", database.MinifySQL(query)),
+			slog.Int64("id", id),
+			slog.String("consumer", consumer),
+		),
+	)
+
+	var claimedID int64
+	logger.Info("performing query")
+	err := m.DB.QueryRowContext(qCtx, query, id, consumer).Scan(&claimedID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			logger.Info("event already claimed")
+			return false, nil
+		}
+		logger.Error("an error occurred while performing query", "error", err)
+		return false, err
+	}
+
+	logger.Info("event claimed")
+	return true, nil
+}
+
+// LastID returns the ID of the newest event in the outbox, or 0 if it is empty.
+func (m *Outbox) LastID(ctx context.Context) (int64, error) {
+	logger := logging.LoggerFromContext(ctx)
+
+	query := `
+SELECT COALESCE(MAX(id), 0)
+FROM events.outbox;
+`
+
+	qCtx, cancel := context.WithTimeout(ctx, *m.Timeout)
+	defer cancel()
+
+	logger = logger.With(
+		slog.Group(
+			"query",
+			slog.String("statement", database.MinifySQL(query)),
+		),
+	)
+
+	var id int64
+	logger.Info("performing query")
+	err := m.DB.QueryRowContext(qCtx, query).Scan(&id)
+	if err != nil {
+		logger.Error("an error occurred while performing query", "error", err)
+		return 0, err
+	}
+
+	logger.Info("returning last ID", "id", id)
+	return id, nil
+}
+
+// DeleteBefore removes events stored before the given time, and returns the number of events
+// deleted.
+func (m *Outbox) DeleteBefore(ctx context.Context, before time.Time) (int64, error) {
+	logger := logging.LoggerFromContext(ctx)
+
+	query := `
+DELETE
+FROM events.outbox
+WHERE created_at < $1::timestamp;
+`
+
+	qCtx, cancel := context.WithTimeout(ctx, *m.Timeout)
+	defer cancel()
+
+	// The creation time is stored without a time zone, in UTC
+	before = before.UTC()
+
+	logger = logger.With(
+		slog.Group(
+			"query",
+			slog.String("statement", database.MinifySQL(query)),
+			slog.Time("before", before),
+		),
+	)
+
+	logger.Info("performing query")
+	result, err := m.DB.ExecContext(qCtx, query, before)
+	if err != nil {
+		logger.Error("an error occurred while performing query", "error", err)
+		return 0, err
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	logger.Info("events deleted", "deleted", deleted)
+	return deleted, nil
+}
@@ -0,0 +1,498 @@
+// Package idempotency makes POST requests safe to retry. The response to a request carrying an
+// Idempotency-Key header is stored, and a retry of the request with the same key is answered with
+// the stored response instead of being handled again.
+package idempotency
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"time"
+
+	"github.com/r3d5un/Bookshelf/internal/audit"
+	"github.com/r3d5un/Bookshelf/internal/database"
+	"github.com/r3d5un/Bookshelf/internal/logging"
+	"github.com/r3d5un/Bookshelf/internal/rest"
+)
+
+// Header is the request header holding the idempotency key chosen by the client.
+const Header string = "Idempotency-Key"
+
+// ReplayedHeader is set on responses replayed from the store.
+const ReplayedHeader string = "Idempotent-Replayed"
+
+// TTL is how long a response is stored, and a key cannot be reused for a different request.
+const TTL = 24 * time.Hour
+
+// maxKeyLength is the length of the longest idempotency key accepted.
+const maxKeyLength = 255
+
+// ErrKeyContended is returned by Reserve when the key keeps being released or purged by other
+// requests while it is being claimed.
+var ErrKeyContended = errors.New("idempotency key contended")
+
+// bufferedContentTypes are the content types of the requests the middleware handles. Their bodies
+// are read in full to fingerprint the request, so uploads and multipart forms are left alone.
+// Requests without a content type are handled as well.
+var bufferedContentTypes = map[string]bool{
+	"application/json":                  true,
+	"application/x-www-form-urlencoded": true,
+}
+
+// Record is the request made with an idempotency key, and the response to it once handled.
+type Record struct {
+	Actor       string      `json:"actor"`
+	Key         string      `json:"key"`
+	Fingerprint string      `json:"fingerprint"`
+	Status      *int        `json:"status,omitempty"`
+	Header      http.Header `json:"header,omitempty"`
+	Body        []byte      `json:"body,omitempty"`
+	CreatedAt   *time.Time  `json:"createdAt"`
+}
+
+// Store keeps the responses to requests made with an idempotency key. Keys are scoped to the
+// actor making the request.
+type Store struct {
+	DB      *sql.DB
+	Timeout *time.Duration
+}
+
+func NewStore(db *sql.DB, timeout *time.Duration) *Store {
+	return &Store{DB: db, Timeout: timeout}
+}
+
+// Reserve claims the key for the request with the given fingerprint. Nil is returned once the key
+// is claimed, and the request is to be handled. The record of the earlier request is returned if
+// the key has been claimed within the TTL, whether or not the request has been handled yet. Keys
+// claimed before the TTL are claimed anew.
+//
+// A key released or purged between the failed claim and the read of the earlier request is
+// claimed once more, and ErrKeyContended is returned if that claim fails as well.
+func (s *Store) Reserve(
+	ctx context.Context,
+	actor string,
+	key string,
+	fingerprint string,
+) (*Record, error) {
+	logger := logging.LoggerFromContext(ctx)
+
+	record, err := s.reserve(ctx, actor, key, fingerprint)
+	if !errors.Is(err, sql.ErrNoRows) {
+		return record, err
+	}
+
+	logger.Info("idempotency key no longer claimed; claiming anew")
+	record, err = s.reserve(ctx, actor, key, fingerprint)
+	if errors.Is(err, sql.ErrNoRows) {
+		logger.Info("idempotency key released again while claiming")
+		return nil, ErrKeyContended
+	}
+
+	return record, err
+}
+
+// reserve makes a single attempt at claiming the key. sql.ErrNoRows is returned if the key was
+// claimed by an earlier request that was released or purged before it could be read.
+func (s *Store) reserve(
+	ctx context.Context,
+	actor string,
+	key string,
+	fingerprint string,
+) (*Record, error) {
+	logger := logging.LoggerFromContext(ctx)
+
+	query := `
+INSERT INTO idempotency.keys (actor, key, fingerprint)
+VALUES ($1, $2, $3)
+ON CONFLICT (actor, key) DO UPDATE
+    SET fingerprint = EXCLUDED.fingerprint,
+        status      = NULL,
+        header      = NULL,
+        body        = NULL,
+        created_at  = CURRENT_TIMESTAMP
+WHERE keys.created_at < CURRENT_TIMESTAMP - MAKE_INTERVAL(secs => $4)
+RETURNING key;
+`
+
+	qCtx, cancel := context.WithTimeout(ctx, *s.Timeout)
+	defer cancel()
+
+	logger = logger.With(
+		slog.Group(
+			"query",
+			slog.String("statement", database.MinifySQL(query)),
+			slog.String("actor", actor),
+			slog.String("key", key),
+		),
+	)
+
+	logger.Info("performing query")
+	var claimed string
+	err := s.DB.QueryRowContext(qCtx, query, actor, key, fingerprint, TTL.Seconds()).Scan(&claimed)
+	if err == nil {
+		logger.Info("idempotency key claimed")
+		return nil, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		logger.Error("unable to claim idempotency key", "error", err)
+		return nil, err
+	}
+
+	logger.Info("idempotency key already claimed")
+	return s.get(ctx, actor, key)
+}
+
+func (s *Store) get(ctx context.Context, actor string, key string) (*Record, error) {
+	logger := logging.LoggerFromContext(ctx)
+
+	query := `
+SELECT actor, key, fingerprint, status, header, body, created_at
+FROM idempotency.keys
+WHERE actor = $1
+  AND key = $2;
+`
+
+	qCtx, cancel := context.WithTimeout(ctx, *s.Timeout)
+	defer cancel()
+
+	logger = logger.With(
+		slog.Group(
+			"query",
+			slog.String("statement", database.MinifySQL(query)),
+			slog.String("actor", actor),
+			slog.String("key", key),
+		),
+	)
+
+	var record Record
+	var header []byte
+
+	logger.Info("performing query")
+	err := s.DB.QueryRowContext(qCtx, query, actor, key).Scan(
+		&record.Actor,
+		&record.Key,
+		&record.Fingerprint,
+		&record.Status,
+		&header,
+		&record.Body,
+		&record.CreatedAt,
+	)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			logger.Error("unable to perform query", "error", err)
+		}
+		return nil, err
+	}
+	if header != nil {
+		if err := json.Unmarshal(header, &record.Header); err != nil {
+			logger.Error("unable to decode stored header", "error", err)
+			return nil, err
+		}
+	}
+
+	return &record, nil
+}
+
+// Complete stores the response to the request the key was claimed for.
+func (s *Store) Complete(
+	ctx context.Context,
+	actor string,
+	key string,
+	status int,
+	header http.Header,
+	body []byte,
+) error {
+	logger := logging.LoggerFromContext(ctx)
+
+	query := `
+UPDATE idempotency.keys
+SET status = $3,
+    header = $4::JSONB,
+    body   = $5
+WHERE actor = $1
+  AND key = $2;
+`
+
+	js, err := json.Marshal(header)
+	if err != nil {
+		return err
+	}
+
+	qCtx, cancel := context.WithTimeout(ctx, *s.Timeout)
+	defer cancel()
+
+	logger = logger.With(
+		slog.Group(
+			"query",
+			slog.String("statement", database.MinifySQL(query)),
+			slog.String("actor", actor),
+			slog.String("key", key),
+			slog.Int("status", status),
+		),
+	)
+
+	logger.Info("performing query")
+	if _, err := s.DB.ExecContext(qCtx, query, actor, key, status, string(js), body); err != nil {
+		logger.Error("unable to store response", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+// Release gives up the claim on the key, so that the request can be retried, if the response to
+// it has not been stored.
+func (s *Store) Release(ctx context.Context, actor string, key string) error {
+	logger := logging.LoggerFromContext(ctx)
+
+	query := `
+DELETE
+FROM idempotency.keys
+WHERE actor = $1
+  AND key = $2
+  AND status IS NULL;
+`
+
+	qCtx, cancel := context.WithTimeout(ctx, *s.Timeout)
+	defer cancel()
+
+	logger = logger.With(
+		slog.Group(
+			"query",
+			slog.String("statement", database.MinifySQL(query)),
+			slog.String("actor", actor),
+			slog.String("key", key),
+		),
+	)
+
+	logger.Info("performing query")
+	if _, err := s.DB.ExecContext(qCtx, query, actor, key); err != nil {
+		logger.Error("unable to release idempotency key", "error", err)
+		return err
+	}
+
+	return nil
+}
+
+// Purge deletes the keys claimed before the TTL, and returns the number of keys deleted.
+func (s *Store) Purge(ctx context.Context) (int64, error) {
+	logger := logging.LoggerFromContext(ctx)
+
+	query := `
+DELETE
+FROM idempotency.keys
+WHERE created_at < CURRENT_TIMESTAMP - MAKE_INTERVAL(secs => $1);
+`
+
+	qCtx, cancel := context.WithTimeout(ctx, *s.Timeout)
+	defer cancel()
+
+	logger = logger.With(
+		slog.Group("query", slog.String("statement", database.MinifySQL(query))),
+	)
+
+	logger.Info("performing query")
+	res, err := s.DB.ExecContext(qCtx, query, TTL.Seconds())
+	if err != nil {
+		logger.Error("unable to purge idempotency keys", "error", err)
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
+
+// Fingerprint identifies a request by its method, URL and body, so that a key reused for a
+// different request is detected.
+func Fingerprint(method string, uri string, body []byte) string {
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%s %s\n", method, uri)
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// Middleware answers POST requests carrying an idempotency key once. The response is stored
+// unless the request fails with a server error or panics, in which case the key is released so
+// that the request can be retried. Retries with the same key are answered with the stored
+// response and ReplayedHeader. A retry arriving while the request is still being handled, or
+// after its response could not be stored, is answered with 409 Conflict, and a key reused for a
+// different request with 422 Unprocessable Entity.
+//
+// Only requests with a JSON or URL-encoded form body, or no body, are handled. Other requests,
+// such as file uploads, are passed on without reading their body into memory.
+//
+// The middleware expects the actor of the request to be set in the context by audit.WithActor.
+func (s *Store) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(Header)
+		if r.Method != http.MethodPost || key == "" || !buffered(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		ctx := r.Context()
+		logger := logging.LoggerFromContext(ctx).With(slog.String("idempotencyKey", key))
+		ctx = logging.WithLogger(ctx, logger)
+		r = r.WithContext(ctx)
+
+		if len(key) > maxKeyLength {
+			logger.Info("idempotency key too long", "length", len(key))
+			rest.BadRequestResponse(
+				w, r, fmt.Sprintf("the %s header must not exceed %d characters", Header, maxKeyLength),
+			)
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			logger.Info("unable to read request body", "error", err)
+			rest.BadRequestResponse(w, r, fmt.Sprintf("unable to read request body: %s\n", err))
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		actor := audit.ActorFromContext(ctx)
+		fingerprint := Fingerprint(r.Method, r.URL.RequestURI(), body)
+
+		record, err := s.Reserve(ctx, actor, key, fingerprint)
+		if err != nil {
+			if errors.Is(err, ErrKeyContended) {
+				rest.ConflictResponse(
+					w, r, fmt.Sprintf("the %s is being claimed by another request", Header),
+				)
+				return
+			}
+			rest.ServerErrorResponse(w, r, err)
+			return
+		}
+		if record != nil {
+			replay(w, r, record, fingerprint)
+			return
+		}
+
+		release := func() {
+			if err := s.Release(context.WithoutCancel(ctx), actor, key); err != nil {
+				logger.Error("unable to release idempotency key", "error", err)
+			}
+		}
+
+		rec := &recorder{header: http.Header{}}
+		handled := false
+		defer func() {
+			// A panicking handler may not have made its change, and the key is released so that
+			// the request can be retried
+			if !handled {
+				release()
+			}
+		}()
+
+		next.ServeHTTP(rec, r)
+		handled = true
+		// Handlers writing nothing are answered with 200 OK
+		rec.WriteHeader(http.StatusOK)
+
+		if rec.status >= http.StatusInternalServerError {
+			logger.Info("request failed; releasing idempotency key", "status", rec.status)
+			release()
+			rec.writeTo(w)
+			return
+		}
+
+		err = s.Complete(
+			context.WithoutCancel(ctx), actor, key, rec.status, rec.header, rec.body.Bytes(),
+		)
+		if err != nil {
+			// The change has been made, so the claim is kept rather than letting a retry make
+			// it again. Retries are answered with 409 Conflict until the key expires.
+			logger.Error("unable to store response; keeping idempotency key claimed", "error", err)
+		}
+
+		rec.writeTo(w)
+	})
+}
+
+// buffered reports whether the body of the request is of a content type handled by the
+// middleware.
+func buffered(r *http.Request) bool {
+	contentType := r.Header.Get("Content-Type")
+	if contentType == "" {
+		return true
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+
+	return bufferedContentTypes[mediaType]
+}
+
+// replay answers a retry with the stored response to the request first made with the key.
+func replay(w http.ResponseWriter, r *http.Request, record *Record, fingerprint string) {
+	logger := logging.LoggerFromContext(r.Context())
+
+	switch {
+	case record.Fingerprint != fingerprint:
+		logger.Info("idempotency key reused for a different request")
+		rest.ErrorResponse(
+			w,
+			r,
+			http.StatusUnprocessableEntity,
+			fmt.Sprintf("the %s has been used for a different request", Header),
+		)
+	case record.Status == nil:
+		logger.Info("request with idempotency key in progress")
+		rest.ConflictResponse(
+			w, r, fmt.Sprintf("a request with the %s is still being processed", Header),
+		)
+	default:
+		logger.Info("replaying response", "status", *record.Status)
+		for name, values := range record.Header {
+			w.Header()[name] = values
+		}
+		w.Header().Set(ReplayedHeader, "true")
+		w.WriteHeader(*record.Status)
+		if _, err := w.Write(record.Body); err != nil {
+			logger.Error("unable to write response", "error", err)
+		}
+	}
+}
+
+// recorder holds the response to a request, so that it can be stored before it is written.
+type recorder struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (rec *recorder) Header() http.Header {
+	return rec.header
+}
+
+func (rec *recorder) WriteHeader(status int) {
+	if rec.status == 0 {
+		rec.status = status
+	}
+}
+
+func (rec *recorder) Write(b []byte) (int, error) {
+	rec.WriteHeader(http.StatusOK)
+	return rec.body.Write(b)
+}
+
+// writeTo writes the recorded response.
+func (rec *recorder) writeTo(w http.ResponseWriter) {
+	for name, values := range rec.header {
+		w.Header()[name] = values
+	}
+	w.WriteHeader(rec.status)
+	_, _ = w.Write(rec.body.Bytes())
+}
@@ -0,0 +1,87 @@
+package idempotency_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/r3d5un/Bookshelf/internal/idempotency"
+)
+
+func TestFingerprint(t *testing.T) {
+	fingerprint := idempotency.Fingerprint(http.MethodPost, "/api/v1/books/books", []byte(`{}`))
+
+	for _, tc := range []struct {
+		name   string
+		method string
+		uri    string
+		body   string
+		same   bool
+	}{
+		{"Same", http.MethodPost, "/api/v1/books/books", `{}`, true},
+		{"Method", http.MethodPut, "/api/v1/books/books", `{}`, false},
+		{"URI", http.MethodPost, "/api/v1/books/authors", `{}`, false},
+		{"Body", http.MethodPost, "/api/v1/books/books", `{"title": "Dune"}`, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			other := idempotency.Fingerprint(tc.method, tc.uri, []byte(tc.body))
+			if (other == fingerprint) != tc.same {
+				t.Errorf("expected same fingerprint to be %t, got %s and %s", tc.same, fingerprint, other)
+			}
+		})
+	}
+}
+
+func TestMiddlewarePassThrough(t *testing.T) {
+	// Requests the middleware does not apply to never reach the store
+	store := idempotency.NewStore(nil, nil)
+	handled := 0
+	handler := store.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handled++
+		w.WriteHeader(http.StatusCreated)
+	}))
+
+	for _, tc := range []struct {
+		name        string
+		method      string
+		key         string
+		contentType string
+		expected    int
+		handled     int
+	}{
+		{"Get", http.MethodGet, "a-key", "", http.StatusCreated, 1},
+		{"NoKey", http.MethodPost, "", "", http.StatusCreated, 1},
+		{"LongKey", http.MethodPost, strings.Repeat("k", 256), "", http.StatusBadRequest, 0},
+		{"Upload", http.MethodPost, "a-key", "application/epub+zip", http.StatusCreated, 1},
+		{
+			"Multipart",
+			http.MethodPost,
+			"a-key",
+			"multipart/form-data; boundary=xyz",
+			http.StatusCreated,
+			1,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			handled = 0
+			req := httptest.NewRequest(tc.method, "/api/v1/books/books", strings.NewReader(`{}`))
+			if tc.key != "" {
+				req.Header.Set(idempotency.Header, tc.key)
+			}
+			if tc.contentType != "" {
+				req.Header.Set("Content-Type", tc.contentType)
+			}
+
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			if rr.Code != tc.expected {
+				t.Errorf("expected status %d, got %d", tc.expected, rr.Code)
+			}
+			if handled != tc.handled {
+				t.Errorf("expected the request to be handled %d times, got %d", tc.handled, handled)
+			}
+		})
+	}
+}
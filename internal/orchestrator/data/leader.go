@@ -0,0 +1,188 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/r3d5un/Bookshelf/internal/database"
+	"github.com/r3d5un/Bookshelf/internal/logging"
+)
+
+// LeaderLockKey is the key of the session level advisory lock held by the leader.
+const LeaderLockKey int64 = 0x626f6f6b
+
+var (
+	// ErrStaleTerm is returned when a write fenced by a leader term is made after a new leader
+	// has been elected
+	ErrStaleTerm = errors.New("leader term is no longer current")
+)
+
+type Leader struct {
+	// Term is increased every time a leader is elected, and is used as a fencing token
+	Term       int64      `json:"term"`
+	InstanceID *uuid.UUID `json:"instanceId"`
+	ElectedAt  *time.Time `json:"electedAt"`
+	// Active is true if the leader lock is currently held by an instance
+	Active bool `json:"active"`
+}
+
+// LeaderModel elects a leader among the instances. The leader lock is a session level advisory
+// lock, so it is held by the connection that acquired it until released, or until the session
+// ends.
+type LeaderModel struct {
+	Timeout *time.Duration
+	Pool    *pgxpool.Pool
+}
+
+// TryAcquire attempts to take the leader lock on the given connection without waiting.
+func (m *LeaderModel) TryAcquire(ctx context.Context, conn *pgx.Conn) (acquired bool, err error) {
+	query := `
+SELECT pg_try_advisory_lock($1::bigint);
+`
+
+	logger := logging.LoggerFromContext(ctx).With(slog.Group(
+		"query",
+		slog.String("query", database.MinifySQL(query)),
+		slog.Int64("key", LeaderLockKey),
+	))
+
+	qCtx, cancel := context.WithTimeout(ctx, *m.Timeout)
+	defer cancel()
+
+	logger.Info("performing query")
+	err = conn.QueryRow(qCtx, query, LeaderLockKey).Scan(&acquired)
+	if err != nil {
+		logger.Error("error occurred while performing query", "error", err)
+		return false, err
+	}
+
+	logger.Info("query completed", "acquired", acquired)
+	return acquired, nil
+}
+
+// Release gives up the leader lock held by the given connection.
+func (m *LeaderModel) Release(ctx context.Context, conn *pgx.Conn) error {
+	query := `
+SELECT pg_advisory_unlock($1::bigint);
+`
+
+	logger := logging.LoggerFromContext(ctx).With(slog.Group(
+		"query",
+		slog.String("query", database.MinifySQL(query)),
+		slog.Int64("key", LeaderLockKey),
+	))
+
+	qCtx, cancel := context.WithTimeout(ctx, *m.Timeout)
+	defer cancel()
+
+	var released bool
+
+	logger.Info("performing query")
+	err := conn.QueryRow(qCtx, query, LeaderLockKey).Scan(&released)
+	if err != nil {
+		logger.Error("error occurred while performing query", "error", err)
+		return err
+	}
+
+	logger.Info("query completed", "released", released)
+	return nil
+}
+
+// Elect starts a new leader term for the given instance, which must hold the leader lock. The
+// returned term is the fencing token of the leader.
+func (m *LeaderModel) Elect(ctx context.Context, instanceID uuid.UUID) (leader *Leader, err error) {
+	query := `
+UPDATE orchestrator.leader
+SET term        = term + 1,
+    instance_id = $1::uuid,
+    elected_at  = NOW()
+RETURNING
+    term,
+    instance_id,
+    elected_at;
+`
+
+	logger := logging.LoggerFromContext(ctx).With(slog.Group(
+		"query",
+		slog.String("query", database.MinifySQL(query)),
+		slog.String("instanceId", instanceID.String()),
+	))
+
+	qCtx, cancel := context.WithTimeout(ctx, *m.Timeout)
+	defer cancel()
+
+	leader = &Leader{Active: true}
+
+	logger.Info("performing query")
+	err = m.Pool.QueryRow(qCtx, query, instanceID).Scan(
+		&leader.Term,
+		&leader.InstanceID,
+		&leader.ElectedAt,
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			logger.Info("no rows found")
+			return nil, ErrRecordNotFound
+		default:
+			logger.Error("error occurred while performing query", "error", err)
+			return nil, err
+		}
+	}
+
+	logger.Info("returning leader", "leader", leader)
+	return leader, nil
+}
+
+// Get returns the most recently elected leader, and whether the leader lock is currently held.
+func (m *LeaderModel) Get(ctx context.Context) (leader *Leader, err error) {
+	query := `
+SELECT term,
+       instance_id,
+       elected_at,
+       EXISTS (SELECT 1
+               FROM pg_locks
+               WHERE locktype = 'advisory'
+                 AND classid = 0
+                 AND objid = $1::bigint::oid
+                 AND objsubid = 1
+                 AND granted)
+FROM orchestrator.leader;
+`
+
+	logger := logging.LoggerFromContext(ctx).With(slog.Group(
+		"query",
+		slog.String("query", database.MinifySQL(query)),
+	))
+
+	qCtx, cancel := context.WithTimeout(ctx, *m.Timeout)
+	defer cancel()
+
+	leader = &Leader{}
+
+	logger.Info("performing query")
+	err = m.Pool.QueryRow(qCtx, query, LeaderLockKey).Scan(
+		&leader.Term,
+		&leader.InstanceID,
+		&leader.ElectedAt,
+		&leader.Active,
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			logger.Info("no rows found")
+			return nil, ErrRecordNotFound
+		default:
+			logger.Error("error occurred while performing query", "error", err)
+			return nil, err
+		}
+	}
+
+	logger.Info("returning leader", "leader", leader)
+	return leader, nil
+}
@@ -0,0 +1,114 @@
+package data_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/r3d5un/Bookshelf/internal/orchestrator/data"
+)
+
+func TestLeaderModel(t *testing.T) {
+	leaderConn, err := pgx.ConnectConfig(context.Background(), db.Config().ConnConfig.Copy())
+	if err != nil {
+		t.Errorf("unable to connect to database: %s\n", err)
+		return
+	}
+	defer leaderConn.Close(context.Background())
+
+	otherConn, err := pgx.ConnectConfig(context.Background(), db.Config().ConnConfig.Copy())
+	if err != nil {
+		t.Errorf("unable to connect to database: %s\n", err)
+		return
+	}
+	defer otherConn.Close(context.Background())
+
+	var term int64
+
+	t.Run("TryAcquire", func(t *testing.T) {
+		acquired, err := models.Leader.TryAcquire(context.Background(), leaderConn)
+		if err != nil {
+			t.Errorf("an error occurred while acquiring the leader lock: %s\n", err)
+			return
+		}
+		if !acquired {
+			t.Errorf("expected the leader lock to be acquired")
+			return
+		}
+
+		acquired, err = models.Leader.TryAcquire(context.Background(), otherConn)
+		if err != nil {
+			t.Errorf("an error occurred while acquiring the leader lock: %s\n", err)
+			return
+		}
+		if acquired {
+			t.Errorf("expected the leader lock to be held by the first connection")
+			return
+		}
+	})
+
+	t.Run("Elect", func(t *testing.T) {
+		instanceID := uuid.New()
+		leader, err := models.Leader.Elect(context.Background(), instanceID)
+		if err != nil {
+			t.Errorf("an error occurred while electing leader: %s\n", err)
+			return
+		}
+		term = leader.Term
+
+		leader, err = models.Leader.Get(context.Background())
+		if err != nil {
+			t.Errorf("an error occurred while getting leader: %s\n", err)
+			return
+		}
+		if leader.Term != term || *leader.InstanceID != instanceID || !leader.Active {
+			t.Errorf("unexpected leader: %v\n", leader)
+			return
+		}
+	})
+
+	t.Run("InsertFenced", func(t *testing.T) {
+		task := data.Task{Name: "test_leader_queue"}
+		_, err := models.Tasks.Insert(context.Background(), task)
+		if err != nil {
+			t.Errorf("error occurred while inserting task: %s\n", err)
+			return
+		}
+
+		_, err = models.TaskQueues.InsertFenced(
+			context.Background(), data.TaskQueue{Name: &task.Name}, term,
+		)
+		if err != nil {
+			t.Errorf("error occurred while inserting fenced task: %s\n", err)
+			return
+		}
+
+		_, err = models.TaskQueues.InsertFenced(
+			context.Background(), data.TaskQueue{Name: &task.Name}, term-1,
+		)
+		if !errors.Is(err, data.ErrStaleTerm) {
+			t.Errorf("expected stale term error, got %v\n", err)
+			return
+		}
+	})
+
+	t.Run("Release", func(t *testing.T) {
+		err := models.Leader.Release(context.Background(), leaderConn)
+		if err != nil {
+			t.Errorf("an error occurred while releasing the leader lock: %s\n", err)
+			return
+		}
+
+		acquired, err := models.Leader.TryAcquire(context.Background(), otherConn)
+		if err != nil {
+			t.Errorf("an error occurred while acquiring the leader lock: %s\n", err)
+			return
+		}
+		if !acquired {
+			t.Errorf("expected the leader lock to be released")
+			return
+		}
+	})
+}
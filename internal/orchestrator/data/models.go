@@ -11,6 +11,12 @@ import (
 
 var (
 	ErrRecordNotFound = errors.New("record not found")
+	// ErrConcurrencyLimit is returned when a task cannot be claimed, as the max concurrency of
+	// the task has been reached
+	ErrConcurrencyLimit = errors.New("task concurrency limit reached")
+	// ErrDuplicateRecord is returned when a record cannot be written, as another record has the
+	// same unique value
+	ErrDuplicateRecord = errors.New("duplicate record")
 )
 
 var (
@@ -21,8 +27,11 @@ type Models struct {
 	Tasks             TaskModel
 	TaskQueues        TaskQueueModel
 	TaskNotifications TaskNotificationModel
-	SchedulerLock     SchedulerLockModel
+	Leader            LeaderModel
 	TaskLogs          TaskLogModel
+	TaskSchedules     TaskScheduleModel
+	Webhooks          WebhookModel
+	WebhookDeliveries WebhookDeliveryModel
 	pool              *pgxpool.Pool
 }
 
@@ -31,8 +40,11 @@ func NewModels(pool *pgxpool.Pool, timeout *time.Duration) Models {
 		Tasks:             TaskModel{Pool: pool, Timeout: timeout},
 		TaskQueues:        TaskQueueModel{Pool: pool, Timeout: timeout},
 		TaskNotifications: TaskNotificationModel{Pool: pool, Timeout: timeout},
-		SchedulerLock:     SchedulerLockModel{Pool: pool, Timeout: timeout},
+		Leader:            LeaderModel{Pool: pool, Timeout: timeout},
 		TaskLogs:          TaskLogModel{Pool: pool, Timeout: timeout},
+		TaskSchedules:     TaskScheduleModel{Pool: pool, Timeout: timeout},
+		Webhooks:          WebhookModel{Pool: pool, Timeout: timeout},
+		WebhookDeliveries: WebhookDeliveryModel{Pool: pool, Timeout: timeout},
 		pool:              pool,
 	}
 }
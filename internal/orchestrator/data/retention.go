@@ -0,0 +1,66 @@
+package data
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/r3d5un/Bookshelf/internal/database"
+	"github.com/r3d5un/Bookshelf/internal/logging"
+)
+
+// RetentionPolicy is the number of days finished runs are kept for tasks that do not set their
+// own retention. Days applies to complete and skipped runs, and FailedDays to stopped, failed
+// and dead runs.
+type RetentionPolicy struct {
+	Days       int `json:"days"`
+	FailedDays int `json:"failedDays"`
+}
+
+// DeleteExpired deletes up to limit finished runs older than their retention, and returns the
+// number of runs deleted. Only runs started outside of a workflow are considered, and their
+// child runs and logs are deleted with them.
+func (m *TaskQueueModel) DeleteExpired(
+	ctx context.Context,
+	policy RetentionPolicy,
+	limit int,
+) (deleted int64, err error) {
+	logger := logging.LoggerFromContext(ctx)
+
+	query := `
+WITH expired AS (SELECT q.id
+                 FROM orchestrator.task_queue q
+                          LEFT JOIN orchestrator.tasks t ON t.name = q.name
+                 WHERE q.parent_id IS NULL
+                   AND q.state IN ('complete', 'skipped', 'stopped', 'error', 'dead')
+                   AND COALESCE(q.finished_at, q.updated_at) < NOW() - MAKE_INTERVAL(days => CASE
+                       WHEN q.state IN ('complete', 'skipped')
+                           THEN COALESCE(t.retention_days, $1::integer)
+                       ELSE COALESCE(t.failed_retention_days, $2::integer) END)
+                 LIMIT $3)
+DELETE
+FROM orchestrator.task_queue
+WHERE id IN (SELECT id FROM expired);
+`
+
+	qCtx, cancel := context.WithTimeout(ctx, *m.Timeout)
+	defer cancel()
+
+	logger = logger.With(
+		slog.Group(
+			"query",
+			slog.String("statement", database.MinifySQL(query)),
+			slog.Any("policy", policy),
+			slog.Int("limit", limit),
+		),
+	)
+
+	logger.Info("performing query")
+	result, err := m.Pool.Exec(qCtx, query, policy.Days, policy.FailedDays, limit)
+	if err != nil {
+		logger.Error("an error occurred while performing query", "error", err)
+		return 0, err
+	}
+
+	logger.Info("expired tasks deleted", "deleted", result.RowsAffected())
+	return result.RowsAffected(), nil
+}
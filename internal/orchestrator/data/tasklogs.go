@@ -16,7 +16,10 @@ import (
 type TaskLog struct {
 	ID     uuid.UUID `json:"id"`
 	TaskID uuid.UUID `json:"taskId"`
-	Log    string    `json:"log"`
+	// Seq orders the logs of a run, and is assigned by the database when the log is inserted
+	Seq      int64      `json:"seq"`
+	LoggedAt *time.Time `json:"loggedAt"`
+	Log      string     `json:"log"`
 }
 
 type TaskLogModel struct {
@@ -28,13 +31,17 @@ func (m *TaskLogModel) Insert(ctx context.Context, newTaskLog TaskLog) (*TaskLog
 	query := `
 INSERT INTO orchestrator.task_logs (id,
                                     task_id,
+                                    logged_at,
                                     log)
 VALUES ($1::UUID,
         $2::UUID,
-        $3::JSONB)
+        COALESCE($3::TIMESTAMP, CURRENT_TIMESTAMP),
+        $4::JSONB)
 RETURNING
     id,
     task_id,
+    seq,
+    logged_at,
     log;
 `
 
@@ -49,9 +56,18 @@ RETURNING
 
 	taskLog := &TaskLog{}
 	logger.Info("performing query")
-	err := m.Pool.QueryRow(ctx, query, newTaskLog.ID, newTaskLog.TaskID, newTaskLog.Log).Scan(
+	err := m.Pool.QueryRow(
+		ctx,
+		query,
+		newTaskLog.ID,
+		newTaskLog.TaskID,
+		newTaskLog.LoggedAt,
+		newTaskLog.Log,
+	).Scan(
 		&taskLog.ID,
 		&taskLog.TaskID,
+		&taskLog.Seq,
+		&taskLog.LoggedAt,
 		&taskLog.Log,
 	)
 	if err != nil {
@@ -73,6 +89,8 @@ func (m *TaskLogModel) Get(ctx context.Context, id uuid.UUID) (*TaskLog, error)
 	query := `
 SELECT id,
        task_id,
+       seq,
+       logged_at,
        log
 FROM orchestrator.task_logs
 WHERE id = $1;
@@ -92,6 +110,8 @@ WHERE id = $1;
 	err := m.Pool.QueryRow(ctx, query, id).Scan(
 		&taskLog.ID,
 		&taskLog.TaskID,
+		&taskLog.Seq,
+		&taskLog.LoggedAt,
 		&taskLog.Log,
 	)
 	if err != nil {
@@ -113,10 +133,12 @@ func (m *TaskLogModel) GetByTaskID(ctx context.Context, taskQueueID uuid.UUID) (
 	query := `
 SELECT id,
        task_id,
+       seq,
+       logged_at,
        log
 FROM orchestrator.task_logs
 WHERE task_id = $1
-ORDER BY ((log ->> 'time')::timestamp);
+ORDER BY seq;
 `
 
 	qCtx, cancel := context.WithTimeout(ctx, *m.Timeout)
@@ -138,6 +160,75 @@ ORDER BY ((log ->> 'time')::timestamp);
 		query,
 		taskQueueID,
 	)
+	if err != nil {
+		logger.Error("an error occurred while performing query", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var task TaskLog
+
+		err := rows.Scan(
+			&task.ID,
+			&task.TaskID,
+			&task.Seq,
+			&task.LoggedAt,
+			&task.Log,
+		)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, &task)
+	}
+	if err = rows.Err(); err != nil {
+		logger.Error("an error occurred while parsing query results", "error", err)
+		return nil, err
+	}
+
+	logger.Info("returning records")
+	return tasks, nil
+}
+
+// GetByTaskIDAfter returns the logs of a run with a sequence number greater than afterSeq,
+// ordered by sequence number.
+func (m *TaskLogModel) GetByTaskIDAfter(
+	ctx context.Context,
+	taskQueueID uuid.UUID,
+	afterSeq int64,
+) ([]*TaskLog, error) {
+	query := `
+SELECT id,
+       task_id,
+       seq,
+       logged_at,
+       log
+FROM orchestrator.task_logs
+WHERE task_id = $1
+  AND seq > $2
+ORDER BY seq;
+`
+
+	qCtx, cancel := context.WithTimeout(ctx, *m.Timeout)
+	defer cancel()
+
+	logger := logging.LoggerFromContext(ctx).With(
+		slog.Group(
+			"query",
+			slog.String("statement", database.MinifySQL(query)),
+			"taskId", taskQueueID,
+			"afterSeq", afterSeq,
+		),
+	)
+
+	tasks := []*TaskLog{}
+
+	logger.Info("performing query")
+	rows, err := m.Pool.Query(qCtx, query, taskQueueID, afterSeq)
+	if err != nil {
+		logger.Error("an error occurred while performing query", "error", err)
+		return nil, err
+	}
 	defer rows.Close()
 
 	for rows.Next() {
@@ -146,6 +237,8 @@ ORDER BY ((log ->> 'time')::timestamp);
 		err := rows.Scan(
 			&task.ID,
 			&task.TaskID,
+			&task.Seq,
+			&task.LoggedAt,
 			&task.Log,
 		)
 		if err != nil {
@@ -161,3 +254,46 @@ ORDER BY ((log ->> 'time')::timestamp);
 	logger.Info("returning records")
 	return tasks, nil
 }
+
+// InsertBatch inserts the logs in a single round trip, and returns the number of logs inserted.
+// The logs are assigned sequence numbers in the order they are given.
+func (m *TaskLogModel) InsertBatch(ctx context.Context, newTaskLogs []TaskLog) (int64, error) {
+	columns := []string{"id", "task_id", "logged_at", "log"}
+
+	ctx, cancel := context.WithTimeout(ctx, *m.Timeout)
+	defer cancel()
+
+	logger := logging.LoggerFromContext(ctx).With(slog.Group(
+		"query",
+		slog.String("table", "orchestrator.task_logs"),
+		slog.Any("columns", columns),
+		slog.Int("length", len(newTaskLogs)),
+	))
+
+	logger.Info("copying rows")
+	copied, err := m.Pool.CopyFrom(
+		ctx,
+		pgx.Identifier{"orchestrator", "task_logs"},
+		columns,
+		pgx.CopyFromSlice(len(newTaskLogs), func(i int) ([]any, error) {
+			loggedAt := time.Now()
+			if newTaskLogs[i].LoggedAt != nil {
+				loggedAt = *newTaskLogs[i].LoggedAt
+			}
+
+			return []any{
+				newTaskLogs[i].ID,
+				newTaskLogs[i].TaskID,
+				loggedAt,
+				[]byte(newTaskLogs[i].Log),
+			}, nil
+		}),
+	)
+	if err != nil {
+		logger.Error("an error occurred while copying rows", "error", err)
+		return 0, err
+	}
+
+	logger.Info("rows copied", "copied", copied)
+	return copied, nil
+}
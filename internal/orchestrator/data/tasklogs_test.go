@@ -3,6 +3,7 @@ package data_test
 import (
 	"context"
 	"database/sql"
+	"fmt"
 	"testing"
 	"time"
 
@@ -77,4 +78,56 @@ func TestTaskLogModel(t *testing.T) {
 			return
 		}
 	})
+
+	t.Run("InsertBatch", func(t *testing.T) {
+		batch := make([]data.TaskLog, 0, 3)
+		for i := range 3 {
+			batch = append(batch, data.TaskLog{
+				ID:     uuid.New(),
+				TaskID: insertedTaskQueue.ID,
+				Log:    fmt.Sprintf(`{"msg":"batch log statement %d"}`, i),
+			})
+		}
+
+		copied, err := models.TaskLogs.InsertBatch(context.Background(), batch)
+		if err != nil {
+			t.Errorf("error occurred while inserting task logs: %s\n", err)
+			return
+		}
+		if copied != int64(len(batch)) {
+			t.Errorf("expected %d logs to be inserted, got %d\n", len(batch), copied)
+			return
+		}
+
+		logs, err := models.TaskLogs.GetByTaskID(context.Background(), insertedTaskQueue.ID)
+		if err != nil {
+			t.Errorf("unable to get task logs: %s\n", err)
+			return
+		}
+		logs = logs[len(logs)-len(batch):]
+		for i, log := range logs {
+			if log.ID != batch[i].ID {
+				t.Errorf("expected logs in the order they were inserted, got %v\n", logs)
+				return
+			}
+		}
+	})
+
+	t.Run("GetByTaskIDAfter", func(t *testing.T) {
+		logs, err := models.TaskLogs.GetByTaskIDAfter(context.Background(), insertedTaskQueue.ID, tql.Seq)
+		if err != nil {
+			t.Errorf("unable to get task logs: %s\n", err)
+			return
+		}
+		for _, log := range logs {
+			if log.Seq <= tql.Seq {
+				t.Errorf("expected logs after %d, got %d\n", tql.Seq, log.Seq)
+				return
+			}
+		}
+		if len(logs) != 3 {
+			t.Errorf("expected 3 logs after the first, got %d\n", len(logs))
+			return
+		}
+	})
 }
@@ -7,12 +7,25 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/r3d5un/Bookshelf/internal/database"
+	"github.com/r3d5un/Bookshelf/internal/events"
 	"github.com/r3d5un/Bookshelf/internal/logging"
 )
 
+const (
+	// TaskQueueChannel is the PostgreSQL channel announcing new entries in the task queue.
+	TaskQueueChannel string = "task_queue_notification"
+	// TaskConfigChannel is the PostgreSQL channel announcing changes to the task overview.
+	TaskConfigChannel string = "task_config_notification"
+	// TaskStopChannel is the PostgreSQL channel requesting running tasks to be stopped.
+	TaskStopChannel string = "task_stop_notification"
+	// TaskLogChannel is the PostgreSQL channel announcing new log lines of a run.
+	TaskLogChannel string = "task_log_notification"
+)
+
 // TaskNotification is used by the TaskNotificationModel to create a payload for the notification.
 type TaskNotification struct {
 	// ID task causing the notification
@@ -21,6 +34,19 @@ type TaskNotification struct {
 	Queue string `json:"queue"`
 }
 
+// TaskConfigNotification is the payload sent when the configuration of a task, such as its cron
+// expression or enabled state, has been changed.
+type TaskConfigNotification struct {
+	// Name of the changed task
+	Name string `json:"name"`
+}
+
+// TaskLogNotification is the payload sent when new log lines of a run have been written.
+type TaskLogNotification struct {
+	// TaskID is the run the log lines belong to
+	TaskID uuid.UUID `json:"taskId"`
+}
+
 type TaskNotificationModel struct {
 	Timeout *time.Duration
 	Pool    *pgxpool.Pool
@@ -29,9 +55,40 @@ type TaskNotificationModel struct {
 // Notify sends a notification on the task_queue_notification PostgreSQL channel. Takes in a TaskNotification
 // which is encoded to JSON as a payload for the notification.
 func (m *TaskNotificationModel) Notify(ctx context.Context, notification TaskNotification) error {
+	return m.notify(ctx, TaskQueueChannel, notification)
+}
+
+// NotifyTaskConfig sends a notification on the task_config_notification PostgreSQL channel,
+// telling all instances to reload the schedule of the given task.
+func (m *TaskNotificationModel) NotifyTaskConfig(
+	ctx context.Context,
+	notification TaskConfigNotification,
+) error {
+	return m.notify(ctx, TaskConfigChannel, notification)
+}
+
+// NotifyTaskStop sends a notification on the task_stop_notification PostgreSQL channel, asking
+// the instance running the given task to stop it.
+func (m *TaskNotificationModel) NotifyTaskStop(
+	ctx context.Context,
+	notification TaskNotification,
+) error {
+	return m.notify(ctx, TaskStopChannel, notification)
+}
+
+// NotifyTaskLog sends a notification on the task_log_notification PostgreSQL channel, telling
+// listeners that new log lines of the given run can be read.
+func (m *TaskNotificationModel) NotifyTaskLog(
+	ctx context.Context,
+	notification TaskLogNotification,
+) error {
+	return m.notify(ctx, TaskLogChannel, notification)
+}
+
+func (m *TaskNotificationModel) notify(ctx context.Context, channel string, notification any) error {
 	logger := logging.LoggerFromContext(ctx)
 
-	logger.Info("jsonifying notification", "notification", notification)
+	logger.Info("jsonifying notification", "channel", channel, "notification", notification)
 	payload, err := json.Marshal(notification)
 	if err != nil {
 		return err
@@ -53,7 +110,7 @@ func (m *TaskNotificationModel) Notify(ctx context.Context, notification TaskNot
 	defer conn.Release()
 
 	logger.Info("executing notification statement")
-	_, err = conn.Exec(context.Background(), query, "task_queue_notification", string(payload))
+	_, err = conn.Exec(context.Background(), query, channel, string(payload))
 	if err != nil {
 		logger.Error("unable to execute notification statement", "error", err)
 		return err
@@ -71,70 +128,160 @@ func (m *TaskNotificationModel) Listen(
 	ctx context.Context,
 	notificationCh chan<- pgconn.Notification,
 	done <-chan struct{},
+) {
+	m.listen(ctx, TaskQueueChannel, notificationCh, done)
+}
+
+// ListenTaskConfig listens for notifications on the task_config_notification PostgreSQL channel.
+// The payload can be decoded to a TaskConfigNotification.
+//
+// A done channel is needed to perform a clean shutdown.
+func (m *TaskNotificationModel) ListenTaskConfig(
+	ctx context.Context,
+	notificationCh chan<- pgconn.Notification,
+	done <-chan struct{},
+) {
+	m.listen(ctx, TaskConfigChannel, notificationCh, done)
+}
+
+// ListenTaskStop listens for notifications on the task_stop_notification PostgreSQL channel.
+// The payload can be decoded to a TaskNotification.
+//
+// A done channel is needed to perform a clean shutdown.
+func (m *TaskNotificationModel) ListenTaskStop(
+	ctx context.Context,
+	notificationCh chan<- pgconn.Notification,
+	done <-chan struct{},
+) {
+	m.listen(ctx, TaskStopChannel, notificationCh, done)
+}
+
+// ListenTaskLog listens for notifications on the task_log_notification PostgreSQL channel.
+// The payload can be decoded to a TaskLogNotification.
+//
+// A done channel is needed to perform a clean shutdown.
+func (m *TaskNotificationModel) ListenTaskLog(
+	ctx context.Context,
+	notificationCh chan<- pgconn.Notification,
+	done <-chan struct{},
+) {
+	m.listen(ctx, TaskLogChannel, notificationCh, done)
+}
+
+// ListenEventOutbox listens for notifications on the PostgreSQL channel announcing new events in
+// the event outbox. The payload is the ID of the stored event.
+//
+// A done channel is needed to perform a clean shutdown.
+func (m *TaskNotificationModel) ListenEventOutbox(
+	ctx context.Context,
+	notificationCh chan<- pgconn.Notification,
+	done <-chan struct{},
+) {
+	m.listen(ctx, events.OutboxChannel, notificationCh, done)
+}
+
+func (m *TaskNotificationModel) listen(
+	ctx context.Context,
+	channel string,
+	notificationCh chan<- pgconn.Notification,
+	done <-chan struct{},
 ) {
 	logger := logging.LoggerFromContext(ctx)
 
-	query := `LISTEN task_queue_notification;`
-	unlistenQuery := "UNLISTEN task_queue_notification;"
+	query := "LISTEN " + pgx.Identifier{channel}.Sanitize() + ";"
+	unlistenQuery := "UNLISTEN " + pgx.Identifier{channel}.Sanitize() + ";"
 
 	logger = logger.With(
+		slog.String("channel", channel),
 		slog.Group(
 			"query",
 			slog.String("statement", database.MinifySQL(query)),
 		),
 	)
 
+	// Waiting for a notification blocks until one arrives, so the wait is cancelled on the done
+	// signal to let go of the connection.
+	listenCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		select {
+		case <-done:
+		case <-listenCtx.Done():
+		}
+		cancel()
+	}()
+
 	for {
+		select {
+		case <-done:
+			logger.Info("received done signal, stopping listener.")
+			return
+		default:
+		}
+
 		logger.Info("acquiring connection")
-		conn, err := m.Pool.Acquire(ctx)
+		conn, err := m.Pool.Acquire(listenCtx)
 		if err != nil {
 			logger.Error("unable to acquire connection", "error", err)
 			time.Sleep(5 * time.Second)
 			continue
 		}
-		defer conn.Release()
 		logger.Info("connection acuired")
 
-		logger.Info("listening for task notifications")
-		_, err = conn.Exec(ctx, query)
+		logger.Info("listening for notifications")
+		_, err = conn.Exec(listenCtx, query)
 		if err != nil {
 			logger.Error("unable to listen, releasing connection", "error", err)
 			conn.Release()
 			continue
 		}
 
-		for {
+		if stop := m.receive(listenCtx, conn, notificationCh, done); stop {
+			_, err := conn.Exec(context.Background(), unlistenQuery)
+			if err != nil {
+				logger.Info("unable to stop listening", "error", err, "query", unlistenQuery)
+			}
+			conn.Release()
+			return
+		}
+
+		// The connection is assumed broken, and a new one is acquired
+		conn.Release()
+		time.Sleep(5 * time.Second)
+	}
+}
+
+// receive forwards notifications from the connection to the notification channel. Returns true
+// when the done signal is received, or false if the connection is no longer usable.
+func (m *TaskNotificationModel) receive(
+	ctx context.Context,
+	conn *pgxpool.Conn,
+	notificationCh chan<- pgconn.Notification,
+	done <-chan struct{},
+) bool {
+	logger := logging.LoggerFromContext(ctx)
+
+	for {
+		notification, err := conn.Conn().WaitForNotification(ctx)
+		if err != nil {
 			select {
 			case <-done:
 				logger.Info("received done signal, stopping listener.")
-				_, err := conn.Exec(ctx, unlistenQuery)
-				if err != nil {
-					logger.Info("unable to stop listening", "error", err, "query", unlistenQuery)
-				}
-				return
+				return true
 			default:
-				if notification, err := conn.Conn().WaitForNotification(ctx); err != nil {
-					logger.Info("unable to receive notification", "error", err)
-					break
-				} else {
-					select {
-					case notificationCh <- *notification:
-						logger.Info("notification sent to channel")
-					case <-done:
-						logger.Info("received done signal, stopping listener.")
-
-						_, err := conn.Exec(ctx, unlistenQuery)
-						if err != nil {
-							logger.Info("unable to stop listening", "error", err, "query", unlistenQuery)
-						}
-						return
-					default:
-						logger.Error("channel full, notification not sent")
-						time.Sleep(5 * time.Second)
-						continue
-					}
-				}
+				logger.Info("unable to receive notification", "error", err)
+				return false
 			}
 		}
+
+		select {
+		case notificationCh <- *notification:
+			logger.Info("notification sent to channel")
+		case <-done:
+			logger.Info("received done signal, stopping listener.")
+			return true
+		default:
+			logger.Error("channel full, notification not sent")
+		}
 	}
 }
@@ -66,3 +66,91 @@ func TestTaskNotificationModel(t *testing.T) {
 		t.Fatal("Did not receive notification in time")
 	}
 }
+
+func TestTaskConfigNotification(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	notificationCh := make(chan pgconn.Notification, 1)
+	doneCh := make(chan struct{})
+	defer close(doneCh)
+
+	go models.TaskNotifications.ListenTaskConfig(ctx, notificationCh, doneCh)
+
+	notification := data.TaskConfigNotification{Name: "test_task"}
+
+	time.Sleep(1 * time.Second)
+
+	err := models.TaskNotifications.NotifyTaskConfig(ctx, notification)
+	if err != nil {
+		t.Errorf("unable to notify: %s", err)
+		return
+	}
+
+	select {
+	case n := <-notificationCh:
+		if n.Channel != data.TaskConfigChannel {
+			t.Errorf("expected channel %s, got %s\n", data.TaskConfigChannel, n.Channel)
+			return
+		}
+		var receivedNotification data.TaskConfigNotification
+		err := json.Unmarshal([]byte(n.Payload), &receivedNotification)
+		if err != nil {
+			t.Errorf("unable to unmarhsal notification: %s\n", err)
+			return
+		}
+		if notification.Name != receivedNotification.Name {
+			t.Errorf(
+				"expected task name %s, got %s\n",
+				notification.Name, receivedNotification.Name,
+			)
+			return
+		}
+	case <-ctx.Done():
+		t.Fatal("Did not receive notification in time")
+	}
+}
+
+func TestTaskLogNotification(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	notificationCh := make(chan pgconn.Notification, 1)
+	doneCh := make(chan struct{})
+	defer close(doneCh)
+
+	go models.TaskNotifications.ListenTaskLog(ctx, notificationCh, doneCh)
+
+	notification := data.TaskLogNotification{TaskID: uuid.New()}
+
+	time.Sleep(1 * time.Second)
+
+	err := models.TaskNotifications.NotifyTaskLog(ctx, notification)
+	if err != nil {
+		t.Errorf("unable to notify: %s", err)
+		return
+	}
+
+	select {
+	case n := <-notificationCh:
+		if n.Channel != data.TaskLogChannel {
+			t.Errorf("expected channel %s, got %s\n", data.TaskLogChannel, n.Channel)
+			return
+		}
+		var receivedNotification data.TaskLogNotification
+		err := json.Unmarshal([]byte(n.Payload), &receivedNotification)
+		if err != nil {
+			t.Errorf("unable to unmarhsal notification: %s\n", err)
+			return
+		}
+		if notification.TaskID != receivedNotification.TaskID {
+			t.Errorf(
+				"expected task ID %s, got %s\n",
+				notification.TaskID, receivedNotification.TaskID,
+			)
+			return
+		}
+	case <-ctx.Done():
+		t.Fatal("Did not receive notification in time")
+	}
+}
@@ -2,6 +2,8 @@ package data
 
 import (
 	"context"
+	"database/sql"
+	"encoding/json"
 	"errors"
 	"log/slog"
 	"time"
@@ -22,15 +24,29 @@ const (
 	StoppedTaskState  TaskState = "stopped"
 	ErrorTaskState    TaskState = "error"
 	SkippedTaskState  TaskState = "skipped"
+	// DeadTaskState is used for runs that failed on their final attempt
+	DeadTaskState TaskState = "dead"
+	// BlockedTaskState is used for runs waiting for the run they are chained after to complete
+	BlockedTaskState TaskState = "blocked"
+	// AwaitingTaskState is used for runs that have finished, but are waiting for their children
+	AwaitingTaskState TaskState = "awaiting"
 )
 
 type TaskQueue struct {
-	ID        uuid.UUID  `json:"id"`
-	Name      *string    `json:"name"`
-	State     *string    `json:"state"`
-	CreatedAt *time.Time `json:"createdAt"`
-	UpdatedAt *time.Time `json:"updatedAt"`
-	RunAt     *time.Time `json:"runAt"`
+	ID          uuid.UUID       `json:"id"`
+	Name        *string         `json:"name"`
+	State       *string         `json:"state"`
+	CreatedAt   *time.Time      `json:"createdAt"`
+	UpdatedAt   *time.Time      `json:"updatedAt"`
+	RunAt       *time.Time      `json:"runAt"`
+	Attempt     *int            `json:"attempt"`
+	LastError   *string         `json:"lastError"`
+	HeartbeatAt *time.Time      `json:"heartbeatAt"`
+	Payload     json.RawMessage `json:"payload"`
+	ParentID    *uuid.UUID      `json:"parentId"`
+	AfterID     *uuid.UUID      `json:"afterId"`
+	StartedAt   *time.Time      `json:"startedAt"`
+	FinishedAt  *time.Time      `json:"finishedAt"`
 }
 
 type TaskQueueModel struct {
@@ -47,7 +63,15 @@ SELECT id,
        state,
        created_at,
        updated_at,
-       run_at
+       run_at,
+       attempt,
+       last_error,
+       heartbeat_at,
+       payload,
+       parent_id,
+       after_id,
+       started_at,
+       finished_at
 FROM orchestrator.task_queue
 WHERE id = $1;
 `
@@ -73,6 +97,14 @@ WHERE id = $1;
 		&task.CreatedAt,
 		&task.UpdatedAt,
 		&task.RunAt,
+		&task.Attempt,
+		&task.LastError,
+		&task.HeartbeatAt,
+		&task.Payload,
+		&task.ParentID,
+		&task.AfterID,
+		&task.StartedAt,
+		&task.FinishedAt,
 	)
 	if err != nil {
 		switch {
@@ -102,7 +134,15 @@ SELECT COUNT(*) OVER() AS total,
        state,
        created_at,
        updated_at,
-       run_at
+       run_at,
+       attempt,
+       last_error,
+       heartbeat_at,
+       payload,
+       parent_id,
+       after_id,
+       started_at,
+       finished_at
 FROM orchestrator.task_queue
 WHERE ($1::uuid IS NULL OR id = $1::uuid)
   AND ($2::text IS NULL OR name = $2::text)
@@ -147,6 +187,10 @@ OFFSET $10 FETCH NEXT $11 ROWS ONLY;
 		filters.offset(),
 		filters.limit(),
 	)
+	if err != nil {
+		logger.Error("an error occurred while performing query", "error", err)
+		return nil, nil, err
+	}
 	defer rows.Close()
 
 	for rows.Next() {
@@ -160,6 +204,14 @@ OFFSET $10 FETCH NEXT $11 ROWS ONLY;
 			&task.CreatedAt,
 			&task.UpdatedAt,
 			&task.RunAt,
+			&task.Attempt,
+			&task.LastError,
+			&task.HeartbeatAt,
+			&task.Payload,
+			&task.ParentID,
+			&task.AfterID,
+			&task.StartedAt,
+			&task.FinishedAt,
 		)
 		if err != nil {
 			return nil, nil, err
@@ -188,17 +240,31 @@ func (m *TaskQueueModel) Insert(
 	query := `
 INSERT INTO orchestrator.task_queue (name,
                                state,
-                               run_at)
+                               run_at,
+                               payload,
+                               parent_id,
+                               after_id)
 VALUES ($1::TEXT,
         COALESCE($2::task_state, 'waiting'),
-        COALESCE($3::TIMESTAMP, CURRENT_TIMESTAMP))
+        COALESCE($3::TIMESTAMP, CURRENT_TIMESTAMP),
+        $4::JSONB,
+        $5::UUID,
+        $6::UUID)
 RETURNING
     id,
     name,
     state,
     created_at,
     updated_at,
-    run_at;
+    run_at,
+    attempt,
+    last_error,
+    heartbeat_at,
+    payload,
+    parent_id,
+    after_id,
+    started_at,
+    finished_at;
 `
 
 	qCtx, cancel := context.WithTimeout(ctx, *m.Timeout)
@@ -215,15 +281,31 @@ RETURNING
 	task = &TaskQueue{}
 
 	logger.Info("performing query")
-	err = m.Pool.QueryRow(qCtx, query, newTask.Name, newTask.State, newTask.RunAt).
-		Scan(
-			&task.ID,
-			&task.Name,
-			&task.State,
-			&task.CreatedAt,
-			&task.UpdatedAt,
-			&task.RunAt,
-		)
+	err = m.Pool.QueryRow(
+		qCtx,
+		query,
+		newTask.Name,
+		newTask.State,
+		newTask.RunAt,
+		nullPayload(newTask.Payload),
+		newTask.ParentID,
+		newTask.AfterID,
+	).Scan(
+		&task.ID,
+		&task.Name,
+		&task.State,
+		&task.CreatedAt,
+		&task.UpdatedAt,
+		&task.RunAt,
+		&task.Attempt,
+		&task.LastError,
+		&task.HeartbeatAt,
+		&task.Payload,
+		&task.ParentID,
+		&task.AfterID,
+		&task.StartedAt,
+		&task.FinishedAt,
+	)
 	if err != nil {
 		switch {
 		case errors.Is(err, pgx.ErrNoRows):
@@ -239,6 +321,102 @@ RETURNING
 	return task, nil
 }
 
+// InsertFenced inserts a task in the queue on behalf of the leader with the given term.
+// ErrStaleTerm is returned, and nothing is inserted, if a new leader has been elected since.
+func (m *TaskQueueModel) InsertFenced(
+	ctx context.Context,
+	newTask TaskQueue,
+	term int64,
+) (task *TaskQueue, err error) {
+	logger := logging.LoggerFromContext(ctx)
+
+	query := `
+INSERT INTO orchestrator.task_queue (name,
+                               state,
+                               run_at,
+                               payload,
+                               parent_id,
+                               after_id)
+SELECT $1::TEXT,
+       COALESCE($2::task_state, 'waiting'),
+       COALESCE($3::TIMESTAMP, CURRENT_TIMESTAMP),
+       $4::JSONB,
+       $5::UUID,
+       $6::UUID
+WHERE EXISTS (SELECT 1 FROM orchestrator.leader WHERE term = $7::bigint FOR SHARE)
+RETURNING
+    id,
+    name,
+    state,
+    created_at,
+    updated_at,
+    run_at,
+    attempt,
+    last_error,
+    heartbeat_at,
+    payload,
+    parent_id,
+    after_id,
+    started_at,
+    finished_at;
+`
+
+	qCtx, cancel := context.WithTimeout(ctx, *m.Timeout)
+	defer cancel()
+
+	logger = logger.With(
+		slog.Group(
+			"query",
+			slog.String("statement", database.MinifySQL(query)),
+			"newTask", newTask,
+			slog.Int64("term", term),
+		),
+	)
+
+	task = &TaskQueue{}
+
+	logger.Info("performing query")
+	err = m.Pool.QueryRow(
+		qCtx,
+		query,
+		newTask.Name,
+		newTask.State,
+		newTask.RunAt,
+		nullPayload(newTask.Payload),
+		newTask.ParentID,
+		newTask.AfterID,
+		term,
+	).Scan(
+		&task.ID,
+		&task.Name,
+		&task.State,
+		&task.CreatedAt,
+		&task.UpdatedAt,
+		&task.RunAt,
+		&task.Attempt,
+		&task.LastError,
+		&task.HeartbeatAt,
+		&task.Payload,
+		&task.ParentID,
+		&task.AfterID,
+		&task.StartedAt,
+		&task.FinishedAt,
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			logger.Info("leader term is no longer current")
+			return nil, ErrStaleTerm
+		default:
+			logger.Error("an error occurred while performing query", "error", err)
+			return nil, err
+		}
+	}
+
+	logger.Info("returning task")
+	return task, nil
+}
+
 func (m *TaskQueueModel) Update(
 	ctx context.Context,
 	newTaskData TaskQueue,
@@ -250,7 +428,9 @@ UPDATE orchestrator.task_queue
 SET name = COALESCE($2::text, name),
 	state = COALESCE($3::task_state, state),
 	created_at = COALESCE($4::timestamp, created_at),
-	run_at = COALESCE($5::timestamp, run_at)
+	run_at = COALESCE($5::timestamp, run_at),
+	attempt = COALESCE($6::integer, attempt),
+	last_error = COALESCE($7::text, last_error)
 WHERE id = $1::uuid
 RETURNING
     id,
@@ -258,7 +438,15 @@ RETURNING
     state,
     created_at,
     updated_at,
-    run_at;
+    run_at,
+    attempt,
+    last_error,
+    heartbeat_at,
+    payload,
+    parent_id,
+    after_id,
+    started_at,
+    finished_at;
 `
 
 	qCtx, cancel := context.WithTimeout(ctx, *m.Timeout)
@@ -283,6 +471,8 @@ RETURNING
 		newTaskData.State,
 		newTaskData.CreatedAt,
 		newTaskData.RunAt,
+		newTaskData.Attempt,
+		newTaskData.LastError,
 	).Scan(
 		&updatedTask.ID,
 		&updatedTask.Name,
@@ -290,6 +480,14 @@ RETURNING
 		&updatedTask.CreatedAt,
 		&updatedTask.UpdatedAt,
 		&updatedTask.RunAt,
+		&updatedTask.Attempt,
+		&updatedTask.LastError,
+		&updatedTask.HeartbeatAt,
+		&updatedTask.Payload,
+		&updatedTask.ParentID,
+		&updatedTask.AfterID,
+		&updatedTask.StartedAt,
+		&updatedTask.FinishedAt,
 	)
 	if err != nil {
 		switch {
@@ -318,7 +516,15 @@ RETURNING
     state,
     created_at,
     updated_at,
-    run_at;
+    run_at,
+    attempt,
+    last_error,
+    heartbeat_at,
+    payload,
+    parent_id,
+    after_id,
+    started_at,
+    finished_at;
 
 `
 	qCtx, cancel := context.WithTimeout(ctx, *m.Timeout)
@@ -342,6 +548,14 @@ RETURNING
 		&task.CreatedAt,
 		&task.UpdatedAt,
 		&task.RunAt,
+		&task.Attempt,
+		&task.LastError,
+		&task.HeartbeatAt,
+		&task.Payload,
+		&task.ParentID,
+		&task.AfterID,
+		&task.StartedAt,
+		&task.FinishedAt,
 	)
 	if err != nil {
 		switch {
@@ -358,12 +572,112 @@ RETURNING
 	return task, nil
 }
 
-// Uses a preexisting transaction to select a task and lock a row by it's ID.
-// The row cannot be changed while the transaction is active.
+// Uses a preexisting transaction to select a task and lock a row by it's ID, so that it can be
+// set to a running state. ErrConcurrencyLimit is returned if the max concurrency of the task
+// has been reached.
+//
+// Claims of the same task are serialized with an advisory lock held until the transaction
+// ends, so the state of the claimed row must be updated before the transaction is committed.
 func (m *TaskQueueModel) ClaimTx(
 	ctx context.Context,
 	tx pgx.Tx,
 	id uuid.UUID,
+) (task *TaskQueue, err error) {
+	err = m.checkConcurrencyTx(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return m.LockTx(ctx, tx, id)
+}
+
+// checkConcurrencyTx returns ErrConcurrencyLimit if the task of the queue row with the given ID
+// already has as many running rows as the max concurrency of the task allows.
+func (m *TaskQueueModel) checkConcurrencyTx(ctx context.Context, tx pgx.Tx, id uuid.UUID) error {
+	logger := logging.LoggerFromContext(ctx)
+
+	query := `
+SELECT q.name,
+       t.max_concurrency
+FROM orchestrator.task_queue q
+         LEFT JOIN orchestrator.tasks t ON t.name = q.name
+WHERE q.id = $1::uuid;
+`
+
+	lockQuery := `
+SELECT pg_advisory_xact_lock(hashtext('orchestrator.task_queue.' || $1::text));
+`
+
+	countQuery := `
+SELECT COUNT(*)
+FROM orchestrator.task_queue
+WHERE name = $1::text
+  AND state = 'running';
+`
+
+	qCtx, cancel := context.WithTimeout(ctx, *m.Timeout)
+	defer cancel()
+
+	logger = logger.With(
+		slog.Group(
+			"query",
+			slog.String("statement", database.MinifySQL(query)),
+			slog.String("id", id.String()),
+		),
+	)
+
+	var name string
+	var maxConcurrency sql.NullInt32
+
+	logger.Info("performing query")
+	err := tx.QueryRow(qCtx, query, id.String()).Scan(&name, &maxConcurrency)
+	if err != nil {
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			logger.Info("no rows found", slog.String("taskId", id.String()))
+			return ErrRecordNotFound
+		default:
+			logger.Error("an error occurred while performing query", "error", err)
+			return err
+		}
+	}
+	if !maxConcurrency.Valid || maxConcurrency.Int32 <= 0 {
+		logger.Info("task has no concurrency limit", slog.String("name", name))
+		return nil
+	}
+
+	logger.Info("acquiring task lock", slog.String("statement", database.MinifySQL(lockQuery)))
+	_, err = tx.Exec(qCtx, lockQuery, name)
+	if err != nil {
+		logger.Error("an error occurred while acquiring task lock", "error", err)
+		return err
+	}
+
+	var running int32
+	logger.Info("counting running tasks", slog.String("statement", database.MinifySQL(countQuery)))
+	err = tx.QueryRow(qCtx, countQuery, name).Scan(&running)
+	if err != nil {
+		logger.Error("an error occurred while counting running tasks", "error", err)
+		return err
+	}
+	if running >= maxConcurrency.Int32 {
+		logger.Info(
+			"task concurrency limit reached",
+			slog.Int("running", int(running)),
+			slog.Int("maxConcurrency", int(maxConcurrency.Int32)),
+		)
+		return ErrConcurrencyLimit
+	}
+
+	return nil
+}
+
+// Uses a preexisting transaction to select a waiting task and lock a row by it's ID.
+// The row cannot be changed while the transaction is active.
+func (m *TaskQueueModel) LockTx(
+	ctx context.Context,
+	tx pgx.Tx,
+	id uuid.UUID,
 ) (task *TaskQueue, err error) {
 	logger := logging.LoggerFromContext(ctx)
 
@@ -373,7 +687,15 @@ SELECT id,
        state,
        created_at,
        updated_at,
-       run_at
+       run_at,
+       attempt,
+       last_error,
+       heartbeat_at,
+       payload,
+       parent_id,
+       after_id,
+       started_at,
+       finished_at
 FROM orchestrator.task_queue
 WHERE id = $1::uuid
 	AND run_at <= NOW()
@@ -404,6 +726,14 @@ LIMIT 1;
 		&task.CreatedAt,
 		&task.UpdatedAt,
 		&task.RunAt,
+		&task.Attempt,
+		&task.LastError,
+		&task.HeartbeatAt,
+		&task.Payload,
+		&task.ParentID,
+		&task.AfterID,
+		&task.StartedAt,
+		&task.FinishedAt,
 	)
 	if err != nil {
 		switch {
@@ -441,7 +771,15 @@ RETURNING
     state,
     created_at,
     updated_at,
-	run_at;
+	run_at,
+	attempt,
+	last_error,
+	heartbeat_at,
+	payload,
+	parent_id,
+	after_id,
+	started_at,
+	finished_at;
 `
 
 	qCtx, cancel := context.WithTimeout(ctx, *m.Timeout)
@@ -465,6 +803,14 @@ RETURNING
 		&task.CreatedAt,
 		&task.UpdatedAt,
 		&task.RunAt,
+		&task.Attempt,
+		&task.LastError,
+		&task.HeartbeatAt,
+		&task.Payload,
+		&task.ParentID,
+		&task.AfterID,
+		&task.StartedAt,
+		&task.FinishedAt,
 	)
 	if err != nil {
 		switch {
@@ -493,7 +839,9 @@ UPDATE orchestrator.task_queue
 SET name = COALESCE($2::text, name),
 	state = COALESCE($3::task_state, state),
 	created_at = COALESCE($4::timestamp, created_at),
-	run_at = COALESCE($5::timestamp, run_at)
+	run_at = COALESCE($5::timestamp, run_at),
+	attempt = COALESCE($6::integer, attempt),
+	last_error = COALESCE($7::text, last_error)
 WHERE id = $1::uuid
 RETURNING
     id,
@@ -501,7 +849,15 @@ RETURNING
     state,
     created_at,
     updated_at,
-	run_at;
+	run_at,
+	attempt,
+	last_error,
+	heartbeat_at,
+	payload,
+	parent_id,
+	after_id,
+	started_at,
+	finished_at;
 `
 
 	qCtx, cancel := context.WithTimeout(ctx, *m.Timeout)
@@ -526,6 +882,8 @@ RETURNING
 		taskQueue.State,
 		taskQueue.CreatedAt,
 		taskQueue.RunAt,
+		taskQueue.Attempt,
+		taskQueue.LastError,
 	).Scan(
 		&task.ID,
 		&task.Name,
@@ -533,6 +891,14 @@ RETURNING
 		&task.CreatedAt,
 		&task.UpdatedAt,
 		&task.RunAt,
+		&task.Attempt,
+		&task.LastError,
+		&task.HeartbeatAt,
+		&task.Payload,
+		&task.ParentID,
+		&task.AfterID,
+		&task.StartedAt,
+		&task.FinishedAt,
 	)
 	if err != nil {
 		switch {
@@ -548,3 +914,834 @@ RETURNING
 	logger.Info("returning task")
 	return task, nil
 }
+
+// Cancel sets a waiting or blocked task to the stopped state, preventing it from being run.
+// Tasks that are not waiting or blocked are left as is, and ErrRecordNotFound is returned.
+func (m *TaskQueueModel) Cancel(ctx context.Context, id uuid.UUID) (task *TaskQueue, err error) {
+	logger := logging.LoggerFromContext(ctx)
+
+	query := `
+UPDATE orchestrator.task_queue
+SET state = 'stopped'
+WHERE id = $1::uuid
+  AND state IN ('waiting', 'blocked')
+RETURNING
+    id,
+    name,
+    state,
+    created_at,
+    updated_at,
+    run_at,
+    attempt,
+    last_error,
+    heartbeat_at,
+    payload,
+    parent_id,
+    after_id,
+    started_at,
+    finished_at;
+`
+
+	qCtx, cancel := context.WithTimeout(ctx, *m.Timeout)
+	defer cancel()
+
+	logger = logger.With(
+		slog.Group(
+			"query",
+			slog.String("statement", database.MinifySQL(query)),
+			slog.String("id", id.String()),
+		),
+	)
+
+	task = &TaskQueue{}
+
+	logger.Info("performing query")
+	err = m.Pool.QueryRow(qCtx, query, id.String()).Scan(
+		&task.ID,
+		&task.Name,
+		&task.State,
+		&task.CreatedAt,
+		&task.UpdatedAt,
+		&task.RunAt,
+		&task.Attempt,
+		&task.LastError,
+		&task.HeartbeatAt,
+		&task.Payload,
+		&task.ParentID,
+		&task.AfterID,
+		&task.StartedAt,
+		&task.FinishedAt,
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			logger.Info("no waiting task found")
+			return nil, ErrRecordNotFound
+		default:
+			logger.Error("an error occurred while performing query", "error", err)
+			return nil, err
+		}
+	}
+
+	logger.Info("returning task")
+	return task, nil
+}
+
+// Replay puts a dead task back in the queue to run immediately, resetting the attempt count. Tasks
+// that are not dead are left as is, and ErrRecordNotFound is returned.
+//
+// The children of the task are removed, as the task enqueues them again when it is run.
+func (m *TaskQueueModel) Replay(ctx context.Context, id uuid.UUID) (task *TaskQueue, err error) {
+	logger := logging.LoggerFromContext(ctx)
+
+	query := `
+WITH replayed AS (
+    UPDATE orchestrator.task_queue
+        SET state = 'waiting',
+            attempt = 1,
+            run_at = NOW()
+        WHERE id = $1::uuid
+            AND state = 'dead'
+        RETURNING
+            id,
+            name,
+            state,
+            created_at,
+            updated_at,
+            run_at,
+            attempt,
+            last_error,
+            heartbeat_at,
+            payload,
+            parent_id,
+            after_id,
+            started_at,
+            finished_at),
+     removed AS (
+         DELETE FROM orchestrator.task_queue
+             WHERE parent_id IN (SELECT id FROM replayed))
+SELECT *
+FROM replayed;
+`
+
+	qCtx, cancel := context.WithTimeout(ctx, *m.Timeout)
+	defer cancel()
+
+	logger = logger.With(
+		slog.Group(
+			"query",
+			slog.String("statement", database.MinifySQL(query)),
+			slog.String("id", id.String()),
+		),
+	)
+
+	task = &TaskQueue{}
+
+	logger.Info("performing query")
+	err = m.Pool.QueryRow(qCtx, query, id.String()).Scan(
+		&task.ID,
+		&task.Name,
+		&task.State,
+		&task.CreatedAt,
+		&task.UpdatedAt,
+		&task.RunAt,
+		&task.Attempt,
+		&task.LastError,
+		&task.HeartbeatAt,
+		&task.Payload,
+		&task.ParentID,
+		&task.AfterID,
+		&task.StartedAt,
+		&task.FinishedAt,
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			logger.Info("no dead task found")
+			return nil, ErrRecordNotFound
+		default:
+			logger.Error("an error occurred while performing query", "error", err)
+			return nil, err
+		}
+	}
+
+	logger.Info("returning task")
+	return task, nil
+}
+
+// Heartbeat marks a running task as alive. Running tasks without a recent heartbeat are
+// considered orphaned, and are put back in the queue.
+func (m *TaskQueueModel) Heartbeat(ctx context.Context, id uuid.UUID) error {
+	logger := logging.LoggerFromContext(ctx)
+
+	query := `
+UPDATE orchestrator.task_queue
+SET heartbeat_at = NOW()
+WHERE id = $1::uuid
+  AND state = 'running';
+`
+
+	qCtx, cancel := context.WithTimeout(ctx, *m.Timeout)
+	defer cancel()
+
+	logger = logger.With(
+		slog.Group(
+			"query",
+			slog.String("statement", database.MinifySQL(query)),
+			slog.String("id", id.String()),
+		),
+	)
+
+	logger.Info("performing query")
+	result, err := m.Pool.Exec(qCtx, query, id.String())
+	if err != nil {
+		logger.Error("an error occurred while performing query", "error", err)
+		return err
+	}
+	if result.RowsAffected() < 1 {
+		logger.Info("no running task found")
+		return ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// RequeueOrphaned puts running tasks without a heartbeat since staleBefore back in the queue,
+// to run immediately. The orphaned run counts as an attempt, and tasks without attempts left are
+// moved to the dead state instead. Returns the requeued and dead tasks.
+func (m *TaskQueueModel) RequeueOrphaned(
+	ctx context.Context,
+	staleBefore time.Time,
+) (tasks []*TaskQueue, err error) {
+	logger := logging.LoggerFromContext(ctx)
+
+	query := `
+UPDATE orchestrator.task_queue q
+SET state = CASE
+                WHEN q.attempt < t.max_attempts THEN 'waiting'::task_state
+                ELSE 'dead'::task_state
+    END,
+    attempt = CASE WHEN q.attempt < t.max_attempts THEN q.attempt + 1 ELSE q.attempt END,
+    run_at = CASE WHEN q.attempt < t.max_attempts THEN NOW() ELSE q.run_at END,
+    heartbeat_at = NULL,
+    last_error = 'run orphaned: no heartbeat since ' ||
+                 to_char(COALESCE(q.heartbeat_at, q.updated_at), 'YYYY-MM-DD"T"HH24:MI:SS')
+FROM orchestrator.tasks t
+WHERE t.name = q.name
+  AND q.state = 'running'
+  AND COALESCE(q.heartbeat_at, q.updated_at) < $1::timestamp
+RETURNING
+    q.id,
+    q.name,
+    q.state,
+    q.created_at,
+    q.updated_at,
+    q.run_at,
+    q.attempt,
+    q.last_error,
+    q.heartbeat_at,
+    q.payload,
+    q.parent_id,
+    q.after_id,
+    q.started_at,
+    q.finished_at;
+`
+
+	qCtx, cancel := context.WithTimeout(ctx, *m.Timeout)
+	defer cancel()
+
+	logger = logger.With(
+		slog.Group(
+			"query",
+			slog.String("statement", database.MinifySQL(query)),
+			slog.Time("staleBefore", staleBefore),
+		),
+	)
+
+	tasks = []*TaskQueue{}
+
+	logger.Info("performing query")
+	rows, err := m.Pool.Query(qCtx, query, staleBefore)
+	if err != nil {
+		logger.Error("an error occurred while performing query", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var task TaskQueue
+
+		err := rows.Scan(
+			&task.ID,
+			&task.Name,
+			&task.State,
+			&task.CreatedAt,
+			&task.UpdatedAt,
+			&task.RunAt,
+			&task.Attempt,
+			&task.LastError,
+			&task.HeartbeatAt,
+			&task.Payload,
+			&task.ParentID,
+			&task.AfterID,
+			&task.StartedAt,
+			&task.FinishedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, &task)
+	}
+	if err = rows.Err(); err != nil {
+		logger.Error("an error occurred while parsing query results", "error", err)
+		return nil, err
+	}
+
+	logger.Info("returning tasks", "length", len(tasks))
+	return tasks, nil
+}
+
+// GetRunnable returns waiting tasks that are due to run, ordered by the priority of their task
+// and then by when they were due.
+func (m *TaskQueueModel) GetRunnable(ctx context.Context, limit int) (tasks []*TaskQueue, err error) {
+	logger := logging.LoggerFromContext(ctx)
+
+	query := `
+SELECT q.id,
+       q.name,
+       q.state,
+       q.created_at,
+       q.updated_at,
+       q.run_at,
+       q.attempt,
+       q.last_error,
+       q.heartbeat_at,
+       q.payload,
+       q.parent_id,
+       q.after_id,
+       q.started_at,
+       q.finished_at
+FROM orchestrator.task_queue q
+         LEFT JOIN orchestrator.tasks t ON t.name = q.name
+WHERE q.state = 'waiting'
+  AND q.run_at <= NOW()
+ORDER BY COALESCE(t.priority, 0) DESC, q.run_at, q.created_at
+LIMIT $1;
+`
+
+	qCtx, cancel := context.WithTimeout(ctx, *m.Timeout)
+	defer cancel()
+
+	logger = logger.With(
+		slog.Group(
+			"query",
+			slog.String("statement", database.MinifySQL(query)),
+			slog.Int("limit", limit),
+		),
+	)
+
+	tasks = []*TaskQueue{}
+
+	logger.Info("performing query")
+	rows, err := m.Pool.Query(qCtx, query, limit)
+	if err != nil {
+		logger.Error("an error occurred while performing query", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var task TaskQueue
+
+		err := rows.Scan(
+			&task.ID,
+			&task.Name,
+			&task.State,
+			&task.CreatedAt,
+			&task.UpdatedAt,
+			&task.RunAt,
+			&task.Attempt,
+			&task.LastError,
+			&task.HeartbeatAt,
+			&task.Payload,
+			&task.ParentID,
+			&task.AfterID,
+			&task.StartedAt,
+			&task.FinishedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, &task)
+	}
+	if err = rows.Err(); err != nil {
+		logger.Error("an error occurred while parsing query results", "error", err)
+		return nil, err
+	}
+
+	logger.Info("returning tasks", "length", len(tasks))
+	return tasks, nil
+}
+
+// GetNextRunnable returns the waiting run of the named task that is next in line to run, which
+// is the run that has been due the longest. ErrRecordNotFound is returned if no run of the task
+// is due.
+func (m *TaskQueueModel) GetNextRunnable(ctx context.Context, name string) (task *TaskQueue, err error) {
+	logger := logging.LoggerFromContext(ctx)
+
+	query := `
+SELECT id,
+       name,
+       state,
+       created_at,
+       updated_at,
+       run_at,
+       attempt,
+       last_error,
+       heartbeat_at,
+       payload,
+       parent_id,
+       after_id,
+       started_at,
+       finished_at
+FROM orchestrator.task_queue
+WHERE name = $1::text
+  AND state = 'waiting'
+  AND run_at <= NOW()
+ORDER BY run_at, created_at
+LIMIT 1;
+`
+
+	qCtx, cancel := context.WithTimeout(ctx, *m.Timeout)
+	defer cancel()
+
+	logger = logger.With(
+		slog.Group(
+			"query",
+			slog.String("statement", database.MinifySQL(query)),
+			slog.String("name", name),
+		),
+	)
+
+	task = &TaskQueue{}
+
+	logger.Info("performing query")
+	err = m.Pool.QueryRow(qCtx, query, name).Scan(
+		&task.ID,
+		&task.Name,
+		&task.State,
+		&task.CreatedAt,
+		&task.UpdatedAt,
+		&task.RunAt,
+		&task.Attempt,
+		&task.LastError,
+		&task.HeartbeatAt,
+		&task.Payload,
+		&task.ParentID,
+		&task.AfterID,
+		&task.StartedAt,
+		&task.FinishedAt,
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			logger.Info("no rows found", slog.String("name", name))
+			return nil, ErrRecordNotFound
+		default:
+			logger.Error("an error occurred while performing query", "error", err)
+			return nil, err
+		}
+	}
+
+	logger.Info("returning task")
+	return task, nil
+}
+
+// Uses a preexisting transaction to insert a task in the queue. Used to insert related tasks,
+// such as the children of a run, all at once.
+func (m *TaskQueueModel) InsertTx(
+	ctx context.Context,
+	tx pgx.Tx,
+	newTask TaskQueue,
+) (task *TaskQueue, err error) {
+	logger := logging.LoggerFromContext(ctx)
+
+	query := `
+INSERT INTO orchestrator.task_queue (name,
+                               state,
+                               run_at,
+                               payload,
+                               parent_id,
+                               after_id)
+VALUES ($1::TEXT,
+        COALESCE($2::task_state, 'waiting'),
+        COALESCE($3::TIMESTAMP, CURRENT_TIMESTAMP),
+        $4::JSONB,
+        $5::UUID,
+        $6::UUID)
+RETURNING
+    id,
+    name,
+    state,
+    created_at,
+    updated_at,
+    run_at,
+    attempt,
+    last_error,
+    heartbeat_at,
+    payload,
+    parent_id,
+    after_id,
+    started_at,
+    finished_at;
+`
+
+	qCtx, cancel := context.WithTimeout(ctx, *m.Timeout)
+	defer cancel()
+
+	logger = logger.With(
+		slog.Group(
+			"query",
+			slog.String("statement", database.MinifySQL(query)),
+			"newTask", newTask,
+		),
+	)
+
+	task = &TaskQueue{}
+
+	logger.Info("performing query")
+	err = tx.QueryRow(
+		qCtx,
+		query,
+		newTask.Name,
+		newTask.State,
+		newTask.RunAt,
+		nullPayload(newTask.Payload),
+		newTask.ParentID,
+		newTask.AfterID,
+	).Scan(
+		&task.ID,
+		&task.Name,
+		&task.State,
+		&task.CreatedAt,
+		&task.UpdatedAt,
+		&task.RunAt,
+		&task.Attempt,
+		&task.LastError,
+		&task.HeartbeatAt,
+		&task.Payload,
+		&task.ParentID,
+		&task.AfterID,
+		&task.StartedAt,
+		&task.FinishedAt,
+	)
+	if err != nil {
+		logger.Error("an error occurred while performing query", "error", err)
+		return nil, err
+	}
+
+	logger.Info("returning task")
+	return task, nil
+}
+
+// Uses a preexisting transaction to select and lock a row by it's ID, regardless of the state of
+// the task. Used to serialize changes to a run and the runs related to it.
+func (m *TaskQueueModel) GetForUpdateTx(
+	ctx context.Context,
+	tx pgx.Tx,
+	id uuid.UUID,
+) (task *TaskQueue, err error) {
+	logger := logging.LoggerFromContext(ctx)
+
+	query := `
+SELECT
+    id,
+    name,
+    state,
+    created_at,
+    updated_at,
+    run_at,
+    attempt,
+    last_error,
+    heartbeat_at,
+    payload,
+    parent_id,
+    after_id,
+    started_at,
+    finished_at
+FROM orchestrator.task_queue
+WHERE id = $1::uuid
+    FOR UPDATE;
+`
+
+	qCtx, cancel := context.WithTimeout(ctx, *m.Timeout)
+	defer cancel()
+
+	logger = logger.With(
+		slog.Group(
+			"query",
+			slog.String("statement", database.MinifySQL(query)),
+			slog.String("id", id.String()),
+		),
+	)
+
+	task = &TaskQueue{}
+
+	logger.Info("performing query")
+	err = tx.QueryRow(qCtx, query, id.String()).Scan(
+		&task.ID,
+		&task.Name,
+		&task.State,
+		&task.CreatedAt,
+		&task.UpdatedAt,
+		&task.RunAt,
+		&task.Attempt,
+		&task.LastError,
+		&task.HeartbeatAt,
+		&task.Payload,
+		&task.ParentID,
+		&task.AfterID,
+		&task.StartedAt,
+		&task.FinishedAt,
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			logger.Info("no rows found")
+			return nil, ErrRecordNotFound
+		default:
+			logger.Error("an error occurred while performing query", "error", err)
+			return nil, err
+		}
+	}
+
+	logger.Info("returning task")
+	return task, nil
+}
+
+// Uses a preexisting transaction to count the children of a run. Unfinished children are
+// children that have yet to run, or are running, while failed children are children that
+// stopped or ran out of attempts.
+func (m *TaskQueueModel) CountChildrenTx(
+	ctx context.Context,
+	tx pgx.Tx,
+	parentID uuid.UUID,
+) (unfinished int, failed int, err error) {
+	logger := logging.LoggerFromContext(ctx)
+
+	query := `
+SELECT COUNT(*) FILTER (WHERE state IN ('waiting', 'running', 'blocked', 'awaiting')),
+       COUNT(*) FILTER (WHERE state IN ('stopped', 'dead', 'error'))
+FROM orchestrator.task_queue
+WHERE parent_id = $1::uuid;
+`
+
+	qCtx, cancel := context.WithTimeout(ctx, *m.Timeout)
+	defer cancel()
+
+	logger = logger.With(
+		slog.Group(
+			"query",
+			slog.String("statement", database.MinifySQL(query)),
+			slog.String("parentId", parentID.String()),
+		),
+	)
+
+	logger.Info("performing query")
+	err = tx.QueryRow(qCtx, query, parentID.String()).Scan(&unfinished, &failed)
+	if err != nil {
+		logger.Error("an error occurred while performing query", "error", err)
+		return 0, 0, err
+	}
+
+	logger.Info("returning counts", "unfinished", unfinished, "failed", failed)
+	return unfinished, failed, nil
+}
+
+// Uses a preexisting transaction to move the blocked runs chained after the given run to the
+// given state. Blocked runs are released to the waiting state when the run they are chained
+// after completes, or skipped if it fails.
+func (m *TaskQueueModel) ReleaseBlockedTx(
+	ctx context.Context,
+	tx pgx.Tx,
+	afterID uuid.UUID,
+	state TaskState,
+) (tasks []*TaskQueue, err error) {
+	logger := logging.LoggerFromContext(ctx)
+
+	query := `
+UPDATE orchestrator.task_queue
+SET state = $2::task_state,
+    run_at = NOW()
+WHERE after_id = $1::uuid
+  AND state = 'blocked'
+RETURNING
+    id,
+    name,
+    state,
+    created_at,
+    updated_at,
+    run_at,
+    attempt,
+    last_error,
+    heartbeat_at,
+    payload,
+    parent_id,
+    after_id,
+    started_at,
+    finished_at;
+`
+
+	qCtx, cancel := context.WithTimeout(ctx, *m.Timeout)
+	defer cancel()
+
+	logger = logger.With(
+		slog.Group(
+			"query",
+			slog.String("statement", database.MinifySQL(query)),
+			slog.String("afterId", afterID.String()),
+			slog.String("state", string(state)),
+		),
+	)
+
+	tasks = []*TaskQueue{}
+
+	logger.Info("performing query")
+	rows, err := tx.Query(qCtx, query, afterID.String(), string(state))
+	if err != nil {
+		logger.Error("an error occurred while performing query", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var task TaskQueue
+
+		err := rows.Scan(
+			&task.ID,
+			&task.Name,
+			&task.State,
+			&task.CreatedAt,
+			&task.UpdatedAt,
+			&task.RunAt,
+			&task.Attempt,
+			&task.LastError,
+			&task.HeartbeatAt,
+			&task.Payload,
+			&task.ParentID,
+			&task.AfterID,
+			&task.StartedAt,
+			&task.FinishedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, &task)
+	}
+	if err = rows.Err(); err != nil {
+		logger.Error("an error occurred while parsing query results", "error", err)
+		return nil, err
+	}
+
+	logger.Info("returning tasks", "length", len(tasks))
+	return tasks, nil
+}
+
+// GetWorkflow returns the run with the given ID followed by all of its descendants, ordered by
+// when they were created.
+func (m *TaskQueueModel) GetWorkflow(ctx context.Context, id uuid.UUID) (tasks []*TaskQueue, err error) {
+	logger := logging.LoggerFromContext(ctx)
+
+	query := `
+WITH RECURSIVE workflow AS (SELECT *, 0 AS depth
+                            FROM orchestrator.task_queue
+                            WHERE id = $1::uuid
+                            UNION ALL
+                            SELECT q.*, w.depth + 1
+                            FROM orchestrator.task_queue q
+                                     JOIN workflow w ON q.parent_id = w.id)
+SELECT
+    id,
+    name,
+    state,
+    created_at,
+    updated_at,
+    run_at,
+    attempt,
+    last_error,
+    heartbeat_at,
+    payload,
+    parent_id,
+    after_id,
+    started_at,
+    finished_at
+FROM workflow
+ORDER BY depth, created_at;
+`
+
+	qCtx, cancel := context.WithTimeout(ctx, *m.Timeout)
+	defer cancel()
+
+	logger = logger.With(
+		slog.Group(
+			"query",
+			slog.String("statement", database.MinifySQL(query)),
+			slog.String("id", id.String()),
+		),
+	)
+
+	tasks = []*TaskQueue{}
+
+	logger.Info("performing query")
+	rows, err := m.Pool.Query(qCtx, query, id.String())
+	if err != nil {
+		logger.Error("an error occurred while performing query", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var task TaskQueue
+
+		err := rows.Scan(
+			&task.ID,
+			&task.Name,
+			&task.State,
+			&task.CreatedAt,
+			&task.UpdatedAt,
+			&task.RunAt,
+			&task.Attempt,
+			&task.LastError,
+			&task.HeartbeatAt,
+			&task.Payload,
+			&task.ParentID,
+			&task.AfterID,
+			&task.StartedAt,
+			&task.FinishedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, &task)
+	}
+	if err = rows.Err(); err != nil {
+		logger.Error("an error occurred while parsing query results", "error", err)
+		return nil, err
+	}
+	if len(tasks) == 0 {
+		logger.Info("no rows found")
+		return nil, ErrRecordNotFound
+	}
+
+	logger.Info("returning tasks", "length", len(tasks))
+	return tasks, nil
+}
+
+// nullPayload converts an empty payload to a NULL value.
+func nullPayload(payload json.RawMessage) *string {
+	if len(payload) == 0 {
+		return nil
+	}
+	p := string(payload)
+	return &p
+}
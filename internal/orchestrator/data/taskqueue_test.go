@@ -3,6 +3,8 @@ package data_test
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"testing"
 	"time"
 
@@ -93,3 +95,527 @@ func TestTaskQueueModel(t *testing.T) {
 		}
 	})
 }
+
+func TestTaskQueueHeartbeat(t *testing.T) {
+	task := data.Task{
+		Name:        "test_heartbeat_queue",
+		CronExpr:    sql.NullString{String: "* * * * *", Valid: true},
+		Enabled:     sql.NullBool{Bool: false, Valid: true},
+		UpdatedAt:   sql.NullTime{Time: time.Now(), Valid: true},
+		MaxAttempts: sql.NullInt32{Int32: 2, Valid: true},
+	}
+	_, err := models.Tasks.Insert(context.Background(), task)
+	if err != nil {
+		t.Errorf("error occurred while inserting task: %s\n", err)
+		return
+	}
+
+	state := string(data.RunningTaskState)
+	tq, err := models.TaskQueues.Insert(
+		context.Background(),
+		data.TaskQueue{Name: &task.Name, State: &state},
+	)
+	if err != nil {
+		t.Errorf("error occurred while inserting new task: %s\n", err)
+		return
+	}
+
+	t.Run("Heartbeat", func(t *testing.T) {
+		err := models.TaskQueues.Heartbeat(context.Background(), tq.ID)
+		if err != nil {
+			t.Errorf("error occurred while sending heartbeat: %s\n", err)
+			return
+		}
+
+		beatingTask, err := models.TaskQueues.Get(context.Background(), tq.ID)
+		if err != nil {
+			t.Errorf("error occurred while reading task: %s\n", err)
+			return
+		}
+		if beatingTask.HeartbeatAt == nil {
+			t.Errorf("expected heartbeat to be set\n")
+			return
+		}
+	})
+
+	t.Run("RequeueOrphanedFresh", func(t *testing.T) {
+		requeuedTasks, err := models.TaskQueues.RequeueOrphaned(
+			context.Background(), time.Now().Add(-time.Hour),
+		)
+		if err != nil {
+			t.Errorf("error occurred while requeueing orphaned tasks: %s\n", err)
+			return
+		}
+		for _, requeuedTask := range requeuedTasks {
+			if requeuedTask.ID == tq.ID {
+				t.Errorf("expected task with a recent heartbeat to be left running\n")
+				return
+			}
+		}
+	})
+
+	t.Run("RequeueOrphanedStale", func(t *testing.T) {
+		requeuedTasks, err := models.TaskQueues.RequeueOrphaned(
+			context.Background(), time.Now().Add(time.Hour),
+		)
+		if err != nil {
+			t.Errorf("error occurred while requeueing orphaned tasks: %s\n", err)
+			return
+		}
+
+		found := false
+		for _, requeuedTask := range requeuedTasks {
+			if requeuedTask.ID == tq.ID {
+				found = true
+				if *requeuedTask.State != string(data.WaitingTaskState) {
+					t.Errorf(
+						"expected state %s, got %s\n", data.WaitingTaskState, *requeuedTask.State,
+					)
+					return
+				}
+				if *requeuedTask.Attempt != 2 {
+					t.Errorf("expected the orphaned run to count as an attempt, got %d\n", *requeuedTask.Attempt)
+					return
+				}
+			}
+		}
+		if !found {
+			t.Errorf("expected task without a recent heartbeat to be requeued\n")
+			return
+		}
+	})
+
+	t.Run("RequeueOrphanedNoAttemptsLeft", func(t *testing.T) {
+		running := string(data.RunningTaskState)
+		_, err := models.TaskQueues.Update(context.Background(), data.TaskQueue{ID: tq.ID, State: &running})
+		if err != nil {
+			t.Errorf("error occurred while updating task: %s\n", err)
+			return
+		}
+
+		requeuedTasks, err := models.TaskQueues.RequeueOrphaned(
+			context.Background(), time.Now().Add(time.Hour),
+		)
+		if err != nil {
+			t.Errorf("error occurred while requeueing orphaned tasks: %s\n", err)
+			return
+		}
+
+		for _, requeuedTask := range requeuedTasks {
+			if requeuedTask.ID == tq.ID {
+				if *requeuedTask.State != string(data.DeadTaskState) {
+					t.Errorf(
+						"expected state %s, got %s\n", data.DeadTaskState, *requeuedTask.State,
+					)
+				}
+				return
+			}
+		}
+		t.Errorf("expected task without a recent heartbeat to be returned\n")
+	})
+
+	t.Run("HeartbeatNotRunning", func(t *testing.T) {
+		err := models.TaskQueues.Heartbeat(context.Background(), tq.ID)
+		if !errors.Is(err, data.ErrRecordNotFound) {
+			t.Errorf("expected %s, got %v\n", data.ErrRecordNotFound, err)
+			return
+		}
+	})
+}
+
+func TestTaskQueuePayload(t *testing.T) {
+	task := data.Task{
+		Name:      "test_payload_queue",
+		CronExpr:  sql.NullString{String: "* * * * *", Valid: true},
+		Enabled:   sql.NullBool{Bool: false, Valid: true},
+		UpdatedAt: sql.NullTime{Time: time.Now(), Valid: true},
+	}
+	_, err := models.Tasks.Insert(context.Background(), task)
+	if err != nil {
+		t.Errorf("error occurred while inserting task: %s\n", err)
+		return
+	}
+
+	t.Run("WithPayload", func(t *testing.T) {
+		insertedTask, err := models.TaskQueues.Insert(context.Background(), data.TaskQueue{
+			Name:    &task.Name,
+			Payload: json.RawMessage(`{"directory":"/srv/books"}`),
+		})
+		if err != nil {
+			t.Errorf("error occurred while inserting new task: %s\n", err)
+			return
+		}
+
+		var payload map[string]string
+		err = json.Unmarshal(insertedTask.Payload, &payload)
+		if err != nil {
+			t.Errorf("unable to decode payload: %s\n", err)
+			return
+		}
+		if payload["directory"] != "/srv/books" {
+			t.Errorf("expected directory /srv/books, got %s\n", payload["directory"])
+			return
+		}
+	})
+
+	t.Run("WithoutPayload", func(t *testing.T) {
+		insertedTask, err := models.TaskQueues.Insert(
+			context.Background(),
+			data.TaskQueue{Name: &task.Name},
+		)
+		if err != nil {
+			t.Errorf("error occurred while inserting new task: %s\n", err)
+			return
+		}
+		if len(insertedTask.Payload) != 0 {
+			t.Errorf("expected no payload, got %s\n", insertedTask.Payload)
+			return
+		}
+	})
+}
+
+func TestTaskQueueConcurrencyLimit(t *testing.T) {
+	task := data.Task{
+		Name:           "test_concurrency_queue",
+		CronExpr:       sql.NullString{String: "* * * * *", Valid: true},
+		Enabled:        sql.NullBool{Bool: false, Valid: true},
+		UpdatedAt:      sql.NullTime{Time: time.Now(), Valid: true},
+		MaxConcurrency: sql.NullInt32{Int32: 1, Valid: true},
+	}
+	_, err := models.Tasks.Insert(context.Background(), task)
+	if err != nil {
+		t.Errorf("error occurred while inserting task: %s\n", err)
+		return
+	}
+
+	runningState := string(data.RunningTaskState)
+	runningTask, err := models.TaskQueues.Insert(
+		context.Background(),
+		data.TaskQueue{Name: &task.Name, State: &runningState},
+	)
+	if err != nil {
+		t.Errorf("error occurred while inserting new task: %s\n", err)
+		return
+	}
+	waitingTask, err := models.TaskQueues.Insert(
+		context.Background(),
+		data.TaskQueue{Name: &task.Name},
+	)
+	if err != nil {
+		t.Errorf("error occurred while inserting new task: %s\n", err)
+		return
+	}
+
+	t.Run("ClaimAtLimit", func(t *testing.T) {
+		tx, err := models.BeginTx(context.Background())
+		if err != nil {
+			t.Errorf("unable to start transaction: %s\n", err)
+			return
+		}
+		defer tx.Rollback(context.Background())
+
+		_, err = models.TaskQueues.ClaimTx(context.Background(), tx, waitingTask.ID)
+		if !errors.Is(err, data.ErrConcurrencyLimit) {
+			t.Errorf("expected %s, got %v\n", data.ErrConcurrencyLimit, err)
+			return
+		}
+	})
+
+	t.Run("ClaimBelowLimit", func(t *testing.T) {
+		_, err := models.TaskQueues.Delete(context.Background(), runningTask.ID)
+		if err != nil {
+			t.Errorf("error occurred while deleting task: %s\n", err)
+			return
+		}
+
+		tx, err := models.BeginTx(context.Background())
+		if err != nil {
+			t.Errorf("unable to start transaction: %s\n", err)
+			return
+		}
+		defer tx.Rollback(context.Background())
+
+		claimedTask, err := models.TaskQueues.ClaimTx(context.Background(), tx, waitingTask.ID)
+		if err != nil {
+			t.Errorf("unable to claim task: %s\n", err)
+			return
+		}
+		if claimedTask.ID != waitingTask.ID {
+			t.Errorf("expected task %s, got %s\n", waitingTask.ID, claimedTask.ID)
+			return
+		}
+	})
+}
+
+func TestTaskQueueGetRunnable(t *testing.T) {
+	lowTask := data.Task{
+		Name:      "test_low_priority_queue",
+		CronExpr:  sql.NullString{String: "* * * * *", Valid: true},
+		Enabled:   sql.NullBool{Bool: false, Valid: true},
+		UpdatedAt: sql.NullTime{Time: time.Now(), Valid: true},
+	}
+	highTask := data.Task{
+		Name:      "test_high_priority_queue",
+		CronExpr:  sql.NullString{String: "* * * * *", Valid: true},
+		Enabled:   sql.NullBool{Bool: false, Valid: true},
+		UpdatedAt: sql.NullTime{Time: time.Now(), Valid: true},
+		Priority:  sql.NullInt32{Int32: 10, Valid: true},
+	}
+
+	var queued []*data.TaskQueue
+	for _, task := range []data.Task{lowTask, highTask} {
+		_, err := models.Tasks.Insert(context.Background(), task)
+		if err != nil {
+			t.Errorf("error occurred while inserting task: %s\n", err)
+			return
+		}
+
+		tq, err := models.TaskQueues.Insert(context.Background(), data.TaskQueue{Name: &task.Name})
+		if err != nil {
+			t.Errorf("error occurred while inserting new task: %s\n", err)
+			return
+		}
+		queued = append(queued, tq)
+	}
+
+	runnable, err := models.TaskQueues.GetRunnable(context.Background(), 1_000)
+	if err != nil {
+		t.Errorf("error occurred while reading runnable tasks: %s\n", err)
+		return
+	}
+
+	lowIndex, highIndex := -1, -1
+	for i, tq := range runnable {
+		switch tq.ID {
+		case queued[0].ID:
+			lowIndex = i
+		case queued[1].ID:
+			highIndex = i
+		}
+	}
+	if lowIndex == -1 || highIndex == -1 {
+		t.Errorf("expected both tasks to be runnable, got %v\n", runnable)
+		return
+	}
+	if highIndex > lowIndex {
+		t.Errorf("expected the high priority task before the low priority task\n")
+		return
+	}
+}
+
+func TestTaskQueueGetNextRunnable(t *testing.T) {
+	task := data.Task{
+		Name:           "test_next_runnable_queue",
+		CronExpr:       sql.NullString{String: "* * * * *", Valid: true},
+		Enabled:        sql.NullBool{Bool: false, Valid: true},
+		UpdatedAt:      sql.NullTime{Time: time.Now(), Valid: true},
+		MaxConcurrency: sql.NullInt32{Int32: 1, Valid: true},
+	}
+	otherTask := data.Task{
+		Name:      "test_next_runnable_other_queue",
+		CronExpr:  sql.NullString{String: "* * * * *", Valid: true},
+		Enabled:   sql.NullBool{Bool: false, Valid: true},
+		UpdatedAt: sql.NullTime{Time: time.Now(), Valid: true},
+		Priority:  sql.NullInt32{Int32: 10, Valid: true},
+	}
+	for _, task := range []data.Task{task, otherTask} {
+		_, err := models.Tasks.Insert(context.Background(), task)
+		if err != nil {
+			t.Errorf("error occurred while inserting task: %s\n", err)
+			return
+		}
+	}
+
+	runningState := string(data.RunningTaskState)
+	runningTask, err := models.TaskQueues.Insert(
+		context.Background(),
+		data.TaskQueue{Name: &task.Name, State: &runningState},
+	)
+	if err != nil {
+		t.Errorf("error occurred while inserting new task: %s\n", err)
+		return
+	}
+
+	// Inserted in reverse order, so that the order of the runs is decided by when they were due
+	var waiting []*data.TaskQueue
+	for _, due := range []time.Duration{time.Minute, 2 * time.Minute} {
+		runAt := time.Now().Add(-due)
+		tq, err := models.TaskQueues.Insert(
+			context.Background(),
+			data.TaskQueue{Name: &task.Name, RunAt: &runAt},
+		)
+		if err != nil {
+			t.Errorf("error occurred while inserting new task: %s\n", err)
+			return
+		}
+		waiting = append([]*data.TaskQueue{tq}, waiting...)
+	}
+	_, err = models.TaskQueues.Insert(context.Background(), data.TaskQueue{Name: &otherTask.Name})
+	if err != nil {
+		t.Errorf("error occurred while inserting new task: %s\n", err)
+		return
+	}
+
+	for i, expected := range waiting {
+		completedState := string(data.CompleteTaskState)
+		_, err := models.TaskQueues.Update(
+			context.Background(),
+			data.TaskQueue{ID: runningTask.ID, State: &completedState},
+		)
+		if err != nil {
+			t.Errorf("error occurred while completing task: %s\n", err)
+			return
+		}
+
+		next, err := models.TaskQueues.GetNextRunnable(context.Background(), task.Name)
+		if err != nil {
+			t.Errorf("error occurred while reading next runnable task: %s\n", err)
+			return
+		}
+		if next.ID != expected.ID {
+			t.Errorf("expected run %d (%s) next, got %s\n", i, expected.ID, next.ID)
+			return
+		}
+
+		tx, err := models.BeginTx(context.Background())
+		if err != nil {
+			t.Errorf("unable to start transaction: %s\n", err)
+			return
+		}
+		claimedTask, err := models.TaskQueues.ClaimTx(context.Background(), tx, next.ID)
+		if err != nil {
+			tx.Rollback(context.Background())
+			t.Errorf("unable to claim task: %s\n", err)
+			return
+		}
+		claimedTask.State = &runningState
+		_, err = models.TaskQueues.UpdateTx(context.Background(), tx, *claimedTask)
+		if err != nil {
+			tx.Rollback(context.Background())
+			t.Errorf("unable to set the task state: %s\n", err)
+			return
+		}
+		if err := tx.Commit(context.Background()); err != nil {
+			t.Errorf("unable to commit transaction: %s\n", err)
+			return
+		}
+		runningTask = claimedTask
+	}
+
+	_, err = models.TaskQueues.GetNextRunnable(context.Background(), task.Name)
+	if !errors.Is(err, data.ErrRecordNotFound) {
+		t.Errorf("expected %s, got %v\n", data.ErrRecordNotFound, err)
+		return
+	}
+}
+
+func TestTaskQueueStats(t *testing.T) {
+	task := data.Task{
+		Name:      "test_stats_queue",
+		CronExpr:  sql.NullString{String: "* * * * *", Valid: true},
+		Enabled:   sql.NullBool{Bool: false, Valid: true},
+		UpdatedAt: sql.NullTime{Time: time.Now(), Valid: true},
+	}
+	_, err := models.Tasks.Insert(context.Background(), task)
+	if err != nil {
+		t.Errorf("error occurred while inserting task: %s\n", err)
+		return
+	}
+
+	tq, err := models.TaskQueues.Insert(context.Background(), data.TaskQueue{Name: &task.Name})
+	if err != nil {
+		t.Errorf("error occurred while inserting new task: %s\n", err)
+		return
+	}
+
+	for _, state := range []data.TaskState{data.RunningTaskState, data.CompleteTaskState} {
+		s := string(state)
+		tq, err = models.TaskQueues.Update(context.Background(), data.TaskQueue{ID: tq.ID, State: &s})
+		if err != nil {
+			t.Errorf("error occurred while updating task state: %s\n", err)
+			return
+		}
+	}
+	if tq.StartedAt == nil || tq.FinishedAt == nil {
+		t.Errorf("expected run timestamps to be set, got %v\n", tq)
+		return
+	}
+
+	stats, err := models.TaskQueues.GetStats(context.Background())
+	if err != nil {
+		t.Errorf("error occurred while reading task stats: %s\n", err)
+		return
+	}
+
+	for _, taskStats := range stats {
+		if taskStats.Name != task.Name {
+			continue
+		}
+		if taskStats.Runs[string(data.CompleteTaskState)] != 1 {
+			t.Errorf("expected one complete run, got %v\n", taskStats.Runs)
+		}
+		if taskStats.DurationP50 == nil || taskStats.LastSuccessAt == nil {
+			t.Errorf("expected duration and last success to be set, got %v\n", taskStats)
+		}
+		return
+	}
+	t.Errorf("expected stats for %s, got %v\n", task.Name, stats)
+}
+
+func TestTaskQueueDeleteExpired(t *testing.T) {
+	task := data.Task{
+		Name:                "test_retention_queue",
+		CronExpr:            sql.NullString{String: "* * * * *", Valid: true},
+		Enabled:             sql.NullBool{Bool: false, Valid: true},
+		UpdatedAt:           sql.NullTime{Time: time.Now(), Valid: true},
+		RetentionDays:       sql.NullInt32{Int32: 1, Valid: true},
+		FailedRetentionDays: sql.NullInt32{Int32: 7, Valid: true},
+	}
+	_, err := models.Tasks.Insert(context.Background(), task)
+	if err != nil {
+		t.Errorf("error occurred while inserting task: %s\n", err)
+		return
+	}
+
+	runs := map[data.TaskState]*data.TaskQueue{}
+	for _, state := range []data.TaskState{data.CompleteTaskState, data.DeadTaskState} {
+		s := string(state)
+		tq, err := models.TaskQueues.Insert(
+			context.Background(), data.TaskQueue{Name: &task.Name, State: &s},
+		)
+		if err != nil {
+			t.Errorf("error occurred while inserting new task: %s\n", err)
+			return
+		}
+		runs[state] = tq
+	}
+
+	_, err = db.Exec(
+		context.Background(),
+		"UPDATE orchestrator.task_queue SET finished_at = NOW() - INTERVAL '2 days' WHERE name = $1",
+		task.Name,
+	)
+	if err != nil {
+		t.Errorf("error occurred while ageing runs: %s\n", err)
+		return
+	}
+
+	_, err = models.TaskQueues.DeleteExpired(
+		context.Background(), data.RetentionPolicy{Days: 30, FailedDays: 90}, 1_000,
+	)
+	if err != nil {
+		t.Errorf("error occurred while deleting expired runs: %s\n", err)
+		return
+	}
+
+	_, err = models.TaskQueues.Get(context.Background(), runs[data.CompleteTaskState].ID)
+	if !errors.Is(err, data.ErrRecordNotFound) {
+		t.Errorf("expected the complete run to be deleted, got %v\n", err)
+		return
+	}
+	_, err = models.TaskQueues.Get(context.Background(), runs[data.DeadTaskState].ID)
+	if err != nil {
+		t.Errorf("expected the dead run to be kept, got %v\n", err)
+		return
+	}
+}
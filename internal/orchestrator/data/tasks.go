@@ -14,10 +14,18 @@ import (
 )
 
 type Task struct {
-	Name      string         `json:"name"`
-	CronExpr  sql.NullString `json:"cronExpr"`
-	Enabled   sql.NullBool   `json:"enabled"`
-	UpdatedAt sql.NullTime   `json:"timestamp"`
+	Name                string         `json:"name"`
+	CronExpr            sql.NullString `json:"cronExpr"`
+	Enabled             sql.NullBool   `json:"enabled"`
+	UpdatedAt           sql.NullTime   `json:"timestamp"`
+	MaxAttempts         sql.NullInt32  `json:"maxAttempts"`
+	RetryBackoff        sql.NullInt32  `json:"retryBackoff"`
+	RetryJitter         sql.NullInt32  `json:"retryJitter"`
+	MaxRuntime          sql.NullInt32  `json:"maxRuntime"`
+	MaxConcurrency      sql.NullInt32  `json:"maxConcurrency"`
+	Priority            sql.NullInt32  `json:"priority"`
+	RetentionDays       sql.NullInt32  `json:"retentionDays"`
+	FailedRetentionDays sql.NullInt32  `json:"failedRetentionDays"`
 }
 
 type TaskModel struct {
@@ -31,7 +39,15 @@ SELECT
     name,
     cron_expr,
     enabled,
-    updated_at
+    updated_at,
+    max_attempts,
+    retry_backoff,
+    retry_jitter,
+    max_runtime,
+    max_concurrency,
+    priority,
+    retention_days,
+    failed_retention_days
 FROM orchestrator.tasks
 WHERE name = $1;
 `
@@ -53,6 +69,14 @@ WHERE name = $1;
 		&task.CronExpr,
 		&task.Enabled,
 		&task.UpdatedAt,
+		&task.MaxAttempts,
+		&task.RetryBackoff,
+		&task.RetryJitter,
+		&task.MaxRuntime,
+		&task.MaxConcurrency,
+		&task.Priority,
+		&task.RetentionDays,
+		&task.FailedRetentionDays,
 	)
 	if err != nil {
 		switch {
@@ -78,7 +102,15 @@ SELECT COUNT(*) OVER() AS total,
        name,
        cron_expr,
        enabled,
-       updated_at
+       updated_at,
+       max_attempts,
+       retry_backoff,
+       retry_jitter,
+       max_runtime,
+       max_concurrency,
+       priority,
+       retention_days,
+       failed_retention_days
 FROM orchestrator.tasks
 WHERE ($1::text IS NULL OR name = $1::text)
   AND ($2::text IS NULL OR cron_expr = $2::text)
@@ -113,6 +145,10 @@ OFFSET $6 FETCH NEXT $7 ROWS ONLY;
 		filters.offset(),
 		filters.limit(),
 	)
+	if err != nil {
+		logger.Error("an error occurred while performing query", "error", err)
+		return nil, nil, err
+	}
 	defer rows.Close()
 
 	for rows.Next() {
@@ -124,6 +160,14 @@ OFFSET $6 FETCH NEXT $7 ROWS ONLY;
 			&task.CronExpr,
 			&task.Enabled,
 			&task.UpdatedAt,
+			&task.MaxAttempts,
+			&task.RetryBackoff,
+			&task.RetryJitter,
+			&task.MaxRuntime,
+			&task.MaxConcurrency,
+			&task.Priority,
+			&task.RetentionDays,
+			&task.FailedRetentionDays,
 		)
 		if err != nil {
 			return nil, nil, err
@@ -148,16 +192,40 @@ func (m *TaskModel) Insert(ctx context.Context, newTask Task) (task *Task, err e
 INSERT INTO orchestrator.tasks (name,
                                 cron_expr,
                                 enabled,
-                                updated_at)
+                                updated_at,
+                                max_attempts,
+                                retry_backoff,
+                                retry_jitter,
+                                max_runtime,
+                                max_concurrency,
+                                priority,
+                                retention_days,
+                                failed_retention_days)
 VALUES ($1::TEXT,
         $2::TEXT,
         COALESCE($3::BOOLEAN, false),
-        NOW())
+        NOW(),
+        COALESCE($4::INTEGER, 1),
+        COALESCE($5::INTEGER, 60),
+        COALESCE($6::INTEGER, 0),
+        $7::INTEGER,
+        $8::INTEGER,
+        COALESCE($9::INTEGER, 0),
+        $10::INTEGER,
+        $11::INTEGER)
 RETURNING
     name,
     cron_expr,
     enabled,
-    updated_at;
+    updated_at,
+    max_attempts,
+    retry_backoff,
+    retry_jitter,
+    max_runtime,
+    max_concurrency,
+    priority,
+    retention_days,
+    failed_retention_days;
 `
 
 	ctx, cancel := context.WithTimeout(ctx, *m.Timeout)
@@ -178,11 +246,27 @@ RETURNING
 		newTask.Name,
 		newTask.CronExpr,
 		newTask.Enabled,
+		newTask.MaxAttempts,
+		newTask.RetryBackoff,
+		newTask.RetryJitter,
+		newTask.MaxRuntime,
+		newTask.MaxConcurrency,
+		newTask.Priority,
+		newTask.RetentionDays,
+		newTask.FailedRetentionDays,
 	).Scan(
 		&task.Name,
 		&task.CronExpr,
 		&task.Enabled,
 		&task.UpdatedAt,
+		&task.MaxAttempts,
+		&task.RetryBackoff,
+		&task.RetryJitter,
+		&task.MaxRuntime,
+		&task.MaxConcurrency,
+		&task.Priority,
+		&task.RetentionDays,
+		&task.FailedRetentionDays,
 	)
 	if err != nil {
 		switch {
@@ -202,15 +286,31 @@ RETURNING
 func (m *TaskModel) Update(ctx context.Context, newTask Task) (task *Task, err error) {
 	query := `
 UPDATE orchestrator.tasks
-SET cron_expr  = COALESCE($2::text, cron_expr),
-    enabled    = COALESCE($3::boolean, enabled),
-    updated_at = NOW()
+SET cron_expr             = COALESCE($2::text, cron_expr),
+    enabled               = COALESCE($3::boolean, enabled),
+    max_attempts          = COALESCE($4::integer, max_attempts),
+    retry_backoff         = COALESCE($5::integer, retry_backoff),
+    retry_jitter          = COALESCE($6::integer, retry_jitter),
+    max_runtime           = COALESCE($7::integer, max_runtime),
+    max_concurrency       = COALESCE($8::integer, max_concurrency),
+    priority              = COALESCE($9::integer, priority),
+    retention_days        = COALESCE($10::integer, retention_days),
+    failed_retention_days = COALESCE($11::integer, failed_retention_days),
+    updated_at            = NOW()
 WHERE name = $1::text
 RETURNING
     name,
     cron_expr,
     enabled,
-    updated_at;
+    updated_at,
+    max_attempts,
+    retry_backoff,
+    retry_jitter,
+    max_runtime,
+    max_concurrency,
+    priority,
+    retention_days,
+    failed_retention_days;
 `
 
 	ctx, cancel := context.WithTimeout(ctx, *m.Timeout)
@@ -231,11 +331,27 @@ RETURNING
 		newTask.Name,
 		newTask.CronExpr,
 		newTask.Enabled,
+		newTask.MaxAttempts,
+		newTask.RetryBackoff,
+		newTask.RetryJitter,
+		newTask.MaxRuntime,
+		newTask.MaxConcurrency,
+		newTask.Priority,
+		newTask.RetentionDays,
+		newTask.FailedRetentionDays,
 	).Scan(
 		&task.Name,
 		&task.CronExpr,
 		&task.Enabled,
 		&task.UpdatedAt,
+		&task.MaxAttempts,
+		&task.RetryBackoff,
+		&task.RetryJitter,
+		&task.MaxRuntime,
+		&task.MaxConcurrency,
+		&task.Priority,
+		&task.RetentionDays,
+		&task.FailedRetentionDays,
 	)
 	if err != nil {
 		switch {
@@ -263,7 +379,8 @@ ON CONFLICT (name)
     DO UPDATE SET cron_expr  = EXCLUDED.cron_expr,
                   enabled    = EXCLUDED.enabled,
                   updated_at = EXCLUDED.updated_at
-RETURNING name, cron_expr, enabled, updated_at;
+RETURNING name, cron_expr, enabled, updated_at, max_attempts, retry_backoff, retry_jitter, max_runtime,
+          max_concurrency, priority, retention_days, failed_retention_days;
 `
 
 	ctx, cancel := context.WithTimeout(ctx, *m.Timeout)
@@ -289,6 +406,14 @@ RETURNING name, cron_expr, enabled, updated_at;
 		&task.CronExpr,
 		&task.Enabled,
 		&task.UpdatedAt,
+		&task.MaxAttempts,
+		&task.RetryBackoff,
+		&task.RetryJitter,
+		&task.MaxRuntime,
+		&task.MaxConcurrency,
+		&task.Priority,
+		&task.RetentionDays,
+		&task.FailedRetentionDays,
 	)
 	if err != nil {
 		switch {
@@ -314,7 +439,15 @@ RETURNING
     name,
     cron_expr,
     enabled,
-    updated_at;
+    updated_at,
+    max_attempts,
+    retry_backoff,
+    retry_jitter,
+    max_runtime,
+    max_concurrency,
+    priority,
+    retention_days,
+    failed_retention_days;
 `
 	ctx, cancel := context.WithTimeout(ctx, *m.Timeout)
 	defer cancel()
@@ -337,6 +470,14 @@ RETURNING
 		&task.CronExpr,
 		&task.Enabled,
 		&task.UpdatedAt,
+		&task.MaxAttempts,
+		&task.RetryBackoff,
+		&task.RetryJitter,
+		&task.MaxRuntime,
+		&task.MaxConcurrency,
+		&task.Priority,
+		&task.RetentionDays,
+		&task.FailedRetentionDays,
 	)
 	if err != nil {
 		switch {
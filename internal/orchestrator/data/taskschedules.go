@@ -0,0 +1,401 @@
+package data
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/r3d5un/Bookshelf/internal/database"
+	"github.com/r3d5un/Bookshelf/internal/logging"
+)
+
+// uniqueViolationCode is the PostgreSQL error code of unique constraint violations
+const uniqueViolationCode = "23505"
+
+// TaskSchedule is an additional cron schedule of a task, running the task with its own payload.
+type TaskSchedule struct {
+	ID        uuid.UUID       `json:"id"`
+	Name      *string         `json:"name"`
+	TaskName  *string         `json:"taskName"`
+	CronExpr  *string         `json:"cronExpr"`
+	Payload   json.RawMessage `json:"payload"`
+	Enabled   *bool           `json:"enabled"`
+	CreatedAt *time.Time      `json:"createdAt"`
+	UpdatedAt *time.Time      `json:"updatedAt"`
+}
+
+type TaskScheduleModel struct {
+	Timeout *time.Duration
+	Pool    *pgxpool.Pool
+}
+
+func (m *TaskScheduleModel) Get(ctx context.Context, id uuid.UUID) (schedule *TaskSchedule, err error) {
+	logger := logging.LoggerFromContext(ctx)
+
+	query := `
+SELECT id,
+       name,
+       task_name,
+       cron_expr,
+       payload,
+       enabled,
+       created_at,
+       updated_at
+FROM orchestrator.task_schedules
+WHERE id = $1;
+`
+
+	qCtx, cancel := context.WithTimeout(ctx, *m.Timeout)
+	defer cancel()
+
+	logger = logger.With(
+		slog.Group(
+			"query",
+			slog.String("statement", database.MinifySQL(query)),
+			slog.String("id", id.String()),
+		),
+	)
+
+	schedule = &TaskSchedule{}
+
+	logger.Info("performing query")
+	err = m.Pool.QueryRow(qCtx, query, id).Scan(
+		&schedule.ID,
+		&schedule.Name,
+		&schedule.TaskName,
+		&schedule.CronExpr,
+		&schedule.Payload,
+		&schedule.Enabled,
+		&schedule.CreatedAt,
+		&schedule.UpdatedAt,
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			logger.Info("no rows found")
+			return nil, ErrRecordNotFound
+		default:
+			logger.Error("an error occurred while performing query", "error", err)
+			return nil, err
+		}
+	}
+
+	logger.Info("returning schedule")
+	return schedule, nil
+}
+
+// GetAll returns the schedules matching the filters. The Name filter matches the name of the
+// task the schedules run.
+func (m *TaskScheduleModel) GetAll(
+	ctx context.Context,
+	filters Filters,
+) (schedules []*TaskSchedule, metadata *Metadata, err error) {
+	logger := logging.LoggerFromContext(ctx)
+
+	query := `
+SELECT COUNT(*) OVER() AS total,
+       id,
+       name,
+       task_name,
+       cron_expr,
+       payload,
+       enabled,
+       created_at,
+       updated_at
+FROM orchestrator.task_schedules
+WHERE ($1::uuid IS NULL OR id = $1::uuid)
+  AND ($2::text IS NULL OR task_name = $2::text)
+  AND ($3::boolean IS NULL OR enabled = $3::boolean)
+  AND ($4::timestamp IS NULL OR created_at >= $4::timestamp)
+  AND ($5::timestamp IS NULL OR created_at < $5::timestamp)
+` + database.CreateOrderByClause(filters.OrderBy) + `
+OFFSET $6 FETCH NEXT $7 ROWS ONLY;
+`
+
+	qCtx, cancel := context.WithTimeout(ctx, *m.Timeout)
+	defer cancel()
+
+	logger = logger.With(
+		slog.Group(
+			"query",
+			slog.String("statement", database.MinifySQL(query)),
+			slog.Any("filters", filters),
+		),
+	)
+
+	schedules = []*TaskSchedule{}
+	totalResults := 0
+
+	logger.Info("performing query")
+	rows, err := m.Pool.Query(
+		qCtx,
+		query,
+		filters.ID,
+		filters.Name,
+		filters.Enabled,
+		filters.CreatedAtFrom,
+		filters.CreatedAtTo,
+		filters.offset(),
+		filters.limit(),
+	)
+	if err != nil {
+		logger.Error("an error occurred while performing query", "error", err)
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var schedule TaskSchedule
+
+		err := rows.Scan(
+			&totalResults,
+			&schedule.ID,
+			&schedule.Name,
+			&schedule.TaskName,
+			&schedule.CronExpr,
+			&schedule.Payload,
+			&schedule.Enabled,
+			&schedule.CreatedAt,
+			&schedule.UpdatedAt,
+		)
+		if err != nil {
+			return nil, nil, err
+		}
+		schedules = append(schedules, &schedule)
+	}
+	if err = rows.Err(); err != nil {
+		logger.Error("an error occurred while parsing query results", "error", err)
+		return nil, nil, err
+	}
+
+	logger.Info("calculating metadata")
+	md := calculateMetadata(totalResults, filters.Page, filters.PageSize, filters.OrderBy)
+	logger.Info("metadata calculated", "metadata", md)
+
+	logger.Info("returning records")
+	return schedules, &md, nil
+}
+
+// Insert adds a new schedule. ErrDuplicateRecord is returned if another schedule has the same
+// name.
+func (m *TaskScheduleModel) Insert(
+	ctx context.Context,
+	newSchedule TaskSchedule,
+) (schedule *TaskSchedule, err error) {
+	logger := logging.LoggerFromContext(ctx)
+
+	query := `
+INSERT INTO orchestrator.task_schedules (name,
+                                         task_name,
+                                         cron_expr,
+                                         payload,
+                                         enabled)
+VALUES ($1::TEXT,
+        $2::TEXT,
+        $3::TEXT,
+        $4::JSONB,
+        COALESCE($5::BOOLEAN, TRUE))
+RETURNING
+    id,
+    name,
+    task_name,
+    cron_expr,
+    payload,
+    enabled,
+    created_at,
+    updated_at;
+`
+
+	qCtx, cancel := context.WithTimeout(ctx, *m.Timeout)
+	defer cancel()
+
+	logger = logger.With(
+		slog.Group(
+			"query",
+			slog.String("statement", database.MinifySQL(query)),
+			"newSchedule", newSchedule,
+		),
+	)
+
+	schedule = &TaskSchedule{}
+
+	logger.Info("performing query")
+	err = m.Pool.QueryRow(
+		qCtx,
+		query,
+		newSchedule.Name,
+		newSchedule.TaskName,
+		newSchedule.CronExpr,
+		nullPayload(newSchedule.Payload),
+		newSchedule.Enabled,
+	).Scan(
+		&schedule.ID,
+		&schedule.Name,
+		&schedule.TaskName,
+		&schedule.CronExpr,
+		&schedule.Payload,
+		&schedule.Enabled,
+		&schedule.CreatedAt,
+		&schedule.UpdatedAt,
+	)
+	if err != nil {
+		switch {
+		case isUniqueViolation(err):
+			logger.Info("schedule name already in use")
+			return nil, ErrDuplicateRecord
+		default:
+			logger.Error("an error occurred while performing query", "error", err)
+			return nil, err
+		}
+	}
+
+	logger.Info("returning schedule")
+	return schedule, nil
+}
+
+// Update changes the fields of the schedule that are set. The task a schedule runs cannot be
+// changed. ErrDuplicateRecord is returned if another schedule has the new name.
+func (m *TaskScheduleModel) Update(
+	ctx context.Context,
+	newSchedule TaskSchedule,
+) (schedule *TaskSchedule, err error) {
+	logger := logging.LoggerFromContext(ctx)
+
+	query := `
+UPDATE orchestrator.task_schedules
+SET name      = COALESCE($2::text, name),
+    cron_expr = COALESCE($3::text, cron_expr),
+    payload   = COALESCE($4::jsonb, payload),
+    enabled   = COALESCE($5::boolean, enabled)
+WHERE id = $1::uuid
+RETURNING
+    id,
+    name,
+    task_name,
+    cron_expr,
+    payload,
+    enabled,
+    created_at,
+    updated_at;
+`
+
+	qCtx, cancel := context.WithTimeout(ctx, *m.Timeout)
+	defer cancel()
+
+	logger = logger.With(
+		slog.Group(
+			"query",
+			slog.String("statement", database.MinifySQL(query)),
+			"newSchedule", newSchedule,
+		),
+	)
+
+	schedule = &TaskSchedule{}
+
+	logger.Info("performing query")
+	err = m.Pool.QueryRow(
+		qCtx,
+		query,
+		newSchedule.ID,
+		newSchedule.Name,
+		newSchedule.CronExpr,
+		nullPayload(newSchedule.Payload),
+		newSchedule.Enabled,
+	).Scan(
+		&schedule.ID,
+		&schedule.Name,
+		&schedule.TaskName,
+		&schedule.CronExpr,
+		&schedule.Payload,
+		&schedule.Enabled,
+		&schedule.CreatedAt,
+		&schedule.UpdatedAt,
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			logger.Info("no rows found")
+			return nil, ErrRecordNotFound
+		case isUniqueViolation(err):
+			logger.Info("schedule name already in use")
+			return nil, ErrDuplicateRecord
+		default:
+			logger.Error("an error occurred while performing query", "error", err)
+			return nil, err
+		}
+	}
+
+	logger.Info("returning schedule")
+	return schedule, nil
+}
+
+func (m *TaskScheduleModel) Delete(
+	ctx context.Context,
+	id uuid.UUID,
+) (schedule *TaskSchedule, err error) {
+	logger := logging.LoggerFromContext(ctx)
+
+	query := `
+DELETE
+FROM orchestrator.task_schedules
+WHERE id = $1
+RETURNING
+    id,
+    name,
+    task_name,
+    cron_expr,
+    payload,
+    enabled,
+    created_at,
+    updated_at;
+`
+
+	qCtx, cancel := context.WithTimeout(ctx, *m.Timeout)
+	defer cancel()
+
+	logger = logger.With(
+		slog.Group(
+			"query",
+			slog.String("statement", database.MinifySQL(query)),
+			slog.String("id", id.String()),
+		),
+	)
+
+	schedule = &TaskSchedule{}
+
+	logger.Info("performing query")
+	err = m.Pool.QueryRow(qCtx, query, id).Scan(
+		&schedule.ID,
+		&schedule.Name,
+		&schedule.TaskName,
+		&schedule.CronExpr,
+		&schedule.Payload,
+		&schedule.Enabled,
+		&schedule.CreatedAt,
+		&schedule.UpdatedAt,
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			logger.Info("no rows found")
+			return nil, ErrRecordNotFound
+		default:
+			logger.Error("an error occurred while performing query", "error", err)
+			return nil, err
+		}
+	}
+
+	logger.Info("returning schedule")
+	return schedule, nil
+}
+
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == uniqueViolationCode
+}
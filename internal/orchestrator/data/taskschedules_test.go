@@ -0,0 +1,119 @@
+package data_test
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/r3d5un/Bookshelf/internal/orchestrator/data"
+)
+
+func TestTaskScheduleModel(t *testing.T) {
+	task := data.Task{
+		Name:      "task_schedule_test",
+		CronExpr:  sql.NullString{String: "* * * * *", Valid: true},
+		Enabled:   sql.NullBool{Bool: false, Valid: true},
+		UpdatedAt: sql.NullTime{Time: time.Now(), Valid: true},
+	}
+	_, err := models.Tasks.Insert(context.Background(), task)
+	if err != nil {
+		t.Errorf("error occurred while inserting task: %s\n", err)
+		return
+	}
+
+	name := "nightly_import"
+	cronExpr := "0 1 * * *"
+	schedule := data.TaskSchedule{
+		Name:     &name,
+		TaskName: &task.Name,
+		CronExpr: &cronExpr,
+		Payload:  json.RawMessage(`{"directory":"/imports/nightly"}`),
+	}
+
+	t.Run("Insert", func(t *testing.T) {
+		insertedSchedule, err := models.TaskSchedules.Insert(context.Background(), schedule)
+		if err != nil {
+			t.Errorf("error occurred while inserting schedule: %s\n", err)
+			return
+		}
+		if !*insertedSchedule.Enabled {
+			t.Error("expected schedule to be enabled by default")
+			return
+		}
+
+		schedule = *insertedSchedule
+	})
+
+	t.Run("InsertDuplicate", func(t *testing.T) {
+		_, err := models.TaskSchedules.Insert(context.Background(), schedule)
+		if !errors.Is(err, data.ErrDuplicateRecord) {
+			t.Errorf("expected %s, got %v\n", data.ErrDuplicateRecord, err)
+			return
+		}
+	})
+
+	t.Run("Get", func(t *testing.T) {
+		_, err := models.TaskSchedules.Get(context.Background(), schedule.ID)
+		if err != nil {
+			t.Errorf("error occurred while querying schedule: %s\n", err)
+			return
+		}
+	})
+
+	t.Run("GetAll", func(t *testing.T) {
+		filters := data.Filters{
+			Page:     1,
+			PageSize: 100,
+			Name:     &task.Name,
+			OrderBy:  []string{"name"},
+		}
+		schedules, _, err := models.TaskSchedules.GetAll(context.Background(), filters)
+		if err != nil {
+			t.Errorf("error occurred while reading schedules: %s\n", err)
+			return
+		}
+		if len(schedules) != 1 {
+			t.Errorf("expected 1 schedule, got %d\n", len(schedules))
+			return
+		}
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		newCronExpr := "0 2 * * *"
+		enabled := false
+
+		updatedSchedule, err := models.TaskSchedules.Update(
+			context.Background(),
+			data.TaskSchedule{ID: schedule.ID, CronExpr: &newCronExpr, Enabled: &enabled},
+		)
+		if err != nil {
+			t.Errorf("error occurred while updating schedule: %s\n", err)
+			return
+		}
+		if *updatedSchedule.CronExpr != newCronExpr {
+			t.Errorf("expected cron expression %s, got %s\n", newCronExpr, *updatedSchedule.CronExpr)
+			return
+		}
+		if *updatedSchedule.Name != name {
+			t.Errorf("expected name %s to be kept, got %s\n", name, *updatedSchedule.Name)
+			return
+		}
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		_, err := models.TaskSchedules.Delete(context.Background(), schedule.ID)
+		if err != nil {
+			t.Errorf("error occurred while deleting schedule: %s\n", err)
+			return
+		}
+
+		_, err = models.TaskSchedules.Get(context.Background(), schedule.ID)
+		if !errors.Is(err, data.ErrRecordNotFound) {
+			t.Errorf("expected %s, got %v\n", data.ErrRecordNotFound, err)
+			return
+		}
+	})
+}
@@ -0,0 +1,95 @@
+package data
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/r3d5un/Bookshelf/internal/database"
+	"github.com/r3d5un/Bookshelf/internal/logging"
+)
+
+// TaskStats is a summary of the runs of a task currently in the queue.
+type TaskStats struct {
+	Name string `json:"name"`
+	// Runs is the number of runs in each state
+	Runs map[string]int `json:"runs"`
+	// DurationP50 and DurationP95 are percentiles of the duration of completed runs, in seconds
+	DurationP50 *float64 `json:"durationP50"`
+	DurationP95 *float64 `json:"durationP95"`
+	// LastSuccessAt is when the most recent completed run finished
+	LastSuccessAt *time.Time `json:"lastSuccessAt"`
+}
+
+// GetStats returns the run statistics of every task in the overview, ordered by name. Tasks
+// without runs are included with no runs.
+func (m *TaskQueueModel) GetStats(ctx context.Context) (stats []*TaskStats, err error) {
+	logger := logging.LoggerFromContext(ctx)
+
+	query := `
+WITH counts AS (SELECT name, state, COUNT(*) AS runs
+                FROM orchestrator.task_queue
+                GROUP BY name, state),
+     completed AS (SELECT name,
+                          PERCENTILE_CONT(0.5) WITHIN GROUP (
+                              ORDER BY EXTRACT(EPOCH FROM finished_at - started_at))  AS p50,
+                          PERCENTILE_CONT(0.95) WITHIN GROUP (
+                              ORDER BY EXTRACT(EPOCH FROM finished_at - started_at))  AS p95,
+                          MAX(COALESCE(finished_at, updated_at))                      AS last_success_at
+                   FROM orchestrator.task_queue
+                   WHERE state = 'complete'
+                   GROUP BY name)
+SELECT t.name,
+       COALESCE((SELECT jsonb_object_agg(c.state, c.runs) FROM counts c WHERE c.name = t.name),
+                '{}'::jsonb),
+       c.p50,
+       c.p95,
+       c.last_success_at
+FROM orchestrator.tasks t
+         LEFT JOIN completed c ON c.name = t.name
+ORDER BY t.name;
+`
+
+	qCtx, cancel := context.WithTimeout(ctx, *m.Timeout)
+	defer cancel()
+
+	logger = logger.With(
+		slog.Group(
+			"query",
+			slog.String("statement", database.MinifySQL(query)),
+		),
+	)
+
+	stats = []*TaskStats{}
+
+	logger.Info("performing query")
+	rows, err := m.Pool.Query(qCtx, query)
+	if err != nil {
+		logger.Error("an error occurred while performing query", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var taskStats TaskStats
+
+		err := rows.Scan(
+			&taskStats.Name,
+			&taskStats.Runs,
+			&taskStats.DurationP50,
+			&taskStats.DurationP95,
+			&taskStats.LastSuccessAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		stats = append(stats, &taskStats)
+	}
+	if err = rows.Err(); err != nil {
+		logger.Error("an error occurred while parsing query results", "error", err)
+		return nil, err
+	}
+
+	logger.Info("returning task stats", "length", len(stats))
+	return stats, nil
+}
@@ -0,0 +1,204 @@
+package data
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/r3d5un/Bookshelf/internal/database"
+	"github.com/r3d5un/Bookshelf/internal/logging"
+)
+
+// WebhookDelivery is a single attempt at delivering an event to a webhook. Every attempt of a
+// delivery shares the ID of the task run delivering it.
+type WebhookDelivery struct {
+	ID         uuid.UUID  `json:"id"`
+	WebhookID  uuid.UUID  `json:"webhookId"`
+	TaskID     uuid.UUID  `json:"taskId"`
+	Event      string     `json:"event"`
+	Attempt    int        `json:"attempt"`
+	StatusCode *int       `json:"statusCode"`
+	Error      *string    `json:"error"`
+	DurationMS int        `json:"durationMs"`
+	CreatedAt  *time.Time `json:"createdAt"`
+}
+
+type WebhookDeliveryModel struct {
+	Timeout *time.Duration
+	Pool    *pgxpool.Pool
+}
+
+// GetAll returns the delivery attempts of the webhook matching the filters. The ID filter matches
+// the task run of the delivery, and the Name filter matches the event delivered.
+func (m *WebhookDeliveryModel) GetAll(
+	ctx context.Context,
+	webhookID uuid.UUID,
+	filters Filters,
+) (deliveries []*WebhookDelivery, metadata *Metadata, err error) {
+	logger := logging.LoggerFromContext(ctx)
+
+	query := `
+SELECT COUNT(*) OVER() AS total,
+       id,
+       webhook_id,
+       task_id,
+       event,
+       attempt,
+       status_code,
+       error,
+       duration_ms,
+       created_at
+FROM orchestrator.webhook_deliveries
+WHERE webhook_id = $1::uuid
+  AND ($2::uuid IS NULL OR task_id = $2::uuid)
+  AND ($3::text IS NULL OR event = $3::text)
+  AND ($4::timestamp IS NULL OR created_at >= $4::timestamp)
+  AND ($5::timestamp IS NULL OR created_at < $5::timestamp)
+` + database.CreateOrderByClause(filters.OrderBy) + `
+OFFSET $6 FETCH NEXT $7 ROWS ONLY;
+`
+
+	qCtx, cancel := context.WithTimeout(ctx, *m.Timeout)
+	defer cancel()
+
+	logger = logger.With(
+		slog.Group(
+			"query",
+			slog.String("statement", database.MinifySQL(query)),
+			slog.String("webhookId", webhookID.String()),
+			slog.Any("filters", filters),
+		),
+	)
+
+	deliveries = []*WebhookDelivery{}
+	totalResults := 0
+
+	logger.Info("performing query")
+	rows, err := m.Pool.Query(
+		qCtx,
+		query,
+		webhookID,
+		filters.ID,
+		filters.Name,
+		filters.CreatedAtFrom,
+		filters.CreatedAtTo,
+		filters.offset(),
+		filters.limit(),
+	)
+	if err != nil {
+		logger.Error("an error occurred while performing query", "error", err)
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var delivery WebhookDelivery
+
+		err := rows.Scan(
+			&totalResults,
+			&delivery.ID,
+			&delivery.WebhookID,
+			&delivery.TaskID,
+			&delivery.Event,
+			&delivery.Attempt,
+			&delivery.StatusCode,
+			&delivery.Error,
+			&delivery.DurationMS,
+			&delivery.CreatedAt,
+		)
+		if err != nil {
+			return nil, nil, err
+		}
+		deliveries = append(deliveries, &delivery)
+	}
+	if err = rows.Err(); err != nil {
+		logger.Error("an error occurred while parsing query results", "error", err)
+		return nil, nil, err
+	}
+
+	logger.Info("calculating metadata")
+	md := calculateMetadata(totalResults, filters.Page, filters.PageSize, filters.OrderBy)
+	logger.Info("metadata calculated", "metadata", md)
+
+	logger.Info("returning records")
+	return deliveries, &md, nil
+}
+
+func (m *WebhookDeliveryModel) Insert(
+	ctx context.Context,
+	newDelivery WebhookDelivery,
+) (delivery *WebhookDelivery, err error) {
+	logger := logging.LoggerFromContext(ctx)
+
+	query := `
+INSERT INTO orchestrator.webhook_deliveries (webhook_id,
+                                             task_id,
+                                             event,
+                                             attempt,
+                                             status_code,
+                                             error,
+                                             duration_ms)
+VALUES ($1::UUID,
+        $2::UUID,
+        $3::TEXT,
+        $4::INTEGER,
+        $5::INTEGER,
+        $6::TEXT,
+        $7::INTEGER)
+RETURNING
+    id,
+    webhook_id,
+    task_id,
+    event,
+    attempt,
+    status_code,
+    error,
+    duration_ms,
+    created_at;
+`
+
+	qCtx, cancel := context.WithTimeout(ctx, *m.Timeout)
+	defer cancel()
+
+	logger = logger.With(
+		slog.Group(
+			"query",
+			slog.String("statement", database.MinifySQL(query)),
+			"newDelivery", newDelivery,
+		),
+	)
+
+	delivery = &WebhookDelivery{}
+
+	logger.Info("performing query")
+	err = m.Pool.QueryRow(
+		qCtx,
+		query,
+		newDelivery.WebhookID,
+		newDelivery.TaskID,
+		newDelivery.Event,
+		newDelivery.Attempt,
+		newDelivery.StatusCode,
+		newDelivery.Error,
+		newDelivery.DurationMS,
+	).Scan(
+		&delivery.ID,
+		&delivery.WebhookID,
+		&delivery.TaskID,
+		&delivery.Event,
+		&delivery.Attempt,
+		&delivery.StatusCode,
+		&delivery.Error,
+		&delivery.DurationMS,
+		&delivery.CreatedAt,
+	)
+	if err != nil {
+		logger.Error("an error occurred while performing query", "error", err)
+		return nil, err
+	}
+
+	logger.Info("returning delivery")
+	return delivery, nil
+}
@@ -0,0 +1,366 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/r3d5un/Bookshelf/internal/database"
+	"github.com/r3d5un/Bookshelf/internal/logging"
+)
+
+// Webhook is a URL that events are delivered to, signed with the secret of the webhook.
+type Webhook struct {
+	ID        uuid.UUID  `json:"id"`
+	URL       *string    `json:"url"`
+	Secret    *string    `json:"secret"`
+	Events    []string   `json:"events"`
+	Enabled   *bool      `json:"enabled"`
+	CreatedAt *time.Time `json:"createdAt"`
+	UpdatedAt *time.Time `json:"updatedAt"`
+}
+
+type WebhookModel struct {
+	Timeout *time.Duration
+	Pool    *pgxpool.Pool
+}
+
+func (m *WebhookModel) Get(ctx context.Context, id uuid.UUID) (webhook *Webhook, err error) {
+	logger := logging.LoggerFromContext(ctx)
+
+	query := `
+SELECT id,
+       url,
+       secret,
+       events,
+       enabled,
+       created_at,
+       updated_at
+FROM orchestrator.webhooks
+WHERE id = $1;
+`
+
+	qCtx, cancel := context.WithTimeout(ctx, *m.Timeout)
+	defer cancel()
+
+	logger = logger.With(
+		slog.Group(
+			"query",
+			slog.String("statement", database.MinifySQL(query)),
+			slog.String("id", id.String()),
+		),
+	)
+
+	webhook = &Webhook{}
+
+	logger.Info("performing query")
+	err = m.Pool.QueryRow(qCtx, query, id).Scan(
+		&webhook.ID,
+		&webhook.URL,
+		&webhook.Secret,
+		&webhook.Events,
+		&webhook.Enabled,
+		&webhook.CreatedAt,
+		&webhook.UpdatedAt,
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			logger.Info("no rows found")
+			return nil, ErrRecordNotFound
+		default:
+			logger.Error("an error occurred while performing query", "error", err)
+			return nil, err
+		}
+	}
+
+	logger.Info("returning webhook")
+	return webhook, nil
+}
+
+// GetAll returns the webhooks matching the filters. The Name filter matches webhooks subscribed
+// to the event with the name.
+func (m *WebhookModel) GetAll(
+	ctx context.Context,
+	filters Filters,
+) (webhooks []*Webhook, metadata *Metadata, err error) {
+	logger := logging.LoggerFromContext(ctx)
+
+	query := `
+SELECT COUNT(*) OVER() AS total,
+       id,
+       url,
+       secret,
+       events,
+       enabled,
+       created_at,
+       updated_at
+FROM orchestrator.webhooks
+WHERE ($1::uuid IS NULL OR id = $1::uuid)
+  AND ($2::text IS NULL OR $2::text = ANY (events))
+  AND ($3::boolean IS NULL OR enabled = $3::boolean)
+  AND ($4::timestamp IS NULL OR created_at >= $4::timestamp)
+  AND ($5::timestamp IS NULL OR created_at < $5::timestamp)
+` + database.CreateOrderByClause(filters.OrderBy) + `
+OFFSET $6 FETCH NEXT $7 ROWS ONLY;
+`
+
+	qCtx, cancel := context.WithTimeout(ctx, *m.Timeout)
+	defer cancel()
+
+	logger = logger.With(
+		slog.Group(
+			"query",
+			slog.String("statement", database.MinifySQL(query)),
+			slog.Any("filters", filters),
+		),
+	)
+
+	webhooks = []*Webhook{}
+	totalResults := 0
+
+	logger.Info("performing query")
+	rows, err := m.Pool.Query(
+		qCtx,
+		query,
+		filters.ID,
+		filters.Name,
+		filters.Enabled,
+		filters.CreatedAtFrom,
+		filters.CreatedAtTo,
+		filters.offset(),
+		filters.limit(),
+	)
+	if err != nil {
+		logger.Error("an error occurred while performing query", "error", err)
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var webhook Webhook
+
+		err := rows.Scan(
+			&totalResults,
+			&webhook.ID,
+			&webhook.URL,
+			&webhook.Secret,
+			&webhook.Events,
+			&webhook.Enabled,
+			&webhook.CreatedAt,
+			&webhook.UpdatedAt,
+		)
+		if err != nil {
+			return nil, nil, err
+		}
+		webhooks = append(webhooks, &webhook)
+	}
+	if err = rows.Err(); err != nil {
+		logger.Error("an error occurred while parsing query results", "error", err)
+		return nil, nil, err
+	}
+
+	logger.Info("calculating metadata")
+	md := calculateMetadata(totalResults, filters.Page, filters.PageSize, filters.OrderBy)
+	logger.Info("metadata calculated", "metadata", md)
+
+	logger.Info("returning records")
+	return webhooks, &md, nil
+}
+
+func (m *WebhookModel) Insert(
+	ctx context.Context,
+	newWebhook Webhook,
+) (webhook *Webhook, err error) {
+	logger := logging.LoggerFromContext(ctx)
+
+	query := `
+INSERT INTO orchestrator.webhooks (url,
+                                   secret,
+                                   events,
+                                   enabled)
+VALUES ($1::TEXT,
+        $2::TEXT,
+        $3::TEXT[],
+        COALESCE($4::BOOLEAN, TRUE))
+RETURNING
+    id,
+    url,
+    secret,
+    events,
+    enabled,
+    created_at,
+    updated_at;
+`
+
+	qCtx, cancel := context.WithTimeout(ctx, *m.Timeout)
+	defer cancel()
+
+	// The secret is left out of the logs
+	logger = logger.With(
+		slog.Group(
+			"query",
+			slog.String("statement", database.MinifySQL(query)),
+			slog.Any("url", newWebhook.URL),
+			slog.Any("events", newWebhook.Events),
+		),
+	)
+
+	webhook = &Webhook{}
+
+	logger.Info("performing query")
+	err = m.Pool.QueryRow(
+		qCtx,
+		query,
+		newWebhook.URL,
+		newWebhook.Secret,
+		newWebhook.Events,
+		newWebhook.Enabled,
+	).Scan(
+		&webhook.ID,
+		&webhook.URL,
+		&webhook.Secret,
+		&webhook.Events,
+		&webhook.Enabled,
+		&webhook.CreatedAt,
+		&webhook.UpdatedAt,
+	)
+	if err != nil {
+		logger.Error("an error occurred while performing query", "error", err)
+		return nil, err
+	}
+
+	logger.Info("returning webhook")
+	return webhook, nil
+}
+
+// Update changes the fields of the webhook that are set.
+func (m *WebhookModel) Update(
+	ctx context.Context,
+	newWebhook Webhook,
+) (webhook *Webhook, err error) {
+	logger := logging.LoggerFromContext(ctx)
+
+	query := `
+UPDATE orchestrator.webhooks
+SET url     = COALESCE($2::text, url),
+    secret  = COALESCE($3::text, secret),
+    events  = COALESCE($4::text[], events),
+    enabled = COALESCE($5::boolean, enabled)
+WHERE id = $1::uuid
+RETURNING
+    id,
+    url,
+    secret,
+    events,
+    enabled,
+    created_at,
+    updated_at;
+`
+
+	qCtx, cancel := context.WithTimeout(ctx, *m.Timeout)
+	defer cancel()
+
+	logger = logger.With(
+		slog.Group(
+			"query",
+			slog.String("statement", database.MinifySQL(query)),
+			slog.String("id", newWebhook.ID.String()),
+			slog.Any("url", newWebhook.URL),
+			slog.Any("events", newWebhook.Events),
+		),
+	)
+
+	webhook = &Webhook{}
+
+	logger.Info("performing query")
+	err = m.Pool.QueryRow(
+		qCtx,
+		query,
+		newWebhook.ID,
+		newWebhook.URL,
+		newWebhook.Secret,
+		newWebhook.Events,
+		newWebhook.Enabled,
+	).Scan(
+		&webhook.ID,
+		&webhook.URL,
+		&webhook.Secret,
+		&webhook.Events,
+		&webhook.Enabled,
+		&webhook.CreatedAt,
+		&webhook.UpdatedAt,
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			logger.Info("no rows found")
+			return nil, ErrRecordNotFound
+		default:
+			logger.Error("an error occurred while performing query", "error", err)
+			return nil, err
+		}
+	}
+
+	logger.Info("returning webhook")
+	return webhook, nil
+}
+
+func (m *WebhookModel) Delete(ctx context.Context, id uuid.UUID) (webhook *Webhook, err error) {
+	logger := logging.LoggerFromContext(ctx)
+
+	query := `
+DELETE
+FROM orchestrator.webhooks
+WHERE id = $1
+RETURNING
+    id,
+    url,
+    secret,
+    events,
+    enabled,
+    created_at,
+    updated_at;
+`
+
+	qCtx, cancel := context.WithTimeout(ctx, *m.Timeout)
+	defer cancel()
+
+	logger = logger.With(
+		slog.Group(
+			"query",
+			slog.String("statement", database.MinifySQL(query)),
+			slog.String("id", id.String()),
+		),
+	)
+
+	webhook = &Webhook{}
+
+	logger.Info("performing query")
+	err = m.Pool.QueryRow(qCtx, query, id).Scan(
+		&webhook.ID,
+		&webhook.URL,
+		&webhook.Secret,
+		&webhook.Events,
+		&webhook.Enabled,
+		&webhook.CreatedAt,
+		&webhook.UpdatedAt,
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			logger.Info("no rows found")
+			return nil, ErrRecordNotFound
+		default:
+			logger.Error("an error occurred while performing query", "error", err)
+			return nil, err
+		}
+	}
+
+	logger.Info("returning webhook")
+	return webhook, nil
+}
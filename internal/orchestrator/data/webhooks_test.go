@@ -0,0 +1,152 @@
+package data_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/r3d5un/Bookshelf/internal/orchestrator/data"
+)
+
+func TestWebhookModel(t *testing.T) {
+	url := "https://example.com/hooks/bookshelf"
+	secret := "a-very-secret-signing-key"
+	webhook := data.Webhook{
+		URL:    &url,
+		Secret: &secret,
+		Events: []string{"book.created", "task.failed"},
+	}
+
+	t.Run("Insert", func(t *testing.T) {
+		insertedWebhook, err := models.Webhooks.Insert(context.Background(), webhook)
+		if err != nil {
+			t.Errorf("error occurred while inserting webhook: %s\n", err)
+			return
+		}
+		if !*insertedWebhook.Enabled {
+			t.Error("expected webhook to be enabled by default")
+			return
+		}
+
+		webhook = *insertedWebhook
+	})
+
+	t.Run("Get", func(t *testing.T) {
+		readWebhook, err := models.Webhooks.Get(context.Background(), webhook.ID)
+		if err != nil {
+			t.Errorf("error occurred while querying webhook: %s\n", err)
+			return
+		}
+		if len(readWebhook.Events) != 2 {
+			t.Errorf("expected 2 events, got %d\n", len(readWebhook.Events))
+			return
+		}
+	})
+
+	t.Run("GetAllByEvent", func(t *testing.T) {
+		for event, expected := range map[string]int{"task.failed": 1, "book.deleted": 0} {
+			filters := data.Filters{
+				Page:     1,
+				PageSize: 100,
+				ID:       &webhook.ID,
+				Name:     &event,
+				OrderBy:  []string{"created_at"},
+			}
+			webhooks, _, err := models.Webhooks.GetAll(context.Background(), filters)
+			if err != nil {
+				t.Errorf("error occurred while reading webhooks: %s\n", err)
+				return
+			}
+			if len(webhooks) != expected {
+				t.Errorf("expected %d webhooks for %s, got %d\n", expected, event, len(webhooks))
+				return
+			}
+		}
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		enabled := false
+
+		updatedWebhook, err := models.Webhooks.Update(
+			context.Background(),
+			data.Webhook{ID: webhook.ID, Events: []string{"book.deleted"}, Enabled: &enabled},
+		)
+		if err != nil {
+			t.Errorf("error occurred while updating webhook: %s\n", err)
+			return
+		}
+		if len(updatedWebhook.Events) != 1 || updatedWebhook.Events[0] != "book.deleted" {
+			t.Errorf("expected events to be replaced, got %v\n", updatedWebhook.Events)
+			return
+		}
+		if *updatedWebhook.Secret != secret {
+			t.Error("expected secret to be kept")
+			return
+		}
+	})
+
+	taskID := uuid.New()
+
+	t.Run("InsertDelivery", func(t *testing.T) {
+		statusCode := http.StatusServiceUnavailable
+		errMsg := "unexpected webhook response status: 503 Service Unavailable"
+		_, err := models.WebhookDeliveries.Insert(context.Background(), data.WebhookDelivery{
+			WebhookID:  webhook.ID,
+			TaskID:     taskID,
+			Event:      "book.deleted",
+			Attempt:    1,
+			StatusCode: &statusCode,
+			Error:      &errMsg,
+			DurationMS: 12,
+		})
+		if err != nil {
+			t.Errorf("error occurred while inserting delivery: %s\n", err)
+			return
+		}
+	})
+
+	t.Run("GetAllDeliveries", func(t *testing.T) {
+		filters := data.Filters{
+			Page:     1,
+			PageSize: 100,
+			ID:       &taskID,
+			OrderBy:  []string{"created_at"},
+		}
+		deliveries, _, err := models.WebhookDeliveries.GetAll(context.Background(), webhook.ID, filters)
+		if err != nil {
+			t.Errorf("error occurred while reading deliveries: %s\n", err)
+			return
+		}
+		if len(deliveries) != 1 {
+			t.Errorf("expected 1 delivery, got %d\n", len(deliveries))
+			return
+		}
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		_, err := models.Webhooks.Delete(context.Background(), webhook.ID)
+		if err != nil {
+			t.Errorf("error occurred while deleting webhook: %s\n", err)
+			return
+		}
+
+		_, err = models.Webhooks.Get(context.Background(), webhook.ID)
+		if !errors.Is(err, data.ErrRecordNotFound) {
+			t.Errorf("expected %s, got %v\n", data.ErrRecordNotFound, err)
+			return
+		}
+
+		filters := data.Filters{Page: 1, PageSize: 100, OrderBy: []string{"created_at"}}
+		deliveries, _, err := models.WebhookDeliveries.GetAll(context.Background(), webhook.ID, filters)
+		if err != nil {
+			t.Errorf("error occurred while reading deliveries: %s\n", err)
+			return
+		}
+		if len(deliveries) != 0 {
+			t.Errorf("expected deliveries to be deleted with the webhook, got %d\n", len(deliveries))
+			return
+		}
+	})
+}
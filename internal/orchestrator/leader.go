@@ -0,0 +1,198 @@
+package orchestrator
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/r3d5un/Bookshelf/internal/logging"
+	"github.com/r3d5un/Bookshelf/internal/orchestrator/data"
+)
+
+// LeaderElector elects one instance as the leader using a session level advisory lock. The lock
+// is taken on a connection outside of the pool, and leadership is held for as long as that
+// connection is alive. If the connection is lost, the database releases the lock, and another
+// instance can be elected without waiting for a heartbeat to expire.
+//
+// Each election starts a new term, which is passed to the callbacks and can be used to fence
+// writes made on behalf of the leader.
+type LeaderElector struct {
+	instanceID uuid.UUID
+	interval   time.Duration
+	pool       *pgxpool.Pool
+	models     *data.Models
+
+	mu        sync.Mutex
+	conn      *pgx.Conn
+	term      int64
+	onElected []func(term int64)
+	onDemoted []func()
+}
+
+func NewLeaderElector(
+	instanceID uuid.UUID,
+	interval time.Duration,
+	pool *pgxpool.Pool,
+	models *data.Models,
+) *LeaderElector {
+	return &LeaderElector{
+		instanceID: instanceID,
+		interval:   interval,
+		pool:       pool,
+		models:     models,
+	}
+}
+
+// OnElected registers a function called with the new term when the instance becomes the leader.
+func (e *LeaderElector) OnElected(fn func(term int64)) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.onElected = append(e.onElected, fn)
+}
+
+// OnDemoted registers a function called when the instance is no longer the leader.
+func (e *LeaderElector) OnDemoted(fn func()) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.onDemoted = append(e.onDemoted, fn)
+}
+
+// IsLeader reports whether the instance currently holds the leader lock.
+func (e *LeaderElector) IsLeader() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.conn != nil
+}
+
+// Term returns the term of the instance while it is the leader, or zero otherwise.
+func (e *LeaderElector) Term() int64 {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.term
+}
+
+// Run campaigns for leadership until the done signal is received. While the instance is the
+// leader, the connection holding the lock is checked on every interval. Leadership is given up
+// before returning.
+func (e *LeaderElector) Run(ctx context.Context, done <-chan struct{}) {
+	logger := logging.LoggerFromContext(ctx).With("instanceId", e.instanceID)
+
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+
+	for {
+		if e.IsLeader() {
+			e.check(ctx)
+		} else {
+			e.campaign(ctx)
+		}
+
+		select {
+		case <-done:
+			logger.Info("received done signal; resigning leadership")
+			e.resign(ctx)
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// campaign attempts to take the leader lock, and starts a new term if it was acquired.
+func (e *LeaderElector) campaign(ctx context.Context) {
+	logger := logging.LoggerFromContext(ctx).With("instanceId", e.instanceID)
+
+	conn, err := pgx.ConnectConfig(ctx, e.pool.Config().ConnConfig.Copy())
+	if err != nil {
+		logger.Error("unable to connect to database", "error", err)
+		return
+	}
+
+	acquired, err := e.models.Leader.TryAcquire(ctx, conn)
+	if err != nil || !acquired {
+		logger.Info("leader lock not acquired")
+		conn.Close(ctx)
+		return
+	}
+
+	leader, err := e.models.Leader.Elect(ctx, e.instanceID)
+	if err != nil {
+		// Closing the connection releases the lock, letting any instance try again
+		logger.Error("unable to start leader term", "error", err)
+		conn.Close(ctx)
+		return
+	}
+	logger.Info("elected leader", "term", leader.Term)
+
+	e.mu.Lock()
+	e.conn = conn
+	e.term = leader.Term
+	callbacks := e.onElected
+	e.mu.Unlock()
+
+	for _, fn := range callbacks {
+		fn(leader.Term)
+	}
+}
+
+// check verifies that the connection holding the leader lock is still alive, and demotes the
+// instance if it is not.
+func (e *LeaderElector) check(ctx context.Context) {
+	logger := logging.LoggerFromContext(ctx).With("instanceId", e.instanceID)
+
+	e.mu.Lock()
+	conn := e.conn
+	e.mu.Unlock()
+
+	pingCtx, cancel := context.WithTimeout(ctx, e.interval)
+	defer cancel()
+
+	err := conn.Ping(pingCtx)
+	if err == nil {
+		return
+	}
+
+	logger.Error("lost connection holding leader lock", "error", err)
+	e.demote(ctx)
+}
+
+// resign releases the leader lock if held, so that another instance can be elected right away.
+func (e *LeaderElector) resign(ctx context.Context) {
+	logger := logging.LoggerFromContext(ctx).With("instanceId", e.instanceID)
+
+	e.mu.Lock()
+	conn := e.conn
+	e.mu.Unlock()
+
+	if conn == nil {
+		return
+	}
+
+	err := e.models.Leader.Release(ctx, conn)
+	if err != nil {
+		// The lock is released when the connection is closed regardless
+		logger.Error("unable to release leader lock", "error", err)
+	}
+	e.demote(ctx)
+}
+
+func (e *LeaderElector) demote(ctx context.Context) {
+	e.mu.Lock()
+	conn := e.conn
+	e.conn = nil
+	e.term = 0
+	callbacks := e.onDemoted
+	e.mu.Unlock()
+
+	conn.Close(ctx)
+
+	for _, fn := range callbacks {
+		fn()
+	}
+}
@@ -0,0 +1,59 @@
+package orchestrator
+
+import (
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// LogHub fans out notifications about new log lines of a run to everyone following its logs on
+// this instance, so that a single database listener can serve any number of followers.
+//
+// Notifications carry no log lines. Followers read the new lines from the database when
+// notified, and notifications arriving while a follower is busy are merged into one.
+type LogHub struct {
+	mu          sync.Mutex
+	subscribers map[uuid.UUID]map[chan struct{}]struct{}
+}
+
+func NewLogHub() *LogHub {
+	return &LogHub{subscribers: make(map[uuid.UUID]map[chan struct{}]struct{})}
+}
+
+// Subscribe returns a channel receiving a value when new log lines of the run are written, and
+// a function ending the subscription.
+func (h *LogHub) Subscribe(taskID uuid.UUID) (<-chan struct{}, func()) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	ch := make(chan struct{}, 1)
+	if h.subscribers[taskID] == nil {
+		h.subscribers[taskID] = make(map[chan struct{}]struct{})
+	}
+	h.subscribers[taskID][ch] = struct{}{}
+
+	unsubscribe := func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+
+		delete(h.subscribers[taskID], ch)
+		if len(h.subscribers[taskID]) == 0 {
+			delete(h.subscribers, taskID)
+		}
+	}
+
+	return ch, unsubscribe
+}
+
+// Publish notifies the subscribers of the run that new log lines have been written.
+func (h *LogHub) Publish(taskID uuid.UUID) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for ch := range h.subscribers[taskID] {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package orchestrator_test
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/r3d5un/Bookshelf/internal/orchestrator"
+)
+
+func TestLogHub(t *testing.T) {
+	hub := orchestrator.NewLogHub()
+
+	taskID := uuid.New()
+	ch, unsubscribe := hub.Subscribe(taskID)
+	other, unsubscribeOther := hub.Subscribe(uuid.New())
+	defer unsubscribeOther()
+
+	t.Run("Publish", func(t *testing.T) {
+		hub.Publish(taskID)
+		hub.Publish(taskID)
+
+		select {
+		case <-ch:
+		default:
+			t.Error("expected a notification")
+			return
+		}
+		select {
+		case <-ch:
+			t.Error("expected notifications to be merged")
+			return
+		default:
+		}
+		select {
+		case <-other:
+			t.Error("expected no notification for another run")
+			return
+		default:
+		}
+	})
+
+	t.Run("Unsubscribe", func(t *testing.T) {
+		unsubscribe()
+		hub.Publish(taskID)
+
+		select {
+		case <-ch:
+			t.Error("expected no notification after unsubscribing")
+			return
+		default:
+		}
+	})
+}
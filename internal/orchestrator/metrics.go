@@ -0,0 +1,127 @@
+package orchestrator
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/r3d5un/Bookshelf/internal/orchestrator/types"
+)
+
+// MetricsContentType is the content type of the Prometheus text exposition format.
+const MetricsContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// Metrics is a snapshot of the state of the orchestrator on one instance.
+type Metrics struct {
+	Tasks       []*types.TaskStats
+	Leader      bool
+	Workers     int
+	BusyWorkers int
+}
+
+// WriteMetrics writes the metrics in the Prometheus text exposition format. Values that are not
+// known, such as the last success of a task that never completed, are left out.
+func WriteMetrics(w io.Writer, metrics Metrics) error {
+	bw := bufio.NewWriter(w)
+
+	writeHeader(bw, "bookshelf_orchestrator_leader",
+		"Whether this instance is the leader running the scheduler.")
+	fmt.Fprintf(bw, "bookshelf_orchestrator_leader %d\n", boolValue(metrics.Leader))
+
+	writeHeader(bw, "bookshelf_orchestrator_workers",
+		"Number of tasks this instance can run at the same time.")
+	fmt.Fprintf(bw, "bookshelf_orchestrator_workers %d\n", metrics.Workers)
+
+	writeHeader(bw, "bookshelf_orchestrator_workers_busy",
+		"Number of tasks currently running on this instance.")
+	fmt.Fprintf(bw, "bookshelf_orchestrator_workers_busy %d\n", metrics.BusyWorkers)
+
+	writeHeader(bw, "bookshelf_orchestrator_task_runs",
+		"Number of runs of a task in the task queue by state.")
+	for _, task := range metrics.Tasks {
+		states := make([]string, 0, len(task.Runs))
+		for state := range task.Runs {
+			states = append(states, state)
+		}
+		sort.Strings(states)
+
+		for _, state := range states {
+			fmt.Fprintf(
+				bw, "bookshelf_orchestrator_task_runs{task=%s,state=%s} %d\n",
+				label(task.Name), label(state), task.Runs[state],
+			)
+		}
+	}
+
+	writeHeader(bw, "bookshelf_orchestrator_task_success_ratio",
+		"Share of finished runs of a task that completed, excluding skipped runs.")
+	for _, task := range metrics.Tasks {
+		if task.SuccessRate != nil {
+			fmt.Fprintf(
+				bw, "bookshelf_orchestrator_task_success_ratio{task=%s} %g\n",
+				label(task.Name), *task.SuccessRate,
+			)
+		}
+	}
+
+	writeHeader(bw, "bookshelf_orchestrator_task_duration_seconds",
+		"Duration of completed runs of a task by quantile.")
+	for _, task := range metrics.Tasks {
+		if task.DurationP50 != nil {
+			fmt.Fprintf(
+				bw, "bookshelf_orchestrator_task_duration_seconds{task=%s,quantile=\"0.5\"} %g\n",
+				label(task.Name), *task.DurationP50,
+			)
+		}
+		if task.DurationP95 != nil {
+			fmt.Fprintf(
+				bw, "bookshelf_orchestrator_task_duration_seconds{task=%s,quantile=\"0.95\"} %g\n",
+				label(task.Name), *task.DurationP95,
+			)
+		}
+	}
+
+	writeHeader(bw, "bookshelf_orchestrator_task_last_success_timestamp_seconds",
+		"Unix time the last completed run of a task finished.")
+	for _, task := range metrics.Tasks {
+		if task.LastSuccessAt != nil {
+			fmt.Fprintf(
+				bw, "bookshelf_orchestrator_task_last_success_timestamp_seconds{task=%s} %d\n",
+				label(task.Name), task.LastSuccessAt.Unix(),
+			)
+		}
+	}
+
+	writeHeader(bw, "bookshelf_orchestrator_task_next_run_timestamp_seconds",
+		"Unix time a task is next scheduled to run.")
+	for _, task := range metrics.Tasks {
+		if task.NextRunAt != nil {
+			fmt.Fprintf(
+				bw, "bookshelf_orchestrator_task_next_run_timestamp_seconds{task=%s} %d\n",
+				label(task.Name), task.NextRunAt.Unix(),
+			)
+		}
+	}
+
+	return bw.Flush()
+}
+
+func writeHeader(w io.Writer, name string, help string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s gauge\n", name, help, name)
+}
+
+var labelReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// label quotes a label value, escaping the characters not allowed in the exposition format.
+func label(value string) string {
+	return `"` + labelReplacer.Replace(value) + `"`
+}
+
+func boolValue(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,67 @@
+package orchestrator_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/r3d5un/Bookshelf/internal/orchestrator"
+	"github.com/r3d5un/Bookshelf/internal/orchestrator/types"
+)
+
+func TestWriteMetrics(t *testing.T) {
+	successRate := 0.75
+	p50 := 1.5
+	lastSuccess := time.Unix(1_700_000_000, 0)
+
+	metrics := orchestrator.Metrics{
+		Tasks: []*types.TaskStats{
+			{
+				Name:          "Remove Old Scheduled Tasks",
+				Runs:          map[string]int{"error": 1, "complete": 3},
+				SuccessRate:   &successRate,
+				DurationP50:   &p50,
+				LastSuccessAt: &lastSuccess,
+			},
+			{
+				Name: `Quoted "Task"`,
+				Runs: map[string]int{},
+			},
+		},
+		Leader:      true,
+		Workers:     4,
+		BusyWorkers: 1,
+	}
+
+	var sb strings.Builder
+	err := orchestrator.WriteMetrics(&sb, metrics)
+	if err != nil {
+		t.Errorf("unable to write metrics: %s\n", err)
+		return
+	}
+	output := sb.String()
+
+	expected := []string{
+		"bookshelf_orchestrator_leader 1\n",
+		"bookshelf_orchestrator_workers_busy 1\n",
+		`bookshelf_orchestrator_task_runs{task="Remove Old Scheduled Tasks",state="complete"} 3` + "\n",
+		`bookshelf_orchestrator_task_success_ratio{task="Remove Old Scheduled Tasks"} 0.75` + "\n",
+		`bookshelf_orchestrator_task_duration_seconds{task="Remove Old Scheduled Tasks",quantile="0.5"} 1.5` + "\n",
+		`bookshelf_orchestrator_task_last_success_timestamp_seconds{task="Remove Old Scheduled Tasks"} 1700000000` + "\n",
+	}
+	for _, line := range expected {
+		if !strings.Contains(output, line) {
+			t.Errorf("expected metrics to contain %q, got:\n%s", line, output)
+		}
+	}
+
+	if strings.Index(output, `state="complete"`) > strings.Index(output, `state="error"`) {
+		t.Errorf("expected states to be sorted, got:\n%s", output)
+	}
+	if strings.Contains(output, `task="Quoted "Task""`) {
+		t.Errorf("expected label values to be escaped, got:\n%s", output)
+	}
+	if strings.Contains(output, "quantile=\"0.95\"") {
+		t.Errorf("expected unknown values to be left out, got:\n%s", output)
+	}
+}
@@ -9,6 +9,8 @@ var (
 	ErrNoTask = errors.New("task does not exist")
 )
 
+// Task is the function run for a task. The context is cancelled when the run is stopped or
+// exceeds its max runtime, and tasks should return as soon as possible once it is.
 type Task func(context.Context) error
 
 type Collection map[string]Task
@@ -0,0 +1,61 @@
+package orchestrator
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrNoRun     = errors.New("no task run in context")
+	ErrNoPayload = errors.New("task run has no payload")
+)
+
+// Run describes the task run a task function is called for.
+type Run struct {
+	// ID of the run in the task queue
+	ID uuid.UUID
+	// Name of the task
+	Name string
+	// Attempt is the number of the current attempt, starting at 1
+	Attempt int
+	// Payload holds the arguments the run was enqueued with as JSON
+	Payload json.RawMessage
+}
+
+type runContextKey struct{}
+
+// WithRun returns a copy of the context carrying the given run.
+func WithRun(ctx context.Context, run Run) context.Context {
+	return context.WithValue(ctx, runContextKey{}, run)
+}
+
+// RunFromContext returns the run embedded in the context by the task runner.
+func RunFromContext(ctx context.Context) (Run, bool) {
+	run, ok := ctx.Value(runContextKey{}).(Run)
+	return run, ok
+}
+
+// DecodePayload decodes the payload of the run in the context into a value of type T.
+// ErrNoPayload is returned if the run was enqueued without a payload.
+func DecodePayload[T any](ctx context.Context) (T, error) {
+	var payload T
+
+	run, ok := RunFromContext(ctx)
+	if !ok {
+		return payload, ErrNoRun
+	}
+	if len(run.Payload) == 0 || string(run.Payload) == "null" {
+		return payload, ErrNoPayload
+	}
+
+	err := json.Unmarshal(run.Payload, &payload)
+	if err != nil {
+		return payload, fmt.Errorf("unable to decode payload of task %s: %w", run.Name, err)
+	}
+
+	return payload, nil
+}
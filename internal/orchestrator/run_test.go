@@ -0,0 +1,66 @@
+package orchestrator_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/r3d5un/Bookshelf/internal/orchestrator"
+)
+
+func TestDecodePayload(t *testing.T) {
+	type importPayload struct {
+		Directory string `json:"directory"`
+	}
+
+	t.Run("Payload", func(t *testing.T) {
+		ctx := orchestrator.WithRun(context.Background(), orchestrator.Run{
+			ID:      uuid.New(),
+			Name:    "import",
+			Payload: json.RawMessage(`{"directory": "/srv/books"}`),
+		})
+
+		payload, err := orchestrator.DecodePayload[importPayload](ctx)
+		if err != nil {
+			t.Errorf("unable to decode payload: %s\n", err)
+			return
+		}
+		if payload.Directory != "/srv/books" {
+			t.Errorf("expected directory /srv/books, got %s\n", payload.Directory)
+			return
+		}
+	})
+
+	t.Run("NoPayload", func(t *testing.T) {
+		ctx := orchestrator.WithRun(context.Background(), orchestrator.Run{ID: uuid.New()})
+
+		_, err := orchestrator.DecodePayload[importPayload](ctx)
+		if !errors.Is(err, orchestrator.ErrNoPayload) {
+			t.Errorf("expected %s, got %v\n", orchestrator.ErrNoPayload, err)
+			return
+		}
+	})
+
+	t.Run("InvalidPayload", func(t *testing.T) {
+		ctx := orchestrator.WithRun(context.Background(), orchestrator.Run{
+			ID:      uuid.New(),
+			Payload: json.RawMessage(`{"directory": 1}`),
+		})
+
+		_, err := orchestrator.DecodePayload[importPayload](ctx)
+		if err == nil {
+			t.Errorf("expected an error decoding an invalid payload\n")
+			return
+		}
+	})
+
+	t.Run("NoRun", func(t *testing.T) {
+		_, err := orchestrator.DecodePayload[importPayload](context.Background())
+		if !errors.Is(err, orchestrator.ErrNoRun) {
+			t.Errorf("expected %s, got %v\n", orchestrator.ErrNoRun, err)
+			return
+		}
+	})
+}
@@ -2,7 +2,12 @@ package orchestrator
 
 import (
 	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/r3d5un/Bookshelf/internal/logging"
 	"github.com/r3d5un/Bookshelf/internal/orchestrator/data"
 	"github.com/r3d5un/Bookshelf/internal/orchestrator/types"
@@ -11,18 +16,34 @@ import (
 
 // CronScheduler emits tasks to the orchestrator task queue, and notifies
 // listeners about new tasks. The scheduler does not run any tasks itself.
+//
+// Only the leader should run the scheduler. Runs enqueued by cron jobs are fenced by the leader
+// term the scheduler was started with, and are rejected once another instance has been elected.
+//
+// Besides the schedule of each task, a task can have any number of schedules stored in the
+// database, each enqueuing runs with its own payload.
 type CronScheduler struct {
-	cron   *cron.Cron
-	models *data.Models
+	cron    *cron.Cron
+	models  *data.Models
+	mu      sync.Mutex
+	entries map[string]cron.EntryID
+	// schedules holds the cron jobs of the stored schedules of each task, by task name
+	schedules map[string]map[uuid.UUID]cron.EntryID
+	term      atomic.Int64
 }
 
 func NewScheduler(models *data.Models) *CronScheduler {
 	return &CronScheduler{
-		cron:   cron.New(),
-		models: models,
+		cron:      cron.New(),
+		models:    models,
+		entries:   make(map[string]cron.EntryID),
+		schedules: make(map[string]map[uuid.UUID]cron.EntryID),
 	}
 }
 
+// AddCronJob schedules the task using the given cron expression. Any cron job previously added
+// for a task with the same name is replaced, allowing the schedule to change while the
+// scheduler is running.
 func (s *CronScheduler) AddCronJob(
 	ctx context.Context,
 	cronExpr string,
@@ -30,49 +51,204 @@ func (s *CronScheduler) AddCronJob(
 ) (err error) {
 	logger := logging.LoggerFromContext(ctx)
 
-	_, err = s.cron.AddFunc(cronExpr, func() {
-		err := s.Enqueue(ctx, task)
-		if err != nil {
-			logger.Error("unable to enqueue task", "error", err)
-			// Ideally, an alert should be sent here to notify the admin of the error
-		}
-	})
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entryID, err := s.cron.AddFunc(cronExpr, s.job(ctx, task))
 	if err != nil {
 		logger.Error("uanble to add cronjob", "error", err)
 		return err
 	}
 
+	if oldEntryID, found := s.entries[*task.Name]; found {
+		logger.Info("replacing existing cronjob", "entryId", oldEntryID)
+		s.cron.Remove(oldEntryID)
+	}
+	s.entries[*task.Name] = entryID
+
 	return nil
 }
 
-// TODO: Cleanup the function signature
-func (s *CronScheduler) Enqueue(ctx context.Context, newTask types.ScheduledTask) error {
-	logger := logging.LoggerFromContext(ctx).With("newTask", newTask)
-	newTaskRow := data.TaskQueue{
-		Name:  newTask.Name,
-		State: newTask.State,
-		RunAt: newTask.RunAt,
+// RemoveCronJob stops the given task from being scheduled, or does nothing if the task has no
+// cron job.
+func (s *CronScheduler) RemoveCronJob(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if entryID, found := s.entries[name]; found {
+		s.cron.Remove(entryID)
+		delete(s.entries, name)
+	}
+}
+
+// SetSchedules replaces the cron jobs of the stored schedules of the named task. Each schedule
+// enqueues runs of the task with the payload of the schedule, and disabled schedules are left
+// out. Passing no schedules removes all of them.
+//
+// If any schedule cannot be added, the previous cron jobs of the task are kept.
+func (s *CronScheduler) SetSchedules(
+	ctx context.Context,
+	taskName string,
+	schedules []*types.TaskSchedule,
+) error {
+	logger := logging.LoggerFromContext(ctx).With("taskName", taskName)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries := make(map[uuid.UUID]cron.EntryID, len(schedules))
+	for _, schedule := range schedules {
+		if schedule.Enabled != nil && !*schedule.Enabled {
+			continue
+		}
+
+		task := types.ScheduledTask{Name: &taskName, Payload: schedule.Payload}
+		entryID, err := s.cron.AddFunc(*schedule.CronExpr, s.job(ctx, task))
+		if err != nil {
+			logger.Error("unable to add schedule cronjob", "schedule", schedule, "error", err)
+			for _, entryID := range entries {
+				s.cron.Remove(entryID)
+			}
+			return err
+		}
+		entries[schedule.ID] = entryID
 	}
 
-	enqueuedTask, err := s.models.TaskQueues.Insert(ctx, newTaskRow)
+	for _, oldEntryID := range s.schedules[taskName] {
+		s.cron.Remove(oldEntryID)
+	}
+	if len(entries) == 0 {
+		delete(s.schedules, taskName)
+	} else {
+		s.schedules[taskName] = entries
+	}
+	logger.Info("schedules set", "length", len(entries))
+
+	return nil
+}
+
+// Next returns when the named task is next enqueued by its own cron job or any of its stored
+// schedules, or false if the task is not scheduled. The time is computed from the schedules, so
+// it is known even while the scheduler is not running on this instance.
+func (s *CronScheduler) Next(name string) (time.Time, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	var next time.Time
+
+	entryIDs := make([]cron.EntryID, 0, len(s.schedules[name])+1)
+	if entryID, found := s.entries[name]; found {
+		entryIDs = append(entryIDs, entryID)
+	}
+	for _, entryID := range s.schedules[name] {
+		entryIDs = append(entryIDs, entryID)
+	}
+
+	for _, entryID := range entryIDs {
+		entryNext := s.cron.Entry(entryID).Schedule.Next(now)
+		if next.IsZero() || entryNext.Before(next) {
+			next = entryNext
+		}
+	}
+
+	return next, !next.IsZero()
+}
+
+// NextSchedule returns when the stored schedule next enqueues a run, or false if the schedule is
+// not added to the scheduler, such as when it or its task is disabled.
+func (s *CronScheduler) NextSchedule(id uuid.UUID) (time.Time, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, entries := range s.schedules {
+		if entryID, found := entries[id]; found {
+			return s.cron.Entry(entryID).Schedule.Next(time.Now()), true
+		}
+	}
+
+	return time.Time{}, false
+}
+
+// job returns the function run by a cron job, enqueuing the task while the scheduler holds the
+// current leader term.
+func (s *CronScheduler) job(ctx context.Context, task types.ScheduledTask) func() {
+	logger := logging.LoggerFromContext(ctx)
+
+	return func() {
+		_, err := s.enqueueFenced(ctx, task)
+		if err != nil {
+			switch {
+			case errors.Is(err, data.ErrStaleTerm):
+				logger.Info("leader term no longer current; task not enqueued")
+			default:
+				logger.Error("unable to enqueue task", "error", err)
+				// Ideally, an alert should be sent here to notify the admin of the error
+			}
+		}
+	}
+}
+
+// Enqueue inserts the task into the task queue, and notifies listeners about the new task.
+func (s *CronScheduler) Enqueue(
+	ctx context.Context,
+	newTask types.ScheduledTask,
+) (*types.ScheduledTask, error) {
+	logger := logging.LoggerFromContext(ctx).With("newTask", newTask)
+
+	enqueuedTask, err := types.ScheduleTask(ctx, s.models, newTask)
 	if err != nil {
 		logger.Error("unable to enqueue task")
-		return err
+		return nil, err
 	}
 	logger.Info("task enqueued", "enqueuedTask", enqueuedTask)
 
+	s.notify(ctx, enqueuedTask)
+
+	return enqueuedTask, nil
+}
+
+// enqueueFenced inserts the task into the task queue if the term the scheduler was started with
+// is still the current leader term, and notifies listeners about the new task.
+func (s *CronScheduler) enqueueFenced(
+	ctx context.Context,
+	newTask types.ScheduledTask,
+) (*types.ScheduledTask, error) {
+	term := s.term.Load()
+	logger := logging.LoggerFromContext(ctx).With("newTask", newTask, "term", term)
+
+	enqueuedTask, err := types.ScheduleFencedTask(ctx, s.models, newTask, term)
+	if err != nil {
+		logger.Error("unable to enqueue task", "error", err)
+		return nil, err
+	}
+	logger.Info("task enqueued", "enqueuedTask", enqueuedTask)
+
+	s.notify(ctx, enqueuedTask)
+
+	return enqueuedTask, nil
+}
+
+func (s *CronScheduler) notify(ctx context.Context, enqueuedTask *types.ScheduledTask) {
+	logger := logging.LoggerFromContext(ctx).With("enqueuedTask", enqueuedTask)
+
 	logger.Info("notifying listeners of new task")
-	err = s.models.TaskNotifications.Notify(
+	err := s.models.TaskNotifications.Notify(
 		ctx,
 		data.TaskNotification{ID: enqueuedTask.ID, Queue: *enqueuedTask.Name},
 	)
+	if err != nil {
+		// The task reminder picks up waiting tasks that were never announced
+		logger.Error("unable to notify listeners", "error", err)
+		return
+	}
 	logger.Info("listeners notified")
-
-	return nil
 }
 
-// Start the scheduler in it's own goroutine, or no-op if already running.
-func (s *CronScheduler) Start() {
+// Start the scheduler in it's own goroutine for the given leader term, or only update the term
+// if already running.
+func (s *CronScheduler) Start(term int64) {
+	s.term.Store(term)
 	s.cron.Start()
 }
 
@@ -0,0 +1,80 @@
+package orchestrator_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/r3d5un/Bookshelf/internal/orchestrator"
+	"github.com/r3d5un/Bookshelf/internal/orchestrator/types"
+)
+
+func TestCronSchedulerSchedules(t *testing.T) {
+	scheduler := orchestrator.NewScheduler(nil)
+
+	taskName := "Import Books"
+	hourly, daily, invalid := "0 * * * *", "0 0 * * *", "not a cron expression"
+	disabled := false
+
+	hourlySchedule := &types.TaskSchedule{ID: uuid.New(), CronExpr: &hourly}
+	dailySchedule := &types.TaskSchedule{ID: uuid.New(), CronExpr: &daily}
+	disabledSchedule := &types.TaskSchedule{ID: uuid.New(), CronExpr: &hourly, Enabled: &disabled}
+
+	t.Run("SetSchedules", func(t *testing.T) {
+		err := scheduler.SetSchedules(
+			context.Background(),
+			taskName,
+			[]*types.TaskSchedule{hourlySchedule, dailySchedule, disabledSchedule},
+		)
+		if err != nil {
+			t.Errorf("unable to set schedules: %s\n", err)
+			return
+		}
+
+		next, ok := scheduler.Next(taskName)
+		if !ok {
+			t.Error("expected task to be scheduled")
+			return
+		}
+		if next.After(time.Now().Add(time.Hour)) {
+			t.Errorf("expected the hourly schedule to be next, got %s\n", next)
+			return
+		}
+		if _, ok := scheduler.NextSchedule(disabledSchedule.ID); ok {
+			t.Error("expected disabled schedule not to be scheduled")
+			return
+		}
+	})
+
+	t.Run("SetInvalidSchedules", func(t *testing.T) {
+		invalidSchedule := &types.TaskSchedule{ID: uuid.New(), CronExpr: &invalid}
+
+		err := scheduler.SetSchedules(
+			context.Background(),
+			taskName,
+			[]*types.TaskSchedule{dailySchedule, invalidSchedule},
+		)
+		if err == nil {
+			t.Error("expected an error for an invalid cron expression")
+			return
+		}
+		if _, ok := scheduler.NextSchedule(hourlySchedule.ID); !ok {
+			t.Error("expected previous schedules to be kept")
+			return
+		}
+	})
+
+	t.Run("RemoveSchedules", func(t *testing.T) {
+		err := scheduler.SetSchedules(context.Background(), taskName, nil)
+		if err != nil {
+			t.Errorf("unable to remove schedules: %s\n", err)
+			return
+		}
+
+		if _, ok := scheduler.Next(taskName); ok {
+			t.Error("expected task not to be scheduled")
+			return
+		}
+	})
+}
@@ -0,0 +1,41 @@
+package types
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/r3d5un/Bookshelf/internal/orchestrator/data"
+)
+
+type Leader struct {
+	// Term is increased every time a leader is elected, and is used as a fencing token
+	Term       int64      `json:"term"`
+	InstanceID *uuid.UUID `json:"instanceId"`
+	ElectedAt  *time.Time `json:"electedAt"`
+	// Active is true if the leader lock is currently held by an instance
+	Active bool `json:"active"`
+}
+
+// LeaderStatus describes the current leader as seen by the instance reporting it.
+type LeaderStatus struct {
+	Leader     *Leader   `json:"leader"`
+	InstanceID uuid.UUID `json:"instanceId"`
+	IsLeader   bool      `json:"isLeader"`
+}
+
+func ReadLeader(ctx context.Context, models *data.Models) (*Leader, error) {
+	leaderRow, err := models.Leader.Get(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	leader := Leader{
+		Term:       leaderRow.Term,
+		InstanceID: leaderRow.InstanceID,
+		ElectedAt:  leaderRow.ElectedAt,
+		Active:     leaderRow.Active,
+	}
+
+	return &leader, nil
+}
@@ -2,7 +2,10 @@ package types
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"log/slog"
+	"math/rand/v2"
 	"time"
 
 	"github.com/google/uuid"
@@ -10,14 +13,32 @@ import (
 	"github.com/r3d5un/Bookshelf/internal/orchestrator/data"
 )
 
+var (
+	ErrTaskNotWaiting = errors.New("scheduled task is not waiting")
+	ErrTaskNotDead    = errors.New("scheduled task is not dead")
+	ErrTaskNotRunning = errors.New("scheduled task is not running")
+)
+
 type ScheduledTask struct {
-	ID        uuid.UUID  `json:"id"`
-	Name      *string    `json:"queue"`
-	State     *string    `json:"state"`
-	CreatedAt *time.Time `json:"createdAt"`
-	UpdatedAt *time.Time `json:"updatedAt"`
-	RunAt     *time.Time `json:"runAt"`
-	TaskData  *string    `json:"task_data,omitempty"`
+	ID          uuid.UUID  `json:"id"`
+	Name        *string    `json:"queue"`
+	State       *string    `json:"state"`
+	CreatedAt   *time.Time `json:"createdAt"`
+	UpdatedAt   *time.Time `json:"updatedAt"`
+	RunAt       *time.Time `json:"runAt"`
+	Attempt     *int       `json:"attempt,omitempty"`
+	LastError   *string    `json:"lastError,omitempty"`
+	HeartbeatAt *time.Time `json:"heartbeatAt,omitempty"`
+	// Payload holds the arguments of the run as JSON, decoded by the task with DecodePayload
+	Payload json.RawMessage `json:"payload,omitempty"`
+	// ParentID is the run that started this run as part of a workflow
+	ParentID *uuid.UUID `json:"parentId,omitempty"`
+	// AfterID is the run that must complete before this run is started
+	AfterID *uuid.UUID `json:"afterId,omitempty"`
+	// StartedAt is when the run was last claimed by a task runner
+	StartedAt *time.Time `json:"startedAt,omitempty"`
+	// FinishedAt is when the run last reached a finished state
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
 }
 
 type ScheduledTaskCollection struct {
@@ -40,16 +61,7 @@ func ReadScheduledTask(
 		return nil, err
 	}
 
-	task := ScheduledTask{
-		ID:        tq.ID,
-		Name:      tq.Name,
-		State:     tq.State,
-		CreatedAt: tq.CreatedAt,
-		UpdatedAt: tq.UpdatedAt,
-		RunAt:     tq.RunAt,
-	}
-
-	return &task, nil
+	return newScheduledTaskFromRow(tq), nil
 }
 
 func ReadAllScheudledTasks(
@@ -64,16 +76,7 @@ func ReadAllScheudledTasks(
 
 	var tasks []*ScheduledTask
 	for _, t := range tq {
-		task := ScheduledTask{
-			ID:        t.ID,
-			Name:      t.Name,
-			State:     t.State,
-			CreatedAt: t.CreatedAt,
-			UpdatedAt: t.UpdatedAt,
-			RunAt:     t.RunAt,
-		}
-
-		tasks = append(tasks, &task)
+		tasks = append(tasks, newScheduledTaskFromRow(t))
 	}
 
 	tc = &ScheduledTaskCollection{
@@ -98,9 +101,15 @@ func ScheduleTask(
 	newTask ScheduledTask,
 ) (createdTask *ScheduledTask, err error) {
 	newTaskRow := data.TaskQueue{
-		Name:  newTask.Name,
-		State: newTask.State,
-		RunAt: newTask.RunAt,
+		Name:        newTask.Name,
+		State:       newTask.State,
+		RunAt:       newTask.RunAt,
+		Attempt:     newTask.Attempt,
+		LastError:   newTask.LastError,
+		HeartbeatAt: newTask.HeartbeatAt,
+		Payload:     newTask.Payload,
+		ParentID:    newTask.ParentID,
+		AfterID:     newTask.AfterID,
 	}
 
 	insertedTask, err := models.TaskQueues.Insert(ctx, newTaskRow)
@@ -108,16 +117,32 @@ func ScheduleTask(
 		return nil, err
 	}
 
-	createdTask = &ScheduledTask{
-		ID:        insertedTask.ID,
-		Name:      insertedTask.Name,
-		State:     insertedTask.State,
-		CreatedAt: insertedTask.CreatedAt,
-		UpdatedAt: insertedTask.UpdatedAt,
-		RunAt:     insertedTask.RunAt,
+	return newScheduledTaskFromRow(insertedTask), nil
+}
+
+// ScheduleFencedTask enqueues a new task to the task queue on behalf of the leader with the given
+// term. data.ErrStaleTerm is returned if the leader has since been replaced.
+func ScheduleFencedTask(
+	ctx context.Context,
+	models *data.Models,
+	newTask ScheduledTask,
+	term int64,
+) (createdTask *ScheduledTask, err error) {
+	newTaskRow := data.TaskQueue{
+		Name:     newTask.Name,
+		State:    newTask.State,
+		RunAt:    newTask.RunAt,
+		Payload:  newTask.Payload,
+		ParentID: newTask.ParentID,
+		AfterID:  newTask.AfterID,
 	}
 
-	return createdTask, nil
+	insertedTask, err := models.TaskQueues.InsertFenced(ctx, newTaskRow, term)
+	if err != nil {
+		return nil, err
+	}
+
+	return newScheduledTaskFromRow(insertedTask), nil
 }
 
 func UpdateScheduledTask(
@@ -126,28 +151,25 @@ func UpdateScheduledTask(
 	newTaskData ScheduledTask,
 ) (updatedTask *ScheduledTask, err error) {
 	newTaskRow := data.TaskQueue{
-		ID:        newTaskData.ID,
-		Name:      newTaskData.Name,
-		State:     newTaskData.State,
-		CreatedAt: newTaskData.CreatedAt,
-		UpdatedAt: newTaskData.UpdatedAt,
-		RunAt:     newTaskData.RunAt,
+		ID:          newTaskData.ID,
+		Name:        newTaskData.Name,
+		State:       newTaskData.State,
+		CreatedAt:   newTaskData.CreatedAt,
+		UpdatedAt:   newTaskData.UpdatedAt,
+		RunAt:       newTaskData.RunAt,
+		Attempt:     newTaskData.Attempt,
+		LastError:   newTaskData.LastError,
+		HeartbeatAt: newTaskData.HeartbeatAt,
+		Payload:     newTaskData.Payload,
+		ParentID:    newTaskData.ParentID,
+		AfterID:     newTaskData.AfterID,
 	}
 	updatedTaskRow, err := models.TaskQueues.Update(ctx, newTaskRow)
 	if err != nil {
 		return nil, err
 	}
 
-	updatedTask = &ScheduledTask{
-		ID:        updatedTaskRow.ID,
-		Name:      updatedTaskRow.Name,
-		State:     updatedTaskRow.State,
-		CreatedAt: updatedTaskRow.CreatedAt,
-		UpdatedAt: updatedTaskRow.UpdatedAt,
-		RunAt:     updatedTaskRow.RunAt,
-	}
-
-	return updatedTask, nil
+	return newScheduledTaskFromRow(updatedTaskRow), nil
 }
 
 func DeleteScheduledTask(
@@ -160,16 +182,7 @@ func DeleteScheduledTask(
 		return nil, err
 	}
 
-	task := ScheduledTask{
-		ID:        deletedTaskRow.ID,
-		Name:      deletedTaskRow.Name,
-		State:     deletedTaskRow.State,
-		CreatedAt: deletedTaskRow.CreatedAt,
-		UpdatedAt: deletedTaskRow.UpdatedAt,
-		RunAt:     deletedTaskRow.RunAt,
-	}
-
-	return &task, nil
+	return newScheduledTaskFromRow(deletedTaskRow), nil
 }
 
 // ClaimScheduledTaskByID selects and locks a task from the queue, marking it with the running state
@@ -222,16 +235,7 @@ func ClaimScheduledTaskByID(
 	}
 	logger.Info("task set to running")
 
-	task := ScheduledTask{
-		ID:        taskRow.ID,
-		Name:      taskRow.Name,
-		State:     taskRow.State,
-		CreatedAt: taskRow.CreatedAt,
-		UpdatedAt: taskRow.UpdatedAt,
-		RunAt:     taskRow.RunAt,
-	}
-
-	return &task, nil
+	return newScheduledTaskFromRow(taskRow), nil
 }
 
 // SetScheduledTaskState selects and locks a task from the queue, setting it with the desired
@@ -248,7 +252,7 @@ func SetScheduledTaskState(
 	}
 	defer tx.Rollback(ctx)
 
-	taskRow, err := models.TaskQueues.ClaimTx(ctx, tx, taskID)
+	taskRow, err := models.TaskQueues.LockTx(ctx, tx, taskID)
 	if err != nil {
 		return nil, err
 	}
@@ -256,16 +260,40 @@ func SetScheduledTaskState(
 	newState := string(state)
 	taskRow.State = &newState
 
-	task := ScheduledTask{
-		ID:        taskRow.ID,
-		Name:      taskRow.Name,
-		State:     taskRow.State,
-		CreatedAt: taskRow.CreatedAt,
-		UpdatedAt: taskRow.UpdatedAt,
-		RunAt:     taskRow.RunAt,
+	taskRow, err = models.TaskQueues.UpdateTx(ctx, tx, *taskRow)
+	if err != nil {
+		return nil, err
 	}
 
-	return &task, nil
+	err = tx.Commit(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return newScheduledTaskFromRow(taskRow), nil
+}
+
+// CancelScheduledTask sets a waiting or blocked task to the stopped state, preventing it from
+// running. ErrTaskNotWaiting is returned if the task has already been picked up or has finished.
+func CancelScheduledTask(
+	ctx context.Context,
+	models *data.Models,
+	taskID uuid.UUID,
+) (*ScheduledTask, error) {
+	_, err := models.TaskQueues.Get(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	taskRow, err := models.TaskQueues.Cancel(ctx, taskID)
+	if err != nil {
+		if errors.Is(err, data.ErrRecordNotFound) {
+			return nil, ErrTaskNotWaiting
+		}
+		return nil, err
+	}
+
+	return newScheduledTaskFromRow(taskRow), nil
 }
 
 // DequeueScheduledTask selects and locks a task from the queue, then deletes it. The task is returned
@@ -281,7 +309,7 @@ func DequeueScheduledTask(
 	}
 	defer tx.Rollback(ctx)
 
-	taskRow, err := models.TaskQueues.ClaimTx(ctx, tx, taskID)
+	taskRow, err := models.TaskQueues.LockTx(ctx, tx, taskID)
 	if err != nil {
 		return nil, err
 	}
@@ -291,14 +319,176 @@ func DequeueScheduledTask(
 		return nil, err
 	}
 
-	task := ScheduledTask{
-		ID:        taskRow.ID,
-		Name:      taskRow.Name,
-		State:     taskRow.State,
-		CreatedAt: taskRow.CreatedAt,
-		UpdatedAt: taskRow.UpdatedAt,
-		RunAt:     taskRow.RunAt,
+	err = tx.Commit(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return newScheduledTaskFromRow(taskRow), nil
+}
+
+// FailScheduledTask records a failed run of a task. If the task has attempts left, the run is put
+// back in the queue to be retried once the retry delay has passed. Runs failing on their final
+// attempt are moved to the dead state, where they are kept until replayed.
+func FailScheduledTask(
+	ctx context.Context,
+	models *data.Models,
+	scheduledTask ScheduledTask,
+	task Task,
+	runErr error,
+) (*ScheduledTask, error) {
+	logger := logging.LoggerFromContext(ctx).With(slog.String("taskId", scheduledTask.ID.String()))
+
+	attempt := 1
+	if scheduledTask.Attempt != nil {
+		attempt = *scheduledTask.Attempt
+	}
+	maxAttempts := 1
+	if task.MaxAttempts != nil {
+		maxAttempts = *task.MaxAttempts
 	}
 
-	return &task, nil
+	lastError := runErr.Error()
+	failedTask := ScheduledTask{ID: scheduledTask.ID, LastError: &lastError}
+
+	if attempt < maxAttempts {
+		state := string(data.WaitingTaskState)
+		nextAttempt := attempt + 1
+		runAt := time.Now().Add(RetryDelay(task, attempt))
+		logger.Info(
+			"scheduling retry",
+			"attempt", nextAttempt,
+			"maxAttempts", maxAttempts,
+			"runAt", runAt,
+		)
+
+		failedTask.State = &state
+		failedTask.Attempt = &nextAttempt
+		failedTask.RunAt = &runAt
+	} else {
+		logger.Info("no attempts left; moving task to dead state", "attempt", attempt)
+		state := string(data.DeadTaskState)
+		failedTask.State = &state
+	}
+
+	return UpdateScheduledTask(ctx, models, failedTask)
+}
+
+// RetryDelay returns how long to wait before retrying a task that failed on the given attempt.
+// The delay starts at the retry backoff of the task, doubling for each attempt, with a random
+// jitter of up to the retry jitter of the task added.
+func RetryDelay(task Task, attempt int) time.Duration {
+	backoff := time.Minute
+	if task.RetryBackoff != nil {
+		backoff = time.Duration(*task.RetryBackoff) * time.Second
+	}
+	var jitter time.Duration
+	if task.RetryJitter != nil {
+		jitter = time.Duration(*task.RetryJitter) * time.Second
+	}
+
+	// Limit the exponent to avoid overflowing the delay
+	exponent := min(max(attempt-1, 0), 16)
+	delay := backoff * (1 << exponent)
+	if jitter > 0 {
+		delay += rand.N(jitter + 1)
+	}
+
+	return delay
+}
+
+// ReplayScheduledTask puts a dead task back in the queue to run immediately, starting over
+// from the first attempt. ErrTaskNotDead is returned if the task is not dead.
+func ReplayScheduledTask(
+	ctx context.Context,
+	models *data.Models,
+	taskID uuid.UUID,
+) (*ScheduledTask, error) {
+	_, err := models.TaskQueues.Get(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	taskRow, err := models.TaskQueues.Replay(ctx, taskID)
+	if err != nil {
+		if errors.Is(err, data.ErrRecordNotFound) {
+			return nil, ErrTaskNotDead
+		}
+		return nil, err
+	}
+
+	return newScheduledTaskFromRow(taskRow), nil
+}
+
+// HeartbeatScheduledTask marks a running task as alive.
+func HeartbeatScheduledTask(ctx context.Context, models *data.Models, taskID uuid.UUID) error {
+	return models.TaskQueues.Heartbeat(ctx, taskID)
+}
+
+// RequeueOrphanedScheduledTasks puts running tasks without a heartbeat since staleBefore back in
+// the queue. These are runs that were left behind by an instance that stopped unexpectedly. Each
+// orphaned run counts as a failed attempt, so that runs of tasks crashing the instance are moved
+// to the dead state once their attempts are used up, rather than being requeued forever.
+func RequeueOrphanedScheduledTasks(
+	ctx context.Context,
+	models *data.Models,
+	staleBefore time.Time,
+) ([]*ScheduledTask, error) {
+	taskRows, err := models.TaskQueues.RequeueOrphaned(ctx, staleBefore)
+	if err != nil {
+		return nil, err
+	}
+
+	tasks := make([]*ScheduledTask, 0, len(taskRows))
+	for _, taskRow := range taskRows {
+		tasks = append(tasks, newScheduledTaskFromRow(taskRow))
+	}
+
+	return tasks, nil
+}
+
+// ReadRunnableScheduledTasks returns up to limit waiting tasks that are due to run, with tasks of
+// a higher priority first.
+func ReadRunnableScheduledTasks(
+	ctx context.Context,
+	models *data.Models,
+	limit int,
+) ([]*ScheduledTask, error) {
+	taskRows, err := models.TaskQueues.GetRunnable(ctx, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	tasks := make([]*ScheduledTask, 0, len(taskRows))
+	for _, taskRow := range taskRows {
+		tasks = append(tasks, newScheduledTaskFromRow(taskRow))
+	}
+
+	return tasks, nil
+}
+
+// ReadNextRunnableScheduledTask returns the waiting run of the named task that is next in line
+// to run.
+func ReadNextRunnableScheduledTask(
+	ctx context.Context,
+	models *data.Models,
+	name string,
+) (*ScheduledTask, error) {
+	taskRow, err := models.TaskQueues.GetNextRunnable(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	return newScheduledTaskFromRow(taskRow), nil
+}
+
+// DeleteExpiredScheduledTasks deletes up to limit finished runs older than their retention, and
+// returns the number of runs deleted.
+func DeleteExpiredScheduledTasks(
+	ctx context.Context,
+	models *data.Models,
+	policy data.RetentionPolicy,
+	limit int,
+) (int64, error) {
+	return models.TaskQueues.DeleteExpired(ctx, policy, limit)
 }
@@ -2,9 +2,11 @@ package types_test
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/r3d5un/Bookshelf/internal/orchestrator/data"
 	"github.com/r3d5un/Bookshelf/internal/orchestrator/types"
 )
@@ -89,3 +91,208 @@ func TestScheduledTaskTypes(t *testing.T) {
 		}
 	})
 }
+
+func TestCancelScheduledTask(t *testing.T) {
+	insertedTask, err := types.CreateTask(
+		context.Background(),
+		models,
+		types.NewTask("test_cancel_queue", "* * * * *", false, time.Now(), nil),
+	)
+	if err != nil {
+		t.Errorf("an error occurred while creating parent task for overview: %s\n", err)
+		return
+	}
+
+	runAt := time.Now().Add(time.Hour)
+	scheduledTask, err := types.ScheduleTask(context.Background(), models, types.ScheduledTask{
+		Name:  &insertedTask.Name,
+		RunAt: &runAt,
+	})
+	if err != nil {
+		t.Errorf("error occurred while creating task: %s\n", err)
+		return
+	}
+
+	t.Run("CancelWaiting", func(t *testing.T) {
+		cancelledTask, err := types.CancelScheduledTask(
+			context.Background(), models, scheduledTask.ID,
+		)
+		if err != nil {
+			t.Errorf("error occurred while cancelling task: %s\n", err)
+			return
+		}
+		if *cancelledTask.State != string(data.StoppedTaskState) {
+			t.Errorf(
+				"expected task state %s, got %s\n", data.StoppedTaskState, *cancelledTask.State,
+			)
+			return
+		}
+	})
+
+	t.Run("CancelStopped", func(t *testing.T) {
+		_, err := types.CancelScheduledTask(context.Background(), models, scheduledTask.ID)
+		if !errors.Is(err, types.ErrTaskNotWaiting) {
+			t.Errorf("expected %s, got %v\n", types.ErrTaskNotWaiting, err)
+			return
+		}
+	})
+
+	t.Run("CancelNonExistent", func(t *testing.T) {
+		_, err := types.CancelScheduledTask(context.Background(), models, uuid.New())
+		if !errors.Is(err, data.ErrRecordNotFound) {
+			t.Errorf("expected %s, got %v\n", data.ErrRecordNotFound, err)
+			return
+		}
+	})
+}
+
+func TestSetScheduledTaskState(t *testing.T) {
+	insertedTask, err := types.CreateTask(
+		context.Background(),
+		models,
+		types.NewTask("test_state_queue", "* * * * *", false, time.Now(), nil),
+	)
+	if err != nil {
+		t.Errorf("an error occurred while creating parent task for overview: %s\n", err)
+		return
+	}
+
+	scheduledTask, err := types.ScheduleTask(context.Background(), models, types.ScheduledTask{
+		Name: &insertedTask.Name,
+	})
+	if err != nil {
+		t.Errorf("error occurred while creating task: %s\n", err)
+		return
+	}
+
+	_, err = types.SetScheduledTaskState(
+		context.Background(), models, scheduledTask.ID, data.StoppedTaskState,
+	)
+	if err != nil {
+		t.Errorf("error occurred while setting task state: %s\n", err)
+		return
+	}
+
+	readTask, err := types.ReadScheduledTask(context.Background(), models, scheduledTask.ID)
+	if err != nil {
+		t.Errorf("error occurred while reading task: %s\n", err)
+		return
+	}
+	if *readTask.State != string(data.StoppedTaskState) {
+		t.Errorf("expected task state %s, got %s\n", data.StoppedTaskState, *readTask.State)
+		return
+	}
+}
+
+func TestFailScheduledTask(t *testing.T) {
+	maxAttempts, retryBackoff := 2, 60
+	newTask := types.NewTask("test_retry_queue", "* * * * *", false, time.Now(), nil)
+	newTask.MaxAttempts = &maxAttempts
+	newTask.RetryBackoff = &retryBackoff
+
+	insertedTask, err := types.CreateTask(context.Background(), models, newTask)
+	if err != nil {
+		t.Errorf("an error occurred while creating parent task for overview: %s\n", err)
+		return
+	}
+
+	scheduledTask, err := types.ScheduleTask(context.Background(), models, types.ScheduledTask{
+		Name: &insertedTask.Name,
+	})
+	if err != nil {
+		t.Errorf("error occurred while creating task: %s\n", err)
+		return
+	}
+
+	runErr := errors.New("task failed")
+
+	t.Run("Retry", func(t *testing.T) {
+		failedTask, err := types.FailScheduledTask(
+			context.Background(), models, *scheduledTask, *insertedTask, runErr,
+		)
+		if err != nil {
+			t.Errorf("error occurred while failing task: %s\n", err)
+			return
+		}
+		if *failedTask.State != string(data.WaitingTaskState) {
+			t.Errorf(
+				"expected task state %s, got %s\n", data.WaitingTaskState, *failedTask.State,
+			)
+			return
+		}
+		if *failedTask.Attempt != 2 {
+			t.Errorf("expected attempt 2, got %d\n", *failedTask.Attempt)
+			return
+		}
+		if !failedTask.RunAt.After(*scheduledTask.RunAt) {
+			t.Errorf("expected run at to be delayed, got %s\n", failedTask.RunAt)
+			return
+		}
+		if *failedTask.LastError != runErr.Error() {
+			t.Errorf("expected last error %s, got %s\n", runErr, *failedTask.LastError)
+			return
+		}
+
+		scheduledTask = failedTask
+	})
+
+	t.Run("Dead", func(t *testing.T) {
+		failedTask, err := types.FailScheduledTask(
+			context.Background(), models, *scheduledTask, *insertedTask, runErr,
+		)
+		if err != nil {
+			t.Errorf("error occurred while failing task: %s\n", err)
+			return
+		}
+		if *failedTask.State != string(data.DeadTaskState) {
+			t.Errorf("expected task state %s, got %s\n", data.DeadTaskState, *failedTask.State)
+			return
+		}
+	})
+
+	t.Run("Replay", func(t *testing.T) {
+		replayedTask, err := types.ReplayScheduledTask(
+			context.Background(), models, scheduledTask.ID,
+		)
+		if err != nil {
+			t.Errorf("error occurred while replaying task: %s\n", err)
+			return
+		}
+		if *replayedTask.State != string(data.WaitingTaskState) {
+			t.Errorf(
+				"expected task state %s, got %s\n", data.WaitingTaskState, *replayedTask.State,
+			)
+			return
+		}
+		if *replayedTask.Attempt != 1 {
+			t.Errorf("expected attempt 1, got %d\n", *replayedTask.Attempt)
+			return
+		}
+	})
+
+	t.Run("ReplayWaiting", func(t *testing.T) {
+		_, err := types.ReplayScheduledTask(context.Background(), models, scheduledTask.ID)
+		if !errors.Is(err, types.ErrTaskNotDead) {
+			t.Errorf("expected %s, got %v\n", types.ErrTaskNotDead, err)
+			return
+		}
+	})
+}
+
+func TestRetryDelay(t *testing.T) {
+	retryBackoff, retryJitter := 10, 5
+	task := types.Task{RetryBackoff: &retryBackoff, RetryJitter: &retryJitter}
+
+	for attempt, base := range map[int]time.Duration{
+		1: 10 * time.Second,
+		2: 20 * time.Second,
+		3: 40 * time.Second,
+	} {
+		delay := types.RetryDelay(task, attempt)
+		if delay < base || delay > base+5*time.Second {
+			t.Errorf("expected delay of attempt %d within %s and %s, got %s\n",
+				attempt, base, base+5*time.Second, delay)
+			return
+		}
+	}
+}
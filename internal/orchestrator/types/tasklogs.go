@@ -2,27 +2,47 @@ package types
 
 import (
 	"context"
+	"encoding/json"
 	"io"
 	"log/slog"
 	"os"
 	"sync"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/r3d5un/Bookshelf/internal/orchestrator/data"
 )
 
+const (
+	// logBatchSize is the number of log lines written to the database at a time
+	logBatchSize = 100
+	// logFlushInterval is how long log lines are buffered before being written to the database
+	logFlushInterval = 1 * time.Second
+)
+
 type TaskLog struct {
-	ID     uuid.UUID `json:"id"`
-	TaskID uuid.UUID `json:"taskId"`
-	Log    string    `json:"log"`
+	ID       uuid.UUID  `json:"id"`
+	TaskID   uuid.UUID  `json:"taskId"`
+	Seq      int64      `json:"seq"`
+	LoggedAt *time.Time `json:"loggedAt"`
+	Log      string     `json:"log"`
 }
 
+// TaskLogWriter writes the log lines of a run to the task log table. Lines are written in
+// batches by a single goroutine, either when a batch is full or when the flush interval has
+// passed, so that they are stored in the order they were logged.
+//
+// Runs logging more than maxLines lines have the remaining lines dropped, and a single line
+// noting the truncation written in their place. A maxLines of 0 means no limit.
 type TaskLogWriter struct {
 	taskID    uuid.UUID
-	done      chan struct{}
 	logBuffer chan TaskLog
 	models    *data.Models
-	wg        *sync.WaitGroup
+	wg        sync.WaitGroup
+	mu        sync.Mutex
+	stopped   bool
+	maxLines  int
+	lines     int
 }
 
 func NewTaskLogWriter(
@@ -30,31 +50,52 @@ func NewTaskLogWriter(
 	models *data.Models,
 	taskID uuid.UUID,
 	logBufferSize int,
-) TaskLogWriter {
-	var wg sync.WaitGroup
-
-	logWriter := TaskLogWriter{
+	maxLines int,
+) *TaskLogWriter {
+	logWriter := &TaskLogWriter{
 		taskID:    taskID,
 		logBuffer: make(chan TaskLog, logBufferSize),
-		done:      make(chan struct{}, 1),
 		models:    models,
-		wg:        &wg,
+		maxLines:  maxLines,
 	}
 
-	wg.Add(1)
+	logWriter.wg.Add(1)
 	go func() {
-		defer wg.Done()
+		defer logWriter.wg.Done()
 		logWriter.LogSink(ctx)
 	}()
 
 	return logWriter
 }
 
+// Write buffers a log line to be written to the database. Lines written after the writer is
+// stopped are dropped.
 func (tlw *TaskLogWriter) Write(p []byte) (n int, err error) {
+	tlw.mu.Lock()
+	defer tlw.mu.Unlock()
+
+	if tlw.stopped {
+		return len(p), nil
+	}
+
+	line := string(p)
+
+	if tlw.maxLines > 0 {
+		tlw.lines++
+		switch {
+		case tlw.lines > tlw.maxLines+1:
+			return len(p), nil
+		case tlw.lines == tlw.maxLines+1:
+			line = truncatedLogLine(tlw.maxLines)
+		}
+	}
+
+	loggedAt := time.Now()
 	log := TaskLog{
-		ID:     uuid.New(),
-		TaskID: tlw.taskID,
-		Log:    string(p),
+		ID:       uuid.New(),
+		TaskID:   tlw.taskID,
+		LoggedAt: &loggedAt,
+		Log:      line,
 	}
 
 	tlw.logBuffer <- log
@@ -62,41 +103,89 @@ func (tlw *TaskLogWriter) Write(p []byte) (n int, err error) {
 	return len(p), nil
 }
 
+// LogSink writes buffered log lines to the database in batches until the writer is stopped,
+// then writes the remaining lines before returning.
 func (tlw *TaskLogWriter) LogSink(ctx context.Context) {
+	ticker := time.NewTicker(logFlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]TaskLog, 0, logBatchSize)
+
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+
+		_, err := CreateTaskLogs(ctx, tlw.models, batch)
+		if err != nil {
+			slog.Error("unable to create log records", "error", err, "length", len(batch))
+		}
+		batch = batch[:0]
+
+		// Followers of the run read the new lines from the database when notified
+		err = tlw.models.TaskNotifications.NotifyTaskLog(
+			ctx, data.TaskLogNotification{TaskID: tlw.taskID},
+		)
+		if err != nil {
+			slog.Error("unable to notify log listeners", "error", err)
+		}
+	}
+
 	for {
 		select {
-		case <-tlw.done:
-			return
+		case <-ticker.C:
+			flush()
 		case log, ok := <-tlw.logBuffer:
 			if !ok {
-				slog.Error("log buffer channel closed")
+				flush()
 				return
 			}
 
-			tlw.wg.Add(1)
-			go func() {
-				defer tlw.wg.Done()
-				_, err := CreateTaskLog(ctx, tlw.models, log)
-				if err != nil {
-					slog.Error("unable to create log records", "error", err)
-				}
-			}()
+			batch = append(batch, log)
+			if len(batch) >= logBatchSize {
+				flush()
+			}
 		}
 	}
 }
 
+// truncatedLogLine returns the JSON log line written in place of the lines dropped after the
+// line limit of a run is reached.
+func truncatedLogLine(maxLines int) string {
+	line, _ := json.Marshal(struct {
+		Time  time.Time `json:"time"`
+		Level string    `json:"level"`
+		Msg   string    `json:"msg"`
+		Limit int       `json:"limit"`
+	}{
+		Time:  time.Now(),
+		Level: slog.LevelWarn.String(),
+		Msg:   "log line limit reached; remaining lines are not stored",
+		Limit: maxLines,
+	})
+
+	return string(line)
+}
+
+// Stop waits for all buffered logs to be written before returning. Logs written after the
+// writer is stopped are dropped, and calling Stop more than once has no effect.
 func (tlw *TaskLogWriter) Stop() {
-	tlw.wg.Wait()
+	tlw.mu.Lock()
+	if !tlw.stopped {
+		tlw.stopped = true
+		close(tlw.logBuffer)
+	}
+	tlw.mu.Unlock()
 
-	close(tlw.logBuffer)
-	close(tlw.done)
+	tlw.wg.Wait()
 }
 
 func CreateTaskLog(ctx context.Context, models *data.Models, log TaskLog) (*TaskLog, error) {
 	logRow := data.TaskLog{
-		ID:     log.ID,
-		TaskID: log.TaskID,
-		Log:    log.Log,
+		ID:       log.ID,
+		TaskID:   log.TaskID,
+		LoggedAt: log.LoggedAt,
+		Log:      log.Log,
 	}
 
 	insertedLogRow, err := models.TaskLogs.Insert(ctx, logRow)
@@ -105,14 +194,32 @@ func CreateTaskLog(ctx context.Context, models *data.Models, log TaskLog) (*Task
 	}
 
 	createdLog := TaskLog{
-		ID:     insertedLogRow.ID,
-		TaskID: insertedLogRow.TaskID,
-		Log:    insertedLogRow.Log,
+		ID:       insertedLogRow.ID,
+		TaskID:   insertedLogRow.TaskID,
+		Seq:      insertedLogRow.Seq,
+		LoggedAt: insertedLogRow.LoggedAt,
+		Log:      insertedLogRow.Log,
 	}
 
 	return &createdLog, nil
 }
 
+// CreateTaskLogs writes the logs to the database in a single batch, in the given order, and
+// returns the number of logs written.
+func CreateTaskLogs(ctx context.Context, models *data.Models, logs []TaskLog) (int64, error) {
+	logRows := make([]data.TaskLog, 0, len(logs))
+	for _, log := range logs {
+		logRows = append(logRows, data.TaskLog{
+			ID:       log.ID,
+			TaskID:   log.TaskID,
+			LoggedAt: log.LoggedAt,
+			Log:      log.Log,
+		})
+	}
+
+	return models.TaskLogs.InsertBatch(ctx, logRows)
+}
+
 func ReadLogsByTaskQueueID(
 	ctx context.Context,
 	models *data.Models,
@@ -123,13 +230,45 @@ func ReadLogsByTaskQueueID(
 		return nil, err
 	}
 
-	logs := make([]*TaskLog, len(logRows))
+	logs := make([]*TaskLog, 0, len(logRows))
+
+	for _, logRow := range logRows {
+		log := TaskLog{
+			ID:       logRow.ID,
+			TaskID:   logRow.TaskID,
+			Seq:      logRow.Seq,
+			LoggedAt: logRow.LoggedAt,
+			Log:      logRow.Log,
+		}
+
+		logs = append(logs, &log)
+	}
+
+	return logs, nil
+}
+
+// ReadLogsByTaskQueueIDAfter returns the logs of a run logged after the log with the given
+// sequence number, in the order they were logged.
+func ReadLogsByTaskQueueIDAfter(
+	ctx context.Context,
+	models *data.Models,
+	taskID uuid.UUID,
+	afterSeq int64,
+) ([]*TaskLog, error) {
+	logRows, err := models.TaskLogs.GetByTaskIDAfter(ctx, taskID, afterSeq)
+	if err != nil {
+		return nil, err
+	}
+
+	logs := make([]*TaskLog, 0, len(logRows))
 
 	for _, logRow := range logRows {
 		log := TaskLog{
-			ID:     logRow.ID,
-			TaskID: logRow.TaskID,
-			Log:    logRow.Log,
+			ID:       logRow.ID,
+			TaskID:   logRow.TaskID,
+			Seq:      logRow.Seq,
+			LoggedAt: logRow.LoggedAt,
+			Log:      logRow.Log,
 		}
 
 		logs = append(logs, &log)
@@ -139,17 +278,20 @@ func ReadLogsByTaskQueueID(
 }
 
 // NewTaskLogger creates a new logger that writes logs to stdout and the task log
-// database table. It also returns a stop function, which stop associated goroutines
-// that writes to the database in a non-blocking manner.
+// database table. At most maxLines lines are stored in the database, where 0 means no limit.
+// It also returns a stop function, which stop associated goroutines that writes to the
+// database in a non-blocking manner.
 func NewTaskLogger(
 	ctx context.Context,
 	models *data.Models,
 	taskName string,
 	taskQueueID uuid.UUID,
+	maxLines int,
 ) (logger *slog.Logger, stop func()) {
-	logWriter := NewTaskLogWriter(ctx, models, taskQueueID, 100)
+	// Logs are written after the run context is cancelled, such as when a run is stopped
+	logWriter := NewTaskLogWriter(context.WithoutCancel(ctx), models, taskQueueID, 100, maxLines)
 
-	handler := slog.NewJSONHandler(io.MultiWriter(&logWriter, os.Stdout), nil)
+	handler := slog.NewJSONHandler(io.MultiWriter(logWriter, os.Stdout), nil)
 	logger = slog.New(handler).With(slog.Group(
 		"task",
 		slog.String("taskName", taskName),
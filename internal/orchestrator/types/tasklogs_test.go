@@ -0,0 +1,89 @@
+package types_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/r3d5un/Bookshelf/internal/orchestrator/types"
+)
+
+func TestTaskLogWriter(t *testing.T) {
+	insertedTask, err := types.CreateTask(
+		context.Background(),
+		models,
+		types.NewTask("test_log_writer_queue", "* * * * *", false, time.Now(), nil),
+	)
+	if err != nil {
+		t.Errorf("an error occurred while creating task: %s\n", err)
+		return
+	}
+
+	newRun := func(t *testing.T) *types.ScheduledTask {
+		run, err := types.ScheduleTask(
+			context.Background(),
+			models,
+			types.ScheduledTask{Name: &insertedTask.Name},
+		)
+		if err != nil {
+			t.Fatalf("error occurred while creating run: %s\n", err)
+		}
+		return run
+	}
+
+	t.Run("Ordered", func(t *testing.T) {
+		run := newRun(t)
+
+		logger, stop := types.NewTaskLogger(context.Background(), models, insertedTask.Name, run.ID, 0)
+		for i := range 250 {
+			logger.Info("line", "i", i)
+		}
+		stop()
+
+		logs, err := types.ReadLogsByTaskQueueID(context.Background(), models, run.ID)
+		if err != nil {
+			t.Errorf("error occurred while reading logs: %s\n", err)
+			return
+		}
+		if len(logs) != 250 {
+			t.Errorf("expected 250 logs, got %d\n", len(logs))
+			return
+		}
+		for i, log := range logs {
+			var line struct {
+				I int `json:"i"`
+			}
+			err := json.Unmarshal([]byte(log.Log), &line)
+			if err != nil {
+				t.Errorf("unable to decode log: %s\n", err)
+				return
+			}
+			if line.I != i {
+				t.Errorf("expected line %d at position %d, got %d\n", i, i, line.I)
+				return
+			}
+		}
+	})
+
+	t.Run("Truncated", func(t *testing.T) {
+		run := newRun(t)
+
+		logger, stop := types.NewTaskLogger(context.Background(), models, insertedTask.Name, run.ID, 10)
+		for i := range 20 {
+			logger.Info(fmt.Sprintf("line %d", i))
+		}
+		stop()
+
+		logs, err := types.ReadLogsByTaskQueueID(context.Background(), models, run.ID)
+		if err != nil {
+			t.Errorf("error occurred while reading logs: %s\n", err)
+			return
+		}
+		if len(logs) != 11 {
+			t.Errorf("expected 10 logs and a truncation notice, got %d\n", len(logs))
+			return
+		}
+	})
+}
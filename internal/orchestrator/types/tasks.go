@@ -3,19 +3,50 @@ package types
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"sync"
 	"time"
 
 	"github.com/r3d5un/Bookshelf/internal/logging"
 	"github.com/r3d5un/Bookshelf/internal/orchestrator/data"
+	"github.com/r3d5un/Bookshelf/internal/validator"
+	"github.com/robfig/cron/v3"
+)
+
+var (
+	ErrTaskDisabled = errors.New("task is disabled")
+	// ErrTaskStopped is the cause of a run context cancelled by a stop request
+	ErrTaskStopped = errors.New("task run stopped")
+	// ErrTaskTimedOut is the cause of a run context cancelled for exceeding the max runtime
+	ErrTaskTimedOut = errors.New("task run exceeded max runtime")
 )
 
 type Task struct {
-	Name      string                      `json:"name"`
-	CronExpr  *string                     `json:"cronExpr,omitempty"`
-	Enabled   *bool                       `json:"enabled,omitempty"`
-	UpdatedAt *time.Time                  `json:"timestamp,omitempty"`
-	Job       func(context.Context) error `json:"-"`
+	Name      string     `json:"name"`
+	CronExpr  *string    `json:"cronExpr,omitempty"`
+	Enabled   *bool      `json:"enabled,omitempty"`
+	UpdatedAt *time.Time `json:"timestamp,omitempty"`
+	// MaxAttempts is the number of times a run is attempted before it is moved to the dead state
+	MaxAttempts *int `json:"maxAttempts,omitempty"`
+	// RetryBackoff is the number of seconds to wait before the first retry, doubling per attempt
+	RetryBackoff *int `json:"retryBackoff,omitempty"`
+	// RetryJitter is the upper limit of seconds randomly added to the retry delay
+	RetryJitter *int `json:"retryJitter,omitempty"`
+	// MaxRuntime is the number of seconds a run may take before it is cancelled, where 0 or no
+	// value means no limit
+	MaxRuntime *int `json:"maxRuntime,omitempty"`
+	// MaxConcurrency is the number of runs of the task allowed at the same time across all
+	// instances, where 0 or no value means no limit
+	MaxConcurrency *int `json:"maxConcurrency,omitempty"`
+	// Priority orders waiting runs, where runs of tasks with a higher priority are run first
+	Priority *int `json:"priority,omitempty"`
+	// RetentionDays is the number of days finished runs are kept, where no value means the
+	// configured default
+	RetentionDays *int `json:"retentionDays,omitempty"`
+	// FailedRetentionDays is the number of days stopped, failed and dead runs are kept, where no
+	// value means the configured default
+	FailedRetentionDays *int                        `json:"failedRetentionDays,omitempty"`
+	Job                 func(context.Context) error `json:"-"`
 }
 
 type TaskCollection struct {
@@ -47,6 +78,36 @@ func NewTask(
 	}
 }
 
+// ValidateTask checks the user editable fields of a task.
+func ValidateTask(v *validator.Validator, task Task) {
+	if task.CronExpr != nil {
+		_, err := cron.ParseStandard(*task.CronExpr)
+		v.Check(err == nil, "cronExpr", "must be a valid cron expression")
+	}
+	if task.MaxAttempts != nil {
+		v.Check(*task.MaxAttempts >= 1, "maxAttempts", "must be at least 1")
+		v.Check(*task.MaxAttempts <= 100, "maxAttempts", "must not be more than 100")
+	}
+	if task.RetryBackoff != nil {
+		v.Check(*task.RetryBackoff >= 0, "retryBackoff", "must not be negative")
+	}
+	if task.RetryJitter != nil {
+		v.Check(*task.RetryJitter >= 0, "retryJitter", "must not be negative")
+	}
+	if task.MaxRuntime != nil {
+		v.Check(*task.MaxRuntime >= 0, "maxRuntime", "must not be negative")
+	}
+	if task.MaxConcurrency != nil {
+		v.Check(*task.MaxConcurrency >= 0, "maxConcurrency", "must not be negative")
+	}
+	if task.RetentionDays != nil {
+		v.Check(*task.RetentionDays >= 1, "retentionDays", "must be at least 1")
+	}
+	if task.FailedRetentionDays != nil {
+		v.Check(*task.FailedRetentionDays >= 1, "failedRetentionDays", "must be at least 1")
+	}
+}
+
 func ReadTask(ctx context.Context, models *data.Models, taskName string) (*Task, error) {
 	taskRow, err := models.Tasks.Get(ctx, taskName)
 	if err != nil {
@@ -54,10 +115,18 @@ func ReadTask(ctx context.Context, models *data.Models, taskName string) (*Task,
 	}
 
 	task := Task{
-		Name:      taskRow.Name,
-		CronExpr:  &taskRow.CronExpr.String,
-		Enabled:   &taskRow.Enabled.Bool,
-		UpdatedAt: &taskRow.UpdatedAt.Time,
+		Name:                taskRow.Name,
+		CronExpr:            &taskRow.CronExpr.String,
+		Enabled:             &taskRow.Enabled.Bool,
+		UpdatedAt:           &taskRow.UpdatedAt.Time,
+		MaxAttempts:         nullInt32ToPtr(taskRow.MaxAttempts),
+		RetryBackoff:        nullInt32ToPtr(taskRow.RetryBackoff),
+		RetryJitter:         nullInt32ToPtr(taskRow.RetryJitter),
+		MaxRuntime:          nullInt32ToPtr(taskRow.MaxRuntime),
+		MaxConcurrency:      nullInt32ToPtr(taskRow.MaxConcurrency),
+		Priority:            nullInt32ToPtr(taskRow.Priority),
+		RetentionDays:       nullInt32ToPtr(taskRow.RetentionDays),
+		FailedRetentionDays: nullInt32ToPtr(taskRow.FailedRetentionDays),
 	}
 
 	return &task, nil
@@ -85,10 +154,18 @@ func ReadAllTasks(
 	var tasks []*Task
 	for _, t := range taskRows {
 		task := Task{
-			Name:      t.Name,
-			CronExpr:  &t.CronExpr.String,
-			Enabled:   &t.Enabled.Bool,
-			UpdatedAt: &t.UpdatedAt.Time,
+			Name:                t.Name,
+			CronExpr:            &t.CronExpr.String,
+			Enabled:             &t.Enabled.Bool,
+			UpdatedAt:           &t.UpdatedAt.Time,
+			MaxAttempts:         nullInt32ToPtr(t.MaxAttempts),
+			RetryBackoff:        nullInt32ToPtr(t.RetryBackoff),
+			RetryJitter:         nullInt32ToPtr(t.RetryJitter),
+			MaxRuntime:          nullInt32ToPtr(t.MaxRuntime),
+			MaxConcurrency:      nullInt32ToPtr(t.MaxConcurrency),
+			Priority:            nullInt32ToPtr(t.Priority),
+			RetentionDays:       nullInt32ToPtr(t.RetentionDays),
+			FailedRetentionDays: nullInt32ToPtr(t.FailedRetentionDays),
 		}
 
 		tasks = append(tasks, &task)
@@ -108,10 +185,18 @@ func ReadAllTasks(
 
 func CreateTask(ctx context.Context, models *data.Models, task Task) (*Task, error) {
 	dbRow := data.Task{
-		Name:      task.Name,
-		CronExpr:  newNullString(task.CronExpr),
-		Enabled:   newNullBool(task.Enabled),
-		UpdatedAt: newNullTime(task.UpdatedAt),
+		Name:                task.Name,
+		CronExpr:            newNullString(task.CronExpr),
+		Enabled:             newNullBool(task.Enabled),
+		UpdatedAt:           newNullTime(task.UpdatedAt),
+		MaxAttempts:         newNullInt32(task.MaxAttempts),
+		RetryBackoff:        newNullInt32(task.RetryBackoff),
+		RetryJitter:         newNullInt32(task.RetryJitter),
+		MaxRuntime:          newNullInt32(task.MaxRuntime),
+		MaxConcurrency:      newNullInt32(task.MaxConcurrency),
+		Priority:            newNullInt32(task.Priority),
+		RetentionDays:       newNullInt32(task.RetentionDays),
+		FailedRetentionDays: newNullInt32(task.FailedRetentionDays),
 	}
 
 	insertedTask, err := models.Tasks.Insert(ctx, dbRow)
@@ -120,10 +205,18 @@ func CreateTask(ctx context.Context, models *data.Models, task Task) (*Task, err
 	}
 
 	task = Task{
-		Name:      insertedTask.Name,
-		CronExpr:  nullStringToPtr(insertedTask.CronExpr),
-		Enabled:   nullBoolToPtr(insertedTask.Enabled),
-		UpdatedAt: nullTimeToPtr(insertedTask.UpdatedAt),
+		Name:                insertedTask.Name,
+		CronExpr:            nullStringToPtr(insertedTask.CronExpr),
+		Enabled:             nullBoolToPtr(insertedTask.Enabled),
+		UpdatedAt:           nullTimeToPtr(insertedTask.UpdatedAt),
+		MaxAttempts:         nullInt32ToPtr(insertedTask.MaxAttempts),
+		RetryBackoff:        nullInt32ToPtr(insertedTask.RetryBackoff),
+		RetryJitter:         nullInt32ToPtr(insertedTask.RetryJitter),
+		MaxRuntime:          nullInt32ToPtr(insertedTask.MaxRuntime),
+		MaxConcurrency:      nullInt32ToPtr(insertedTask.MaxConcurrency),
+		Priority:            nullInt32ToPtr(insertedTask.Priority),
+		RetentionDays:       nullInt32ToPtr(insertedTask.RetentionDays),
+		FailedRetentionDays: nullInt32ToPtr(insertedTask.FailedRetentionDays),
 	}
 
 	return &task, nil
@@ -131,10 +224,18 @@ func CreateTask(ctx context.Context, models *data.Models, task Task) (*Task, err
 
 func UpdateTask(ctx context.Context, models *data.Models, task Task) (*Task, error) {
 	dbRow := data.Task{
-		Name:      task.Name,
-		CronExpr:  newNullString(task.CronExpr),
-		Enabled:   newNullBool(task.Enabled),
-		UpdatedAt: newNullTime(task.UpdatedAt),
+		Name:                task.Name,
+		CronExpr:            newNullString(task.CronExpr),
+		Enabled:             newNullBool(task.Enabled),
+		UpdatedAt:           newNullTime(task.UpdatedAt),
+		MaxAttempts:         newNullInt32(task.MaxAttempts),
+		RetryBackoff:        newNullInt32(task.RetryBackoff),
+		RetryJitter:         newNullInt32(task.RetryJitter),
+		MaxRuntime:          newNullInt32(task.MaxRuntime),
+		MaxConcurrency:      newNullInt32(task.MaxConcurrency),
+		Priority:            newNullInt32(task.Priority),
+		RetentionDays:       newNullInt32(task.RetentionDays),
+		FailedRetentionDays: newNullInt32(task.FailedRetentionDays),
 	}
 
 	updatedTask, err := models.Tasks.Update(ctx, dbRow)
@@ -143,10 +244,18 @@ func UpdateTask(ctx context.Context, models *data.Models, task Task) (*Task, err
 	}
 
 	task = Task{
-		Name:      updatedTask.Name,
-		CronExpr:  nullStringToPtr(updatedTask.CronExpr),
-		Enabled:   nullBoolToPtr(updatedTask.Enabled),
-		UpdatedAt: nullTimeToPtr(updatedTask.UpdatedAt),
+		Name:                updatedTask.Name,
+		CronExpr:            nullStringToPtr(updatedTask.CronExpr),
+		Enabled:             nullBoolToPtr(updatedTask.Enabled),
+		UpdatedAt:           nullTimeToPtr(updatedTask.UpdatedAt),
+		MaxAttempts:         nullInt32ToPtr(updatedTask.MaxAttempts),
+		RetryBackoff:        nullInt32ToPtr(updatedTask.RetryBackoff),
+		RetryJitter:         nullInt32ToPtr(updatedTask.RetryJitter),
+		MaxRuntime:          nullInt32ToPtr(updatedTask.MaxRuntime),
+		MaxConcurrency:      nullInt32ToPtr(updatedTask.MaxConcurrency),
+		Priority:            nullInt32ToPtr(updatedTask.Priority),
+		RetentionDays:       nullInt32ToPtr(updatedTask.RetentionDays),
+		FailedRetentionDays: nullInt32ToPtr(updatedTask.FailedRetentionDays),
 	}
 
 	return &task, nil
@@ -159,10 +268,18 @@ func DeleteTask(ctx context.Context, models *data.Models, name string) (*Task, e
 	}
 
 	task := Task{
-		Name:      deletedTask.Name,
-		CronExpr:  nullStringToPtr(deletedTask.CronExpr),
-		Enabled:   nullBoolToPtr(deletedTask.Enabled),
-		UpdatedAt: nullTimeToPtr(deletedTask.UpdatedAt),
+		Name:                deletedTask.Name,
+		CronExpr:            nullStringToPtr(deletedTask.CronExpr),
+		Enabled:             nullBoolToPtr(deletedTask.Enabled),
+		UpdatedAt:           nullTimeToPtr(deletedTask.UpdatedAt),
+		MaxAttempts:         nullInt32ToPtr(deletedTask.MaxAttempts),
+		RetryBackoff:        nullInt32ToPtr(deletedTask.RetryBackoff),
+		RetryJitter:         nullInt32ToPtr(deletedTask.RetryJitter),
+		MaxRuntime:          nullInt32ToPtr(deletedTask.MaxRuntime),
+		MaxConcurrency:      nullInt32ToPtr(deletedTask.MaxConcurrency),
+		Priority:            nullInt32ToPtr(deletedTask.Priority),
+		RetentionDays:       nullInt32ToPtr(deletedTask.RetentionDays),
+		FailedRetentionDays: nullInt32ToPtr(deletedTask.FailedRetentionDays),
 	}
 
 	return &task, nil
@@ -171,9 +288,13 @@ func DeleteTask(ctx context.Context, models *data.Models, name string) (*Task, e
 // SyncTasks accepts a slice of tasks which from the caller, which acts
 // as the master, syncing the task overview.
 //
-// New tasks are inserted in a disabled state.
+// New tasks are inserted with the enabled state of the given task, which is disabled for all
+// but the tasks that are only run when enqueued by the app, such as webhook deliveries.
 //
-// Old tasks are updated with data from the given list.
+// Old tasks are updated with data from the given list, except for the enabled state, the cron
+// expression, the retry policy and the max runtime. These are maintained by the users of the app, making the task
+// overview the source of truth for the schedule. The values of the given task are only used as
+// defaults.
 //
 // Tasks in the task overview not found in the given task slice is deleted from
 // the overview. The deletion is cascading, meaning all task run records will
@@ -217,10 +338,23 @@ func SyncTasks(ctx context.Context, models *data.Models, tasks []Task) error {
 			// They should keep their enabled state as set by the users of the app,
 			// independent of what the scheduler sets during their initialization.
 			appTask.Enabled = dbTasks[appTask.Name].Enabled
+			if dbCronExpr := dbTasks[appTask.Name].CronExpr; dbCronExpr != nil && *dbCronExpr != "" {
+				appTask.CronExpr = dbCronExpr
+			}
+			appTask.MaxAttempts = dbTasks[appTask.Name].MaxAttempts
+			appTask.RetryBackoff = dbTasks[appTask.Name].RetryBackoff
+			appTask.RetryJitter = dbTasks[appTask.Name].RetryJitter
+			appTask.MaxRuntime = dbTasks[appTask.Name].MaxRuntime
+			appTask.MaxConcurrency = dbTasks[appTask.Name].MaxConcurrency
+			appTask.Priority = dbTasks[appTask.Name].Priority
+			appTask.RetentionDays = dbTasks[appTask.Name].RetentionDays
+			appTask.FailedRetentionDays = dbTasks[appTask.Name].FailedRetentionDays
 			updateableTasks = append(updateableTasks, appTask)
 		} else {
-			enabled := false
-			appTask.Enabled = &enabled
+			if appTask.Enabled == nil {
+				enabled := false
+				appTask.Enabled = &enabled
+			}
 			newTasks = append(newTasks, appTask)
 		}
 	}
@@ -309,6 +443,13 @@ func newNullBool(b *bool) sql.NullBool {
 	return sql.NullBool{Bool: *b, Valid: true}
 }
 
+func newNullInt32(i *int) sql.NullInt32 {
+	if i == nil {
+		return sql.NullInt32{Valid: false}
+	}
+	return sql.NullInt32{Int32: int32(*i), Valid: true}
+}
+
 func newNullTime(t *time.Time) sql.NullTime {
 	if t == nil {
 		return sql.NullTime{Valid: false}
@@ -330,6 +471,14 @@ func nullBoolToPtr(nb sql.NullBool) *bool {
 	return &nb.Bool
 }
 
+func nullInt32ToPtr(ni sql.NullInt32) *int {
+	if !ni.Valid {
+		return nil
+	}
+	i := int(ni.Int32)
+	return &i
+}
+
 func nullTimeToPtr(nt sql.NullTime) *time.Time {
 	if !nt.Valid {
 		return nil
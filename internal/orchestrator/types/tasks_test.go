@@ -110,6 +110,56 @@ func TestTaskTypes(t *testing.T) {
 		}
 	})
 
+	t.Run("SyncTasksKeepsCronExpr", func(t *testing.T) {
+		cronExpr := "30 4 * * *"
+		_, err := types.UpdateTask(
+			context.Background(),
+			models,
+			types.Task{Name: tasks[0].Name, CronExpr: &cronExpr},
+		)
+		if err != nil {
+			t.Errorf("an error occurred while updating task: %s\n", err)
+			return
+		}
+
+		err = types.SyncTasks(context.Background(), models, tasks)
+		if err != nil {
+			t.Errorf("an error occurred while syncing tasks: %s\n", err)
+			return
+		}
+
+		syncedTask, err := types.ReadTask(context.Background(), models, tasks[0].Name)
+		if err != nil {
+			t.Errorf("an error occurred while reading synced task: %s\n", err)
+			return
+		}
+		if *syncedTask.CronExpr != cronExpr {
+			t.Errorf("expected %s, got %s\n", cronExpr, *syncedTask.CronExpr)
+			return
+		}
+	})
+
+	t.Run("SyncTasksKeepsNewTaskEnabled", func(t *testing.T) {
+		enabledTask := types.NewTask("task5", "", true, time.Now(), nil)
+		tasks = append(tasks, enabledTask)
+
+		err := types.SyncTasks(context.Background(), models, tasks)
+		if err != nil {
+			t.Errorf("an error occurred while syncing tasks: %s\n", err)
+			return
+		}
+
+		syncedTask, err := types.ReadTask(context.Background(), models, enabledTask.Name)
+		if err != nil {
+			t.Errorf("an error occurred while reading synced task: %s\n", err)
+			return
+		}
+		if !*syncedTask.Enabled {
+			t.Errorf("expected %s to be enabled\n", enabledTask.Name)
+			return
+		}
+	})
+
 	t.Run("Delete", func(t *testing.T) {
 		for _, task := range tasks {
 			_, err := types.DeleteTask(context.Background(), models, task.Name)
@@ -0,0 +1,197 @@
+package types
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/r3d5un/Bookshelf/internal/orchestrator/data"
+	"github.com/r3d5un/Bookshelf/internal/validator"
+	"github.com/robfig/cron/v3"
+)
+
+// ErrScheduleTaskNotFound is returned when creating a schedule for a task that does not exist
+var ErrScheduleTaskNotFound = errors.New("task of schedule not found")
+
+// TaskSchedule is an additional cron schedule of a task, stored in the database. Each schedule
+// runs the task with its own payload, allowing the same task to run on several schedules with
+// different arguments.
+//
+// Schedules only run while both the schedule and its task are enabled.
+type TaskSchedule struct {
+	ID       uuid.UUID `json:"id"`
+	Name     *string   `json:"name,omitempty"`
+	TaskName *string   `json:"taskName,omitempty"`
+	CronExpr *string   `json:"cronExpr,omitempty"`
+	// Payload holds the arguments passed to every run started by the schedule
+	Payload   json.RawMessage `json:"payload,omitempty"`
+	Enabled   *bool           `json:"enabled,omitempty"`
+	CreatedAt *time.Time      `json:"createdAt,omitempty"`
+	UpdatedAt *time.Time      `json:"updatedAt,omitempty"`
+	// NextRunAt is when the schedule next enqueues a run, which is not stored and only known
+	// while the schedule and its task are enabled
+	NextRunAt *time.Time `json:"nextRunAt,omitempty"`
+}
+
+type TaskScheduleCollection struct {
+	CurrentPage  int             `json:"current_page,omitempty"`
+	PageSize     int             `json:"page_size,omitempty"`
+	FirstPage    int             `json:"first_page,omitempty"`
+	LastPage     int             `json:"last_page,omitempty"`
+	TotalRecords int             `json:"total_records,omitempty"`
+	OrderBy      string          `json:"order_by,omitempty"`
+	Data         []*TaskSchedule `json:"data"`
+}
+
+// ValidateNewTaskSchedule checks that a schedule has the fields required to create it, and that
+// the fields are valid.
+func ValidateNewTaskSchedule(v *validator.Validator, schedule TaskSchedule) {
+	v.Check(schedule.Name != nil, "name", "must be provided")
+	v.Check(schedule.TaskName != nil, "taskName", "must be provided")
+	v.Check(schedule.CronExpr != nil, "cronExpr", "must be provided")
+
+	ValidateTaskSchedule(v, schedule)
+}
+
+// ValidateTaskSchedule checks the fields of a schedule that are set.
+func ValidateTaskSchedule(v *validator.Validator, schedule TaskSchedule) {
+	if schedule.Name != nil {
+		v.Check(*schedule.Name != "", "name", "must not be empty")
+		v.Check(len(*schedule.Name) <= 128, "name", "must not be more than 128 characters")
+	}
+	if schedule.CronExpr != nil {
+		_, err := cron.ParseStandard(*schedule.CronExpr)
+		v.Check(err == nil, "cronExpr", "must be a valid cron expression")
+	}
+}
+
+func ReadTaskSchedule(
+	ctx context.Context,
+	models *data.Models,
+	id uuid.UUID,
+) (*TaskSchedule, error) {
+	scheduleRow, err := models.TaskSchedules.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return newTaskScheduleFromRow(scheduleRow), nil
+}
+
+func ReadAllTaskSchedules(
+	ctx context.Context,
+	models *data.Models,
+	filters data.Filters,
+) (*TaskScheduleCollection, error) {
+	if filters.Page <= 0 {
+		filters.Page = 1
+	}
+
+	scheduleRows, metadata, err := models.TaskSchedules.GetAll(ctx, filters)
+	if err != nil {
+		return nil, err
+	}
+
+	schedules := make([]*TaskSchedule, 0, len(scheduleRows))
+	for _, scheduleRow := range scheduleRows {
+		schedules = append(schedules, newTaskScheduleFromRow(scheduleRow))
+	}
+
+	return &TaskScheduleCollection{
+		CurrentPage:  metadata.CurrentPage,
+		PageSize:     metadata.PageSize,
+		FirstPage:    metadata.FirstPage,
+		LastPage:     metadata.LastPage,
+		TotalRecords: metadata.TotalRecords,
+		Data:         schedules,
+	}, nil
+}
+
+// ReadTaskSchedulesByTaskName returns every schedule of the named task.
+func ReadTaskSchedulesByTaskName(
+	ctx context.Context,
+	models *data.Models,
+	taskName string,
+) ([]*TaskSchedule, error) {
+	filters := data.Filters{
+		Page:     1,
+		PageSize: 50_000, // Set to a high value to retrieve all schedules
+		Name:     &taskName,
+		OrderBy:  []string{"name"},
+	}
+
+	schedules, err := ReadAllTaskSchedules(ctx, models, filters)
+	if err != nil {
+		return nil, err
+	}
+
+	return schedules.Data, nil
+}
+
+func CreateTaskSchedule(
+	ctx context.Context,
+	models *data.Models,
+	schedule TaskSchedule,
+) (*TaskSchedule, error) {
+	scheduleRow, err := models.TaskSchedules.Insert(ctx, data.TaskSchedule{
+		Name:     schedule.Name,
+		TaskName: schedule.TaskName,
+		CronExpr: schedule.CronExpr,
+		Payload:  schedule.Payload,
+		Enabled:  schedule.Enabled,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return newTaskScheduleFromRow(scheduleRow), nil
+}
+
+// UpdateTaskSchedule changes the fields of the schedule that are set. The task of a schedule
+// cannot be changed.
+func UpdateTaskSchedule(
+	ctx context.Context,
+	models *data.Models,
+	schedule TaskSchedule,
+) (*TaskSchedule, error) {
+	scheduleRow, err := models.TaskSchedules.Update(ctx, data.TaskSchedule{
+		ID:       schedule.ID,
+		Name:     schedule.Name,
+		CronExpr: schedule.CronExpr,
+		Payload:  schedule.Payload,
+		Enabled:  schedule.Enabled,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return newTaskScheduleFromRow(scheduleRow), nil
+}
+
+func DeleteTaskSchedule(
+	ctx context.Context,
+	models *data.Models,
+	id uuid.UUID,
+) (*TaskSchedule, error) {
+	scheduleRow, err := models.TaskSchedules.Delete(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return newTaskScheduleFromRow(scheduleRow), nil
+}
+
+func newTaskScheduleFromRow(scheduleRow *data.TaskSchedule) *TaskSchedule {
+	return &TaskSchedule{
+		ID:        scheduleRow.ID,
+		Name:      scheduleRow.Name,
+		TaskName:  scheduleRow.TaskName,
+		CronExpr:  scheduleRow.CronExpr,
+		Payload:   scheduleRow.Payload,
+		Enabled:   scheduleRow.Enabled,
+		CreatedAt: scheduleRow.CreatedAt,
+		UpdatedAt: scheduleRow.UpdatedAt,
+	}
+}
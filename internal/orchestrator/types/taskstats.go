@@ -0,0 +1,68 @@
+package types
+
+import (
+	"context"
+	"time"
+
+	"github.com/r3d5un/Bookshelf/internal/orchestrator/data"
+)
+
+// TaskStats summarizes the runs of a task still kept in the task queue.
+type TaskStats struct {
+	Name string `json:"name"`
+	// Runs is the number of runs in each state
+	Runs map[string]int `json:"runs"`
+	// SuccessRate is the share of finished runs that completed, excluding skipped runs. It is
+	// not set if no runs have finished.
+	SuccessRate *float64 `json:"successRate"`
+	// DurationP50 and DurationP95 are percentiles of the duration of completed runs, in seconds
+	DurationP50   *float64   `json:"durationP50"`
+	DurationP95   *float64   `json:"durationP95"`
+	LastSuccessAt *time.Time `json:"lastSuccessAt"`
+	// NextRunAt is when the task is next scheduled to run, if it has a cron job
+	NextRunAt *time.Time `json:"nextRunAt"`
+}
+
+// ReadTaskStats returns the run statistics of every task, ordered by name. NextRunAt is left to
+// be set by the caller, as it depends on the scheduler.
+func ReadTaskStats(ctx context.Context, models *data.Models) ([]*TaskStats, error) {
+	statsRows, err := models.TaskQueues.GetStats(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := make([]*TaskStats, 0, len(statsRows))
+	for _, row := range statsRows {
+		taskStats := TaskStats{
+			Name:          row.Name,
+			Runs:          row.Runs,
+			SuccessRate:   successRate(row.Runs),
+			DurationP50:   row.DurationP50,
+			DurationP95:   row.DurationP95,
+			LastSuccessAt: row.LastSuccessAt,
+		}
+
+		stats = append(stats, &taskStats)
+	}
+
+	return stats, nil
+}
+
+func successRate(runs map[string]int) *float64 {
+	var completed, finished int
+	for state, count := range runs {
+		if !IsFinished(state) || state == string(data.SkippedTaskState) {
+			continue
+		}
+		if state == string(data.CompleteTaskState) {
+			completed += count
+		}
+		finished += count
+	}
+	if finished == 0 {
+		return nil
+	}
+
+	rate := float64(completed) / float64(finished)
+	return &rate
+}
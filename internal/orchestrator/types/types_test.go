@@ -29,25 +29,12 @@ func TestMain(m *testing.M) {
 	dbUser := "postgres"
 	dbPassword := "postgres"
 
-	projectRoot, err := tt.FindProjectRoot()
-	if err != nil {
-		slog.Error("unable to get project root")
-		os.Exit(1)
-	}
-
-	migrations, err := tt.ListUpMigrationScrips(fmt.Sprintf("%s/migrations", projectRoot))
-	if err != nil {
-		slog.Error("unable to list up migrations", "error", err)
-		os.Exit(1)
-	}
-
 	postgresContainer, err := postgres.Run(
 		context.Background(),
 		"docker.io/postgres:16-alpine",
 		postgres.WithDatabase(dbName),
 		postgres.WithUsername(dbUser),
 		postgres.WithPassword(dbPassword),
-		postgres.WithInitScripts(migrations...),
 		testcontainers.WithWaitStrategy(
 			wait.ForLog("database system is ready to accept connections").
 				WithOccurrence(2).
@@ -82,6 +69,12 @@ func TestMain(m *testing.M) {
 	)
 	slog.Info("DSN", "connString", connString)
 
+	err = tt.MigrateDatabase(context.Background(), connString)
+	if err != nil {
+		slog.Error("unable to apply migrations", "error", err)
+		os.Exit(1)
+	}
+
 	duration := time.Second * 5
 	dbConfig, err := pgxpool.ParseConfig(connString)
 	if err != nil {
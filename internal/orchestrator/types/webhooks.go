@@ -0,0 +1,278 @@
+package types
+
+import (
+	"context"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/r3d5un/Bookshelf/internal/orchestrator/data"
+	"github.com/r3d5un/Bookshelf/internal/validator"
+	"github.com/r3d5un/Bookshelf/internal/webhooks"
+)
+
+// Webhook is a URL that subscribed events are posted to.
+//
+// The secret is used to sign deliveries, and is only written, never returned.
+type Webhook struct {
+	ID        uuid.UUID  `json:"id"`
+	URL       *string    `json:"url,omitempty"`
+	Secret    *string    `json:"secret,omitempty"`
+	Events    []string   `json:"events,omitempty"`
+	Enabled   *bool      `json:"enabled,omitempty"`
+	CreatedAt *time.Time `json:"createdAt,omitempty"`
+	UpdatedAt *time.Time `json:"updatedAt,omitempty"`
+}
+
+type WebhookCollection struct {
+	CurrentPage  int        `json:"current_page,omitempty"`
+	PageSize     int        `json:"page_size,omitempty"`
+	FirstPage    int        `json:"first_page,omitempty"`
+	LastPage     int        `json:"last_page,omitempty"`
+	TotalRecords int        `json:"total_records,omitempty"`
+	OrderBy      string     `json:"order_by,omitempty"`
+	Data         []*Webhook `json:"data"`
+}
+
+// WebhookDelivery is a single attempt at delivering an event to a webhook.
+type WebhookDelivery struct {
+	ID        uuid.UUID `json:"id"`
+	WebhookID uuid.UUID `json:"webhookId"`
+	// TaskID is the task run delivering the event, shared by every attempt of the delivery
+	TaskID  uuid.UUID `json:"taskId"`
+	Event   string    `json:"event"`
+	Attempt int       `json:"attempt"`
+	// StatusCode is the status of the response, which is not set if no response was received
+	StatusCode *int       `json:"statusCode,omitempty"`
+	Error      *string    `json:"error,omitempty"`
+	DurationMS int        `json:"durationMs"`
+	CreatedAt  *time.Time `json:"createdAt,omitempty"`
+}
+
+type WebhookDeliveryCollection struct {
+	CurrentPage  int                `json:"current_page,omitempty"`
+	PageSize     int                `json:"page_size,omitempty"`
+	FirstPage    int                `json:"first_page,omitempty"`
+	LastPage     int                `json:"last_page,omitempty"`
+	TotalRecords int                `json:"total_records,omitempty"`
+	OrderBy      string             `json:"order_by,omitempty"`
+	Data         []*WebhookDelivery `json:"data"`
+}
+
+// ValidateNewWebhook checks that a webhook has the fields required to create it, and that the
+// fields are valid.
+func ValidateNewWebhook(v *validator.Validator, webhook Webhook) {
+	v.Check(webhook.URL != nil, "url", "must be provided")
+	v.Check(webhook.Secret != nil, "secret", "must be provided")
+	v.Check(webhook.Events != nil, "events", "must be provided")
+
+	ValidateWebhook(v, webhook)
+}
+
+// ValidateWebhook checks the fields of a webhook that are set.
+func ValidateWebhook(v *validator.Validator, webhook Webhook) {
+	if webhook.URL != nil {
+		u, err := url.Parse(*webhook.URL)
+		v.Check(
+			err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "",
+			"url",
+			"must be an absolute http or https URL",
+		)
+		v.Check(len(*webhook.URL) <= 2048, "url", "must not be more than 2048 characters")
+	}
+	if webhook.Secret != nil {
+		v.Check(len(*webhook.Secret) >= 16, "secret", "must be at least 16 characters")
+		v.Check(len(*webhook.Secret) <= 256, "secret", "must not be more than 256 characters")
+	}
+	if webhook.Events != nil {
+		v.Check(len(webhook.Events) > 0, "events", "must contain at least one event")
+		seen := make(map[string]bool, len(webhook.Events))
+		for _, event := range webhook.Events {
+			v.Check(webhooks.ValidEvent(event), "events", "must only contain known events")
+			v.Check(!seen[event], "events", "must not contain duplicate events")
+			seen[event] = true
+		}
+	}
+}
+
+func ReadWebhook(ctx context.Context, models *data.Models, id uuid.UUID) (*Webhook, error) {
+	webhookRow, err := models.Webhooks.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return newWebhookFromRow(webhookRow), nil
+}
+
+func ReadAllWebhooks(
+	ctx context.Context,
+	models *data.Models,
+	filters data.Filters,
+) (*WebhookCollection, error) {
+	if filters.Page <= 0 {
+		filters.Page = 1
+	}
+
+	webhookRows, metadata, err := models.Webhooks.GetAll(ctx, filters)
+	if err != nil {
+		return nil, err
+	}
+
+	webhookList := make([]*Webhook, 0, len(webhookRows))
+	for _, webhookRow := range webhookRows {
+		webhookList = append(webhookList, newWebhookFromRow(webhookRow))
+	}
+
+	return &WebhookCollection{
+		CurrentPage:  metadata.CurrentPage,
+		PageSize:     metadata.PageSize,
+		FirstPage:    metadata.FirstPage,
+		LastPage:     metadata.LastPage,
+		TotalRecords: metadata.TotalRecords,
+		Data:         webhookList,
+	}, nil
+}
+
+// ReadSubscribedWebhooks returns every enabled webhook subscribed to the event, including their
+// secrets, for delivering the event.
+func ReadSubscribedWebhooks(
+	ctx context.Context,
+	models *data.Models,
+	event string,
+) ([]*data.Webhook, error) {
+	enabled := true
+	filters := data.Filters{
+		Page:     1,
+		PageSize: 50_000, // Set to a high value to retrieve all webhooks
+		Name:     &event,
+		Enabled:  &enabled,
+		OrderBy:  []string{"created_at"},
+	}
+
+	webhookRows, _, err := models.Webhooks.GetAll(ctx, filters)
+	if err != nil {
+		return nil, err
+	}
+
+	return webhookRows, nil
+}
+
+func CreateWebhook(ctx context.Context, models *data.Models, webhook Webhook) (*Webhook, error) {
+	webhookRow, err := models.Webhooks.Insert(ctx, data.Webhook{
+		URL:     webhook.URL,
+		Secret:  webhook.Secret,
+		Events:  webhook.Events,
+		Enabled: webhook.Enabled,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return newWebhookFromRow(webhookRow), nil
+}
+
+// UpdateWebhook changes the fields of the webhook that are set.
+func UpdateWebhook(ctx context.Context, models *data.Models, webhook Webhook) (*Webhook, error) {
+	webhookRow, err := models.Webhooks.Update(ctx, data.Webhook{
+		ID:      webhook.ID,
+		URL:     webhook.URL,
+		Secret:  webhook.Secret,
+		Events:  webhook.Events,
+		Enabled: webhook.Enabled,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return newWebhookFromRow(webhookRow), nil
+}
+
+// DeleteWebhook deletes the webhook along with its delivery log.
+func DeleteWebhook(ctx context.Context, models *data.Models, id uuid.UUID) (*Webhook, error) {
+	webhookRow, err := models.Webhooks.Delete(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return newWebhookFromRow(webhookRow), nil
+}
+
+// ReadAllWebhookDeliveries returns the delivery attempts of the webhook matching the filters.
+// The ID filter matches the task run of the delivery, and the Name filter matches the event.
+func ReadAllWebhookDeliveries(
+	ctx context.Context,
+	models *data.Models,
+	webhookID uuid.UUID,
+	filters data.Filters,
+) (*WebhookDeliveryCollection, error) {
+	if filters.Page <= 0 {
+		filters.Page = 1
+	}
+
+	deliveryRows, metadata, err := models.WebhookDeliveries.GetAll(ctx, webhookID, filters)
+	if err != nil {
+		return nil, err
+	}
+
+	deliveries := make([]*WebhookDelivery, 0, len(deliveryRows))
+	for _, deliveryRow := range deliveryRows {
+		deliveries = append(deliveries, newWebhookDeliveryFromRow(deliveryRow))
+	}
+
+	return &WebhookDeliveryCollection{
+		CurrentPage:  metadata.CurrentPage,
+		PageSize:     metadata.PageSize,
+		FirstPage:    metadata.FirstPage,
+		LastPage:     metadata.LastPage,
+		TotalRecords: metadata.TotalRecords,
+		Data:         deliveries,
+	}, nil
+}
+
+// CreateWebhookDelivery records an attempt at delivering an event to a webhook.
+func CreateWebhookDelivery(
+	ctx context.Context,
+	models *data.Models,
+	delivery WebhookDelivery,
+) (*WebhookDelivery, error) {
+	deliveryRow, err := models.WebhookDeliveries.Insert(ctx, data.WebhookDelivery{
+		WebhookID:  delivery.WebhookID,
+		TaskID:     delivery.TaskID,
+		Event:      delivery.Event,
+		Attempt:    delivery.Attempt,
+		StatusCode: delivery.StatusCode,
+		Error:      delivery.Error,
+		DurationMS: delivery.DurationMS,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return newWebhookDeliveryFromRow(deliveryRow), nil
+}
+
+// newWebhookFromRow leaves out the secret of the webhook.
+func newWebhookFromRow(webhookRow *data.Webhook) *Webhook {
+	return &Webhook{
+		ID:        webhookRow.ID,
+		URL:       webhookRow.URL,
+		Events:    webhookRow.Events,
+		Enabled:   webhookRow.Enabled,
+		CreatedAt: webhookRow.CreatedAt,
+		UpdatedAt: webhookRow.UpdatedAt,
+	}
+}
+
+func newWebhookDeliveryFromRow(deliveryRow *data.WebhookDelivery) *WebhookDelivery {
+	return &WebhookDelivery{
+		ID:         deliveryRow.ID,
+		WebhookID:  deliveryRow.WebhookID,
+		TaskID:     deliveryRow.TaskID,
+		Event:      deliveryRow.Event,
+		Attempt:    deliveryRow.Attempt,
+		StatusCode: deliveryRow.StatusCode,
+		Error:      deliveryRow.Error,
+		DurationMS: deliveryRow.DurationMS,
+		CreatedAt:  deliveryRow.CreatedAt,
+	}
+}
@@ -0,0 +1,363 @@
+package types
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/r3d5un/Bookshelf/internal/logging"
+	"github.com/r3d5un/Bookshelf/internal/orchestrator/data"
+)
+
+// WorkflowRun is a run together with the child runs it started.
+type WorkflowRun struct {
+	ScheduledTask
+	Children []*WorkflowRun `json:"children,omitempty"`
+}
+
+// Workflow is the status of a run and all of its descendants.
+type Workflow struct {
+	Run *WorkflowRun `json:"run"`
+	// States is the number of descendants of the run in each state
+	States map[string]int `json:"states"`
+	// Finished is true once the run and all of its descendants have finished
+	Finished bool `json:"finished"`
+}
+
+// IsFinished reports whether a run in the given state has finished, and will not run again
+// unless it is replayed.
+func IsFinished(state string) bool {
+	switch data.TaskState(state) {
+	case data.CompleteTaskState,
+		data.StoppedTaskState,
+		data.ErrorTaskState,
+		data.SkippedTaskState,
+		data.DeadTaskState:
+		return true
+	default:
+		return false
+	}
+}
+
+// ReadWorkflow returns the run with the given ID as a tree of the runs it started.
+func ReadWorkflow(ctx context.Context, models *data.Models, id uuid.UUID) (*Workflow, error) {
+	taskRows, err := models.TaskQueues.GetWorkflow(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	workflow := Workflow{States: map[string]int{}, Finished: true}
+	runs := make(map[uuid.UUID]*WorkflowRun, len(taskRows))
+
+	// Rows are ordered by depth, so parents are always added before their children
+	for _, taskRow := range taskRows {
+		run := &WorkflowRun{ScheduledTask: *newScheduledTaskFromRow(taskRow)}
+		runs[run.ID] = run
+
+		if taskRow.State != nil && !IsFinished(*taskRow.State) {
+			workflow.Finished = false
+		}
+
+		if run.ID == id {
+			workflow.Run = run
+			continue
+		}
+		if taskRow.State != nil {
+			workflow.States[*taskRow.State]++
+		}
+		if parent, ok := runs[*taskRow.ParentID]; ok {
+			parent.Children = append(parent.Children, run)
+		}
+	}
+
+	return &workflow, nil
+}
+
+// EnqueueChildScheduledTasks adds runs to the queue as children of the given parent run, which
+// must be running. The parent is not completed before all of its children have finished.
+//
+// If chained is true, each child is blocked until the child before it completes, and only the
+// first child is waiting to run. Otherwise all children are waiting to run at once. The runs
+// that are waiting are returned as ready, so that the task runners can be notified.
+func EnqueueChildScheduledTasks(
+	ctx context.Context,
+	models *data.Models,
+	parentID uuid.UUID,
+	chained bool,
+	children []ScheduledTask,
+) (tasks []*ScheduledTask, ready []*ScheduledTask, err error) {
+	logger := logging.LoggerFromContext(ctx).With(slog.String("parentId", parentID.String()))
+
+	logger.Info("starting transaction")
+	tx, err := models.BeginTx(ctx)
+	if err != nil {
+		logger.Info("unable to start transaction", "error", err)
+		return nil, nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	logger.Info("locking parent task")
+	parentRow, err := models.TaskQueues.GetForUpdateTx(ctx, tx, parentID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if *parentRow.State != string(data.RunningTaskState) {
+		logger.Info("parent task not running", "state", *parentRow.State)
+		return nil, nil, ErrTaskNotRunning
+	}
+
+	blockedState := string(data.BlockedTaskState)
+	var previousID *uuid.UUID
+
+	for _, child := range children {
+		childRow := data.TaskQueue{
+			Name:     child.Name,
+			RunAt:    child.RunAt,
+			Payload:  child.Payload,
+			ParentID: &parentID,
+		}
+		if chained && previousID != nil {
+			childRow.State = &blockedState
+			childRow.AfterID = previousID
+		}
+
+		insertedRow, err := models.TaskQueues.InsertTx(ctx, tx, childRow)
+		if err != nil {
+			logger.Info("unable to insert child task", "error", err)
+			return nil, nil, err
+		}
+		previousID = &insertedRow.ID
+
+		task := newScheduledTaskFromRow(insertedRow)
+		tasks = append(tasks, task)
+		if *task.State == string(data.WaitingTaskState) {
+			ready = append(ready, task)
+		}
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	logger.Info("child tasks enqueued", "count", len(tasks))
+
+	return tasks, ready, nil
+}
+
+// CompleteScheduledTask marks a run as complete once its task has returned without error. Runs
+// with unfinished children are moved to the awaiting state instead, and are completed when
+// their last child finishes.
+//
+// Runs that become ready to run as a result, such as the next run in a chain, are returned so
+// that the task runners can be notified.
+func CompleteScheduledTask(
+	ctx context.Context,
+	models *data.Models,
+	taskID uuid.UUID,
+) (task *ScheduledTask, ready []*ScheduledTask, err error) {
+	logger := logging.LoggerFromContext(ctx).With(slog.String("taskId", taskID.String()))
+
+	logger.Info("starting transaction")
+	tx, err := models.BeginTx(ctx)
+	if err != nil {
+		logger.Info("unable to start transaction", "error", err)
+		return nil, nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	taskRow, err := models.TaskQueues.GetForUpdateTx(ctx, tx, taskID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	unfinished, failed, err := models.TaskQueues.CountChildrenTx(ctx, tx, taskID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if unfinished > 0 {
+		logger.Info("task has unfinished children; awaiting children", "unfinished", unfinished)
+		state := string(data.AwaitingTaskState)
+		taskRow.State = &state
+	} else {
+		finishParent(taskRow, failed)
+	}
+
+	taskRow, err = models.TaskQueues.UpdateTx(ctx, tx, *taskRow)
+	if err != nil {
+		logger.Info("unable to update task state", "error", err)
+		return nil, nil, err
+	}
+
+	readyRows, err := settleTx(ctx, models, tx, taskRow)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, readyRow := range readyRows {
+		ready = append(ready, newScheduledTaskFromRow(readyRow))
+	}
+
+	return newScheduledTaskFromRow(taskRow), ready, nil
+}
+
+// SettleScheduledTask updates the runs related to a run that has finished. Runs chained after
+// it are released if it completed, and skipped otherwise. The parent of the run is finished
+// if it was awaiting this run as its last unfinished child.
+//
+// Runs that become ready to run are returned so that the task runners can be notified. Runs
+// that have not finished are left as is.
+func SettleScheduledTask(
+	ctx context.Context,
+	models *data.Models,
+	taskID uuid.UUID,
+) (ready []*ScheduledTask, err error) {
+	logger := logging.LoggerFromContext(ctx).With(slog.String("taskId", taskID.String()))
+
+	logger.Info("starting transaction")
+	tx, err := models.BeginTx(ctx)
+	if err != nil {
+		logger.Info("unable to start transaction", "error", err)
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	taskRow, err := models.TaskQueues.GetForUpdateTx(ctx, tx, taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	readyRows, err := settleTx(ctx, models, tx, taskRow)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, readyRow := range readyRows {
+		ready = append(ready, newScheduledTaskFromRow(readyRow))
+	}
+
+	return ready, nil
+}
+
+// settleTx releases or skips the runs chained after a finished run, and finishes its parent if
+// the parent is awaiting no other children. Skipped and finished runs are settled in turn.
+func settleTx(
+	ctx context.Context,
+	models *data.Models,
+	tx pgx.Tx,
+	taskRow *data.TaskQueue,
+) (ready []*data.TaskQueue, err error) {
+	logger := logging.LoggerFromContext(ctx).With(slog.String("taskId", taskRow.ID.String()))
+
+	if taskRow.State == nil || !IsFinished(*taskRow.State) {
+		logger.Info("task not finished; nothing to settle")
+		return nil, nil
+	}
+
+	if *taskRow.State == string(data.CompleteTaskState) {
+		logger.Info("releasing blocked tasks")
+		released, err := models.TaskQueues.ReleaseBlockedTx(
+			ctx, tx, taskRow.ID, data.WaitingTaskState,
+		)
+		if err != nil {
+			return nil, err
+		}
+		ready = append(ready, released...)
+	} else {
+		logger.Info("skipping blocked tasks", "state", *taskRow.State)
+		skipped, err := models.TaskQueues.ReleaseBlockedTx(
+			ctx, tx, taskRow.ID, data.SkippedTaskState,
+		)
+		if err != nil {
+			return nil, err
+		}
+		for _, skippedRow := range skipped {
+			skippedReady, err := settleTx(ctx, models, tx, skippedRow)
+			if err != nil {
+				return nil, err
+			}
+			ready = append(ready, skippedReady...)
+		}
+	}
+
+	if taskRow.ParentID == nil {
+		return ready, nil
+	}
+
+	logger.Info("locking parent task", "parentId", *taskRow.ParentID)
+	parentRow, err := models.TaskQueues.GetForUpdateTx(ctx, tx, *taskRow.ParentID)
+	if err != nil {
+		return nil, err
+	}
+	if *parentRow.State != string(data.AwaitingTaskState) {
+		logger.Info("parent task not awaiting children", "state", *parentRow.State)
+		return ready, nil
+	}
+
+	unfinished, failed, err := models.TaskQueues.CountChildrenTx(ctx, tx, parentRow.ID)
+	if err != nil {
+		return nil, err
+	}
+	if unfinished > 0 {
+		logger.Info("parent task has unfinished children", "unfinished", unfinished)
+		return ready, nil
+	}
+
+	logger.Info("finishing parent task", "failed", failed)
+	finishParent(parentRow, failed)
+	parentRow, err = models.TaskQueues.UpdateTx(ctx, tx, *parentRow)
+	if err != nil {
+		return nil, err
+	}
+
+	parentReady, err := settleTx(ctx, models, tx, parentRow)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(ready, parentReady...), nil
+}
+
+// finishParent sets the state of a run whose children have all finished. The run is complete
+// if none of its children failed, and dead otherwise, so that it can be replayed.
+func finishParent(taskRow *data.TaskQueue, failed int) {
+	state := string(data.CompleteTaskState)
+	if failed > 0 {
+		state = string(data.DeadTaskState)
+		lastError := fmt.Sprintf("%d child runs did not complete", failed)
+		taskRow.LastError = &lastError
+	}
+	taskRow.State = &state
+}
+
+// newScheduledTaskFromRow builds the scheduled task of a task queue row.
+func newScheduledTaskFromRow(taskRow *data.TaskQueue) *ScheduledTask {
+	return &ScheduledTask{
+		ID:          taskRow.ID,
+		Name:        taskRow.Name,
+		State:       taskRow.State,
+		CreatedAt:   taskRow.CreatedAt,
+		UpdatedAt:   taskRow.UpdatedAt,
+		RunAt:       taskRow.RunAt,
+		Attempt:     taskRow.Attempt,
+		LastError:   taskRow.LastError,
+		HeartbeatAt: taskRow.HeartbeatAt,
+		Payload:     taskRow.Payload,
+		ParentID:    taskRow.ParentID,
+		AfterID:     taskRow.AfterID,
+		StartedAt:   taskRow.StartedAt,
+		FinishedAt:  taskRow.FinishedAt,
+	}
+}
@@ -0,0 +1,116 @@
+package types_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/r3d5un/Bookshelf/internal/orchestrator/data"
+	"github.com/r3d5un/Bookshelf/internal/orchestrator/types"
+)
+
+func TestWorkflow(t *testing.T) {
+	insertedTask, err := types.CreateTask(
+		context.Background(),
+		models,
+		types.NewTask("test_workflow_queue", "* * * * *", false, time.Now(), nil),
+	)
+	if err != nil {
+		t.Errorf("an error occurred while creating task for overview: %s\n", err)
+		return
+	}
+
+	runningState := string(data.RunningTaskState)
+	parent, err := types.ScheduleTask(
+		context.Background(),
+		models,
+		types.ScheduledTask{Name: &insertedTask.Name, State: &runningState},
+	)
+	if err != nil {
+		t.Errorf("error occurred while creating parent task: %s\n", err)
+		return
+	}
+
+	var children []*types.ScheduledTask
+
+	t.Run("EnqueueChainedChildren", func(t *testing.T) {
+		var ready []*types.ScheduledTask
+		children, ready, err = types.EnqueueChildScheduledTasks(
+			context.Background(),
+			models,
+			parent.ID,
+			true,
+			[]types.ScheduledTask{{Name: &insertedTask.Name}, {Name: &insertedTask.Name}},
+		)
+		if err != nil {
+			t.Errorf("error occurred while enqueuing children: %s\n", err)
+			return
+		}
+		if len(ready) != 1 || ready[0].ID != children[0].ID {
+			t.Errorf("expected only the first child to be ready, got %v\n", ready)
+			return
+		}
+		if *children[1].State != string(data.BlockedTaskState) {
+			t.Errorf("expected the second child to be blocked, got %s\n", *children[1].State)
+			return
+		}
+	})
+
+	t.Run("CompleteParentAwaitsChildren", func(t *testing.T) {
+		completedParent, _, err := types.CompleteScheduledTask(context.Background(), models, parent.ID)
+		if err != nil {
+			t.Errorf("error occurred while completing parent: %s\n", err)
+			return
+		}
+		if *completedParent.State != string(data.AwaitingTaskState) {
+			t.Errorf("expected parent to be awaiting, got %s\n", *completedParent.State)
+			return
+		}
+	})
+
+	t.Run("CompleteFirstChildReleasesSecond", func(t *testing.T) {
+		_, err := types.ClaimScheduledTaskByID(context.Background(), models, children[0].ID)
+		if err != nil {
+			t.Errorf("error occurred while claiming child: %s\n", err)
+			return
+		}
+
+		_, ready, err := types.CompleteScheduledTask(context.Background(), models, children[0].ID)
+		if err != nil {
+			t.Errorf("error occurred while completing child: %s\n", err)
+			return
+		}
+		if len(ready) != 1 || ready[0].ID != children[1].ID {
+			t.Errorf("expected the second child to be released, got %v\n", ready)
+			return
+		}
+	})
+
+	t.Run("CompleteLastChildCompletesParent", func(t *testing.T) {
+		_, err := types.ClaimScheduledTaskByID(context.Background(), models, children[1].ID)
+		if err != nil {
+			t.Errorf("error occurred while claiming child: %s\n", err)
+			return
+		}
+
+		_, _, err = types.CompleteScheduledTask(context.Background(), models, children[1].ID)
+		if err != nil {
+			t.Errorf("error occurred while completing child: %s\n", err)
+			return
+		}
+
+		workflow, err := types.ReadWorkflow(context.Background(), models, parent.ID)
+		if err != nil {
+			t.Errorf("error occurred while reading workflow: %s\n", err)
+			return
+		}
+		if *workflow.Run.State != string(data.CompleteTaskState) {
+			t.Errorf("expected parent to be complete, got %s\n", *workflow.Run.State)
+			return
+		}
+		if !workflow.Finished || len(workflow.Run.Children) != 2 {
+			t.Errorf("expected a finished workflow with two children, got %v\n", workflow)
+			return
+		}
+	})
+}
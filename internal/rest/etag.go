@@ -0,0 +1,104 @@
+package rest
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrNoPrecondition is returned when a request changing a resource has no If-Match header
+	ErrNoPrecondition = errors.New("missing If-Match header")
+	// ErrInvalidETag is returned when an entity tag was not issued by ETag
+	ErrInvalidETag = errors.New("invalid entity tag")
+)
+
+// ETag returns the entity tag of a resource last updated at the given time. The tag changes with
+// every update of the resource, as updates are timestamped with microsecond precision.
+func ETag(updatedAt *time.Time) string {
+	if updatedAt == nil {
+		return ""
+	}
+	return `"` + strconv.FormatInt(updatedAt.UnixMicro(), 10) + `"`
+}
+
+// ParseETag returns the update time of the resource version identified by the entity tag.
+func ParseETag(tag string) (time.Time, error) {
+	tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+	if len(tag) < 2 || !strings.HasPrefix(tag, `"`) || !strings.HasSuffix(tag, `"`) {
+		return time.Time{}, ErrInvalidETag
+	}
+
+	micro, err := strconv.ParseInt(tag[1:len(tag)-1], 10, 64)
+	if err != nil {
+		return time.Time{}, ErrInvalidETag
+	}
+
+	return time.UnixMicro(micro).UTC(), nil
+}
+
+// ETagHeader returns the response headers carrying the entity tag of a resource last updated at
+// the given time.
+func ETagHeader(updatedAt *time.Time) http.Header {
+	headers := http.Header{}
+	if tag := ETag(updatedAt); tag != "" {
+		headers.Set("ETag", tag)
+	}
+	return headers
+}
+
+// NotModified reports whether the If-None-Match header of the request matches the entity tag, so
+// that the client already holds the current version of the resource.
+func NotModified(r *http.Request, tag string) bool {
+	header := r.Header.Get("If-None-Match")
+	if header == "" || tag == "" {
+		return false
+	}
+
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == tag {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ReadIfMatch returns the update time of the resource version the request is based on, read
+// from the If-Match header by ParseIfMatch.
+func ReadIfMatch(r *http.Request) (*time.Time, error) {
+	return ParseIfMatch(r.Header.Get("If-Match"))
+}
+
+// ParseIfMatch returns the update time of the resource version identified by an If-Match value.
+// A nil time is returned for *, which matches any version. ErrNoPrecondition is returned if the
+// value is empty, and ErrInvalidETag if it does not hold a single entity tag issued by ETag.
+func ParseIfMatch(value string) (*time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, ErrNoPrecondition
+	}
+	if value == "*" {
+		return nil, nil
+	}
+
+	version, err := ParseETag(value)
+	if err != nil {
+		return nil, err
+	}
+
+	return &version, nil
+}
+
+// PreconditionErrorResponse answers a request whose If-Match header could not be read by
+// ReadIfMatch: 428 if the header is missing, and 412 if it can never match.
+func PreconditionErrorResponse(w http.ResponseWriter, r *http.Request, err error) {
+	if errors.Is(err, ErrNoPrecondition) {
+		PreconditionRequiredResponse(w, r)
+		return
+	}
+	PreconditionFailedResponse(w, r)
+}
@@ -0,0 +1,69 @@
+package rest
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// EventStream writes server-sent events to a response.
+type EventStream struct {
+	w  http.ResponseWriter
+	rc *http.ResponseController
+}
+
+// NewEventStream starts a server-sent event response. The write deadline of the server is
+// cleared, as a stream is expected to outlive it.
+func NewEventStream(w http.ResponseWriter) (*EventStream, error) {
+	rc := http.NewResponseController(w)
+
+	err := rc.SetWriteDeadline(time.Time{})
+	if err != nil && !errors.Is(err, http.ErrNotSupported) {
+		return nil, err
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	stream := &EventStream{w: w, rc: rc}
+
+	return stream, stream.rc.Flush()
+}
+
+// Send writes an event and flushes it to the client. The id and event fields are left out when
+// empty, and data spanning multiple lines is sent as one data field per line.
+func (s *EventStream) Send(id string, event string, data string) error {
+	var b strings.Builder
+
+	if id != "" {
+		fmt.Fprintf(&b, "id: %s\n", id)
+	}
+	if event != "" {
+		fmt.Fprintf(&b, "event: %s\n", event)
+	}
+	for _, line := range strings.Split(data, "\n") {
+		fmt.Fprintf(&b, "data: %s\n", line)
+	}
+	b.WriteString("\n")
+
+	_, err := s.w.Write([]byte(b.String()))
+	if err != nil {
+		return err
+	}
+
+	return s.rc.Flush()
+}
+
+// Comment writes a comment, which clients ignore, and is used to keep idle connections open.
+func (s *EventStream) Comment(text string) error {
+	_, err := fmt.Fprintf(s.w, ": %s\n\n", text)
+	if err != nil {
+		return err
+	}
+
+	return s.rc.Flush()
+}
@@ -146,6 +146,25 @@ func ReadQueryInt(
 	return i
 }
 
+func ReadQueryBool(
+	qs url.Values,
+	key string,
+	v *validator.Validator,
+) *bool {
+	s := qs.Get(key)
+	if s == "" {
+		return nil
+	}
+
+	b, err := strconv.ParseBool(s)
+	if err != nil {
+		v.AddError(key, "must be a boolean value")
+		return nil
+	}
+
+	return &b
+}
+
 func ReadQueryUUID(
 	qs url.Values,
 	key string,
@@ -50,6 +50,28 @@ func BadRequestResponse(w http.ResponseWriter, r *http.Request, message string)
 	ErrorResponse(w, r, http.StatusBadRequest, message)
 }
 
+func ConflictResponse(w http.ResponseWriter, r *http.Request, message string) {
+	ErrorResponse(w, r, http.StatusConflict, message)
+}
+
+func PreconditionFailedResponse(w http.ResponseWriter, r *http.Request) {
+	message := "the resource has been changed since it was read; read it again and retry"
+	ErrorResponse(w, r, http.StatusPreconditionFailed, message)
+}
+
+func PreconditionRequiredResponse(w http.ResponseWriter, r *http.Request) {
+	message := "the If-Match header must hold the ETag of the resource"
+	ErrorResponse(w, r, http.StatusPreconditionRequired, message)
+}
+
+// NotModifiedResponse tells the client that the version of the resource it holds, identified by
+// the entity tag, is current.
+func NotModifiedResponse(w http.ResponseWriter, r *http.Request, tag string) {
+	logging.LoggerFromContext(r.Context()).Info("resource not modified", "etag", tag)
+	w.Header().Set("ETag", tag)
+	w.WriteHeader(http.StatusNotModified)
+}
+
 func Respond(
 	w http.ResponseWriter,
 	r *http.Request,
@@ -15,6 +15,8 @@ import (
 
 	"github.com/justinas/alice"
 	"github.com/r3d5un/Bookshelf/internal/config"
+	"github.com/r3d5un/Bookshelf/internal/events"
+	"github.com/r3d5un/Bookshelf/internal/idempotency"
 )
 
 type MonolithApplication struct {
@@ -24,6 +26,7 @@ type MonolithApplication struct {
 	modules *Modules
 	db      *sql.DB
 	cfg     *config.Config
+	events  *events.Bus
 }
 
 func (app *MonolithApplication) Context() context.Context {
@@ -50,6 +53,10 @@ func (app *MonolithApplication) Modules() *Modules {
 	return app.modules
 }
 
+func (app *MonolithApplication) Events() *events.Bus {
+	return app.events
+}
+
 func NewMonolith(
 	ctx context.Context,
 	logger *slog.Logger,
@@ -57,6 +64,7 @@ func NewMonolith(
 	modules *Modules,
 	db *sql.DB,
 	cfg *config.Config,
+	bus *events.Bus,
 ) MonolithApplication {
 	return MonolithApplication{
 		ctx:     ctx,
@@ -65,6 +73,7 @@ func NewMonolith(
 		modules: modules,
 		db:      db,
 		cfg:     cfg,
+		events:  bus,
 	}
 }
 
@@ -112,8 +121,12 @@ func (app *MonolithApplication) Serve() error {
 }
 
 func (app *MonolithApplication) routes() http.Handler {
+	timeout := time.Duration(app.cfg.DB.Timeout) * time.Second
+	keys := idempotency.NewStore(app.db, &timeout)
+
 	app.logger.Info("creating standard middleware chain")
-	standard := alice.New(app.recoverPanic, app.logRequest)
+	// Idempotency keys are scoped to the actor set by logRequest
+	standard := alice.New(app.recoverPanic, app.logRequest, keys.Middleware)
 
 	handler := standard.Then(app.Mux())
 	return handler
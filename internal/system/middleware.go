@@ -6,25 +6,38 @@ import (
 	"net/http"
 
 	"github.com/google/uuid"
+	"github.com/r3d5un/Bookshelf/internal/audit"
 	"github.com/r3d5un/Bookshelf/internal/logging"
 	"github.com/r3d5un/Bookshelf/internal/rest"
 )
 
 func (app *MonolithApplication) logRequest(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := uuid.New()
+		actor := r.Header.Get(audit.ActorHeader)
+		if actor == "" {
+			actor = audit.Anonymous
+		}
+
 		rCtx := r.Context()
 		requestLogger := app.logger.With(
 			slog.Group(
 				"request",
-				slog.String("id", uuid.New().String()),
+				slog.String("id", requestID.String()),
 				slog.String("method", r.Method),
 				slog.String("protocol", r.Proto),
 				slog.String("url", r.URL.Path),
+				slog.String("actor", actor),
 			),
 		)
 		loggerCtx := logging.WithLogger(rCtx, requestLogger)
+		// Changes made while handling the request are recorded in the audit log with the actor
+		// and request ID
+		loggerCtx = audit.WithRequestID(audit.WithActor(loggerCtx, actor), requestID)
 		requestLogger.Info("received request")
 
+		w.Header().Set(audit.RequestIDHeader, requestID.String())
+
 		next.ServeHTTP(w, r.WithContext(loggerCtx))
 	})
 }
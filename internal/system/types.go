@@ -3,13 +3,16 @@ package system
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"log/slog"
 	"net/http"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/r3d5un/Bookshelf/internal/books/data"
 	"github.com/r3d5un/Bookshelf/internal/books/types"
 	"github.com/r3d5un/Bookshelf/internal/config"
+	"github.com/r3d5un/Bookshelf/internal/events"
 	orchestratorData "github.com/r3d5un/Bookshelf/internal/orchestrator/data"
 	orchestratorTypes "github.com/r3d5un/Bookshelf/internal/orchestrator/types"
 )
@@ -21,6 +24,7 @@ type Monolith interface {
 	DB() *sql.DB
 	Config() *config.Config
 	Modules() *Modules
+	Events() *events.Bus
 }
 
 type Module interface {
@@ -34,6 +38,8 @@ type Modules struct {
 	Orchestrator Orchestrator
 }
 
+// Books manages the catalog. Updates with UpdatedAt set are only made if the record has not been
+// updated since, and return data.ErrEditConflict otherwise.
 type Books interface {
 	// Authors
 	CreateAuthor(ctx context.Context, data types.NewAuthorData) (*uuid.UUID, error)
@@ -41,18 +47,36 @@ type Books interface {
 	ReadAllAuthors(ctx context.Context, filters data.Filters) ([]*types.Author, error)
 	UpdateAuthor(ctx context.Context, data types.Author) (*types.Author, error)
 	DeleteAuthor(ctx context.Context, id uuid.UUID) error
+	RestoreAuthor(ctx context.Context, id uuid.UUID) (*types.Author, error)
+	ReadAuthorHistory(
+		ctx context.Context,
+		id uuid.UUID,
+		filters data.Filters,
+	) ([]*data.AuditEntry, error)
 	// Series
 	CreateSeries(ctx context.Context, newSeriesData types.NewSeriesData) (*uuid.UUID, error)
 	ReadSeries(ctx context.Context, seriesID uuid.UUID) (*types.Series, error)
 	ReadAllSeries(ctx context.Context, filters data.Filters) ([]*types.Series, error)
 	UpdateSeries(ctx context.Context, newSeriesData types.Series) (*types.Series, error)
 	DeleteSeries(ctx context.Context, id uuid.UUID) error
+	RestoreSeries(ctx context.Context, id uuid.UUID) (*types.Series, error)
+	ReadSeriesHistory(
+		ctx context.Context,
+		id uuid.UUID,
+		filters data.Filters,
+	) ([]*data.AuditEntry, error)
 	// Genre
 	CreateGenre(ctx context.Context, newGenreData types.NewGenreData) (*uuid.UUID, error)
 	ReadGenre(ctx context.Context, genreID uuid.UUID) (*types.Genre, error)
 	ReadAllGenre(ctx context.Context, filters data.Filters) ([]*types.Genre, error)
 	UpdateGenre(ctx context.Context, newGenreData types.Genre) (*types.Genre, error)
 	DeleteGenre(ctx context.Context, id uuid.UUID) error
+	RestoreGenre(ctx context.Context, id uuid.UUID) (*types.Genre, error)
+	ReadGenreHistory(
+		ctx context.Context,
+		id uuid.UUID,
+		filters data.Filters,
+	) ([]*data.AuditEntry, error)
 	// Books
 	CreateBook(ctx context.Context, newBookData types.Book) (*uuid.UUID, error)
 	ReadBook(ctx context.Context, genreID uuid.UUID) (*types.Book, error)
@@ -60,11 +84,82 @@ type Books interface {
 	ReadBooksBySeries(ctx context.Context, seriesID uuid.UUID) ([]*types.Book, error)
 	UpdateBook(ctx context.Context, newBookDAta types.Book) (*types.Book, error)
 	DeleteBook(ctx context.Context, id uuid.UUID) error
+	RestoreBook(ctx context.Context, id uuid.UUID) (*types.Book, error)
+	ReadBookHistory(
+		ctx context.Context,
+		id uuid.UUID,
+		filters data.Filters,
+	) ([]*data.AuditEntry, error)
+	// Book files
+	AttachBookFile(
+		ctx context.Context,
+		bookID uuid.UUID,
+		name string,
+		contentType string,
+		content []byte,
+	) (*data.BookFile, error)
+	ReadBookFiles(ctx context.Context, bookID uuid.UUID) ([]*data.BookFile, error)
+	ReadBookFileContent(ctx context.Context, id uuid.UUID) (*data.BookFile, []byte, error)
+	DeleteBookFile(ctx context.Context, id uuid.UUID) error
+	// Trash
+	ReadTrash(ctx context.Context, filters data.Filters) (*types.Trash, error)
+	PurgeTrash(ctx context.Context, before time.Time) (*types.PurgedTrash, error)
 }
 
 type UI interface{}
 
 type Orchestrator interface {
+	// Tasks
+	ReadTask(ctx context.Context, name string) (*orchestratorTypes.Task, error)
+	ReadAllTasks(
+		ctx context.Context,
+		filters orchestratorData.Filters,
+	) (*orchestratorTypes.TaskCollection, error)
+	UpdateTask(ctx context.Context, task orchestratorTypes.Task) (*orchestratorTypes.Task, error)
+	RunTask(
+		ctx context.Context,
+		name string,
+		payload json.RawMessage,
+		runAt *time.Time,
+	) (*orchestratorTypes.ScheduledTask, error)
+	// Task schedules
+	ReadTaskSchedule(ctx context.Context, id uuid.UUID) (*orchestratorTypes.TaskSchedule, error)
+	ReadAllTaskSchedules(
+		ctx context.Context,
+		filters orchestratorData.Filters,
+	) (*orchestratorTypes.TaskScheduleCollection, error)
+	CreateTaskSchedule(
+		ctx context.Context,
+		schedule orchestratorTypes.TaskSchedule,
+	) (*orchestratorTypes.TaskSchedule, error)
+	UpdateTaskSchedule(
+		ctx context.Context,
+		schedule orchestratorTypes.TaskSchedule,
+	) (*orchestratorTypes.TaskSchedule, error)
+	DeleteTaskSchedule(ctx context.Context, id uuid.UUID) (*orchestratorTypes.TaskSchedule, error)
+	// Webhooks
+	ReadWebhook(ctx context.Context, id uuid.UUID) (*orchestratorTypes.Webhook, error)
+	ReadAllWebhooks(
+		ctx context.Context,
+		filters orchestratorData.Filters,
+	) (*orchestratorTypes.WebhookCollection, error)
+	CreateWebhook(
+		ctx context.Context,
+		webhook orchestratorTypes.Webhook,
+	) (*orchestratorTypes.Webhook, error)
+	UpdateWebhook(
+		ctx context.Context,
+		webhook orchestratorTypes.Webhook,
+	) (*orchestratorTypes.Webhook, error)
+	DeleteWebhook(ctx context.Context, id uuid.UUID) (*orchestratorTypes.Webhook, error)
+	ReadAllWebhookDeliveries(
+		ctx context.Context,
+		webhookID uuid.UUID,
+		filters orchestratorData.Filters,
+	) (*orchestratorTypes.WebhookDeliveryCollection, error)
+	// Emit sends an event to the webhooks subscribed to it
+	Emit(ctx context.Context, event string, data any)
+	// Scheduled tasks
 	ReadScheduledTask(
 		ctx context.Context,
 		taskID uuid.UUID,
@@ -95,4 +190,32 @@ type Orchestrator interface {
 		ctx context.Context,
 		taskID uuid.UUID,
 	) (*orchestratorTypes.ScheduledTask, error)
+	CancelScheduledTask(
+		ctx context.Context,
+		taskID uuid.UUID,
+	) (*orchestratorTypes.ScheduledTask, error)
+	StopScheduledTask(
+		ctx context.Context,
+		taskID uuid.UUID,
+	) (*orchestratorTypes.ScheduledTask, error)
+	ReplayScheduledTask(
+		ctx context.Context,
+		taskID uuid.UUID,
+	) (*orchestratorTypes.ScheduledTask, error)
+	ReadWorkflow(ctx context.Context, taskID uuid.UUID) (*orchestratorTypes.Workflow, error)
+	ReadScheduledTaskLogs(
+		ctx context.Context,
+		taskID uuid.UUID,
+	) ([]*orchestratorTypes.TaskLog, error)
+	StreamScheduledTaskLogs(
+		ctx context.Context,
+		taskID uuid.UUID,
+		afterSeq int64,
+		send func(log *orchestratorTypes.TaskLog) error,
+		keepalive func() error,
+	) (*orchestratorTypes.ScheduledTask, error)
+	// Leader
+	ReadLeaderStatus(ctx context.Context) (*orchestratorTypes.LeaderStatus, error)
+	// Statistics
+	ReadTaskStats(ctx context.Context) ([]*orchestratorTypes.TaskStats, error)
 }
@@ -1,10 +1,15 @@
 package testing
 
 import (
+	"context"
+	"database/sql"
 	"fmt"
 	"os"
 	"path/filepath"
-	"strings"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/r3d5un/Bookshelf/internal/database"
+	"github.com/r3d5un/Bookshelf/migrations"
 )
 
 func FindProjectRoot() (string, error) {
@@ -27,17 +32,20 @@ func FindProjectRoot() (string, error) {
 	}
 }
 
-func ListUpMigrationScrips(dirPath string) (migrations []string, err error) {
-	files, err := os.ReadDir(dirPath)
+// MigrateDatabase applies the embedded migrations to the database at the given connection
+// string, using the same migration runner as the application.
+func MigrateDatabase(ctx context.Context, connString string) error {
+	db, err := sql.Open("pgx", connString)
 	if err != nil {
-		return []string{}, err
+		return err
 	}
+	defer db.Close()
 
-	for _, file := range files {
-		if !file.IsDir() && strings.HasSuffix(file.Name(), ".up.sql") {
-			migrations = append(migrations, fmt.Sprintf("%s/%s", dirPath, file.Name()))
-		}
+	migrator, err := database.NewMigrator(db, migrations.Files)
+	if err != nil {
+		return err
 	}
 
-	return migrations, nil
+	_, err = migrator.Up(ctx)
+	return err
 }
@@ -0,0 +1,125 @@
+// Package webhooks holds the events webhooks can subscribe to, and the signing and sending of
+// deliveries to webhook receivers.
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Events webhooks can subscribe to
+const (
+	BookCreated     = "book.created"
+	BookUpdated     = "book.updated"
+	BookDeleted     = "book.deleted"
+	BookRestored    = "book.restored"
+	BookFinished    = "book.finished"
+	TaskCompleted   = "task.completed"
+	TaskFailed      = "task.failed"
+	ImportCompleted = "import.completed"
+)
+
+// Events lists every event webhooks can subscribe to.
+//
+// BookFinished and ImportCompleted are reserved for reading progress and imports, and cannot be
+// subscribed to until those exist and send them.
+var Events = []string{
+	BookCreated,
+	BookUpdated,
+	BookDeleted,
+	BookRestored,
+	TaskCompleted,
+	TaskFailed,
+}
+
+// Headers set on every delivery
+const (
+	EventHeader     = "X-Bookshelf-Event"
+	DeliveryHeader  = "X-Bookshelf-Delivery"
+	SignatureHeader = "X-Bookshelf-Signature"
+)
+
+// signaturePrefix names the algorithm of the signature, leaving room to change it
+const signaturePrefix = "sha256="
+
+// ErrUnexpectedStatus is returned when a receiver answers a delivery with a status outside 2xx
+var ErrUnexpectedStatus = errors.New("unexpected webhook response status")
+
+// ValidEvent reports whether the event is one webhooks can subscribe to.
+func ValidEvent(event string) bool {
+	return slices.Contains(Events, event)
+}
+
+// Delivery is the body posted to a webhook.
+type Delivery struct {
+	// ID is the same for every attempt at delivering the event, and can be used by receivers to
+	// ignore repeated deliveries
+	ID         uuid.UUID       `json:"id"`
+	Event      string          `json:"event"`
+	OccurredAt time.Time       `json:"occurredAt"`
+	Data       json.RawMessage `json:"data"`
+}
+
+// Sign returns the signature of the body, which is the hex encoded HMAC-SHA256 of the body using
+// the secret, prefixed with "sha256=".
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify reports whether the signature is the signature of the body using the secret.
+func Verify(secret string, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, body)), []byte(signature))
+}
+
+// Send posts the delivery to the URL, signed with the secret, and returns the status code of the
+// response. A response outside 2xx returns the status code along with ErrUnexpectedStatus.
+func Send(
+	ctx context.Context,
+	client *http.Client,
+	url string,
+	secret string,
+	delivery Delivery,
+) (statusCode int, err error) {
+	body, err := json.Marshal(delivery)
+	if err != nil {
+		return 0, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, delivery.Event)
+	req.Header.Set(DeliveryHeader, delivery.ID.String())
+	req.Header.Set(SignatureHeader, Sign(secret, body))
+
+	res, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+	// Reading the body allows the connection to be reused
+	_, _ = io.Copy(io.Discard, res.Body)
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return res.StatusCode, fmt.Errorf("%w: %s", ErrUnexpectedStatus, res.Status)
+	}
+
+	return res.StatusCode, nil
+}
@@ -0,0 +1,126 @@
+package webhooks_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/r3d5un/Bookshelf/internal/webhooks"
+)
+
+func TestSend(t *testing.T) {
+	secret := "a-very-secret-signing-key"
+	delivery := webhooks.Delivery{
+		ID:         uuid.New(),
+		Event:      webhooks.BookCreated,
+		OccurredAt: time.Now().UTC(),
+		Data:       json.RawMessage(`{"title":"The Fellowship of the Ring"}`),
+	}
+
+	t.Run("Signed", func(t *testing.T) {
+		var received webhooks.Delivery
+		var verified bool
+		var event, deliveryID string
+
+		receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			verified = webhooks.Verify(secret, body, r.Header.Get(webhooks.SignatureHeader))
+			event = r.Header.Get(webhooks.EventHeader)
+			deliveryID = r.Header.Get(webhooks.DeliveryHeader)
+			_ = json.Unmarshal(body, &received)
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer receiver.Close()
+
+		statusCode, err := webhooks.Send(
+			context.Background(), receiver.Client(), receiver.URL, secret, delivery,
+		)
+		if err != nil {
+			t.Errorf("error occurred while sending delivery: %s\n", err)
+			return
+		}
+		if statusCode != http.StatusNoContent {
+			t.Errorf("expected status %d, got %d\n", http.StatusNoContent, statusCode)
+			return
+		}
+		if !verified {
+			t.Error("expected the signature to be verified with the secret")
+			return
+		}
+		if event != webhooks.BookCreated || deliveryID != delivery.ID.String() {
+			t.Errorf("unexpected headers: event %q, delivery %q\n", event, deliveryID)
+			return
+		}
+		if received.ID != delivery.ID || string(received.Data) != string(delivery.Data) {
+			t.Errorf("expected %+v, got %+v\n", delivery, received)
+			return
+		}
+	})
+
+	t.Run("UnexpectedStatus", func(t *testing.T) {
+		receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer receiver.Close()
+
+		statusCode, err := webhooks.Send(
+			context.Background(), receiver.Client(), receiver.URL, secret, delivery,
+		)
+		if !errors.Is(err, webhooks.ErrUnexpectedStatus) {
+			t.Errorf("expected %s, got %v\n", webhooks.ErrUnexpectedStatus, err)
+			return
+		}
+		if statusCode != http.StatusServiceUnavailable {
+			t.Errorf("expected status %d, got %d\n", http.StatusServiceUnavailable, statusCode)
+			return
+		}
+	})
+}
+
+func TestVerify(t *testing.T) {
+	body := []byte(`{"event":"task.failed"}`)
+	signature := webhooks.Sign("secret", body)
+
+	if !webhooks.Verify("secret", body, signature) {
+		t.Error("expected signature to be verified")
+		return
+	}
+	if webhooks.Verify("other-secret", body, signature) {
+		t.Error("expected signature of another secret to be rejected")
+		return
+	}
+	if webhooks.Verify("secret", []byte(`{"event":"book.created"}`), signature) {
+		t.Error("expected signature of another body to be rejected")
+		return
+	}
+}
+
+func TestValidEvent(t *testing.T) {
+	for _, tc := range []struct {
+		event string
+		valid bool
+	}{
+		{webhooks.BookCreated, true},
+		{webhooks.TaskFailed, true},
+		// Reserved events are not sent yet, and cannot be subscribed to
+		{webhooks.BookFinished, false},
+		{webhooks.ImportCompleted, false},
+		{"book.shelved", false},
+	} {
+		t.Run(tc.event, func(t *testing.T) {
+			if valid := webhooks.ValidEvent(tc.event); valid != tc.valid {
+				t.Errorf("expected %s to be valid %t, got %t", tc.event, tc.valid, valid)
+			}
+		})
+	}
+}
@@ -0,0 +1,24 @@
+ALTER TABLE orchestrator.task_queue
+    DROP COLUMN IF EXISTS attempt,
+    DROP COLUMN IF EXISTS last_error;
+
+ALTER TABLE orchestrator.tasks
+    DROP COLUMN IF EXISTS max_attempts,
+    DROP COLUMN IF EXISTS retry_backoff,
+    DROP COLUMN IF EXISTS retry_jitter;
+
+-- Enum values cannot be dropped, so the type is recreated without the dead state
+UPDATE orchestrator.task_queue
+SET state = 'error'
+WHERE state = 'dead';
+
+ALTER TYPE task_state RENAME TO task_state_old;
+
+CREATE TYPE task_state AS ENUM ('waiting', 'running', 'complete', 'stopped', 'error', 'skipped');
+
+ALTER TABLE orchestrator.task_queue
+    ALTER COLUMN state DROP DEFAULT,
+    ALTER COLUMN state TYPE task_state USING state::text::task_state,
+    ALTER COLUMN state SET DEFAULT 'waiting';
+
+DROP TYPE task_state_old;
@@ -0,0 +1,10 @@
+ALTER TYPE task_state ADD VALUE IF NOT EXISTS 'dead';
+
+ALTER TABLE orchestrator.tasks
+    ADD COLUMN IF NOT EXISTS max_attempts  INTEGER NOT NULL DEFAULT 1,
+    ADD COLUMN IF NOT EXISTS retry_backoff INTEGER NOT NULL DEFAULT 60,
+    ADD COLUMN IF NOT EXISTS retry_jitter  INTEGER NOT NULL DEFAULT 0;
+
+ALTER TABLE orchestrator.task_queue
+    ADD COLUMN IF NOT EXISTS attempt    INTEGER NOT NULL DEFAULT 1,
+    ADD COLUMN IF NOT EXISTS last_error TEXT    NULL;
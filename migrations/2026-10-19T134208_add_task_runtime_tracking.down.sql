@@ -0,0 +1,5 @@
+ALTER TABLE orchestrator.task_queue
+    DROP COLUMN IF EXISTS heartbeat_at;
+
+ALTER TABLE orchestrator.tasks
+    DROP COLUMN IF EXISTS max_runtime;
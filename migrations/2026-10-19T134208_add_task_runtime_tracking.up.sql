@@ -0,0 +1,5 @@
+ALTER TABLE orchestrator.tasks
+    ADD COLUMN IF NOT EXISTS max_runtime INTEGER NULL;
+
+ALTER TABLE orchestrator.task_queue
+    ADD COLUMN IF NOT EXISTS heartbeat_at TIMESTAMP NULL;
@@ -0,0 +1,2 @@
+ALTER TABLE orchestrator.task_queue
+    DROP COLUMN IF EXISTS payload;
@@ -0,0 +1,2 @@
+ALTER TABLE orchestrator.task_queue
+    ADD COLUMN IF NOT EXISTS payload JSONB NULL;
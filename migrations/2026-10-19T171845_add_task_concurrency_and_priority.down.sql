@@ -0,0 +1,3 @@
+ALTER TABLE orchestrator.tasks
+    DROP COLUMN IF EXISTS priority,
+    DROP COLUMN IF EXISTS max_concurrency;
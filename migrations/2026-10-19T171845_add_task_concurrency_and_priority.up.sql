@@ -0,0 +1,3 @@
+ALTER TABLE orchestrator.tasks
+    ADD COLUMN IF NOT EXISTS max_concurrency INTEGER NULL,
+    ADD COLUMN IF NOT EXISTS priority        INTEGER NOT NULL DEFAULT 0;
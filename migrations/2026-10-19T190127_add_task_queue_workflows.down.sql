@@ -0,0 +1,30 @@
+DROP INDEX IF EXISTS orchestrator.task_queue_after_id_idx;
+DROP INDEX IF EXISTS orchestrator.task_queue_parent_id_idx;
+
+ALTER TABLE orchestrator.task_queue
+    DROP CONSTRAINT IF EXISTS fk_task_queue_after,
+    DROP CONSTRAINT IF EXISTS fk_task_queue_parent;
+
+ALTER TABLE orchestrator.task_queue
+    DROP COLUMN IF EXISTS after_id,
+    DROP COLUMN IF EXISTS parent_id;
+
+-- Enum values cannot be dropped, so the type is recreated without the workflow states
+UPDATE orchestrator.task_queue
+SET state = 'skipped'
+WHERE state = 'blocked';
+
+UPDATE orchestrator.task_queue
+SET state = 'complete'
+WHERE state = 'awaiting';
+
+ALTER TYPE task_state RENAME TO task_state_old;
+
+CREATE TYPE task_state AS ENUM ('waiting', 'running', 'complete', 'stopped', 'error', 'skipped', 'dead');
+
+ALTER TABLE orchestrator.task_queue
+    ALTER COLUMN state DROP DEFAULT,
+    ALTER COLUMN state TYPE task_state USING state::text::task_state,
+    ALTER COLUMN state SET DEFAULT 'waiting';
+
+DROP TYPE task_state_old;
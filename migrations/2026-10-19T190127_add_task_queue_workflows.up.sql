@@ -0,0 +1,17 @@
+ALTER TYPE task_state ADD VALUE IF NOT EXISTS 'blocked';
+ALTER TYPE task_state ADD VALUE IF NOT EXISTS 'awaiting';
+
+ALTER TABLE orchestrator.task_queue
+    ADD COLUMN IF NOT EXISTS parent_id UUID NULL,
+    ADD COLUMN IF NOT EXISTS after_id  UUID NULL;
+
+ALTER TABLE orchestrator.task_queue
+    ADD CONSTRAINT fk_task_queue_parent
+        FOREIGN KEY (parent_id) REFERENCES orchestrator.task_queue (id)
+            ON DELETE CASCADE,
+    ADD CONSTRAINT fk_task_queue_after
+        FOREIGN KEY (after_id) REFERENCES orchestrator.task_queue (id)
+            ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS task_queue_parent_id_idx ON orchestrator.task_queue (parent_id);
+CREATE INDEX IF NOT EXISTS task_queue_after_id_idx ON orchestrator.task_queue (after_id);
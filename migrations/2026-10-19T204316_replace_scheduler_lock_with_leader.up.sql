@@ -0,0 +1,14 @@
+DROP TABLE IF EXISTS orchestrator.scheduler_lock;
+
+-- Single row table recording the current leader term, used as a fencing token by the leader
+CREATE TABLE IF NOT EXISTS orchestrator.leader
+(
+    id          BOOLEAN PRIMARY KEY DEFAULT TRUE CHECK (id),
+    term        BIGINT    NOT NULL DEFAULT 0,
+    instance_id UUID      NULL,
+    elected_at  TIMESTAMP NULL
+);
+
+INSERT INTO orchestrator.leader (id, term)
+VALUES (TRUE, 0)
+ON CONFLICT DO NOTHING;
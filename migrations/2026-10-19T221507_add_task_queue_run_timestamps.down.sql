@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS orchestrator.task_queue_name_state_idx;
+
+DROP TRIGGER IF EXISTS set_run_timestamps ON orchestrator.task_queue;
+
+DROP FUNCTION IF EXISTS update_task_run_timestamps;
+
+ALTER TABLE orchestrator.task_queue
+    DROP COLUMN IF EXISTS finished_at,
+    DROP COLUMN IF EXISTS started_at;
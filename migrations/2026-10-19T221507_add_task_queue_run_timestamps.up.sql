@@ -0,0 +1,34 @@
+ALTER TABLE orchestrator.task_queue
+    ADD COLUMN IF NOT EXISTS started_at  TIMESTAMP NULL,
+    ADD COLUMN IF NOT EXISTS finished_at TIMESTAMP NULL;
+
+-- Function and trigger to record when a run is started, and when it finishes. The finished states
+-- match the states considered finished by the orchestrator.
+CREATE OR REPLACE FUNCTION update_task_run_timestamps()
+    RETURNS TRIGGER AS
+$$
+BEGIN
+    IF NEW.state = OLD.state THEN
+        RETURN NEW;
+    END IF;
+
+    IF NEW.state = 'running' THEN
+        NEW.started_at = NOW();
+        NEW.finished_at = NULL;
+    ELSIF NEW.state IN ('complete', 'stopped', 'error', 'skipped', 'dead') THEN
+        NEW.finished_at = NOW();
+    ELSIF NEW.state IN ('waiting', 'blocked') THEN
+        NEW.finished_at = NULL;
+    END IF;
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER set_run_timestamps
+    BEFORE UPDATE OF state
+    ON orchestrator.task_queue
+    FOR EACH ROW
+EXECUTE FUNCTION update_task_run_timestamps();
+
+CREATE INDEX IF NOT EXISTS task_queue_name_state_idx ON orchestrator.task_queue (name, state);
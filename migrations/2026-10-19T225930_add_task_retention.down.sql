@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS orchestrator.task_queue_finished_at_idx;
+
+ALTER TABLE orchestrator.tasks
+    DROP COLUMN IF EXISTS failed_retention_days,
+    DROP COLUMN IF EXISTS retention_days;
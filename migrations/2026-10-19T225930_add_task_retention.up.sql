@@ -0,0 +1,5 @@
+ALTER TABLE orchestrator.tasks
+    ADD COLUMN IF NOT EXISTS retention_days        INTEGER NULL,
+    ADD COLUMN IF NOT EXISTS failed_retention_days INTEGER NULL;
+
+CREATE INDEX IF NOT EXISTS task_queue_finished_at_idx ON orchestrator.task_queue (finished_at);
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS orchestrator.task_logs_task_id_seq_idx;
+
+ALTER TABLE orchestrator.task_logs
+    DROP COLUMN IF EXISTS logged_at,
+    DROP COLUMN IF EXISTS seq;
+
+DROP SEQUENCE IF EXISTS orchestrator.task_logs_seq;
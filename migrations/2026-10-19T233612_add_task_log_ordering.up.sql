@@ -0,0 +1,28 @@
+CREATE SEQUENCE IF NOT EXISTS orchestrator.task_logs_seq;
+
+ALTER TABLE orchestrator.task_logs
+    ADD COLUMN IF NOT EXISTS seq       BIGINT    NULL,
+    ADD COLUMN IF NOT EXISTS logged_at TIMESTAMP NULL;
+
+-- Existing logs are numbered in the order they were logged
+UPDATE orchestrator.task_logs l
+SET seq       = o.seq,
+    logged_at = o.logged_at
+FROM (SELECT id,
+             (log ->> 'time')::timestamp                                   AS logged_at,
+             ROW_NUMBER() OVER (ORDER BY (log ->> 'time')::timestamp, id) AS seq
+      FROM orchestrator.task_logs) o
+WHERE l.id = o.id;
+
+SELECT SETVAL('orchestrator.task_logs_seq', COALESCE(MAX(seq), 0) + 1, FALSE)
+FROM orchestrator.task_logs;
+
+ALTER SEQUENCE orchestrator.task_logs_seq OWNED BY orchestrator.task_logs.seq;
+
+ALTER TABLE orchestrator.task_logs
+    ALTER COLUMN seq SET DEFAULT NEXTVAL('orchestrator.task_logs_seq'),
+    ALTER COLUMN seq SET NOT NULL,
+    ALTER COLUMN logged_at SET DEFAULT CURRENT_TIMESTAMP,
+    ALTER COLUMN logged_at SET NOT NULL;
+
+CREATE INDEX IF NOT EXISTS task_logs_task_id_seq_idx ON orchestrator.task_logs (task_id, seq);
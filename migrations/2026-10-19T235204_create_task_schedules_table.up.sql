@@ -0,0 +1,23 @@
+CREATE TABLE IF NOT EXISTS orchestrator.task_schedules
+(
+    id         UUID                  DEFAULT gen_random_uuid() PRIMARY KEY,
+    name       VARCHAR(128) NOT NULL UNIQUE,
+    task_name  VARCHAR(128) NOT NULL,
+    cron_expr  VARCHAR(128) NOT NULL,
+    payload    JSONB        NULL,
+    enabled    BOOLEAN      NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_task
+        FOREIGN KEY (task_name)
+            REFERENCES orchestrator.tasks (name)
+            ON DELETE CASCADE
+);
+
+CREATE TRIGGER set_updated_at
+    BEFORE UPDATE
+    ON orchestrator.task_schedules
+    FOR EACH ROW
+EXECUTE FUNCTION update_task_timestamp();
+
+CREATE INDEX IF NOT EXISTS task_schedules_task_name_idx ON orchestrator.task_schedules (task_name);
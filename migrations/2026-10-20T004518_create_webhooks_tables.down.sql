@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS orchestrator.webhook_deliveries;
+DROP TABLE IF EXISTS orchestrator.webhooks;
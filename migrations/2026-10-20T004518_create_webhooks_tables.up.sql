@@ -0,0 +1,38 @@
+CREATE TABLE IF NOT EXISTS orchestrator.webhooks
+(
+    id         UUID                  DEFAULT gen_random_uuid() PRIMARY KEY,
+    url        VARCHAR(2048) NOT NULL,
+    secret     VARCHAR(256)  NOT NULL,
+    events     TEXT[]        NOT NULL,
+    enabled    BOOLEAN       NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP     NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP     NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TRIGGER set_updated_at
+    BEFORE UPDATE
+    ON orchestrator.webhooks
+    FOR EACH ROW
+EXECUTE FUNCTION update_task_timestamp();
+
+CREATE INDEX IF NOT EXISTS webhooks_events_idx ON orchestrator.webhooks USING GIN (events);
+
+CREATE TABLE IF NOT EXISTS orchestrator.webhook_deliveries
+(
+    id          UUID               DEFAULT gen_random_uuid() PRIMARY KEY,
+    webhook_id  UUID      NOT NULL,
+    task_id     UUID      NOT NULL,
+    event       TEXT      NOT NULL,
+    attempt     INTEGER   NOT NULL,
+    status_code INTEGER   NULL,
+    error       TEXT      NULL,
+    duration_ms INTEGER   NOT NULL,
+    created_at  TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_webhook
+        FOREIGN KEY (webhook_id)
+            REFERENCES orchestrator.webhooks (id)
+            ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS webhook_deliveries_webhook_id_created_at_idx
+    ON orchestrator.webhook_deliveries (webhook_id, created_at);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS events.outbox;
+DROP SCHEMA IF EXISTS events;
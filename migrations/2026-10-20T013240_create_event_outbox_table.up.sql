@@ -0,0 +1,12 @@
+CREATE SCHEMA IF NOT EXISTS events;
+
+CREATE TABLE IF NOT EXISTS events.outbox
+(
+    id          BIGSERIAL PRIMARY KEY,
+    name        TEXT      NOT NULL,
+    payload     JSONB     NOT NULL,
+    instance_id UUID      NOT NULL,
+    created_at  TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS outbox_created_at_idx ON events.outbox (created_at);
@@ -0,0 +1,9 @@
+DROP TRIGGER IF EXISTS record_audit_log ON books.book_series;
+DROP TRIGGER IF EXISTS record_audit_log ON books.book_genres;
+DROP TRIGGER IF EXISTS record_audit_log ON books.book_authors;
+DROP TRIGGER IF EXISTS record_audit_log ON books.genres;
+DROP TRIGGER IF EXISTS record_audit_log ON books.series;
+DROP TRIGGER IF EXISTS record_audit_log ON books.authors;
+DROP TRIGGER IF EXISTS record_audit_log ON books.books;
+DROP FUNCTION IF EXISTS books.record_audit_log();
+DROP TABLE IF EXISTS books.audit_log;
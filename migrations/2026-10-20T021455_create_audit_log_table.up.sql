@@ -0,0 +1,116 @@
+CREATE TABLE IF NOT EXISTS books.audit_log
+(
+    id          BIGSERIAL PRIMARY KEY,
+    -- table the change was made to, e.g. books or book_authors
+    entity_type TEXT      NOT NULL,
+    -- the changed record, or the book of a changed link
+    entity_id   UUID      NOT NULL,
+    -- the author, genre or series of a changed link
+    related_id  UUID      NULL,
+    action      TEXT      NOT NULL,
+    actor       TEXT      NOT NULL,
+    request_id  UUID      NULL,
+    before      JSONB     NULL,
+    after       JSONB     NULL,
+    changes     JSONB     NOT NULL,
+    created_at  TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS audit_log_entity_id_idx ON books.audit_log (entity_id, id);
+CREATE INDEX IF NOT EXISTS audit_log_related_id_idx ON books.audit_log (related_id, id)
+    WHERE related_id IS NOT NULL;
+
+-- Records every change to a row of the table in the audit log. The first argument names the
+-- column identifying the entity, and the optional second argument the related column of a link.
+--
+-- The actor and request ID are read from the bookshelf.actor and bookshelf.request_id settings
+-- of the transaction, falling back on the database user for changes made outside the
+-- application. Cascading deletes are recorded as well, as they fire the trigger of each row.
+CREATE OR REPLACE FUNCTION books.record_audit_log()
+    RETURNS TRIGGER AS
+$$
+DECLARE
+    before_row JSONB;
+    after_row  JSONB;
+    row_data   JSONB;
+    changed    JSONB;
+BEGIN
+    IF TG_OP <> 'INSERT' THEN
+        before_row := to_jsonb(OLD);
+    END IF;
+    IF TG_OP <> 'DELETE' THEN
+        after_row := to_jsonb(NEW);
+    END IF;
+    row_data := COALESCE(after_row, before_row);
+
+    SELECT jsonb_object_agg(
+                   k.key,
+                   jsonb_build_object('before', before_row -> k.key, 'after', after_row -> k.key)
+           )
+    INTO changed
+    FROM jsonb_object_keys(row_data) AS k(key)
+    WHERE k.key <> 'updated_at'
+      AND (before_row -> k.key) IS DISTINCT FROM (after_row -> k.key);
+
+    -- Updates only touching the update timestamp change nothing worth recording
+    IF changed IS NULL THEN
+        RETURN NULL;
+    END IF;
+
+    INSERT INTO books.audit_log (entity_type, entity_id, related_id, action, actor, request_id,
+                                 before, after, changes)
+    VALUES (TG_TABLE_NAME,
+            (row_data ->> TG_ARGV[0])::UUID,
+            CASE WHEN TG_NARGS > 1 THEN (row_data ->> TG_ARGV[1])::UUID END,
+            lower(TG_OP),
+            COALESCE(NULLIF(current_setting('bookshelf.actor', TRUE), ''), current_user),
+            NULLIF(current_setting('bookshelf.request_id', TRUE), '')::UUID,
+            before_row,
+            after_row,
+            changed);
+
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER record_audit_log
+    AFTER INSERT OR UPDATE OR DELETE
+    ON books.books
+    FOR EACH ROW
+EXECUTE FUNCTION books.record_audit_log('id');
+
+CREATE TRIGGER record_audit_log
+    AFTER INSERT OR UPDATE OR DELETE
+    ON books.authors
+    FOR EACH ROW
+EXECUTE FUNCTION books.record_audit_log('id');
+
+CREATE TRIGGER record_audit_log
+    AFTER INSERT OR UPDATE OR DELETE
+    ON books.series
+    FOR EACH ROW
+EXECUTE FUNCTION books.record_audit_log('id');
+
+CREATE TRIGGER record_audit_log
+    AFTER INSERT OR UPDATE OR DELETE
+    ON books.genres
+    FOR EACH ROW
+EXECUTE FUNCTION books.record_audit_log('id');
+
+CREATE TRIGGER record_audit_log
+    AFTER INSERT OR UPDATE OR DELETE
+    ON books.book_authors
+    FOR EACH ROW
+EXECUTE FUNCTION books.record_audit_log('book_id', 'author_id');
+
+CREATE TRIGGER record_audit_log
+    AFTER INSERT OR UPDATE OR DELETE
+    ON books.book_genres
+    FOR EACH ROW
+EXECUTE FUNCTION books.record_audit_log('book_id', 'genres_id');
+
+CREATE TRIGGER record_audit_log
+    AFTER INSERT OR UPDATE OR DELETE
+    ON books.book_series
+    FOR EACH ROW
+EXECUTE FUNCTION books.record_audit_log('book_id', 'series_id');
@@ -0,0 +1,70 @@
+DROP INDEX IF EXISTS books.genres_name_key;
+ALTER TABLE books.genres
+    ADD CONSTRAINT genres_name_key UNIQUE (name);
+
+DROP INDEX IF EXISTS books.genres_deleted_at_idx;
+DROP INDEX IF EXISTS books.series_deleted_at_idx;
+DROP INDEX IF EXISTS books.authors_deleted_at_idx;
+DROP INDEX IF EXISTS books.books_deleted_at_idx;
+
+ALTER TABLE books.genres
+    DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE books.series
+    DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE books.authors
+    DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE books.books
+    DROP COLUMN IF EXISTS deleted_at;
+
+-- Records every change to a row of the table in the audit log. The first argument names the
+-- column identifying the entity, and the optional second argument the related column of a link.
+--
+-- The actor and request ID are read from the bookshelf.actor and bookshelf.request_id settings
+-- of the transaction, falling back on the database user for changes made outside the
+-- application. Cascading deletes are recorded as well, as they fire the trigger of each row.
+CREATE OR REPLACE FUNCTION books.record_audit_log()
+    RETURNS TRIGGER AS
+$$
+DECLARE
+    before_row JSONB;
+    after_row  JSONB;
+    row_data   JSONB;
+    changed    JSONB;
+BEGIN
+    IF TG_OP <> 'INSERT' THEN
+        before_row := to_jsonb(OLD);
+    END IF;
+    IF TG_OP <> 'DELETE' THEN
+        after_row := to_jsonb(NEW);
+    END IF;
+    row_data := COALESCE(after_row, before_row);
+
+    SELECT jsonb_object_agg(
+                   k.key,
+                   jsonb_build_object('before', before_row -> k.key, 'after', after_row -> k.key)
+           )
+    INTO changed
+    FROM jsonb_object_keys(row_data) AS k(key)
+    WHERE k.key <> 'updated_at'
+      AND (before_row -> k.key) IS DISTINCT FROM (after_row -> k.key);
+
+    -- Updates only touching the update timestamp change nothing worth recording
+    IF changed IS NULL THEN
+        RETURN NULL;
+    END IF;
+
+    INSERT INTO books.audit_log (entity_type, entity_id, related_id, action, actor, request_id,
+                                 before, after, changes)
+    VALUES (TG_TABLE_NAME,
+            (row_data ->> TG_ARGV[0])::UUID,
+            CASE WHEN TG_NARGS > 1 THEN (row_data ->> TG_ARGV[1])::UUID END,
+            lower(TG_OP),
+            COALESCE(NULLIF(current_setting('bookshelf.actor', TRUE), ''), current_user),
+            NULLIF(current_setting('bookshelf.request_id', TRUE), '')::UUID,
+            before_row,
+            after_row,
+            changed);
+
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
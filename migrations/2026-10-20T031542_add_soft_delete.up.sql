@@ -0,0 +1,84 @@
+ALTER TABLE books.books
+    ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP NULL;
+ALTER TABLE books.authors
+    ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP NULL;
+ALTER TABLE books.series
+    ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP NULL;
+ALTER TABLE books.genres
+    ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP NULL;
+
+CREATE INDEX IF NOT EXISTS books_deleted_at_idx ON books.books (deleted_at)
+    WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS authors_deleted_at_idx ON books.authors (deleted_at)
+    WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS series_deleted_at_idx ON books.series (deleted_at)
+    WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS genres_deleted_at_idx ON books.genres (deleted_at)
+    WHERE deleted_at IS NOT NULL;
+
+-- Genres in the trash must not block new genres with the same name
+ALTER TABLE books.genres
+    DROP CONSTRAINT IF EXISTS genres_name_key;
+CREATE UNIQUE INDEX IF NOT EXISTS genres_name_key ON books.genres (name)
+    WHERE deleted_at IS NULL;
+
+-- Records every change to a row of the table in the audit log, as in the previous version of the
+-- function. Moving a record to the trash is recorded as a delete, taking it out as a restore, and
+-- deleting it from the trash as a purge.
+CREATE OR REPLACE FUNCTION books.record_audit_log()
+    RETURNS TRIGGER AS
+$$
+DECLARE
+    before_row   JSONB;
+    after_row    JSONB;
+    row_data     JSONB;
+    changed      JSONB;
+    audit_action TEXT := lower(TG_OP);
+BEGIN
+    IF TG_OP <> 'INSERT' THEN
+        before_row := to_jsonb(OLD);
+    END IF;
+    IF TG_OP <> 'DELETE' THEN
+        after_row := to_jsonb(NEW);
+    END IF;
+    row_data := COALESCE(after_row, before_row);
+
+    IF TG_OP = 'UPDATE' AND before_row ? 'deleted_at' THEN
+        IF before_row ->> 'deleted_at' IS NULL AND after_row ->> 'deleted_at' IS NOT NULL THEN
+            audit_action := 'delete';
+        ELSIF before_row ->> 'deleted_at' IS NOT NULL AND after_row ->> 'deleted_at' IS NULL THEN
+            audit_action := 'restore';
+        END IF;
+    ELSIF TG_OP = 'DELETE' AND before_row ? 'deleted_at' THEN
+        audit_action := 'purge';
+    END IF;
+
+    SELECT jsonb_object_agg(
+                   k.key,
+                   jsonb_build_object('before', before_row -> k.key, 'after', after_row -> k.key)
+           )
+    INTO changed
+    FROM jsonb_object_keys(row_data) AS k(key)
+    WHERE k.key <> 'updated_at'
+      AND (before_row -> k.key) IS DISTINCT FROM (after_row -> k.key);
+
+    -- Updates only touching the update timestamp change nothing worth recording
+    IF changed IS NULL THEN
+        RETURN NULL;
+    END IF;
+
+    INSERT INTO books.audit_log (entity_type, entity_id, related_id, action, actor, request_id,
+                                 before, after, changes)
+    VALUES (TG_TABLE_NAME,
+            (row_data ->> TG_ARGV[0])::UUID,
+            CASE WHEN TG_NARGS > 1 THEN (row_data ->> TG_ARGV[1])::UUID END,
+            audit_action,
+            COALESCE(NULLIF(current_setting('bookshelf.actor', TRUE), ''), current_user),
+            NULLIF(current_setting('bookshelf.request_id', TRUE), '')::UUID,
+            before_row,
+            after_row,
+            changed);
+
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS idempotency.keys;
+DROP SCHEMA IF EXISTS idempotency;
@@ -0,0 +1,15 @@
+CREATE SCHEMA IF NOT EXISTS idempotency;
+
+CREATE TABLE IF NOT EXISTS idempotency.keys
+(
+    actor       TEXT      NOT NULL,
+    key         TEXT      NOT NULL,
+    fingerprint TEXT      NOT NULL,
+    status      INTEGER   NULL,
+    header      JSONB     NULL,
+    body        BYTEA     NULL,
+    created_at  TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (actor, key)
+);
+
+CREATE INDEX IF NOT EXISTS keys_created_at_idx ON idempotency.keys (created_at);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS books.book_file_contents;
+DROP TABLE IF EXISTS books.book_files;
@@ -0,0 +1,26 @@
+CREATE TABLE IF NOT EXISTS books.book_files
+(
+    id           UUID                  DEFAULT gen_random_uuid() PRIMARY KEY,
+    book_id      UUID         NOT NULL REFERENCES books.books (id) ON DELETE CASCADE,
+    name         VARCHAR(512) NOT NULL,
+    content_type VARCHAR(256) NOT NULL,
+    size         BIGINT       NOT NULL,
+    -- hex encoded SHA-256 digest of the content
+    checksum     CHAR(64)     NOT NULL,
+    created_at   TIMESTAMP    NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS book_files_book_id_idx ON books.book_files (book_id);
+
+-- The content is kept apart from the file, so that the audit log records the file without it
+CREATE TABLE IF NOT EXISTS books.book_file_contents
+(
+    file_id UUID  PRIMARY KEY REFERENCES books.book_files (id) ON DELETE CASCADE,
+    content BYTEA NOT NULL
+);
+
+CREATE TRIGGER record_audit_log
+    AFTER INSERT OR UPDATE OR DELETE
+    ON books.book_files
+    FOR EACH ROW
+EXECUTE FUNCTION books.record_audit_log('book_id', 'id');
@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS events.outbox_claims
+(
+    event_id   BIGINT    NOT NULL REFERENCES events.outbox (id) ON DELETE CASCADE,
+    consumer   TEXT      NOT NULL,
+    claimed_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (event_id, consumer)
+);
@@ -0,0 +1,4 @@
+DROP TRIGGER IF EXISTS touch_linked_records ON books.book_series;
+DROP TRIGGER IF EXISTS touch_linked_records ON books.book_genres;
+DROP TRIGGER IF EXISTS touch_linked_records ON books.book_authors;
+DROP FUNCTION IF EXISTS books.touch_linked_records();
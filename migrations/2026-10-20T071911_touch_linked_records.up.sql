@@ -0,0 +1,46 @@
+-- Bumps the update time of the book and of the related author, genre or series when a link
+-- between them is created, changed or deleted, as each nests the other when read, and its entity
+-- tag is derived from its update time. The first argument names the table of the related record,
+-- and the second the column of the link referring to it.
+--
+-- Updates only touching the update timestamp are not recorded in the audit log.
+CREATE OR REPLACE FUNCTION books.touch_linked_records()
+    RETURNS TRIGGER AS
+$$
+DECLARE
+    link JSONB;
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        link := to_jsonb(OLD);
+    ELSE
+        link := to_jsonb(NEW);
+    END IF;
+
+    UPDATE books.books
+    SET updated_at = NOW()
+    WHERE id = (link ->> 'book_id')::UUID;
+
+    EXECUTE format('UPDATE books.%I SET updated_at = NOW() WHERE id = $1', TG_ARGV[0])
+        USING (link ->> TG_ARGV[1])::UUID;
+
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER touch_linked_records
+    AFTER INSERT OR UPDATE OR DELETE
+    ON books.book_authors
+    FOR EACH ROW
+EXECUTE FUNCTION books.touch_linked_records('authors', 'author_id');
+
+CREATE TRIGGER touch_linked_records
+    AFTER INSERT OR UPDATE OR DELETE
+    ON books.book_genres
+    FOR EACH ROW
+EXECUTE FUNCTION books.touch_linked_records('genres', 'genres_id');
+
+CREATE TRIGGER touch_linked_records
+    AFTER INSERT OR UPDATE OR DELETE
+    ON books.book_series
+    FOR EACH ROW
+EXECUTE FUNCTION books.touch_linked_records('series', 'series_id');
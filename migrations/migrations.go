@@ -0,0 +1,8 @@
+// Package migrations embeds the SQL migration scripts, so that the migrations are shipped
+// with the application binary.
+package migrations
+
+import "embed"
+
+//go:embed *.sql
+var Files embed.FS